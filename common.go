@@ -13,8 +13,50 @@ import (
 
 const modeFM = "FM"
 const modeAM = "AM"
+const modePSK = "PSK"
 
-var modes = []string{modeFM, modeAM}
+var modes = []string{modeFM, modeAM, modePSK}
+
+const nbModeBlank = "blank"
+const nbModeInterpolate = "interpolate"
+
+var nbModes = []string{nbModeBlank, nbModeInterpolate}
+
+const notchModeOff = "off"
+const notchModeAuto = "auto"
+const notchModeManual = "manual"
+
+var notchModes = []string{notchModeOff, notchModeAuto, notchModeManual}
+
+const squelchModePower = "power"
+const squelchModeNoise = "noise"
+
+var squelchModes = []string{squelchModePower, squelchModeNoise}
+
+const fftWindowRectangular = "rectangular"
+const fftWindowHamming = "hamming"
+const fftWindowHann = "hann"
+const fftWindowBlackmanHarris = "blackmanharris"
+const fftWindowFlatTop = "flattop"
+
+var fftWindows = []string{fftWindowRectangular, fftWindowHamming, fftWindowHann, fftWindowBlackmanHarris, fftWindowFlatTop}
+
+const fftAveragingExponential = "exponential"
+const fftAveragingLinear = "linear"
+const fftAveragingPeak = "peak"
+const fftAveragingMin = "min"
+
+var fftAveragingModes = []string{fftAveragingExponential, fftAveragingLinear, fftAveragingPeak, fftAveragingMin}
+
+const fftBinModeMax = "max"
+const fftBinModeMean = "mean"
+
+var fftBinModes = []string{fftBinModeMax, fftBinModeMean}
+
+const cfarModeCA = "ca"
+const cfarModeOS = "os"
+
+var cfarModes = []string{cfarModeCA, cfarModeOS}
 
 // endregion
 
@@ -24,6 +66,13 @@ const envCenterFrequency = "CENTER_FREQUENCY"
 const envFFTFrequency = "FFT_FREQUENCY"
 const envHTTPAddr = "HTTP_ADDRESS"
 const envDisplayPixels = "DISPLAY_PIXELS"
+const envIQCorrection = "IQ_CORRECTION"
+const envFFTSize = "FFT_SIZE"
+const envFFTWindow = "FFT_WINDOW"
+const envFFTOverlap = "FFT_OVERLAP"
+const envFFTAveraging = "FFT_AVERAGING"
+const envFFTAverageFrames = "FFT_AVERAGE_FRAMES"
+const envFFTBinMode = "FFT_BIN_MODE"
 const envDecimationStage = "DECIMATION_STAGE"
 const envFFTDecimationStage = "FFT_DECIMATION_STAGE"
 const envOutputRate = "OUTPUT_RATE"
@@ -38,15 +87,67 @@ const envPreset = "PRESET"
 
 const envSquelch = "SQUELCH"
 const envSquelchAlpha = "SQUELCH_ALPHA"
+const envSquelchHysteresis = "SQUELCH_HYSTERESIS"
+const envSquelchAttack = "SQUELCH_ATTACK"
+const envSquelchHang = "SQUELCH_HANG"
+
+// region Noise Blanker Options
+const envNoiseBlanker = "NOISE_BLANKER"
+const envNBThreshold = "NB_THRESHOLD"
+const envNBWidth = "NB_WIDTH"
+const envNBMode = "NB_MODE"
+const envNoiseReduction = "NOISE_REDUCTION"
+
+// endregion
+
+// region Audio Level Options
+const envAudioAGC = "AUDIO_AGC"
+const envAGCAttack = "AGC_ATTACK"
+const envAGCDecay = "AGC_DECAY"
+const envAGCHang = "AGC_HANG"
+const envAGCTarget = "AGC_TARGET"
+const envAGCMaxGain = "AGC_MAX_GAIN"
+const envVolume = "VOLUME"
+const envLimiter = "LIMITER"
+const envLimiterThreshold = "LIMITER_THRESHOLD"
+
+// endregion
+
+// region Signal Detection Options
+const envSignalDetection = "SIGNAL_DETECTION"
+const envCFARMode = "CFAR_MODE"
+const envCFARThreshold = "CFAR_THRESHOLD"
+
+// endregion
+
+// region Burst Detection Options
+const envBurstDetection = "BURST_DETECTION"
+const envBurstFFTSize = "BURST_FFT_SIZE"
+const envBurstMinDuration = "BURST_MIN_DURATION"
+const envBurstMaxDuration = "BURST_MAX_DURATION"
+
+// endregion
 
 // region FM Demodulator Options
 const envFMDeviation = "FM_DEVIATION"
 const envFMTau = "FM_TAU"
+const envAFC = "AFC"
+const envAFCPullIn = "AFC_PULL_IN"
+const envSquelchMode = "SQUELCH_MODE"
+const envNoiseSquelch = "NOISE_SQUELCH"
 
 // endregion
 
 // region AM Demodulator Options
 const envAMAudioCut = "AM_AUDIO_CUT"
+const envAMNotch = "AM_NOTCH"
+const envAMNotchFrequency = "AM_NOTCH_FREQUENCY"
+
+// endregion
+
+// region PSK Demodulator Options
+const envPSKOrder = "PSK_ORDER"
+const envPSKSymbolRate = "PSK_SYMBOL_RATE"
 
 // endregion
 
@@ -56,6 +157,13 @@ const envAMAudioCut = "AM_AUDIO_CUT"
 var addrFlag = flag.String("httpAddr", "localhost:8080", "http service address")
 var radioserverhostFlag = flag.String("radioserver", "localhost:4050", "radioserver address")
 var displayPixelsFlag = flag.Uint("displayPixels", 512, "Width in pixels of the FFT")
+var iqCorrectionFlag = flag.Bool("iqCorrection", false, "Enable DC Offset and IQ imbalance correction for both channel and FFT")
+var fftSizeFlag = flag.Uint("fftSize", 0, "Number of FFT bins (0 to use displayPixels)")
+var fftWindowFlag = flag.String("fftWindow", fftWindowBlackmanHarris, fmt.Sprintf("FFT Window: %s", fftWindows))
+var fftOverlapFlag = flag.Float64("fftOverlap", 0.5, "FFT Frame overlap (0 to 0.95)")
+var fftAveragingFlag = flag.String("fftAveraging", fftAveragingExponential, fmt.Sprintf("FFT Averaging Mode: %s", fftAveragingModes))
+var fftAverageFramesFlag = flag.Uint("fftAverageFrames", 2, "Number of FFT frames averaged")
+var fftBinModeFlag = flag.String("fftBinMode", fftBinModeMax, fmt.Sprintf("FFT bins to display pixels decimation: %s", fftBinModes))
 
 var channelFrequencyFlag = flag.Uint("channelFrequency", 106.3e6, "Channel (IQ) Center Frequency")
 var displayFrequencyFlag = flag.Uint("fftFrequency", 106.3e6, "FFT Center Frequency")
@@ -79,16 +187,68 @@ var presetFlag = flag.String("preset", "none", "presetStruct for Demodulator Par
 
 var squelchFlag = flag.Float64("squelch", -150, "Demodulator Squelch in dB")
 var squelchAlphaFlag = flag.Float64("squelchAlpha", 0.001, "Demodulator Squelch Filter Alpha")
+var squelchHysteresisFlag = flag.Float64("squelchHysteresis", 0, "Demodulator Squelch Hysteresis in dB")
+var squelchAttackFlag = flag.Float64("squelchAttack", 0, "Demodulator Squelch Attack Time in seconds")
+var squelchHangFlag = flag.Float64("squelchHang", 0, "Demodulator Squelch Hang Time in seconds")
+
+// region Signal Detection Flags
+var signalDetectionFlag = flag.Bool("signalDetection", false, "Enable the signal detection over the FFT")
+var cfarModeFlag = flag.String("cfarMode", cfarModeOS, fmt.Sprintf("Signal Detection CFAR Mode: %s", cfarModes))
+var cfarThresholdFlag = flag.Float64("cfarThreshold", 10, "Signal Detection threshold in dB over the noise")
+
+// endregion
+
+// region Burst Detection Flags
+var burstDetectionFlag = flag.Bool("burstDetection", false, "Enable the burst detection and extraction over the channel IQ")
+var burstFFTSizeFlag = flag.Uint("burstFFTSize", 256, "Burst Detection FFT size (its duration is the time resolution)")
+var burstMinDurationFlag = flag.Float64("burstMinDuration", 0.001, "Burst Detection min burst duration in seconds")
+var burstMaxDurationFlag = flag.Float64("burstMaxDuration", 1, "Burst Detection max burst duration in seconds (longer bursts are cut)")
+
+// endregion
+
+// region Noise Blanker Flags
+var noiseBlankerFlag = flag.Bool("noiseBlanker", false, "Enable the Noise Blanker before the demodulator")
+var nbThresholdFlag = flag.Float64("nbThreshold", 10, "Noise Blanker threshold (ratio over the average magnitude)")
+var nbWidthFlag = flag.Uint("nbWidth", 8, "Noise Blanker width in samples")
+var nbModeFlag = flag.String("nbMode", nbModeBlank, fmt.Sprintf("Noise Blanker Mode: %s", nbModes))
+var noiseReductionFlag = flag.Float64("noiseReduction", 0, "Audio Noise Reduction amount in dB (0 to disable)")
+
+// endregion
+
+// region Audio Level Flags
+var audioAGCFlag = flag.Bool("audioAGC", false, "Enable the Audio AGC after the demodulator")
+var agcAttackFlag = flag.Float64("agcAttack", 0.005, "Audio AGC attack time in seconds")
+var agcDecayFlag = flag.Float64("agcDecay", 0.5, "Audio AGC decay time in seconds")
+var agcHangFlag = flag.Float64("agcHang", 0.2, "Audio AGC hang time in seconds")
+var agcTargetFlag = flag.Float64("agcTarget", -6, "Audio AGC target peak level in dB full scale")
+var agcMaxGainFlag = flag.Float64("agcMaxGain", 40, "Audio AGC max gain in dB")
+var volumeFlag = flag.Float64("volume", 0, "Audio Volume in dB")
+var limiterFlag = flag.Bool("limiter", false, "Enable the Audio Soft Limiter")
+var limiterThresholdFlag = flag.Float64("limiterThreshold", 0.8, "Audio Soft Limiter threshold (0 to 1)")
+
+// endregion
 
 // region FM Demodulator Flags
 var filterBandwidthFlag = flag.Uint("filterBandwidth", 120e3, "First Stage Filter Bandwidth in Hertz")
 var fmDeviationFlag = flag.Uint("fmDeviation", 75e3, "FM Demodulator Max Deviation in Hertz")
 var fmTauFlag = flag.Float64("fmTau", 75e-6, "FM Demodulator Tau in seconds (0 to disable)")
+var afcFlag = flag.Bool("afc", false, "Enable Automatic Frequency Control")
+var afcPullInFlag = flag.Float64("afcPullIn", 5000, "Automatic Frequency Control max correction in Hertz")
+var squelchModeFlag = flag.String("squelchMode", squelchModePower, fmt.Sprintf("FM Squelch Mode: %s", squelchModes))
+var noiseSquelchFlag = flag.Float64("noiseSquelch", -10, "FM Noise Squelch threshold in dB (noise mode)")
 
 // endregion
 
 // region AM Demodulator Flags
 var amAudioCutFlag = flag.Float64("amAudioCut", 5000, "AM Low Pass Filter Cut")
+var amNotchFlag = flag.String("amNotch", notchModeOff, fmt.Sprintf("AM Audio Notch Mode: %s", notchModes))
+var amNotchFrequencyFlag = flag.Float64("amNotchFrequency", 1000, "AM Audio Notch Frequency in Hertz (manual mode)")
+
+// endregion
+
+// region PSK Demodulator Flags
+var pskOrderFlag = flag.Uint("pskOrder", 4, "PSK Demodulator order: 2 (BPSK), 4 (QPSK) or 8 (8PSK)")
+var pskSymbolRateFlag = flag.Float64("pskSymbolRate", 4800, "PSK Demodulator Symbol Rate in symbols / second")
 
 // endregion
 
@@ -97,6 +257,13 @@ var amAudioCutFlag = flag.Float64("amAudioCut", 5000, "AM Low Pass Filter Cut")
 var httpAddr string
 var radioserverhost string
 var displayPixels uint
+var iqCorrection bool
+var fftSize uint
+var fftWindow string
+var fftOverlap float32
+var fftAveraging string
+var fftAverageFrames uint
+var fftBinMode string
 
 var channelFrequency uint
 var displayFrequency uint
@@ -109,11 +276,48 @@ var outputRate uint
 var filterBandwidth uint
 var squelch float32
 var squelchAlpha float32
+var squelchHysteresis float32
+var squelchAttack float32
+var squelchHang float32
+
+var signalDetection bool
+var cfarMode string
+var cfarThreshold float32
+
+var burstDetection bool
+var burstFFTSize uint
+var burstMinDuration float32
+var burstMaxDuration float32
+
+var noiseBlanker bool
+var nbThreshold float32
+var nbWidth uint
+var nbMode string
+var noiseReduction float32
+
+var audioAGC bool
+var agcAttack float32
+var agcDecay float32
+var agcHang float32
+var agcTarget float32
+var agcMaxGain float32
+var volume float32
+var limiter bool
+var limiterThreshold float32
 
 var fmDeviation uint
 var fmTau float32
+var afc bool
+var afcPullIn float32
+var squelchMode string
+var noiseSquelch float32
 
 var amAudioCut float32
+var amNotch string
+var amNotchFrequency float32
+
+var pskOrder uint
+var pskSymbolRate float32
 
 var stationName string
 var webCanControl bool
@@ -139,11 +343,13 @@ func applyPreset(preset presetStruct) {
 	case modeAM:
 		applyAMPreset(preset)
 	}
+
+	applyAudioPreset(preset)
 }
 
 func applyFMPreset(preset presetStruct) {
 	log.Printf("PRESET: Setting FM Tau to %f\n", preset.demodOptions["tau"].(float64))
-	log.Printf("PRESET: Setting FM Devation to %f Hz\n", preset.demodOptions["devation"].(float64))
+	log.Printf("PRESET: Setting FM Deviation to %f Hz\n", preset.demodOptions["deviation"].(float64))
 	os.Setenv(envFMTau, strconv.FormatFloat(preset.demodOptions["tau"].(float64), 'E', -1, 32))
 	os.Setenv(envFMDeviation, strconv.FormatFloat(preset.demodOptions["deviation"].(float64), 'E', -1, 32))
 }
@@ -153,10 +359,61 @@ func applyAMPreset(preset presetStruct) {
 	os.Setenv(envAMAudioCut, strconv.FormatFloat(preset.demodOptions["audioCut"].(float64), 'E', -1, 32))
 }
 
+// applyAudioPreset sets the audio level options present in the preset, except the ones set by the user
+func applyAudioPreset(preset presetStruct) {
+	var userSet = map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		userSet[f.Name] = true
+	})
+
+	var boolOptions = []struct {
+		key  string
+		env  string
+		flag string
+		name string
+	}{
+		{"agc", envAudioAGC, "audioAGC", "Audio AGC"},
+		{"limiter", envLimiter, "limiter", "Audio Limiter"},
+	}
+
+	for _, o := range boolOptions {
+		if v, ok := preset.audioOptions[o.key]; ok && os.Getenv(o.env) == "" && !userSet[o.flag] {
+			log.Printf("PRESET: Setting %s to %t\n", o.name, v.(bool))
+			os.Setenv(o.env, strconv.FormatBool(v.(bool)))
+		}
+	}
+
+	var floatOptions = []struct {
+		key  string
+		env  string
+		flag string
+		name string
+	}{
+		{"agcAttack", envAGCAttack, "agcAttack", "Audio AGC Attack"},
+		{"agcDecay", envAGCDecay, "agcDecay", "Audio AGC Decay"},
+		{"agcHang", envAGCHang, "agcHang", "Audio AGC Hang"},
+		{"agcTarget", envAGCTarget, "agcTarget", "Audio AGC Target"},
+		{"agcMaxGain", envAGCMaxGain, "agcMaxGain", "Audio AGC Max Gain"},
+	}
+
+	for _, o := range floatOptions {
+		if v, ok := preset.audioOptions[o.key]; ok && os.Getenv(o.env) == "" && !userSet[o.flag] {
+			log.Printf("PRESET: Setting %s to %f\n", o.name, v.(float64))
+			os.Setenv(o.env, strconv.FormatFloat(v.(float64), 'E', -1, 32))
+		}
+	}
+}
+
 func setEnv() {
 	flag.Parse()
 	// region Parse presetStruct
-	if val, ok := presets[preset]; ok {
+	// The preset variable is only filled at the end, so the selected preset comes from the environment or the flag
+	var selectedPreset = os.Getenv(envPreset)
+	if selectedPreset == "" {
+		selectedPreset = *presetFlag
+	}
+
+	if val, ok := presets[selectedPreset]; ok {
 		log.Printf("Selected %s preset.\n", val.name)
 		applyPreset(val)
 	}
@@ -186,6 +443,34 @@ func setEnv() {
 		os.Setenv(envDisplayPixels, strconv.FormatUint(uint64(*displayPixelsFlag), 10))
 	}
 
+	if os.Getenv(envIQCorrection) == "" {
+		os.Setenv(envIQCorrection, strconv.FormatBool(*iqCorrectionFlag))
+	}
+
+	if os.Getenv(envFFTSize) == "" {
+		os.Setenv(envFFTSize, strconv.FormatUint(uint64(*fftSizeFlag), 10))
+	}
+
+	if os.Getenv(envFFTWindow) == "" {
+		os.Setenv(envFFTWindow, *fftWindowFlag)
+	}
+
+	if os.Getenv(envFFTOverlap) == "" {
+		os.Setenv(envFFTOverlap, strconv.FormatFloat(*fftOverlapFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envFFTAveraging) == "" {
+		os.Setenv(envFFTAveraging, *fftAveragingFlag)
+	}
+
+	if os.Getenv(envFFTAverageFrames) == "" {
+		os.Setenv(envFFTAverageFrames, strconv.FormatUint(uint64(*fftAverageFramesFlag), 10))
+	}
+
+	if os.Getenv(envFFTBinMode) == "" {
+		os.Setenv(envFFTBinMode, *fftBinModeFlag)
+	}
+
 	if os.Getenv(envDecimationStage) == "" {
 		os.Setenv(envDecimationStage, strconv.FormatUint(uint64(*channelDecimationStageFlag), 10))
 	}
@@ -214,6 +499,14 @@ func setEnv() {
 		os.Setenv(envFMTau, strconv.FormatFloat(*fmTauFlag, 'E', -1, 32))
 	}
 
+	if os.Getenv(envAFC) == "" {
+		os.Setenv(envAFC, strconv.FormatBool(*afcFlag))
+	}
+
+	if os.Getenv(envAFCPullIn) == "" {
+		os.Setenv(envAFCPullIn, strconv.FormatFloat(*afcPullInFlag, 'E', -1, 32))
+	}
+
 	if os.Getenv(envSquelch) == "" {
 		os.Setenv(envSquelch, strconv.FormatFloat(*squelchFlag, 'E', -1, 32))
 	}
@@ -222,10 +515,130 @@ func setEnv() {
 		os.Setenv(envSquelchAlpha, strconv.FormatFloat(*squelchAlphaFlag, 'E', -1, 32))
 	}
 
+	if os.Getenv(envSquelchHysteresis) == "" {
+		os.Setenv(envSquelchHysteresis, strconv.FormatFloat(*squelchHysteresisFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envSquelchAttack) == "" {
+		os.Setenv(envSquelchAttack, strconv.FormatFloat(*squelchAttackFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envSquelchHang) == "" {
+		os.Setenv(envSquelchHang, strconv.FormatFloat(*squelchHangFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envSquelchMode) == "" {
+		os.Setenv(envSquelchMode, *squelchModeFlag)
+	}
+
+	if os.Getenv(envNoiseSquelch) == "" {
+		os.Setenv(envNoiseSquelch, strconv.FormatFloat(*noiseSquelchFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envSignalDetection) == "" {
+		os.Setenv(envSignalDetection, strconv.FormatBool(*signalDetectionFlag))
+	}
+
+	if os.Getenv(envCFARMode) == "" {
+		os.Setenv(envCFARMode, *cfarModeFlag)
+	}
+
+	if os.Getenv(envCFARThreshold) == "" {
+		os.Setenv(envCFARThreshold, strconv.FormatFloat(*cfarThresholdFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envBurstDetection) == "" {
+		os.Setenv(envBurstDetection, strconv.FormatBool(*burstDetectionFlag))
+	}
+
+	if os.Getenv(envBurstFFTSize) == "" {
+		os.Setenv(envBurstFFTSize, strconv.FormatUint(uint64(*burstFFTSizeFlag), 10))
+	}
+
+	if os.Getenv(envBurstMinDuration) == "" {
+		os.Setenv(envBurstMinDuration, strconv.FormatFloat(*burstMinDurationFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envBurstMaxDuration) == "" {
+		os.Setenv(envBurstMaxDuration, strconv.FormatFloat(*burstMaxDurationFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envNoiseBlanker) == "" {
+		os.Setenv(envNoiseBlanker, strconv.FormatBool(*noiseBlankerFlag))
+	}
+
+	if os.Getenv(envNBThreshold) == "" {
+		os.Setenv(envNBThreshold, strconv.FormatFloat(*nbThresholdFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envNBWidth) == "" {
+		os.Setenv(envNBWidth, strconv.FormatUint(uint64(*nbWidthFlag), 10))
+	}
+
+	if os.Getenv(envNBMode) == "" {
+		os.Setenv(envNBMode, *nbModeFlag)
+	}
+
+	if os.Getenv(envNoiseReduction) == "" {
+		os.Setenv(envNoiseReduction, strconv.FormatFloat(*noiseReductionFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envAudioAGC) == "" {
+		os.Setenv(envAudioAGC, strconv.FormatBool(*audioAGCFlag))
+	}
+
+	if os.Getenv(envAGCAttack) == "" {
+		os.Setenv(envAGCAttack, strconv.FormatFloat(*agcAttackFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envAGCDecay) == "" {
+		os.Setenv(envAGCDecay, strconv.FormatFloat(*agcDecayFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envAGCHang) == "" {
+		os.Setenv(envAGCHang, strconv.FormatFloat(*agcHangFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envAGCTarget) == "" {
+		os.Setenv(envAGCTarget, strconv.FormatFloat(*agcTargetFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envAGCMaxGain) == "" {
+		os.Setenv(envAGCMaxGain, strconv.FormatFloat(*agcMaxGainFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envVolume) == "" {
+		os.Setenv(envVolume, strconv.FormatFloat(*volumeFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envLimiter) == "" {
+		os.Setenv(envLimiter, strconv.FormatBool(*limiterFlag))
+	}
+
+	if os.Getenv(envLimiterThreshold) == "" {
+		os.Setenv(envLimiterThreshold, strconv.FormatFloat(*limiterThresholdFlag, 'E', -1, 32))
+	}
+
 	if os.Getenv(envAMAudioCut) == "" {
 		os.Setenv(envAMAudioCut, strconv.FormatFloat(*amAudioCutFlag, 'E', -1, 32))
 	}
 
+	if os.Getenv(envAMNotch) == "" {
+		os.Setenv(envAMNotch, *amNotchFlag)
+	}
+
+	if os.Getenv(envAMNotchFrequency) == "" {
+		os.Setenv(envAMNotchFrequency, strconv.FormatFloat(*amNotchFrequencyFlag, 'E', -1, 32))
+	}
+
+	if os.Getenv(envPSKOrder) == "" {
+		os.Setenv(envPSKOrder, strconv.FormatUint(uint64(*pskOrderFlag), 10))
+	}
+
+	if os.Getenv(envPSKSymbolRate) == "" {
+		os.Setenv(envPSKSymbolRate, strconv.FormatFloat(*pskSymbolRateFlag, 'E', -1, 32))
+	}
+
 	if os.Getenv(envStationName) == "" {
 		os.Setenv(envStationName, *stationNameFlag)
 	}
@@ -259,6 +672,32 @@ func setEnv() {
 		panic(err)
 	}
 	displayPixels = uint(dp)
+	iqcorrection, err := strconv.ParseBool(os.Getenv(envIQCorrection))
+	if err != nil {
+		panic(err)
+	}
+	iqCorrection = iqcorrection
+	fs, err := strconv.ParseUint(os.Getenv(envFFTSize), 10, 32)
+	if err != nil {
+		panic(err)
+	}
+	fftSize = uint(fs)
+	if fftSize == 0 {
+		fftSize = displayPixels
+	}
+	fftWindow = os.Getenv(envFFTWindow)
+	fo, err := strconv.ParseFloat(os.Getenv(envFFTOverlap), 32)
+	if err != nil {
+		panic(err)
+	}
+	fftOverlap = float32(fo)
+	fftAveraging = os.Getenv(envFFTAveraging)
+	faf, err := strconv.ParseUint(os.Getenv(envFFTAverageFrames), 10, 16)
+	if err != nil {
+		panic(err)
+	}
+	fftAverageFrames = uint(faf)
+	fftBinMode = os.Getenv(envFFTBinMode)
 	cf, err := strconv.ParseUint(os.Getenv(envCenterFrequency), 10, 32)
 	if err != nil {
 		panic(err)
@@ -300,6 +739,16 @@ func setEnv() {
 		panic(err)
 	}
 	fmTau = float32(fmtau)
+	afcx, err := strconv.ParseBool(os.Getenv(envAFC))
+	if err != nil {
+		panic(err)
+	}
+	afc = afcx
+	afcpullin, err := strconv.ParseFloat(os.Getenv(envAFCPullIn), 32)
+	if err != nil {
+		panic(err)
+	}
+	afcPullIn = float32(afcpullin)
 	squelchx, err := strconv.ParseFloat(os.Getenv(envSquelch), 32)
 	if err != nil {
 		panic(err)
@@ -310,12 +759,151 @@ func setEnv() {
 		panic(err)
 	}
 	squelchAlpha = float32(squelchalpha)
+	squelchhysteresis, err := strconv.ParseFloat(os.Getenv(envSquelchHysteresis), 32)
+	if err != nil {
+		panic(err)
+	}
+	squelchHysteresis = float32(squelchhysteresis)
+	squelchattack, err := strconv.ParseFloat(os.Getenv(envSquelchAttack), 32)
+	if err != nil {
+		panic(err)
+	}
+	squelchAttack = float32(squelchattack)
+	squelchhang, err := strconv.ParseFloat(os.Getenv(envSquelchHang), 32)
+	if err != nil {
+		panic(err)
+	}
+	squelchHang = float32(squelchhang)
+	squelchMode = os.Getenv(envSquelchMode)
+	noisesquelch, err := strconv.ParseFloat(os.Getenv(envNoiseSquelch), 32)
+	if err != nil {
+		panic(err)
+	}
+	noiseSquelch = float32(noisesquelch)
+
+	signaldetection, err := strconv.ParseBool(os.Getenv(envSignalDetection))
+	if err != nil {
+		panic(err)
+	}
+	signalDetection = signaldetection
+	cfarMode = os.Getenv(envCFARMode)
+	cfarthreshold, err := strconv.ParseFloat(os.Getenv(envCFARThreshold), 32)
+	if err != nil {
+		panic(err)
+	}
+	cfarThreshold = float32(cfarthreshold)
+
+	burstdetection, err := strconv.ParseBool(os.Getenv(envBurstDetection))
+	if err != nil {
+		panic(err)
+	}
+	burstDetection = burstdetection
+	burstfftsize, err := strconv.ParseUint(os.Getenv(envBurstFFTSize), 10, 32)
+	if err != nil {
+		panic(err)
+	}
+	burstFFTSize = uint(burstfftsize)
+	burstminduration, err := strconv.ParseFloat(os.Getenv(envBurstMinDuration), 32)
+	if err != nil {
+		panic(err)
+	}
+	burstMinDuration = float32(burstminduration)
+	burstmaxduration, err := strconv.ParseFloat(os.Getenv(envBurstMaxDuration), 32)
+	if err != nil {
+		panic(err)
+	}
+	burstMaxDuration = float32(burstmaxduration)
+
+	noiseblanker, err := strconv.ParseBool(os.Getenv(envNoiseBlanker))
+	if err != nil {
+		panic(err)
+	}
+	noiseBlanker = noiseblanker
+	nbthreshold, err := strconv.ParseFloat(os.Getenv(envNBThreshold), 32)
+	if err != nil {
+		panic(err)
+	}
+	nbThreshold = float32(nbthreshold)
+	nbwidth, err := strconv.ParseUint(os.Getenv(envNBWidth), 10, 32)
+	if err != nil {
+		panic(err)
+	}
+	nbWidth = uint(nbwidth)
+	nbMode = os.Getenv(envNBMode)
+	noisereduction, err := strconv.ParseFloat(os.Getenv(envNoiseReduction), 32)
+	if err != nil {
+		panic(err)
+	}
+	noiseReduction = float32(noisereduction)
+
+	audioagc, err := strconv.ParseBool(os.Getenv(envAudioAGC))
+	if err != nil {
+		panic(err)
+	}
+	audioAGC = audioagc
+	agcattack, err := strconv.ParseFloat(os.Getenv(envAGCAttack), 32)
+	if err != nil {
+		panic(err)
+	}
+	agcAttack = float32(agcattack)
+	agcdecay, err := strconv.ParseFloat(os.Getenv(envAGCDecay), 32)
+	if err != nil {
+		panic(err)
+	}
+	agcDecay = float32(agcdecay)
+	agchang, err := strconv.ParseFloat(os.Getenv(envAGCHang), 32)
+	if err != nil {
+		panic(err)
+	}
+	agcHang = float32(agchang)
+	agctarget, err := strconv.ParseFloat(os.Getenv(envAGCTarget), 32)
+	if err != nil {
+		panic(err)
+	}
+	agcTarget = float32(agctarget)
+	agcmaxgain, err := strconv.ParseFloat(os.Getenv(envAGCMaxGain), 32)
+	if err != nil {
+		panic(err)
+	}
+	agcMaxGain = float32(agcmaxgain)
+	volumef, err := strconv.ParseFloat(os.Getenv(envVolume), 32)
+	if err != nil {
+		panic(err)
+	}
+	volume = float32(volumef)
+	limiterf, err := strconv.ParseBool(os.Getenv(envLimiter))
+	if err != nil {
+		panic(err)
+	}
+	limiter = limiterf
+	limiterthreshold, err := strconv.ParseFloat(os.Getenv(envLimiterThreshold), 32)
+	if err != nil {
+		panic(err)
+	}
+	limiterThreshold = float32(limiterthreshold)
 
 	amaudiocut, err := strconv.ParseFloat(os.Getenv(envAMAudioCut), 32)
 	if err != nil {
 		panic(err)
 	}
 	amAudioCut = float32(amaudiocut)
+	amNotch = os.Getenv(envAMNotch)
+	amnotchfrequency, err := strconv.ParseFloat(os.Getenv(envAMNotchFrequency), 32)
+	if err != nil {
+		panic(err)
+	}
+	amNotchFrequency = float32(amnotchfrequency)
+
+	pskorder, err := strconv.ParseUint(os.Getenv(envPSKOrder), 10, 8)
+	if err != nil {
+		panic(err)
+	}
+	pskOrder = uint(pskorder)
+	psksymbolrate, err := strconv.ParseFloat(os.Getenv(envPSKSymbolRate), 32)
+	if err != nil {
+		panic(err)
+	}
+	pskSymbolRate = float32(psksymbolrate)
 
 	stationName = os.Getenv(envStationName)
 
@@ -36,6 +36,8 @@ let averageTraffic = 0;
 let trafficSum = 0;
 let buffers = null;
 let waterfallBuffers = [];
+let signals = [];
+let diagnosticsEnabled = false;
 
 // From GQRX: https://github.com/csete/gqrx -> qtgui/plotter.cpp
 for (let i = 0; i < 256; i++) {
@@ -217,6 +219,86 @@ function DrawFFT() {
     ctx.stroke();
     ctx.closePath();
     // endregion
+    // region Detected Signals
+    ctx.fillStyle = 'rgba(0, 170, 0, 0.2)';
+    for (let i = 0; i < signals.length; i++) {
+        const signalX = margin + (signals[i].CenterFrequency - startFreq) * invDelta;
+        const signalW = Math.max(signals[i].Bandwidth * invDelta, 2);
+        ctx.fillRect(signalX - signalW / 2, marginTop, signalW, fftHeight);
+    }
+    // endregion
+}
+
+function HandleSignals(data) {
+    signals = data || [];
+}
+
+function SetDiagnostics(enabled) {
+    diagnosticsEnabled = enabled;
+    document.getElementById('diagnostics').style.display = enabled ? 'block' : 'none';
+    if (socket !== null && socket.readyState === WebSocket.OPEN) {
+        socket.send(JSON.stringify({MessageType: 'diagnostics', Enabled: enabled}));
+    }
+}
+
+// UnpackIQ8 returns the int8 I / Q pairs as [I, Q] points from -1 to 1
+function UnpackIQ8(data) {
+    const z = atob(data || '');
+    const points = [];
+    for (let i = 0; i + 1 < z.length; i += 2) {
+        points.push([(z.charCodeAt(i) << 24 >> 24) / 127, (z.charCodeAt(i + 1) << 24 >> 24) / 127]);
+    }
+    return points;
+}
+
+function HandleDiagnostics(data) {
+    trafficSum += (data.Constellation || '').length + (data.Eye || '').length;
+    if (!diagnosticsEnabled) {
+        return;
+    }
+
+    const dCanvas = document.getElementById('diagnostics');
+    const dCtx = dCanvas.getContext('2d');
+    const size = dCanvas.height;
+    const eyeWidth = dCanvas.width - size;
+    const scale = size * 0.45;
+
+    dCtx.fillStyle = 'black';
+    dCtx.fillRect(0, 0, dCanvas.width, size);
+
+    dCtx.strokeStyle = '#004444';
+    dCtx.beginPath();
+    dCtx.moveTo(size / 2, 0);
+    dCtx.lineTo(size / 2, size);
+    dCtx.moveTo(0, size / 2);
+    dCtx.lineTo(dCanvas.width, size / 2);
+    dCtx.moveTo(size, 0);
+    dCtx.lineTo(size, size);
+    dCtx.stroke();
+
+    // Constellation
+    dCtx.fillStyle = '#00ff88';
+    UnpackIQ8(data.Constellation).forEach((p) => {
+        dCtx.fillRect(size / 2 + p[0] * scale - 1, size / 2 - p[1] * scale - 1, 2, 2);
+    });
+
+    // Eye Diagram (I)
+    const eye = UnpackIQ8(data.Eye);
+    const n = data.EyeTracePoints;
+    dCtx.strokeStyle = 'rgba(0, 255, 136, 0.3)';
+    for (let t = 0; t + n <= eye.length; t += n) {
+        dCtx.beginPath();
+        for (let j = 0; j < n; j++) {
+            const x = size + j * eyeWidth / (n - 1);
+            const y = size / 2 - eye[t + j][0] * scale;
+            if (j === 0) {
+                dCtx.moveTo(x, y);
+            } else {
+                dCtx.lineTo(x, y);
+            }
+        }
+        dCtx.stroke();
+    }
 }
 
 function HandleFFT(data) {
@@ -343,7 +425,10 @@ function Connect() {
     url = proto + location.host + "/ws";
     socket = new WebSocket(url);
     socket.onopen = (evt) => {
-        console.log('Connected!')
+        console.log('Connected!');
+        if (diagnosticsEnabled) {
+            SetDiagnostics(true);
+        }
     };
     socket.onclose = (evt) => {
         console.log('Connection closed!');
@@ -364,6 +449,8 @@ function Connect() {
                 case 'fft': HandleFFT(data.FFTData); UpdateLevel(data.DemodOutputLevel); break;
                 case 'data': HandleData(data.Data); break;
                 case 'device': HandleDevice(data); break;
+                case 'signals': HandleSignals(data.Signals); break;
+                case 'diagnostics': HandleDiagnostics(data); break;
                 default: console.log('Unknown Type: ' + data.MessageType);
             }
         } catch (e) {
@@ -1,12 +1,15 @@
 package demodcore
 
 import (
-	"github.com/racerxdl/go.fifo"
 	"github.com/racerxdl/segdsp/dsp"
 	"github.com/racerxdl/segdsp/eventmanager"
+	"github.com/racerxdl/segdsp/flowgraph"
 	"math"
 )
 
+// amCarrierDCTime is the time constant in seconds of the carrier DC removal
+const amCarrierDCTime = 0.1
+
 type AMDemod struct {
 	sampleRate   float64
 	outputRate   uint32
@@ -15,13 +18,23 @@ type AMDemod struct {
 	decimation   int
 	resampler    *dsp.FloatResampler
 	finalStage   *dsp.FloatFirFilter
-	outFifo      *fifo.Queue
+	output       outputBuffer
 	sql          *dsp.Squelch
 	packedParams AMDemodParams
 	ev           *eventmanager.EventManager
 	lastSquelch  bool
 	ffAgc        *dsp.FeedForwardAGC
 	c2m          *dsp.Complex2Magnitude
+	nb           *dsp.NoiseBlanker
+	nr           *dsp.SpectralNoiseReduction
+	notch        *dsp.NotchFilter
+	level        audioLevel
+	carrierDC    float32
+	dcAlpha      float32
+	snr          *dsp.ChannelSNR
+	freqError    *dsp.FrequencyErrorMeter
+	quadRate     float64
+	status       demodStatus
 }
 
 type AMDemodParams struct {
@@ -31,6 +44,11 @@ type AMDemodParams struct {
 	Squelch         float32
 	SquelchAlpha    float32
 	AudioCut        float32
+	SquelchTimingParams
+	NoiseBlankerParams
+	NoiseReductionParams
+	NotchParams
+	AudioLevelParams
 }
 
 func MakeCustomAMDemodulator(sampleRate uint32, signalBw float64, outputRate uint32, audioCut, squelch, squelchAlpha float32) *AMDemod {
@@ -56,7 +74,8 @@ func MakeCustomAMDemodulator(sampleRate uint32, signalBw float64, outputRate uin
 
 	return &AMDemod{
 		sampleRate: float64(sampleRate),
-		firstStage: dsp.MakeFirFilter(
+		firstStage: dsp.MakeDecimationFirFilter(
+			decim,
 			dsp.MakeLowPassFixed(
 				1,
 				float64(sampleRate),
@@ -75,8 +94,8 @@ func MakeCustomAMDemodulator(sampleRate uint32, signalBw float64, outputRate uin
 				31,
 			),
 		),
-		outFifo: fifo.NewQueue(),
-		sql:     sql,
+		output: makeOutputBuffer(),
+		sql:    sql,
 		packedParams: AMDemodParams{
 			SampleRate:      sampleRate,
 			SignalBandwidth: signalBw,
@@ -89,10 +108,18 @@ func MakeCustomAMDemodulator(sampleRate uint32, signalBw float64, outputRate uin
 		ffAgc:       agc,
 		signalBw:    signalBw,
 		c2m:         dsp.MakeComplex2Magnitude(),
+		snr:         dsp.MakeChannelSNR(float32(sampleRate), float32(signalBw), channelSNRFFTSize),
+		freqError:   dsp.MakeFrequencyErrorMeter(float32(quadRate)),
+		quadRate:    quadRate,
+		carrierDC:   1, // The level of an unmodulated carrier
+		dcAlpha:     float32(1 - math.Exp(-1/(amCarrierDCTime*float64(outputRate)))),
+		status:      demodStatus{muted: true},
 	}
 }
 
 func (f *AMDemod) GetDemodParams() interface{} {
+	f.status.lock.Lock()
+	defer f.status.lock.Unlock()
 	return f.packedParams
 }
 
@@ -100,28 +127,128 @@ func (f *AMDemod) SetEventManager(ev *eventmanager.EventManager) {
 	f.ev = ev
 }
 
+// SetNoiseBlanker sets a Noise Blanker to run over the raw IQ before the first stage. nil disables it.
+func (f *AMDemod) SetNoiseBlanker(nb *dsp.NoiseBlanker) {
+	f.nb = nb
+	f.packedParams.NoiseBlankerParams = makeNoiseBlankerParams(nb)
+}
+
+// SetNoiseReduction sets a Spectral Noise Reduction to run over the demodulated audio. nil disables it.
+// It is a stage of the audio block, so it should be set before Connect.
+func (f *AMDemod) SetNoiseReduction(nr *dsp.SpectralNoiseReduction) {
+	f.nr = nr
+	f.packedParams.NoiseReductionParams = makeNoiseReductionParams(nr)
+}
+
+// SetAudioAGC sets an Audio AGC to run over the demodulated audio, after the noise reduction. nil disables it.
+func (f *AMDemod) SetAudioAGC(agc *dsp.AudioAGC) {
+	f.level.agc = agc
+	f.packedParams.AudioLevelParams = f.level.params()
+}
+
+// SetVolume sets a fixed gain to run over the demodulated audio, after the Audio AGC. nil disables it.
+func (f *AMDemod) SetVolume(volume *dsp.AudioGain) {
+	f.level.volume = volume
+	f.packedParams.AudioLevelParams = f.level.params()
+}
+
+// SetLimiter sets a Soft Limiter to run over the demodulated audio, after the volume. nil disables it.
+func (f *AMDemod) SetLimiter(limiter *dsp.SoftLimiter) {
+	f.level.limiter = limiter
+	f.packedParams.AudioLevelParams = f.level.params()
+}
+
+// SetNotch sets a Notch Filter to run over the demodulated audio. nil disables it.
+func (f *AMDemod) SetNotch(n *dsp.NotchFilter) {
+	f.notch = n
+	f.packedParams.NotchParams = makeNotchParams(n)
+}
+
+// SetSquelchTiming sets the squelch hysteresis in dB, and the attack and hang times in seconds
+func (f *AMDemod) SetSquelchTiming(hysteresis, attack, hang float32) {
+	f.packedParams.SquelchTimingParams = SquelchTimingParams{
+		SquelchHysteresis: hysteresis,
+		SquelchAttack:     attack,
+		SquelchHang:       hang,
+	}
+
+	applyPowerSquelchTiming(f.sql, f.packedParams.SquelchTimingParams, f.quadRate)
+}
+
+// GetMeasurements returns the current channel measurements
+func (f *AMDemod) GetMeasurements() Measurements {
+	return f.status.getMeasurements()
+}
+
 func (f *AMDemod) IsMuted() bool {
-	return f.sql.IsMuted()
+	return f.status.isMuted()
 }
 
 func (f *AMDemod) GetLevel() float32 {
-	return f.sql.GetAvgLevel()
+	return f.status.getLevel()
 }
 
-func (f *AMDemod) Work(data []complex64) interface{} {
-	var filteredData = f.firstStage.FilterDecimateOut(data, f.decimation)
-	filteredData = f.sql.Work(filteredData)
-	filteredData = f.ffAgc.Work(filteredData)
+// Connect adds the demodulator blocks to the flowgraph, fed with the IQ samples of input.
+// cb is called with a DemodData on each demodOutputSize audio samples.
+func (f *AMDemod) Connect(g *flowgraph.Flowgraph, input *flowgraph.Port, cb func(interface{})) {
+	var channel = g.AddBlock("am-channel", input,
+		flowgraph.ComplexFunc(f.channelInput),
+		f.firstStage,
+		flowgraph.ComplexFunc(f.channelOutput),
+		f.ffAgc,
+	)
 
-	var amDemodData = f.c2m.Work(filteredData)
+	var audioWorkers = []interface{}{
+		f.c2m,
+		f.resampler,
+		f.finalStage,
+		flowgraph.FloatFunc(f.audioOutput),
+	}
+
+	// The noise reduction works in frames, so it is a stage of its own to get its output buffer sized
+	if f.nr != nil {
+		audioWorkers = append(audioWorkers, f.nr)
+	}
+
+	audioWorkers = append(audioWorkers, flowgraph.FloatFunc(f.level.work))
+
+	var audio = g.AddBlock("am-audio", channel, audioWorkers...)
 
-	amDemodData = f.resampler.Work(amDemodData)
+	addOutputSink(g, "am-output", audio, f.outputRate, &f.output, f.GetLevel, cb)
+}
+
+// channelInput runs over the raw IQ samples, before the channel filter
+func (f *AMDemod) channelInput(data []complex64) []complex64 {
+	if f.nb != nil {
+		data = f.nb.Work(data)
+		var blanked = f.nb.GetBlankedSamples()
+		f.status.lock.Lock()
+		f.packedParams.BlankedSamples = blanked
+		f.status.lock.Unlock()
+	}
 
-	amDemodData = f.finalStage.FilterOut(amDemodData)
+	f.snr.Feed(data)
+
+	return data
+}
 
-	if f.lastSquelch != f.sql.IsMuted() && f.ev != nil {
+// channelOutput runs over the channel filtered samples
+func (f *AMDemod) channelOutput(data []complex64) []complex64 {
+	f.freqError.Feed(data)
+	data = f.sql.Work(data)
+
+	var muted = f.sql.IsMuted()
+
+	f.status.lock.Lock()
+	f.status.level = f.sql.GetAvgLevel()
+	f.status.muted = muted
+	f.status.measurements.SNR = f.snr.GetSNR()
+	f.status.measurements.FrequencyError = f.freqError.GetFrequencyError()
+	f.status.lock.Unlock()
+
+	if f.lastSquelch != muted && f.ev != nil {
 		var evName string
-		if f.sql.IsMuted() {
+		if muted {
 			evName = eventmanager.EvSquelchOn
 		} else {
 			evName = eventmanager.EvSquelchOff
@@ -129,28 +256,27 @@ func (f *AMDemod) Work(data []complex64) interface{} {
 		f.ev.Emit(evName, eventmanager.SquelchEventData{
 			Threshold: f.sql.GetThreshold(),
 			AvgValue:  f.sql.GetAvgLevel(),
+			Criterion: eventmanager.SquelchCriterionPower,
 		})
 	}
 
-	f.lastSquelch = f.sql.IsMuted()
-
-	for i := 0; i < len(amDemodData); i++ {
-		f.outFifo.Add(amDemodData[i] - 1)
-	}
+	f.lastSquelch = muted
 
-	if f.outFifo.Len() >= 16384 {
-		var outBuff = make([]float32, 16384)
+	return data
+}
 
-		for i := 0; i < 16384; i++ {
-			outBuff[i] = f.outFifo.Next().(float32)
-		}
+// audioOutput runs over the resampled audio, before the noise reduction
+func (f *AMDemod) audioOutput(data []float32) []float32 {
+	// Remove the carrier DC. It depends on the modulation depth (the FeedForwardAGC normalizes the peaks, not the
+	// carrier), so it is tracked instead of fixed.
+	for i := 0; i < len(data); i++ {
+		f.carrierDC += f.dcAlpha * (data[i] - f.carrierDC)
+		data[i] -= f.carrierDC
+	}
 
-		return DemodData{
-			OutputRate: f.outputRate,
-			Level:      f.sql.GetAvgLevel(),
-			Data:       outBuff,
-		}
+	if f.notch != nil {
+		data = f.notch.Work(data)
 	}
 
-	return nil
+	return data
 }
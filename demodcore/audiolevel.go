@@ -0,0 +1,64 @@
+package demodcore
+
+import "github.com/racerxdl/segdsp/dsp"
+
+// AudioLevelParams are the Audio AGC, Volume and Soft Limiter parameters reported in the demodulator params
+type AudioLevelParams struct {
+	AudioAGC         bool
+	AudioAGCAttack   float32
+	AudioAGCDecay    float32
+	AudioAGCHang     float32
+	AudioAGCTarget   float32
+	AudioAGCMaxGain  float32
+	Volume           float32
+	Limiter          bool
+	LimiterThreshold float32
+}
+
+// audioLevel is the last stage of the demodulated audio: AGC, then volume, then limiter. Any of them can be nil.
+type audioLevel struct {
+	agc     *dsp.AudioAGC
+	volume  *dsp.AudioGain
+	limiter *dsp.SoftLimiter
+}
+
+func (a *audioLevel) params() AudioLevelParams {
+	var p = AudioLevelParams{}
+
+	if a.agc != nil {
+		p.AudioAGC = true
+		p.AudioAGCAttack = a.agc.GetAttack()
+		p.AudioAGCDecay = a.agc.GetDecay()
+		p.AudioAGCHang = a.agc.GetHang()
+		p.AudioAGCTarget = a.agc.GetTarget()
+		p.AudioAGCMaxGain = a.agc.GetMaxGain()
+	}
+
+	if a.volume != nil {
+		p.Volume = a.volume.GetGain()
+	}
+
+	if a.limiter != nil {
+		p.Limiter = true
+		p.LimiterThreshold = a.limiter.GetThreshold()
+	}
+
+	return p
+}
+
+// work runs the stages in place
+func (a *audioLevel) work(data []float32) []float32 {
+	if a.agc != nil {
+		a.agc.WorkBuffer(data, data)
+	}
+
+	if a.volume != nil {
+		a.volume.WorkBuffer(data, data)
+	}
+
+	if a.limiter != nil {
+		a.limiter.WorkBuffer(data, data)
+	}
+
+	return data
+}
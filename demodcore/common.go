@@ -7,6 +7,9 @@ import (
 	"strings"
 )
 
+// afcEventThreshold is the minimum change in Hertz of the AFC offset to emit a new event
+const afcEventThreshold = 10
+
 type JsonFloat32 []float32
 
 func (u JsonFloat32) MarshalJSON() ([]byte, error) {
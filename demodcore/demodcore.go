@@ -1,11 +1,30 @@
 package demodcore
 
-import "github.com/racerxdl/segdsp/eventmanager"
+import (
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/dsp/digital"
+	"github.com/racerxdl/segdsp/eventmanager"
+	"github.com/racerxdl/segdsp/flowgraph"
+)
 
 type DemodCore interface {
-	Work(data []complex64) interface{}
+	Connect(g *flowgraph.Flowgraph, input *flowgraph.Port, cb func(interface{}))
 	GetDemodParams() interface{}
 	SetEventManager(ev *eventmanager.EventManager)
 	GetLevel() float32
 	IsMuted() bool
+	GetMeasurements() Measurements
+	SetNoiseBlanker(nb *dsp.NoiseBlanker)
+	SetNoiseReduction(nr *dsp.SpectralNoiseReduction)
+	SetSquelchTiming(hysteresis, attack, hang float32)
+	SetAudioAGC(agc *dsp.AudioAGC)
+	SetVolume(volume *dsp.AudioGain)
+	SetLimiter(limiter *dsp.SoftLimiter)
+}
+
+// DiagnosticsProvider is implemented by the demodulators of digital modes that can show their symbols.
+// The taps are disabled until a client asks for the diagnostics, and any of them can be nil.
+type DiagnosticsProvider interface {
+	GetConstellationTap() *digital.ConstellationTap
+	GetEyeTap() *digital.EyeTap
 }
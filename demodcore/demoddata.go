@@ -1,7 +1,13 @@
 package demodcore
 
+import "time"
+
 type DemodData struct {
 	OutputRate uint32
 	Level      float32
 	Data       JsonFloat32
+	// Timestamp is the reception time of the first sample (zero if the source has no time tags)
+	Timestamp time.Time
+	// Frequency is the center frequency in Hertz of the input samples (zero if the source has no frequency tags)
+	Frequency float64
 }
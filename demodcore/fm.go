@@ -6,27 +6,39 @@ import (
 
 	"github.com/racerxdl/segdsp/dsp"
 	"github.com/racerxdl/segdsp/eventmanager"
+	"github.com/racerxdl/segdsp/flowgraph"
+	"github.com/racerxdl/segdsp/tools"
 )
 
 type FMDemod struct {
-	sampleRate      float64
-	outputRate      uint32
-	firstStage      *dsp.FirFilter
-	secondStage     *dsp.FloatFirFilter
-	signalBw        float64
-	deviation       float32
-	quadDemod       *dsp.QuadDemod
-	decimation      int
-	resampler       *dsp.FloatResampler
-	finalStage      *dsp.FloatFirFilter
-	deemph          *dsp.FMDeemph
-	sql             *dsp.Squelch
-	tau             float32
-	packedParams    FMDemodParams
-	ev              *eventmanager.EventManager
-	lastSquelch     bool
-	outputBuffer    []float32
-	outputBufferPos int
+	sampleRate     float64
+	outputRate     uint32
+	firstStage     *dsp.FirFilter
+	secondStage    *dsp.FloatFirFilter
+	signalBw       float64
+	deviation      float32
+	quadDemod      *dsp.QuadDemod
+	decimation     int
+	resampler      *dsp.FloatResampler
+	finalStage     *dsp.FloatFirFilter
+	deemph         *dsp.FMDeemph
+	sql            *dsp.Squelch
+	noiseSql       *dsp.NoiseSquelch
+	tau            float32
+	packedParams   FMDemodParams
+	ev             *eventmanager.EventManager
+	lastSquelch    bool
+	output         outputBuffer
+	quadRate       float64
+	afc            *dsp.AFC
+	nb             *dsp.NoiseBlanker
+	nr             *dsp.SpectralNoiseReduction
+	level          audioLevel
+	lastAFCOffset  float32
+	snr            *dsp.ChannelSNR
+	freqError      *dsp.FrequencyErrorMeter
+	deviationMeter *dsp.DeviationMeter
+	status         demodStatus
 }
 
 type FMDemodParams struct {
@@ -37,6 +49,15 @@ type FMDemodParams struct {
 	Squelch         float32
 	SquelchAlpha    float32
 	MaxDeviation    float32
+	AFCEnabled      bool
+	AFCPullInRange  float32
+	AFCOffset       float32
+	SquelchMode     string
+	NoiseSquelch    float32
+	SquelchTimingParams
+	NoiseBlankerParams
+	NoiseReductionParams
+	AudioLevelParams
 }
 
 func MakeCustomFMDemodulator(sampleRate uint32, signalBw float64, outputRate uint32, tau, squelch, squelchAlpha, maxDeviation float32) *FMDemod {
@@ -62,13 +83,16 @@ func MakeCustomFMDemodulator(sampleRate uint32, signalBw float64, outputRate uin
 
 	var stageCut = math.Min(float64(outputRate), quadRate) / 2
 
+	// The resampler leaves images of the audio over the quad rate, so the audio is cut at the signal band
+	var audioCut = math.Min(float64(outputRate)/2-float64(outputRate)/32, signalBw/2)
+
 	var sql = dsp.MakeSquelch(squelch, squelchAlpha)
 
 	return &FMDemod{
-		outputBufferPos: 0,
-		outputBuffer:    make([]float32, 16384),
-		sampleRate:      float64(sampleRate),
-		firstStage: dsp.MakeFirFilter(
+		output:     makeOutputBuffer(),
+		sampleRate: float64(sampleRate),
+		firstStage: dsp.MakeDecimationFirFilter(
+			decim,
 			dsp.MakeLowPassFixed(
 				1,
 				float64(sampleRate),
@@ -94,7 +118,7 @@ func MakeCustomFMDemodulator(sampleRate uint32, signalBw float64, outputRate uin
 			dsp.MakeLowPassFixed(
 				0.25,
 				float64(outputRate),
-				float64(outputRate)/2-float64(outputRate)/32,
+				audioCut,
 				63,
 			),
 		),
@@ -108,9 +132,15 @@ func MakeCustomFMDemodulator(sampleRate uint32, signalBw float64, outputRate uin
 			Squelch:         squelch,
 			SquelchAlpha:    squelchAlpha,
 			MaxDeviation:    maxDeviation,
+			SquelchMode:     SquelchModePower,
 		},
-		lastSquelch: true,
-		signalBw:    signalBw,
+		lastSquelch:    true,
+		signalBw:       signalBw,
+		quadRate:       quadRate,
+		snr:            dsp.MakeChannelSNR(float32(sampleRate), float32(signalBw), channelSNRFFTSize),
+		freqError:      dsp.MakeFrequencyErrorMeter(float32(quadRate)),
+		deviationMeter: dsp.MakeDeviationMeter(maxDeviation),
+		status:         demodStatus{muted: true},
 	}
 }
 
@@ -119,10 +149,42 @@ func MakeWBFMDemodulator(sampleRate uint32, signalBw float64, outputRate uint32)
 }
 
 func (f *FMDemod) GetLevel() float32 {
-	return f.sql.GetAvgLevel()
+	return f.status.getLevel()
+}
+
+// EnableNoiseSquelch makes the squelch open / close by the discriminator noise level (in dB) instead of the RF power.
+func (f *FMDemod) EnableNoiseSquelch(threshold float32) {
+	var cut = math.Min(f.signalBw*noiseSquelchCutRatio, f.quadRate*0.45)
+	f.noiseSql = dsp.MakeNoiseSquelch(threshold, noiseSquelchAlpha, float32(f.quadRate), float32(cut))
+	applyNoiseSquelchTiming(f.noiseSql, f.packedParams.SquelchTimingParams, f.quadRate)
+	f.packedParams.SquelchMode = SquelchModeNoise
+	f.packedParams.NoiseSquelch = threshold
+}
+
+// DisableNoiseSquelch goes back to the RF power squelch
+func (f *FMDemod) DisableNoiseSquelch() {
+	f.noiseSql = nil
+	f.packedParams.SquelchMode = SquelchModePower
+	f.packedParams.NoiseSquelch = 0
+}
+
+// SetSquelchTiming sets the squelch hysteresis in dB, and the attack and hang times in seconds
+func (f *FMDemod) SetSquelchTiming(hysteresis, attack, hang float32) {
+	f.packedParams.SquelchTimingParams = SquelchTimingParams{
+		SquelchHysteresis: hysteresis,
+		SquelchAttack:     attack,
+		SquelchHang:       hang,
+	}
+
+	applyPowerSquelchTiming(f.sql, f.packedParams.SquelchTimingParams, f.quadRate)
+	if f.noiseSql != nil {
+		applyNoiseSquelchTiming(f.noiseSql, f.packedParams.SquelchTimingParams, f.quadRate)
+	}
 }
 
 func (f *FMDemod) GetDemodParams() interface{} {
+	f.status.lock.Lock()
+	defer f.status.lock.Unlock()
 	return f.packedParams
 }
 
@@ -130,61 +192,227 @@ func (f *FMDemod) SetEventManager(ev *eventmanager.EventManager) {
 	f.ev = ev
 }
 
+// EnableAFC enables the Automatic Frequency Control with the specified pull-in range in Hertz
+func (f *FMDemod) EnableAFC(pullInRange float32) {
+	f.afc = dsp.MakeAFC(float32(f.sampleRate), float32(f.quadRate), float32(f.signalBw), pullInRange)
+	f.lastAFCOffset = 0
+	f.packedParams.AFCEnabled = true
+	f.packedParams.AFCPullInRange = pullInRange
+	f.packedParams.AFCOffset = 0
+}
+
+// DisableAFC disables the Automatic Frequency Control
+func (f *FMDemod) DisableAFC() {
+	f.afc = nil
+	f.packedParams.AFCEnabled = false
+	f.packedParams.AFCPullInRange = 0
+	f.packedParams.AFCOffset = 0
+}
+
+// GetAFCOffset returns the current carrier offset correction in Hertz
+func (f *FMDemod) GetAFCOffset() float32 {
+	f.status.lock.Lock()
+	defer f.status.lock.Unlock()
+	return f.packedParams.AFCOffset
+}
+
+func (f *FMDemod) updateAFC() {
+	var offset = f.afc.GetOffset()
+	f.status.lock.Lock()
+	f.packedParams.AFCOffset = offset
+	f.status.lock.Unlock()
+
+	if f.ev != nil && tools.Abs(offset-f.lastAFCOffset) >= afcEventThreshold {
+		f.lastAFCOffset = offset
+		f.ev.Emit(eventmanager.EvAFCOffset, eventmanager.AFCEventData{
+			Offset:      offset,
+			PullInRange: f.afc.GetPullInRange(),
+		})
+	}
+}
+
+// SetNoiseBlanker sets a Noise Blanker to run over the raw IQ before the first stage. nil disables it.
+func (f *FMDemod) SetNoiseBlanker(nb *dsp.NoiseBlanker) {
+	f.nb = nb
+	f.packedParams.NoiseBlankerParams = makeNoiseBlankerParams(nb)
+}
+
+// SetNoiseReduction sets a Spectral Noise Reduction to run over the demodulated audio. nil disables it.
+// It is a stage of the audio block, so it should be set before Connect.
+func (f *FMDemod) SetNoiseReduction(nr *dsp.SpectralNoiseReduction) {
+	f.nr = nr
+	f.packedParams.NoiseReductionParams = makeNoiseReductionParams(nr)
+}
+
+// SetAudioAGC sets an Audio AGC to run over the demodulated audio, after the noise reduction. nil disables it.
+func (f *FMDemod) SetAudioAGC(agc *dsp.AudioAGC) {
+	f.level.agc = agc
+	f.packedParams.AudioLevelParams = f.level.params()
+}
+
+// SetVolume sets a fixed gain to run over the demodulated audio, after the Audio AGC. nil disables it.
+func (f *FMDemod) SetVolume(volume *dsp.AudioGain) {
+	f.level.volume = volume
+	f.packedParams.AudioLevelParams = f.level.params()
+}
+
+// SetLimiter sets a Soft Limiter to run over the demodulated audio, after the volume. nil disables it.
+func (f *FMDemod) SetLimiter(limiter *dsp.SoftLimiter) {
+	f.level.limiter = limiter
+	f.packedParams.AudioLevelParams = f.level.params()
+}
+
+// GetMeasurements returns the current channel measurements
+func (f *FMDemod) GetMeasurements() Measurements {
+	return f.status.getMeasurements()
+}
+
 func (f *FMDemod) IsMuted() bool {
+	return f.status.isMuted()
+}
+
+// squelchMuted returns the state of the active squelch. It runs in the same block of the active squelch.
+func (f *FMDemod) squelchMuted() bool {
+	if f.noiseSql != nil {
+		return f.noiseSql.IsMuted()
+	}
 	return f.sql.IsMuted()
 }
 
-func (f *FMDemod) Work(data []complex64) interface{} {
-	var filteredData = f.firstStage.FilterDecimateOut(data, f.decimation)
-	filteredData = f.sql.Work(filteredData)
+func (f *FMDemod) squelchEventData() eventmanager.SquelchEventData {
+	if f.noiseSql != nil {
+		return eventmanager.SquelchEventData{
+			Threshold: f.noiseSql.GetThreshold(),
+			AvgValue:  f.noiseSql.GetAvgLevel(),
+			Criterion: eventmanager.SquelchCriterionNoise,
+		}
+	}
+
+	return eventmanager.SquelchEventData{
+		Threshold: f.sql.GetThreshold(),
+		AvgValue:  f.sql.GetAvgLevel(),
+		Criterion: eventmanager.SquelchCriterionPower,
+	}
+}
 
-	var fmDemodData = f.quadDemod.Work(filteredData)
+// Connect adds the demodulator blocks to the flowgraph, fed with the IQ samples of input.
+// cb is called with a DemodData on each demodOutputSize audio samples.
+func (f *FMDemod) Connect(g *flowgraph.Flowgraph, input *flowgraph.Port, cb func(interface{})) {
+	var channel = g.AddBlock("fm-channel", input,
+		flowgraph.ComplexFunc(f.channelInput),
+		f.firstStage,
+		flowgraph.ComplexFunc(f.channelOutput),
+	)
 
-	fmDemodData = f.secondStage.FilterOut(fmDemodData)
-	fmDemodData = f.resampler.Work(fmDemodData)
-	fmDemodData = f.finalStage.FilterOut(fmDemodData)
+	var audioWorkers = []interface{}{
+		f.quadDemod,
+		flowgraph.FloatFunc(f.discriminatorOutput),
+		f.secondStage,
+		f.resampler,
+		f.finalStage,
+		flowgraph.FloatFunc(f.audioOutput),
+	}
+
+	// The noise reduction works in frames, so it is a stage of its own to get its output buffer sized
+	if f.nr != nil {
+		audioWorkers = append(audioWorkers, f.nr)
+	}
+
+	audioWorkers = append(audioWorkers, flowgraph.FloatFunc(f.level.work))
+
+	var audio = g.AddBlock("fm-audio", channel, audioWorkers...)
+
+	addOutputSink(g, "fm-output", audio, f.outputRate, &f.output, f.GetLevel, cb)
+}
+
+// channelInput runs over the raw IQ samples, before the channel filter
+func (f *FMDemod) channelInput(data []complex64) []complex64 {
+	if f.nb != nil {
+		data = f.nb.Work(data)
+		var blanked = f.nb.GetBlankedSamples()
+		f.status.lock.Lock()
+		f.packedParams.BlankedSamples = blanked
+		f.status.lock.Unlock()
+	}
+
+	f.snr.Feed(data)
+
+	if f.afc != nil {
+		data = f.afc.Correct(data)
+	}
+
+	return data
+}
+
+// channelOutput runs over the channel filtered samples
+func (f *FMDemod) channelOutput(data []complex64) []complex64 {
+	var afcOffset = float32(0)
+	if f.afc != nil {
+		data = f.afc.Track(data)
+		f.updateAFC()
+		afcOffset = f.afc.GetOffset()
+	}
+
+	f.freqError.Feed(data)
+
+	// With the noise squelch enabled, the power squelch is only used to measure the level
+	var sqlData = f.sql.Work(data)
+
+	f.status.lock.Lock()
+	f.status.level = f.sql.GetAvgLevel()
+	f.status.measurements.SNR = f.snr.GetSNR()
+	f.status.measurements.FrequencyError = f.freqError.GetFrequencyError() + afcOffset
+	f.status.lock.Unlock()
+
+	if f.noiseSql == nil {
+		data = sqlData
+		f.checkSquelch()
+	}
+
+	return data
+}
+
+// discriminatorOutput runs over the Quadrature Demodulator output
+func (f *FMDemod) discriminatorOutput(data []float32) []float32 {
+	f.deviationMeter.Feed(data)
+
+	f.status.lock.Lock()
+	f.status.measurements.DeviationPeak = f.deviationMeter.GetPeakDeviation()
+	f.status.measurements.DeviationRMS = f.deviationMeter.GetRMSDeviation()
+	f.status.lock.Unlock()
+
+	if f.noiseSql != nil {
+		data = f.noiseSql.Work(data)
+		f.checkSquelch()
+	}
+
+	return data
+}
+
+// audioOutput runs over the resampled audio, before the noise reduction
+func (f *FMDemod) audioOutput(data []float32) []float32 {
 	if f.tau != 0 {
-		fmDemodData = f.deemph.Work(fmDemodData)
+		data = f.deemph.Work(data)
 	}
 
-	if f.lastSquelch != f.sql.IsMuted() && f.ev != nil {
+	return data
+}
+
+// checkSquelch publishes the squelch state and emits the squelch events. It runs in the same block of the active
+// squelch.
+func (f *FMDemod) checkSquelch() {
+	var muted = f.squelchMuted()
+	f.status.setMuted(muted)
+
+	if f.lastSquelch != muted && f.ev != nil {
 		var evName string
-		if f.sql.IsMuted() {
+		if muted {
 			evName = eventmanager.EvSquelchOn
 		} else {
 			evName = eventmanager.EvSquelchOff
 		}
-		f.ev.Emit(evName, eventmanager.SquelchEventData{
-			Threshold: f.sql.GetThreshold(),
-			AvgValue:  f.sql.GetAvgLevel(),
-		})
+		f.ev.Emit(evName, f.squelchEventData())
 	}
 
-	f.lastSquelch = f.sql.IsMuted()
-
-	if f.outputBufferPos+len(fmDemodData) >= len(f.outputBuffer) {
-		// We have more samples than we need to return. Let's break
-		var diff = len(f.outputBuffer) - f.outputBufferPos
-		copy(f.outputBuffer[f.outputBufferPos:], fmDemodData[:diff])
-		var outBuf = make([]float32, len(f.outputBuffer))
-		copy(outBuf, f.outputBuffer)
-
-		// Now we have a outBuf ready to delivery, but we need to process remaining samples
-		fmDemodData = fmDemodData[diff:]
-		f.outputBufferPos = 0
-		copy(f.outputBuffer, fmDemodData)
-		f.outputBufferPos += len(fmDemodData)
-
-		return DemodData{
-			OutputRate: f.outputRate,
-			Level:      f.sql.GetAvgLevel(),
-			Data:       outBuf,
-		}
-	} else {
-		// We dont have enough to overflow, let's just append
-		copy(f.outputBuffer[f.outputBufferPos:], fmDemodData)
-		f.outputBufferPos += len(fmDemodData)
-
-		return nil
-	}
+	f.lastSquelch = muted
 }
@@ -0,0 +1,18 @@
+package demodcore
+
+// Measurements are the signal quality measurements of the demodulator channel.
+// Values that does not apply to the demodulator are always 0.
+type Measurements struct {
+	// SNR is the in-channel Signal to Noise Ratio in dB
+	SNR float32
+	// FrequencyError is the carrier frequency error in Hertz
+	FrequencyError float32
+	// DeviationPeak and DeviationRMS are the FM deviation in Hertz
+	DeviationPeak float32
+	DeviationRMS  float32
+	// MER (in dB) and EVM (in percent) are measured against the decided symbols
+	MER float32
+	EVM float32
+}
+
+const channelSNRFFTSize = 1024
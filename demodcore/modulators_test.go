@@ -0,0 +1,191 @@
+package demodcore
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/flowgraph"
+)
+
+const (
+	roundTripSampleRate = 240000
+	roundTripOutputRate = 48000
+	roundTripTone       = 1000
+	roundTripBlocks     = 4
+)
+
+func makeTone(length int, amplitude, frequency, sampleRate float64) []float32 {
+	var tone = make([]float32, length)
+	var omega = 2 * math.Pi * frequency / sampleRate
+
+	for i := range tone {
+		tone[i] = float32(amplitude * math.Sin(omega*float64(i)))
+	}
+
+	return tone
+}
+
+// toneLevel returns the amplitude of the frequency component of data, and the ratio in dB
+// between its power and the power of everything else
+func toneLevel(data []float32, frequency, sampleRate float64) (amplitude, sinad float64) {
+	var omega = 2 * math.Pi * frequency / sampleRate
+	var re, im, power float64
+
+	// Whole periods only, so the other components don't leak into the tone
+	data = data[:len(data)-len(data)%int(sampleRate/frequency)]
+
+	for i, v := range data {
+		re += float64(v) * math.Cos(omega*float64(i))
+		im += float64(v) * math.Sin(omega*float64(i))
+		power += float64(v) * float64(v)
+	}
+
+	amplitude = 2 * math.Hypot(re, im) / float64(len(data))
+	power /= float64(len(data))
+
+	var tonePower = amplitude * amplitude / 2
+	return amplitude, 10 * math.Log10(tonePower/(power-tonePower))
+}
+
+// demodulate runs iq through the demodulator on a flowgraph, and returns the audio of the last block
+func demodulate(t *testing.T, demod DemodCore, iq []complex64) []float32 {
+	var g = flowgraph.MakeFlowgraph(1 << 16)
+	var src = g.AddComplexSource("source")
+	var blocks = make(chan DemodData, roundTripBlocks)
+
+	demod.Connect(g, src.Port(), func(data interface{}) {
+		select {
+		case blocks <- data.(DemodData):
+		default:
+		}
+	})
+
+	g.Start()
+	defer g.Stop()
+
+	for i := 0; i < len(iq); i += 4096 {
+		var end = i + 4096
+		if end > len(iq) {
+			end = len(iq)
+		}
+		src.Write(iq[i:end])
+	}
+
+	var last DemodData
+	for i := 0; i < roundTripBlocks; i++ {
+		select {
+		case last = <-blocks:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Expected %d audio blocks got %d", roundTripBlocks, i)
+		}
+	}
+
+	if last.OutputRate != roundTripOutputRate {
+		t.Errorf("Expected output rate %d got %d", roundTripOutputRate, last.OutputRate)
+	}
+
+	return last.Data
+}
+
+func TestFMDemodRoundTrip(t *testing.T) {
+	const deviation = 5000
+	var length = (roundTripBlocks + 1) * demodOutputSize * roundTripSampleRate / roundTripOutputRate
+
+	for _, amplitude := range []float64{0.25, 0.5} {
+		var iq = dsp.MakeFMModulator(deviation, roundTripSampleRate, 0).Work(makeTone(length, amplitude, roundTripTone, roundTripSampleRate))
+		var demod = MakeCustomFMDemodulator(roundTripSampleRate, 12500, roundTripOutputRate, 0, -150, 0.01, deviation)
+		var audio = demodulate(t, demod, iq)
+
+		var level, sinad = toneLevel(audio, roundTripTone, roundTripOutputRate)
+		if sinad < 30 {
+			t.Errorf("Amplitude %f: Expected the %d Hz tone to be recovered got %f dB of SINAD", amplitude, roundTripTone, sinad)
+		}
+
+		// The max deviation gives a audio level of 1 before the 0.25 gain of the final stage
+		if math.Abs(level-amplitude*0.25) > 0.02*amplitude {
+			t.Errorf("Amplitude %f: Expected a audio level of %f got %f", amplitude, amplitude*0.25, level)
+		}
+	}
+}
+
+func TestFMDemodRoundTripDeemphasis(t *testing.T) {
+	const deviation = 5000
+	const tau = 75e-6
+	var length = (roundTripBlocks + 1) * demodOutputSize * roundTripSampleRate / roundTripOutputRate
+
+	// The pre-emphasis of the modulator and the de-emphasis of the demodulator should cancel out
+	var levels []float64
+	for _, frequency := range []float64{500, 3000} {
+		var iq = dsp.MakeFMModulator(deviation, roundTripSampleRate, tau).Work(makeTone(length, 0.1, frequency, roundTripSampleRate))
+		var demod = MakeCustomFMDemodulator(roundTripSampleRate, 12500, roundTripOutputRate, tau, -150, 0.01, deviation)
+		var audio = demodulate(t, demod, iq)
+
+		var level, sinad = toneLevel(audio, frequency, roundTripOutputRate)
+		if sinad < 30 {
+			t.Errorf("Frequency %f: Expected the tone to be recovered got %f dB of SINAD", frequency, sinad)
+		}
+		levels = append(levels, level)
+	}
+
+	// Without the de-emphasis the 3 kHz tone would be 4.5 dB over, the channel filter takes some of it
+	if math.Abs(20*math.Log10(levels[1]/levels[0])) > 2 {
+		t.Errorf("Expected a flat response got %f and %f", levels[0], levels[1])
+	}
+}
+
+func TestAMDemodRoundTrip(t *testing.T) {
+	var length = (roundTripBlocks + 1) * demodOutputSize * roundTripSampleRate / roundTripOutputRate
+
+	var indexes = []float32{0.3, 0.8}
+	var levels = make([]float64, len(indexes))
+
+	for i, index := range indexes {
+		var iq = dsp.MakeAMModulator(index).Work(makeTone(length, 1, roundTripTone, roundTripSampleRate))
+		var demod = MakeCustomAMDemodulator(roundTripSampleRate, 10000, roundTripOutputRate, 5000, -150, 0.01)
+		var audio = demodulate(t, demod, iq)
+
+		var level, sinad = toneLevel(audio, roundTripTone, roundTripOutputRate)
+		if sinad < 30 {
+			t.Errorf("Index %f: Expected the %d Hz tone to be recovered got %f dB of SINAD", index, roundTripTone, sinad)
+		}
+		levels[i] = level
+	}
+
+	// The carrier is removed, and the feed forward AGC normalizes the envelope peak (1 + index)
+	var expected = (0.8 / 1.8) / (0.3 / 1.3)
+	if math.Abs(levels[1]/levels[0]-expected) > 0.05*expected {
+		t.Errorf("Expected the audio levels to follow the modulation index got %f and %f", levels[0], levels[1])
+	}
+}
+
+func TestDemodRoundTripNoiseReduction(t *testing.T) {
+	const deviation = 5000
+	var length = (roundTripBlocks + 1) * demodOutputSize * roundTripSampleRate / roundTripOutputRate
+	var tone = makeTone(length, 0.5, roundTripTone, roundTripSampleRate)
+
+	// The noise reduction returns its samples in frames, not one for each input sample
+	var fm = MakeCustomFMDemodulator(roundTripSampleRate, 12500, roundTripOutputRate, 0, -150, 0.01, deviation)
+	fm.SetNoiseReduction(dsp.MakeSpectralNoiseReduction(512, 12))
+
+	var am = MakeCustomAMDemodulator(roundTripSampleRate, 10000, roundTripOutputRate, 5000, -150, 0.01)
+	am.SetNoiseReduction(dsp.MakeSpectralNoiseReduction(512, 12))
+
+	var demods = map[string]struct {
+		demod DemodCore
+		iq    []complex64
+	}{
+		"FM": {fm, dsp.MakeFMModulator(deviation, roundTripSampleRate, 0).Work(tone)},
+		"AM": {am, dsp.MakeAMModulator(0.5).Work(tone)},
+	}
+
+	for name, d := range demods {
+		var audio = demodulate(t, d.demod, d.iq)
+
+		var _, sinad = toneLevel(audio, roundTripTone, roundTripOutputRate)
+		if sinad < 20 {
+			t.Errorf("%s: Expected the %d Hz tone to be kept by the noise reduction got %f dB of SINAD", name, roundTripTone, sinad)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package demodcore
+
+import "github.com/racerxdl/segdsp/dsp"
+
+// NoiseBlankerParams are the Noise Blanker parameters reported in the demodulator params
+type NoiseBlankerParams struct {
+	NoiseBlanker          bool
+	NoiseBlankerThreshold float32
+	NoiseBlankerWidth     int
+	NoiseBlankerMode      dsp.NoiseBlankerMode
+	BlankedSamples        uint64
+}
+
+func makeNoiseBlankerParams(nb *dsp.NoiseBlanker) NoiseBlankerParams {
+	if nb == nil {
+		return NoiseBlankerParams{}
+	}
+
+	return NoiseBlankerParams{
+		NoiseBlanker:          true,
+		NoiseBlankerThreshold: nb.GetThreshold(),
+		NoiseBlankerWidth:     nb.GetBlankingWidth(),
+		NoiseBlankerMode:      nb.GetMode(),
+		BlankedSamples:        nb.GetBlankedSamples(),
+	}
+}
@@ -0,0 +1,22 @@
+package demodcore
+
+import "github.com/racerxdl/segdsp/dsp"
+
+// NoiseReductionParams are the Spectral Noise Reduction parameters reported in the demodulator params
+type NoiseReductionParams struct {
+	NoiseReduction          bool
+	NoiseReductionAmount    float32
+	NoiseReductionFrameSize int
+}
+
+func makeNoiseReductionParams(nr *dsp.SpectralNoiseReduction) NoiseReductionParams {
+	if nr == nil {
+		return NoiseReductionParams{}
+	}
+
+	return NoiseReductionParams{
+		NoiseReduction:          true,
+		NoiseReductionAmount:    nr.GetReduction(),
+		NoiseReductionFrameSize: nr.GetFrameSize(),
+	}
+}
@@ -0,0 +1,30 @@
+package demodcore
+
+import "github.com/racerxdl/segdsp/dsp"
+
+// NotchParams are the Notch Filter parameters reported in the demodulator params
+type NotchParams struct {
+	Notch          bool
+	NotchMode      dsp.NotchMode
+	NotchTaps      int
+	NotchDelay     int
+	NotchStep      float32
+	NotchFrequency float32
+	NotchBandwidth float32
+}
+
+func makeNotchParams(n *dsp.NotchFilter) NotchParams {
+	if n == nil {
+		return NotchParams{}
+	}
+
+	return NotchParams{
+		Notch:          true,
+		NotchMode:      n.GetMode(),
+		NotchTaps:      n.GetNumTaps(),
+		NotchDelay:     n.GetDelay(),
+		NotchStep:      n.GetStep(),
+		NotchFrequency: n.GetFrequency(),
+		NotchBandwidth: n.GetBandwidth(),
+	}
+}
@@ -0,0 +1,68 @@
+package demodcore
+
+import (
+	"github.com/racerxdl/segdsp/flowgraph"
+)
+
+// demodOutputSize is the number of audio samples delivered on each DemodData
+const demodOutputSize = 16384
+
+// outputBuffer groups the demodulated samples in blocks of demodOutputSize samples
+type outputBuffer struct {
+	data   []float32
+	pos    int
+	offset uint64
+}
+
+func makeOutputBuffer() outputBuffer {
+	return outputBuffer{
+		data: make([]float32, demodOutputSize),
+	}
+}
+
+// write adds the samples (offset is the stream offset of the first one) to the buffer,
+// calling cb with a copy of each full block and the stream offset of its first sample
+func (o *outputBuffer) write(data []float32, offset uint64, cb func(block []float32, offset uint64)) {
+	for len(data) > 0 {
+		if o.pos == 0 {
+			o.offset = offset
+		}
+
+		var n = copy(o.data[o.pos:], data)
+		o.pos += n
+		offset += uint64(n)
+		data = data[n:]
+
+		if o.pos == len(o.data) {
+			var block = make([]float32, len(o.data))
+			copy(block, o.data)
+			o.pos = 0
+			cb(block, o.offset)
+		}
+	}
+}
+
+// addOutputSink adds the sink that delivers the demodulated audio in DemodData blocks to cb.
+// The time and frequency tags of the stream are used to report the time and frequency of each block.
+func addOutputSink(g *flowgraph.Flowgraph, name string, input *flowgraph.Port, outputRate uint32, output *outputBuffer, level func() float32, cb func(interface{})) {
+	var timeTracker = flowgraph.MakeTimeTracker(float64(outputRate))
+	var frequency float64
+
+	g.AddFloatTaggedSink(name, input, func(data []float32, offset uint64, tags []flowgraph.Tag) {
+		timeTracker.Update(tags)
+		if tag := flowgraph.FindTag(tags, flowgraph.TagFrequency); tag != nil {
+			frequency, _ = tag.Value.(float64)
+		}
+
+		output.write(data, offset, func(block []float32, blockOffset uint64) {
+			var timestamp, _ = timeTracker.TimeAt(blockOffset)
+			cb(DemodData{
+				OutputRate: outputRate,
+				Level:      level(),
+				Data:       block,
+				Timestamp:  timestamp,
+				Frequency:  frequency,
+			})
+		})
+	})
+}
@@ -0,0 +1,363 @@
+package demodcore
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/dsp/digital"
+	"github.com/racerxdl/segdsp/eventmanager"
+	"github.com/racerxdl/segdsp/flowgraph"
+	"github.com/racerxdl/segdsp/tools"
+)
+
+// pskMinSamplesPerSymbol is the minimum number of samples per symbol left by the first stage decimation
+const pskMinSamplesPerSymbol = 4
+
+// pskRRCAlpha is the roll-off factor of the matched filter, and pskRRCSymbols its length in symbols
+const pskRRCAlpha = 0.35
+const pskRRCSymbols = 11
+
+const pskAGCRate = 1e-3
+const pskClockBandwidth = 0.02
+const pskClockLimit = 0.005
+const pskCostasBandwidth = 2 * math.Pi / 100
+
+// pskAFCCostasRange is the Costas Loop frequency range (in cycles per symbol) with the AFC enabled. The AFC pulls in
+// the carrier, and the Costas Loop is kept under the false lock points while it does.
+const pskAFCCostasRange = 0.02
+
+// pskConstellationPoints and pskEyeTraces are the symbols and traces kept for the diagnostics
+const pskConstellationPoints = 512
+const pskEyeTraces = 48
+
+// PSKDemod recovers the symbols of a BPSK, QPSK or 8PSK signal, for the measurements and the diagnostics.
+// There is no audio on this mode, so the audio options are ignored.
+type PSKDemod struct {
+	sampleRate       float64
+	signalBw         float64
+	symbolRate       float32
+	decimation       int
+	quadRate         float64
+	firstStage       *dsp.FirFilter
+	agc              *dsp.SimpleAGC
+	matchedFilter    *dsp.FirFilter
+	eyeTap           *digital.EyeTap
+	clock            *digital.ComplexGardnerClockRecovery
+	symbolAGC        *dsp.SimpleAGC
+	costas           dsp.CostasLoop
+	lockRotation     complex64
+	mer              *digital.ComplexMERMeter
+	constellationTap *digital.ConstellationTap
+	sql              *dsp.Squelch
+	packedParams     PSKDemodParams
+	ev               *eventmanager.EventManager
+	lastSquelch      bool
+	nb               *dsp.NoiseBlanker
+	snr              *dsp.ChannelSNR
+	afc              *dsp.AFC
+	lastAFCOffset    float32
+	status           demodStatus
+}
+
+type PSKDemodParams struct {
+	SampleRate      uint32
+	SignalBandwidth float64
+	SymbolRate      float32
+	Order           int
+	Squelch         float32
+	SquelchAlpha    float32
+	AFCEnabled      bool
+	AFCPullInRange  float32
+	AFCOffset       float32
+	SquelchTimingParams
+	NoiseBlankerParams
+}
+
+// MakePSKDemodulator creates a PSK demodulator of order 2 (BPSK), 4 (QPSK) or 8 (8PSK) for root raised cosine
+// shaped symbols at symbolRate
+func MakePSKDemodulator(sampleRate uint32, signalBw float64, symbolRate float32, order int, squelch, squelchAlpha float32) *PSKDemod {
+	var constellation digital.Constellation
+	var lockRotation = complex64(1)
+
+	switch order {
+	case 2:
+		constellation = digital.BPSKConstellation
+	case 4:
+		constellation = digital.QPSKConstellation
+	case 8:
+		constellation = digital.PSK8Constellation
+		// The 8th order Costas Loop locks with the points at pi / 8 from the axes
+		lockRotation = complex64(cmplx.Rect(1, -math.Pi/8))
+	default:
+		panic("PSK Demodulator order should be 2, 4 or 8")
+	}
+
+	var decim = int(math.Floor(float64(sampleRate) / float64(symbolRate) / pskMinSamplesPerSymbol))
+
+	if decim < 1 {
+		decim = 1
+	}
+
+	var quadRate = float64(sampleRate) / float64(decim)
+	var samplesPerSymbol = float32(quadRate / float64(symbolRate))
+
+	return &PSKDemod{
+		sampleRate: float64(sampleRate),
+		signalBw:   signalBw,
+		symbolRate: symbolRate,
+		decimation: decim,
+		quadRate:   quadRate,
+		firstStage: dsp.MakeDecimationFirFilter(
+			decim,
+			dsp.MakeLowPassFixed(
+				1,
+				float64(sampleRate),
+				signalBw/2,
+				127,
+			),
+		),
+		agc: dsp.MakeSimpleAGC(pskAGCRate, 1, 1, 0),
+		matchedFilter: dsp.MakeFirFilter(
+			dsp.MakeRRC(1, quadRate, float64(symbolRate), pskRRCAlpha, int(pskRRCSymbols*samplesPerSymbol)),
+		),
+		eyeTap:           digital.MakeEyeTap(samplesPerSymbol, pskEyeTraces, 1),
+		clock:            digital.MakeComplexGardnerClockRecovery(samplesPerSymbol, pskClockBandwidth, pskClockLimit),
+		symbolAGC:        dsp.MakeSimpleAGC(pskAGCRate, 1, 1, 0),
+		costas:           makePSKCostasLoop(order, 1),
+		lockRotation:     lockRotation,
+		mer:              digital.MakeComplexMERMeter(constellation),
+		constellationTap: digital.MakeConstellationTap(pskConstellationPoints, 1),
+		sql:              dsp.MakeSquelch(squelch, squelchAlpha),
+		packedParams: PSKDemodParams{
+			SampleRate:      sampleRate,
+			SignalBandwidth: signalBw,
+			SymbolRate:      symbolRate,
+			Order:           order,
+			Squelch:         squelch,
+			SquelchAlpha:    squelchAlpha,
+		},
+		lastSquelch: true,
+		snr:         dsp.MakeChannelSNR(float32(sampleRate), float32(signalBw), channelSNRFFTSize),
+		status:      demodStatus{muted: true},
+	}
+}
+
+// makePSKCostasLoop creates the Costas Loop for the PSK order, limited to +-maxFrequency radians per symbol
+func makePSKCostasLoop(order int, maxFrequency float32) dsp.CostasLoop {
+	switch order {
+	case 2:
+		return dsp.MakeCostasLoop2WithFrequencyRange(pskCostasBandwidth, -maxFrequency, maxFrequency)
+	case 4:
+		return dsp.MakeCostasLoop4WithFrequencyRange(pskCostasBandwidth, -maxFrequency, maxFrequency)
+	}
+
+	return dsp.MakeCostasLoop8WithFrequencyRange(pskCostasBandwidth, -maxFrequency, maxFrequency)
+}
+
+func (f *PSKDemod) GetDemodParams() interface{} {
+	f.status.lock.Lock()
+	defer f.status.lock.Unlock()
+	return f.packedParams
+}
+
+func (f *PSKDemod) SetEventManager(ev *eventmanager.EventManager) {
+	f.ev = ev
+}
+
+func (f *PSKDemod) GetLevel() float32 {
+	return f.status.getLevel()
+}
+
+func (f *PSKDemod) IsMuted() bool {
+	return f.status.isMuted()
+}
+
+// GetMeasurements returns the current channel measurements
+func (f *PSKDemod) GetMeasurements() Measurements {
+	return f.status.getMeasurements()
+}
+
+// EnableAFC enables the Automatic Frequency Control with the specified pull-in range in Hertz.
+// It keeps the signal centered in the channel filter, so the Costas Loop only has to pull in the residual offset.
+func (f *PSKDemod) EnableAFC(pullInRange float32) {
+	var signalBw = f.symbolRate * (1 + pskRRCAlpha)
+	f.afc = dsp.MakeAFC(float32(f.sampleRate), float32(f.quadRate), signalBw, pullInRange)
+	f.costas = makePSKCostasLoop(f.packedParams.Order, 2*math.Pi*pskAFCCostasRange)
+	f.lastAFCOffset = 0
+	f.packedParams.AFCEnabled = true
+	f.packedParams.AFCPullInRange = pullInRange
+	f.packedParams.AFCOffset = 0
+}
+
+// DisableAFC disables the Automatic Frequency Control
+func (f *PSKDemod) DisableAFC() {
+	f.afc = nil
+	f.costas = makePSKCostasLoop(f.packedParams.Order, 1)
+	f.packedParams.AFCEnabled = false
+	f.packedParams.AFCPullInRange = 0
+	f.packedParams.AFCOffset = 0
+}
+
+// GetAFCOffset returns the current carrier offset correction in Hertz
+func (f *PSKDemod) GetAFCOffset() float32 {
+	f.status.lock.Lock()
+	defer f.status.lock.Unlock()
+	return f.packedParams.AFCOffset
+}
+
+func (f *PSKDemod) updateAFC() {
+	var offset = f.afc.GetOffset()
+	f.status.lock.Lock()
+	f.packedParams.AFCOffset = offset
+	f.status.lock.Unlock()
+
+	if f.ev != nil && tools.Abs(offset-f.lastAFCOffset) >= afcEventThreshold {
+		f.lastAFCOffset = offset
+		f.ev.Emit(eventmanager.EvAFCOffset, eventmanager.AFCEventData{
+			Offset:      offset,
+			PullInRange: f.afc.GetPullInRange(),
+		})
+	}
+}
+
+// GetConstellationTap returns the tap of the symbols after the clock and carrier recovery
+func (f *PSKDemod) GetConstellationTap() *digital.ConstellationTap {
+	return f.constellationTap
+}
+
+// GetEyeTap returns the tap of the matched filter output
+func (f *PSKDemod) GetEyeTap() *digital.EyeTap {
+	return f.eyeTap
+}
+
+// SetNoiseBlanker sets a Noise Blanker to run over the raw IQ before the first stage. nil disables it.
+func (f *PSKDemod) SetNoiseBlanker(nb *dsp.NoiseBlanker) {
+	f.nb = nb
+	f.packedParams.NoiseBlankerParams = makeNoiseBlankerParams(nb)
+}
+
+// SetNoiseReduction is ignored, there is no audio to run it over
+func (f *PSKDemod) SetNoiseReduction(nr *dsp.SpectralNoiseReduction) {}
+
+// SetAudioAGC is ignored, there is no audio to run it over
+func (f *PSKDemod) SetAudioAGC(agc *dsp.AudioAGC) {}
+
+// SetVolume is ignored, there is no audio to run it over
+func (f *PSKDemod) SetVolume(volume *dsp.AudioGain) {}
+
+// SetLimiter is ignored, there is no audio to run it over
+func (f *PSKDemod) SetLimiter(limiter *dsp.SoftLimiter) {}
+
+// SetSquelchTiming sets the squelch hysteresis in dB, and the attack and hang times in seconds
+func (f *PSKDemod) SetSquelchTiming(hysteresis, attack, hang float32) {
+	f.packedParams.SquelchTimingParams = SquelchTimingParams{
+		SquelchHysteresis: hysteresis,
+		SquelchAttack:     attack,
+		SquelchHang:       hang,
+	}
+
+	applyPowerSquelchTiming(f.sql, f.packedParams.SquelchTimingParams, f.quadRate)
+}
+
+// Connect adds the demodulator blocks to the flowgraph, fed with the IQ samples of input.
+// There is no audio, so cb is never called. The symbols are only used by the measurements and the diagnostics.
+func (f *PSKDemod) Connect(g *flowgraph.Flowgraph, input *flowgraph.Port, cb func(interface{})) {
+	var channel = g.AddBlock("psk-channel", input,
+		flowgraph.ComplexFunc(f.channelInput),
+		f.firstStage,
+		flowgraph.ComplexFunc(f.channelOutput),
+		f.agc,
+		f.matchedFilter,
+		f.eyeTap,
+	)
+
+	var symbols = g.AddBlock("psk-symbols", channel,
+		f.clock,
+		f.symbolAGC,
+		f.costas,
+		flowgraph.ComplexFunc(f.symbolsOutput),
+		f.mer,
+		flowgraph.ComplexFunc(f.symbolsMeasured),
+		f.constellationTap,
+	)
+
+	g.AddComplexSink("psk-output", symbols, func([]complex64) {})
+}
+
+// channelInput runs over the raw IQ samples, before the channel filter
+func (f *PSKDemod) channelInput(data []complex64) []complex64 {
+	if f.nb != nil {
+		data = f.nb.Work(data)
+		var blanked = f.nb.GetBlankedSamples()
+		f.status.lock.Lock()
+		f.packedParams.BlankedSamples = blanked
+		f.status.lock.Unlock()
+	}
+
+	f.snr.Feed(data)
+
+	if f.afc != nil {
+		data = f.afc.Correct(data)
+	}
+
+	return data
+}
+
+// channelOutput runs over the channel filtered samples
+func (f *PSKDemod) channelOutput(data []complex64) []complex64 {
+	if f.afc != nil {
+		data = f.afc.Track(data)
+		f.updateAFC()
+	}
+
+	data = f.sql.Work(data)
+
+	var muted = f.sql.IsMuted()
+
+	f.status.lock.Lock()
+	f.status.level = f.sql.GetAvgLevel()
+	f.status.muted = muted
+	f.status.measurements.SNR = f.snr.GetSNR()
+	f.status.lock.Unlock()
+
+	if f.lastSquelch != muted && f.ev != nil {
+		var evName string
+		if muted {
+			evName = eventmanager.EvSquelchOn
+		} else {
+			evName = eventmanager.EvSquelchOff
+		}
+		f.ev.Emit(evName, eventmanager.SquelchEventData{
+			Threshold: f.sql.GetThreshold(),
+			AvgValue:  f.sql.GetAvgLevel(),
+			Criterion: eventmanager.SquelchCriterionPower,
+		})
+	}
+
+	f.lastSquelch = muted
+
+	return data
+}
+
+// symbolsOutput runs over the Costas Loop output, moving the symbols to the constellation points
+func (f *PSKDemod) symbolsOutput(data []complex64) []complex64 {
+	if f.lockRotation != 1 {
+		for i := range data {
+			data[i] *= f.lockRotation
+		}
+	}
+
+	return data
+}
+
+// symbolsMeasured runs over the symbols after the MER meter
+func (f *PSKDemod) symbolsMeasured(data []complex64) []complex64 {
+	f.status.lock.Lock()
+	f.status.measurements.FrequencyError = f.costas.GetFrequencyHz(f.symbolRate) + f.packedParams.AFCOffset
+	f.status.measurements.MER = f.mer.GetMER()
+	f.status.measurements.EVM = f.mer.GetEVM()
+	f.status.lock.Unlock()
+
+	return data
+}
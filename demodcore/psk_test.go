@@ -0,0 +1,123 @@
+package demodcore
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/flowgraph"
+)
+
+const (
+	pskTestSampleRate = 96000
+	pskTestSymbolRate = 4800
+	pskTestSymbols    = 20000
+)
+
+// makePSKSignal makes a random PSK signal of order 2^bits with a carrier offset, a clock offset of 100 ppm and
+// 30 dB of SNR
+func makePSKSignal(bits int, offset float32) []complex64 {
+	const sps = pskTestSampleRate / pskTestSymbolRate
+
+	var r = rand.New(rand.NewSource(1))
+	var data = make([]byte, pskTestSymbols)
+	for i := range data {
+		data[i] = byte(r.Intn(1 << uint(bits)))
+	}
+
+	var psk = dsp.MakePSKModulator(bits, sps, dsp.MakeRRC(sps, sps, 1, pskRRCAlpha, pskRRCSymbols*sps))
+	var c = dsp.MakeChannelSimulator(pskTestSampleRate, 1)
+	c.SetFrequencyOffset(offset, 0)
+	c.SetClockOffset(100)
+	c.SetSNR(30, 1.0/sps)
+
+	return c.Work(psk.Work(data))
+}
+
+// runPSK runs iq through the demodulator on a flowgraph
+func runPSK(demod *PSKDemod, iq []complex64) {
+	var g = flowgraph.MakeFlowgraph(1 << 16)
+	var src = g.AddComplexSource("source")
+	demod.Connect(g, src.Port(), nil)
+
+	g.Start()
+	for i := 0; i < len(iq); i += 4096 {
+		var end = i + 4096
+		if end > len(iq) {
+			end = len(iq)
+		}
+		src.Write(iq[i:end])
+	}
+	g.Stop()
+}
+
+func TestPSKDemod(t *testing.T) {
+	const offset = 100
+
+	for _, bits := range []int{1, 2, 3} {
+		var order = 1 << uint(bits)
+		var demod = MakePSKDemodulator(pskTestSampleRate, 9600, pskTestSymbolRate, order, -150, 0.01)
+		demod.GetConstellationTap().SetEnabled(true)
+		demod.GetEyeTap().SetEnabled(true)
+
+		runPSK(demod, makePSKSignal(bits, offset))
+
+		var m = demod.GetMeasurements()
+		if m.MER < 20 {
+			t.Errorf("%dPSK: Expected a MER over 20 dB got %f dB", order, m.MER)
+		}
+
+		if math.Abs(float64(m.FrequencyError-offset)) > 10 {
+			t.Errorf("%dPSK: Expected a frequency error of %d Hz got %f Hz", order, offset, m.FrequencyError)
+		}
+
+		// The diagnostics see the symbols on the constellation, and the eye of the matched filter output
+		var points = demod.GetConstellationTap().GetPoints()
+		if len(points) != pskConstellationPoints {
+			t.Errorf("%dPSK: Expected %d constellation points got %d", order, pskConstellationPoints, len(points))
+		}
+
+		for _, p := range points {
+			var magnitude = math.Hypot(float64(real(p)), float64(imag(p)))
+			if math.Abs(magnitude-1) > 0.3 {
+				t.Errorf("%dPSK: Expected the symbols on the unit circle got %v", order, p)
+				break
+			}
+		}
+
+		if traces := demod.GetEyeTap().GetTraces(); len(traces) != pskEyeTraces {
+			t.Errorf("%dPSK: Expected %d eye traces got %d", order, pskEyeTraces, len(traces))
+		}
+	}
+}
+
+func TestPSKDemodAFC(t *testing.T) {
+	// Out of the Costas Loop pull-in range, and at the edge of the channel filter
+	const offset = 1500
+
+	for _, bits := range []int{1, 2, 3} {
+		var order = 1 << uint(bits)
+		var demod = MakePSKDemodulator(pskTestSampleRate, 9600, pskTestSymbolRate, order, -150, 0.01)
+		demod.EnableAFC(3000)
+
+		runPSK(demod, makePSKSignal(bits, offset))
+
+		var m = demod.GetMeasurements()
+		if m.MER < 20 {
+			t.Errorf("%dPSK: Expected a MER over 20 dB got %f dB", order, m.MER)
+		}
+
+		if math.Abs(float64(demod.GetAFCOffset()-offset)) > 50 {
+			t.Errorf("%dPSK: Expected a AFC offset of %d Hz got %f Hz", order, offset, demod.GetAFCOffset())
+		}
+
+		if math.Abs(float64(m.FrequencyError-offset)) > 10 {
+			t.Errorf("%dPSK: Expected a frequency error of %d Hz got %f Hz", order, offset, m.FrequencyError)
+		}
+
+		if !demod.GetDemodParams().(PSKDemodParams).AFCEnabled {
+			t.Errorf("%dPSK: Expected the AFC on the params", order)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package demodcore
+
+import "github.com/racerxdl/segdsp/dsp"
+
+// Squelch Modes
+const (
+	SquelchModePower = "power"
+	SquelchModeNoise = "noise"
+)
+
+// noiseSquelchCutRatio is the noise squelch high pass cut frequency relative to the signal bandwidth.
+// It should be over the audio (or stereo multiplex) band, but inside the channel filter.
+const noiseSquelchCutRatio = 0.375
+
+const noiseSquelchAlpha = 0.01
+
+// SquelchTimingParams are the squelch hysteresis (dB), attack and hang times (seconds) reported in the demodulator params
+type SquelchTimingParams struct {
+	SquelchHysteresis float32
+	SquelchAttack     float32
+	SquelchHang       float32
+}
+
+func secondsToSamples(seconds float32, sampleRate float64) int {
+	return int(float64(seconds) * sampleRate)
+}
+
+func applyPowerSquelchTiming(sql *dsp.Squelch, timing SquelchTimingParams, sampleRate float64) {
+	sql.SetHysteresis(timing.SquelchHysteresis)
+	sql.SetAttack(secondsToSamples(timing.SquelchAttack, sampleRate))
+	sql.SetHang(secondsToSamples(timing.SquelchHang, sampleRate))
+}
+
+func applyNoiseSquelchTiming(sql *dsp.NoiseSquelch, timing SquelchTimingParams, sampleRate float64) {
+	sql.SetHysteresis(timing.SquelchHysteresis)
+	sql.SetAttack(secondsToSamples(timing.SquelchAttack, sampleRate))
+	sql.SetHang(secondsToSamples(timing.SquelchHang, sampleRate))
+}
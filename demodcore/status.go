@@ -0,0 +1,37 @@
+package demodcore
+
+import "sync"
+
+// demodStatus is the demodulator state read outside of the flowgraph. Each block runs in its own goroutine, so the
+// blocks copy their level, squelch and measurements here after each chunk, and the getters only read the copies.
+// The lock also guards the demodulator params changed by the blocks (like the AFC offset or the blanked samples).
+type demodStatus struct {
+	lock         sync.Mutex
+	level        float32
+	muted        bool
+	measurements Measurements
+}
+
+func (s *demodStatus) getLevel() float32 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.level
+}
+
+func (s *demodStatus) isMuted() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.muted
+}
+
+func (s *demodStatus) getMeasurements() Measurements {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.measurements
+}
+
+func (s *demodStatus) setMuted(muted bool) {
+	s.lock.Lock()
+	s.muted = muted
+	s.lock.Unlock()
+}
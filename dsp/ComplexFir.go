@@ -7,6 +7,7 @@ type CTFirFilter struct {
 	sampleHistory []complex64
 	tapsLen       int
 	decimation    int
+	lastHistory   int
 }
 
 func MakeCTFirFilter(taps []complex64) *CTFirFilter {
@@ -28,6 +29,7 @@ func MakeDecimationCTFirFilter(decimation int, taps []complex64) *CTFirFilter {
 }
 
 func (f *CTFirFilter) Filter(data []complex64, length int) {
+	f.lastHistory = len(f.sampleHistory)
 	var samples = append(f.sampleHistory, data...)
 	for i := 0; i < length; i++ {
 		ComplexDotProduct(&data[i], samples[i:i+f.tapsLen], f.taps)
@@ -47,6 +49,7 @@ func (f *CTFirFilter) FilterOut(data []complex64) []complex64 {
 }
 
 func (f *CTFirFilter) FilterBuffer(input, output []complex64) int {
+	f.lastHistory = len(f.sampleHistory)
 	var samples = append(f.sampleHistory, input...)
 	var length = len(samples) - f.tapsLen
 
@@ -91,50 +94,28 @@ func (f *CTFirFilter) FilterDecimate(data []complex64, decimate int, length int)
 }
 
 func (f *CTFirFilter) FilterDecimateOut(data []complex64, decimate int) []complex64 {
-	var samples = append(f.sampleHistory, data...)
-	var length = len(samples) / decimate
-	var remainder = len(samples) % decimate
-	var output = make([]complex64, length)
-	for i := 0; i < length; i++ {
-		var srcIdx = decimate * i
-		var sl = samples[srcIdx:]
-		if len(sl) < len(f.taps) {
-			div := len(sl) / decimate
-			if len(sl)%decimate > 0 {
-				div++
-			}
-			length -= div
-			remainder += div * decimate
-			break
-		}
-		output[i] = ComplexDotProductResult(sl, f.taps)
-	}
-	f.sampleHistory = samples[len(samples)-remainder:]
-	return output
+	var output = make([]complex64, (len(f.sampleHistory)+len(data))/decimate+1)
+	var length = f.FilterDecimateBuffer(data, output, decimate)
+	return output[:length]
 }
 
 func (f *CTFirFilter) FilterDecimateBuffer(input, output []complex64, decimate int) int {
+	f.lastHistory = len(f.sampleHistory)
 	var samples = append(f.sampleHistory, input...)
-	var length = len(samples) / decimate
-	var remainder = len(samples) % decimate
+
+	// Only the outputs with all the taps over the samples, the rest stays in the history
+	var length = 0
+	if len(samples) >= f.tapsLen {
+		length = (len(samples)-f.tapsLen)/decimate + 1
+	}
+	var remainder = len(samples) - length*decimate
 
 	if len(output) < length {
 		panic("There is not enough space in output buffer")
 	}
 
 	for i := 0; i < length; i++ {
-		var srcIdx = decimate * i
-		var sl = samples[srcIdx:]
-		if len(sl) < len(f.taps) {
-			div := len(sl) / decimate
-			if len(sl)%decimate > 0 {
-				div++
-			}
-			length -= div
-			remainder += div * decimate
-			break
-		}
-		output[i] = ComplexDotProductResult(sl, f.taps)
+		output[i] = ComplexDotProductResult(samples[decimate*i:], f.taps)
 	}
 	f.sampleHistory = samples[len(samples)-remainder:]
 	return length
@@ -150,4 +131,8 @@ func (f *CTFirFilter) PredictOutputSize(inputLength int) int {
 	return inputLength/f.decimation + 1
 }
 
+func (f *CTFirFilter) MapOffset(offset int) int {
+	return mapFirOffset(offset, f.lastHistory, len(f.taps), f.decimation)
+}
+
 // endregion
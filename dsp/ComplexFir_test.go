@@ -0,0 +1,58 @@
+package dsp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCTFirFilterDecimate(t *testing.T) {
+	var r = rand.New(rand.NewSource(1))
+
+	var taps = make([]complex64, 31)
+	for i := range taps {
+		taps[i] = complex(r.Float32(), r.Float32())
+	}
+
+	var input = make([]complex64, 1000)
+	for i := range input {
+		input[i] = complex(r.Float32()*2-1, r.Float32()*2-1)
+	}
+
+	for _, decimation := range []int{2, 3, 8} {
+		var workFilter = MakeDecimationCTFirFilter(decimation, taps)
+		var bufferFilter = MakeDecimationCTFirFilter(decimation, taps)
+		var singleFilter = MakeDecimationCTFirFilter(decimation, taps)
+
+		// The whole input at once is the reference, from the zeroed history
+		var expected = singleFilter.Work(input)
+		if len(expected) != len(input)/decimation+1 {
+			t.Fatalf("Decimation %d: Expected %d samples got %d", decimation, len(input)/decimation+1, len(expected))
+		}
+
+		// Split in uneven blocks, Work and WorkBuffer should return the same samples
+		var fromWork []complex64
+		var fromBuffer []complex64
+		for i := 0; i < len(input); i += 37 {
+			var end = i + 37
+			if end > len(input) {
+				end = len(input)
+			}
+
+			fromWork = append(fromWork, workFilter.Work(input[i:end])...)
+
+			var output = make([]complex64, bufferFilter.PredictOutputSize(end-i))
+			var n = bufferFilter.WorkBuffer(input[i:end], output)
+			fromBuffer = append(fromBuffer, output[:n]...)
+		}
+
+		if len(fromWork) != len(expected) || len(fromBuffer) != len(expected) {
+			t.Fatalf("Decimation %d: Expected %d samples got %d (Work) and %d (WorkBuffer)", decimation, len(expected), len(fromWork), len(fromBuffer))
+		}
+
+		for i := range expected {
+			if fromWork[i] != expected[i] || fromBuffer[i] != expected[i] {
+				t.Fatalf("Decimation %d: Expected %v at %d got %v (Work) and %v (WorkBuffer)", decimation, expected[i], i, fromWork[i], fromBuffer[i])
+			}
+		}
+	}
+}
@@ -26,6 +26,10 @@ func (f *RationalResampler) PredictOutputSize(inputLength int) int {
 	return f.decimator.PredictOutputSize(f.interpolator.PredictOutputSize(inputLength))
 }
 
+func (f *RationalResampler) MapOffset(offset int) int {
+	return f.decimator.MapOffset(f.interpolator.MapOffset(offset))
+}
+
 type FloatRationalResampler struct {
 	decimator    *FloatDecimator
 	interpolator *FloatInterpolator
@@ -51,3 +55,7 @@ func (f *FloatRationalResampler) WorkBuffer(input, output []float32) int {
 func (f *FloatRationalResampler) PredictOutputSize(inputLength int) int {
 	return f.decimator.PredictOutputSize(f.interpolator.PredictOutputSize(inputLength))
 }
+
+func (f *FloatRationalResampler) MapOffset(offset int) int {
+	return f.decimator.MapOffset(f.interpolator.MapOffset(offset))
+}
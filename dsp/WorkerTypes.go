@@ -1,5 +1,7 @@
 package dsp
 
+import "sort"
+
 type ComplexWorker interface {
 	Work(input []complex64) []complex64
 	WorkBuffer(input, output []complex64) int
@@ -23,3 +25,61 @@ type Float322ByteWorker interface {
 	WorkBuffer(input []float32, output []byte) int
 	PredictOutputSize(inputLength int) int
 }
+
+type Float322ComplexWorker interface {
+	Work(input []float32) []complex64
+	WorkBuffer(input []float32, output []complex64) int
+	PredictOutputSize(inputLength int) int
+}
+
+type Byte2ComplexWorker interface {
+	Work(input []byte) []complex64
+	WorkBuffer(input []byte, output []complex64) int
+	PredictOutputSize(inputLength int) int
+}
+
+type Complex2ByteWorker interface {
+	Work(input []complex64) []byte
+	WorkBuffer(input []complex64, output []byte) int
+	PredictOutputSize(inputLength int) int
+}
+
+type ByteWorker interface {
+	Work(input []byte) []byte
+	WorkBuffer(input, output []byte) int
+	PredictOutputSize(inputLength int) int
+}
+
+// TagOffsetMapper is implemented by the workers that change the sample rate or delay the samples, so the stream
+// tags can follow the samples through them.
+// MapOffset returns the output sample of the last WorkBuffer call the input sample at offset went to, both relative
+// to the first sample of that call. Offsets before the input are the samples kept from the previous calls, and
+// outputs past the output length are the ones that did not come out yet.
+type TagOffsetMapper interface {
+	MapOffset(offset int) int
+}
+
+// TagPositions records the input sample each output sample of a WorkBuffer call is at, for the workers that do not
+// output the samples at a regular rate. The positions are relative to the first input sample of the call.
+type TagPositions struct {
+	positions []int
+}
+
+// Reset clears the positions, it should be called at the start of each WorkBuffer call
+func (t *TagPositions) Reset() {
+	t.positions = t.positions[:0]
+}
+
+// Add records the position of the next output sample. The positions never go back, so the interpolation jitter does
+// not reorder the tags.
+func (t *TagPositions) Add(position int) {
+	if n := len(t.positions); n > 0 && position < t.positions[n-1] {
+		position = t.positions[n-1]
+	}
+	t.positions = append(t.positions, position)
+}
+
+// MapOffset returns the first output sample at or after the input sample at offset
+func (t *TagPositions) MapOffset(offset int) int {
+	return sort.SearchInts(t.positions, offset)
+}
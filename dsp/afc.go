@@ -0,0 +1,122 @@
+package dsp
+
+import "math"
+
+const afcDefaultRollOff = 0.35
+const afcDefaultLoopBandwidth = 0.005
+
+// AFC is an Automatic Frequency Control that tracks and corrects the carrier offset of a channel.
+// The correction is done by a Rotator at the input sample rate (before the channel filter), and the residual offset
+// is measured by a Band-Edge FLL at the channel rate (after the channel filter). After each block the frequency
+// measured by the FLL is transferred to the Rotator, so the signal is kept centered in the channel filter.
+// The total correction is limited to the pull-in range.
+type AFC struct {
+	rotator     *Rotator
+	fll         *FLLBandEdge
+	sampleRate  float32
+	channelRate float32
+	pullInRange float32
+	offset      float32
+}
+
+// MakeAFC creates a new AFC for a channel with signalBandwidth.
+// sampleRate is the rate of the samples passed to Correct and channelRate is the rate of the samples passed to Track.
+// pullInRange is the maximum correction in Hertz
+func MakeAFC(sampleRate, channelRate, signalBandwidth, pullInRange float32) *AFC {
+	var sps = channelRate / signalBandwidth * (1 + afcDefaultRollOff)
+	var filterSize = int(math.Ceil(float64(sps)*8)) | 1
+	var maxFreq = TwoPi * pullInRange / channelRate
+
+	return &AFC{
+		rotator:     MakeRotator(),
+		fll:         MakeFLLBandEdgeWithFrequencyRange(sps, afcDefaultRollOff, filterSize, afcDefaultLoopBandwidth, -maxFreq, maxFreq),
+		sampleRate:  sampleRate,
+		channelRate: channelRate,
+		pullInRange: pullInRange,
+		offset:      0,
+	}
+}
+
+// Correct applies the current frequency correction to samples at sample rate
+func (afc *AFC) Correct(input []complex64) []complex64 {
+	return afc.rotator.Work(input)
+}
+
+// CorrectBuffer applies the current frequency correction to samples at sample rate
+func (afc *AFC) CorrectBuffer(input, output []complex64) int {
+	return afc.rotator.WorkBuffer(input, output)
+}
+
+// Track measures and corrects the residual offset of samples at channel rate.
+// It should be fed with the channel filter output.
+func (afc *AFC) Track(input []complex64) []complex64 {
+	var output = afc.fll.Work(input)
+	afc.transfer()
+	return output
+}
+
+// TrackBuffer measures and corrects the residual offset of samples at channel rate.
+// It should be fed with the channel filter output.
+func (afc *AFC) TrackBuffer(input, output []complex64) int {
+	var l = afc.fll.WorkBuffer(input, output)
+	afc.transfer()
+	return l
+}
+
+func (afc *AFC) transfer() {
+	afc.offset += afc.fll.GetFrequencyHz(afc.channelRate)
+
+	if afc.offset > afc.pullInRange {
+		afc.offset = afc.pullInRange
+	} else if afc.offset < -afc.pullInRange {
+		afc.offset = -afc.pullInRange
+	}
+
+	afc.fll.SetFrequency(0)
+	afc.rotator.SetCenterFrequency(afc.offset, afc.sampleRate)
+}
+
+// Work does both correction and tracking. Only usable if sample rate and channel rate are the same.
+func (afc *AFC) Work(input []complex64) []complex64 {
+	return afc.Track(afc.Correct(input))
+}
+
+// WorkBuffer does both correction and tracking. Only usable if sample rate and channel rate are the same.
+func (afc *AFC) WorkBuffer(input, output []complex64) int {
+	var l = afc.CorrectBuffer(input, output)
+	return afc.TrackBuffer(output[:l], output)
+}
+
+func (afc *AFC) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// GetOffset returns the current estimated carrier offset in Hertz
+func (afc *AFC) GetOffset() float32 {
+	return afc.offset
+}
+
+// GetPullInRange returns the maximum correction in Hertz
+func (afc *AFC) GetPullInRange() float32 {
+	return afc.pullInRange
+}
+
+// SetPullInRange sets the maximum correction in Hertz
+func (afc *AFC) SetPullInRange(pullInRange float32) {
+	var maxFreq = TwoPi * pullInRange / afc.channelRate
+	afc.pullInRange = pullInRange
+	afc.fll.SetRelativeMaxFrequency(maxFreq)
+	afc.fll.SetRelativeMinFrequency(-maxFreq)
+}
+
+// SetLoopBandwidth sets the tracking loop bandwidth
+func (afc *AFC) SetLoopBandwidth(bw float32) error {
+	return afc.fll.SetLoopBandwidth(bw)
+}
+
+// Reset clears the current offset estimation
+func (afc *AFC) Reset() {
+	afc.offset = 0
+	afc.fll.SetFrequency(0)
+	afc.rotator.SetCenterFrequency(0, afc.sampleRate)
+}
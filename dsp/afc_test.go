@@ -0,0 +1,30 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAFC(t *testing.T) {
+	const sampleRate = 48000
+	const signalBandwidth = 10000
+	const carrierOffset = 1500
+
+	var r = rand.New(rand.NewSource(1))
+	var afc = MakeAFC(sampleRate, sampleRate, signalBandwidth, 5000)
+	var shaping = MakeFirFilter(MakeLowPassFixed(1, sampleRate, signalBandwidth/2, 63))
+	var shift = MakeRotatorWithFrequency(-carrierOffset, sampleRate)
+
+	for block := 0; block < 100; block++ {
+		var samples = make([]complex64, 4096)
+		for i := range samples {
+			samples[i] = complex(float32(r.NormFloat64()), float32(r.NormFloat64()))
+		}
+		afc.Work(shift.Work(shaping.Work(samples)))
+	}
+
+	if math.Abs(float64(afc.GetOffset()-carrierOffset)) > 50 {
+		t.Fatalf("Expected AFC offset to be %d got %f", carrierOffset, afc.GetOffset())
+	}
+}
@@ -0,0 +1,268 @@
+package dsp
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+// audioAGCMinEnvelope avoids a huge gain over silence (the gain is limited by maxGain anyway)
+const audioAGCMinEnvelope = 1e-9
+
+func dBToAmplitude(dB float32) float32 {
+	return float32(math.Pow(10, float64(dB)/20))
+}
+
+func amplitudeToDB(amplitude float32) float32 {
+	return float32(20 * math.Log10(float64(amplitude)))
+}
+
+// timeConstantAlpha returns the one pole filter factor of a time constant in seconds (0 means no filtering)
+func timeConstantAlpha(timeConstant, sampleRate float32) float32 {
+	if timeConstant <= 0 {
+		return 1
+	}
+
+	return float32(1 - math.Exp(-1/float64(timeConstant*sampleRate)))
+}
+
+// region Audio AGC
+
+// AudioAGC is an audio AGC that brings the audio peaks to a target level.
+// The envelope follows the peaks with the attack time constant, holds for the hang time after the last peak, and then
+// falls with the decay time constant. The gain is the ratio between the target level and the envelope, up to maxGain.
+type AudioAGC struct {
+	sampleRate  float32
+	attack      float32
+	decay       float32
+	hang        float32
+	target      float32
+	maxGain     float32
+	attackAlpha float32
+	decayAlpha  float32
+	hangSamples int
+	hangCount   int
+	envelope    float32
+	gain        float32
+}
+
+// MakeAudioAGC creates an audio AGC for audio at sampleRate. attack, decay and hang are in seconds,
+// target is the output peak level in dB full scale and maxGain is in dB.
+func MakeAudioAGC(sampleRate, attack, decay, hang, target, maxGain float32) *AudioAGC {
+	if sampleRate <= 0 {
+		panic("Audio AGC sample rate should be positive")
+	}
+
+	var agc = &AudioAGC{
+		sampleRate: sampleRate,
+	}
+
+	agc.SetTiming(attack, decay, hang)
+	agc.SetTarget(target)
+	agc.SetMaxGain(maxGain)
+	agc.gain = agc.maxGain
+
+	return agc
+}
+
+// SetTiming sets the attack, decay and hang times in seconds
+func (agc *AudioAGC) SetTiming(attack, decay, hang float32) {
+	if attack < 0 || decay < 0 || hang < 0 {
+		panic("Audio AGC times should not be negative")
+	}
+
+	agc.attack = attack
+	agc.decay = decay
+	agc.hang = hang
+	agc.attackAlpha = timeConstantAlpha(attack, agc.sampleRate)
+	agc.decayAlpha = timeConstantAlpha(decay, agc.sampleRate)
+	agc.hangSamples = int(hang * agc.sampleRate)
+}
+
+func (agc *AudioAGC) GetAttack() float32 {
+	return agc.attack
+}
+
+func (agc *AudioAGC) GetDecay() float32 {
+	return agc.decay
+}
+
+func (agc *AudioAGC) GetHang() float32 {
+	return agc.hang
+}
+
+// SetTarget sets the output peak level in dB full scale
+func (agc *AudioAGC) SetTarget(target float32) {
+	agc.target = dBToAmplitude(target)
+}
+
+// GetTarget returns the output peak level in dB full scale
+func (agc *AudioAGC) GetTarget() float32 {
+	return amplitudeToDB(agc.target)
+}
+
+// SetMaxGain sets the maximum gain in dB
+func (agc *AudioAGC) SetMaxGain(maxGain float32) {
+	agc.maxGain = dBToAmplitude(maxGain)
+}
+
+// GetMaxGain returns the maximum gain in dB
+func (agc *AudioAGC) GetMaxGain() float32 {
+	return amplitudeToDB(agc.maxGain)
+}
+
+// GetGain returns the current gain in dB
+func (agc *AudioAGC) GetGain() float32 {
+	return amplitudeToDB(agc.gain)
+}
+
+func (agc *AudioAGC) Work(input []float32) []float32 {
+	var output = make([]float32, agc.PredictOutputSize(len(input)))
+	agc.WorkBuffer(input, output)
+	return output
+}
+
+// WorkBuffer applies the AGC. The output can be the input buffer.
+func (agc *AudioAGC) WorkBuffer(input, output []float32) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i, v := range input {
+		var level = tools.Abs(v)
+
+		if level > agc.envelope {
+			agc.envelope += agc.attackAlpha * (level - agc.envelope)
+			agc.hangCount = agc.hangSamples
+		} else if agc.hangCount > 0 {
+			agc.hangCount--
+		} else {
+			agc.envelope += agc.decayAlpha * (level - agc.envelope)
+		}
+
+		agc.gain = agc.maxGain
+		if agc.envelope > audioAGCMinEnvelope && agc.target/agc.envelope < agc.maxGain {
+			agc.gain = agc.target / agc.envelope
+		}
+
+		output[i] = v * agc.gain
+	}
+
+	return len(input)
+}
+
+func (agc *AudioAGC) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+// region Audio Gain
+
+// AudioGain applies a fixed gain (volume) to the audio
+type AudioGain struct {
+	gain float32
+}
+
+// MakeAudioGain creates a fixed gain stage. gain is in dB.
+func MakeAudioGain(gain float32) *AudioGain {
+	return &AudioGain{
+		gain: dBToAmplitude(gain),
+	}
+}
+
+// SetGain sets the gain in dB
+func (g *AudioGain) SetGain(gain float32) {
+	g.gain = dBToAmplitude(gain)
+}
+
+// GetGain returns the gain in dB
+func (g *AudioGain) GetGain() float32 {
+	return amplitudeToDB(g.gain)
+}
+
+func (g *AudioGain) Work(input []float32) []float32 {
+	var output = make([]float32, g.PredictOutputSize(len(input)))
+	g.WorkBuffer(input, output)
+	return output
+}
+
+// WorkBuffer applies the gain. The output can be the input buffer.
+func (g *AudioGain) WorkBuffer(input, output []float32) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i, v := range input {
+		output[i] = v * g.gain
+	}
+
+	return len(input)
+}
+
+func (g *AudioGain) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+// region Soft Limiter
+
+// SoftLimiter keeps the audio inside -1 to 1 without hard clipping.
+// The samples under the threshold pass unchanged, and the ones over it are smoothly compressed (tanh) towards 1.
+type SoftLimiter struct {
+	threshold float32
+}
+
+// MakeSoftLimiter creates a soft limiter. threshold is the level (0 to 1) where the compression starts.
+func MakeSoftLimiter(threshold float32) *SoftLimiter {
+	var l = &SoftLimiter{}
+	l.SetThreshold(threshold)
+	return l
+}
+
+// SetThreshold sets the level (0 to 1) where the compression starts
+func (l *SoftLimiter) SetThreshold(threshold float32) {
+	if threshold < 0 || threshold >= 1 {
+		panic("Soft Limiter threshold should be from 0 to 1")
+	}
+
+	l.threshold = threshold
+}
+
+func (l *SoftLimiter) GetThreshold() float32 {
+	return l.threshold
+}
+
+func (l *SoftLimiter) Work(input []float32) []float32 {
+	var output = make([]float32, l.PredictOutputSize(len(input)))
+	l.WorkBuffer(input, output)
+	return output
+}
+
+// WorkBuffer applies the limiter. The output can be the input buffer.
+func (l *SoftLimiter) WorkBuffer(input, output []float32) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var knee = 1 - l.threshold
+
+	for i, v := range input {
+		var level = tools.Abs(v)
+		if level <= l.threshold {
+			output[i] = v
+			continue
+		}
+
+		// The tanh slope at 0 is 1, so the curve is continuous (and smooth) at the threshold
+		var limited = l.threshold + knee*float32(math.Tanh(float64((level-l.threshold)/knee)))
+		output[i] = tools.Copysign(limited, v)
+	}
+
+	return len(input)
+}
+
+func (l *SoftLimiter) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
@@ -0,0 +1,118 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+func makeTestTone(amplitude float32, length int) []float32 {
+	var tone = make([]float32, length)
+	for i := range tone {
+		tone[i] = amplitude * float32(math.Sin(2*math.Pi*1000*float64(i)/48000))
+	}
+	return tone
+}
+
+func peakLevel(samples []float32) float32 {
+	var peak = float32(0)
+	for _, v := range samples {
+		if tools.Abs(v) > peak {
+			peak = tools.Abs(v)
+		}
+	}
+	return peak
+}
+
+func TestAudioAGC(t *testing.T) {
+	const sampleRate = 48000
+
+	// Very different input levels should come out at the same level
+	for _, amplitude := range []float32{0.01, 0.1, 1} {
+		var agc = MakeAudioAGC(sampleRate, 0.005, 0.5, 0.1, -6, 60)
+		var output = agc.Work(makeTestTone(amplitude, sampleRate))
+		var peak = peakLevel(output[len(output)/2:])
+		var expected = dBToAmplitude(-6)
+
+		if tools.Abs(peak-expected) > 0.02 {
+			t.Errorf("Amplitude %f: Expected a peak of %f got %f", amplitude, expected, peak)
+		}
+
+		var expectedGain = -6 - amplitudeToDB(amplitude)
+		if tools.Abs(agc.GetGain()-expectedGain) > 0.5 {
+			t.Errorf("Amplitude %f: Expected a gain of %f dB got %f dB", amplitude, expectedGain, agc.GetGain())
+		}
+	}
+
+	// The gain should not go over the max gain
+	var agc = MakeAudioAGC(sampleRate, 0.005, 0.5, 0.1, -6, 20)
+	var output = agc.Work(makeTestTone(0.001, sampleRate))
+	if tools.Abs(peakLevel(output)-0.01) > 1e-3 || tools.Abs(agc.GetGain()-20) > 1e-3 {
+		t.Errorf("Expected the gain limited to 20 dB got %f dB (peak %f)", agc.GetGain(), peakLevel(output))
+	}
+
+	// After a loud block the gain holds for the hang time, and then recovers with the decay
+	agc = MakeAudioAGC(sampleRate, 0.001, 0.05, 0.1, 0, 40)
+	agc.Work(makeTestTone(1, sampleRate/10))
+	var loudGain = agc.GetGain()
+	agc.Work(make([]float32, sampleRate/20))
+	if tools.Abs(agc.GetGain()-loudGain) > 0.1 {
+		t.Errorf("Expected the gain to hang at %f dB got %f dB", loudGain, agc.GetGain())
+	}
+	agc.Work(make([]float32, sampleRate))
+	if tools.Abs(agc.GetGain()-40) > 1e-3 {
+		t.Errorf("Expected the gain to recover to 40 dB got %f dB", agc.GetGain())
+	}
+
+	if tools.Abs(agc.GetTarget()) > 1e-3 || tools.Abs(agc.GetMaxGain()-40) > 1e-3 {
+		t.Errorf("Expected target 0 dB and max gain 40 dB got %f dB and %f dB", agc.GetTarget(), agc.GetMaxGain())
+	}
+}
+
+func TestAudioGain(t *testing.T) {
+	var g = MakeAudioGain(-6)
+	var input = makeTestTone(1, 100)
+
+	// In place, as used in the demodulators
+	g.WorkBuffer(input, input)
+
+	var expected = makeTestTone(dBToAmplitude(-6), 100)
+	for i := range input {
+		if tools.Abs(input[i]-expected[i]) > 1e-6 {
+			t.Fatalf("Expected %f at %d got %f", expected[i], i, input[i])
+		}
+	}
+
+	g.SetGain(20)
+	if tools.Abs(g.GetGain()-20) > 1e-4 || tools.Abs(g.Work([]float32{0.5})[0]-5) > 1e-4 {
+		t.Errorf("Expected 20 dB of gain got %f dB", g.GetGain())
+	}
+}
+
+func TestSoftLimiter(t *testing.T) {
+	var l = MakeSoftLimiter(0.8)
+	var input = []float32{0, 0.5, -0.8, 0.9, -1, 2, -10, 1000}
+	var output = l.Work(input)
+
+	for i, v := range input {
+		var o = output[i]
+
+		if tools.Abs(v) <= 0.8 && o != v {
+			t.Errorf("Expected %f under the threshold to pass unchanged got %f", v, o)
+		}
+
+		if tools.Abs(o) > 1 || tools.Signbit(o) != tools.Signbit(v) {
+			t.Errorf("Expected %f limited to 1 with the same sign got %f", v, o)
+		}
+
+		if tools.Abs(v) > 0.8 && (tools.Abs(o) <= 0.8 || tools.Abs(o) > tools.Abs(v)) {
+			t.Errorf("Expected %f compressed between the threshold and the input got %f", v, o)
+		}
+	}
+
+	// Monotonic over the threshold
+	if !(output[3] < -output[4] && -output[4] < output[5] && output[5] <= output[7]) {
+		t.Errorf("Expected a monotonic curve got %v", output)
+	}
+}
@@ -0,0 +1,355 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// burstOversampling is the minimum ratio between the snippet sample rate and the burst bandwidth
+const burstOversampling = 4
+
+// burstHoldFrames is the number of frames without detection that ends a burst
+const burstHoldFrames = 2
+
+// Burst is a short transmission cut out of the wideband IQ and shifted to baseband
+type Burst struct {
+	ID uint64
+	// Start is the time of the first detected frame (zero if the detector has no time reference)
+	Start time.Time
+	// Duration is the detected length of the burst, without the padding
+	Duration time.Duration
+	// CenterFrequency is the power weighted center of the burst in Hertz
+	CenterFrequency float64
+	// Bandwidth is the width of the detected bins in Hertz
+	Bandwidth float64
+	// SNR is the highest SNR (in dB) of the burst frames
+	SNR float32
+	// Offset is the number of the first snippet sample in the wideband stream (the padding included)
+	Offset uint64
+	// SampleRate is the sample rate of the snippet
+	SampleRate float64
+	// Samples is the snippet, centered on the burst, with one frame of padding at each side
+	Samples []complex64
+}
+
+// BurstDecoder receives the bursts found by a BurstDetector
+type BurstDecoder interface {
+	DecodeBurst(burst Burst)
+}
+
+type activeBurst struct {
+	id          uint64
+	startOffset uint64
+	endOffset   uint64
+	startBin    int
+	endBin      int
+	snr         float32
+	frequency   float64
+	weight      float64
+	frames      int
+	missed      int
+}
+
+// BurstDetector finds short transmissions (like frequency hopping or packet bursts) anywhere in the wideband IQ.
+// Each spectrum frame runs through a CFAR detector, and the detections are followed over the frames: a burst starts
+// at a detection that does not overlap any active burst, and ends after a few frames without detections (so every hop
+// of a frequency hopping signal is a burst). Bursts shorter than the minimum duration are dropped, and bursts longer
+// than the maximum duration are cut.
+// Each burst is cut out of the wideband IQ, shifted to baseband with a FrequencyTranslator, and passed to the decoders.
+type BurstDetector struct {
+	sampleRate      float64
+	centerFrequency float64
+	fftSize         int
+	estimator       *SpectrumEstimator
+	detector        *CFARDetector
+	minFrames       int
+	maxFrames       int
+	decoders        []BurstDecoder
+
+	frameFill     int
+	offset        uint64
+	history       []complex64
+	historyOffset uint64
+	active        []*activeBurst
+	nextID        uint64
+
+	timeValid  bool
+	time       time.Time
+	timeOffset uint64
+}
+
+// MakeBurstDetector creates a burst detector for IQ at sampleRate, with spectrum frames of fftSize bins.
+// The time resolution is one frame (fftSize / sampleRate), so minDuration and maxDuration are rounded to frames.
+func MakeBurstDetector(sampleRate float64, fftSize int, detector *CFARDetector, minDuration, maxDuration time.Duration) *BurstDetector {
+	if sampleRate <= 0 {
+		panic("Burst Detector sample rate should be positive")
+	}
+
+	if maxDuration < minDuration {
+		panic("Burst Detector max duration should not be lower than the min duration")
+	}
+
+	var frameDuration = float64(fftSize) / sampleRate
+	var estimator = MakeSpectrumEstimator(fftSize, WindowBlackmanHarris, 0)
+	// One frame per spectrum, the detections are averaged over the frames by the burst tracking
+	estimator.SetAveraging(SpectrumAveragingExponential)
+	estimator.SetAlpha(1)
+
+	var d = &BurstDetector{
+		sampleRate: sampleRate,
+		fftSize:    fftSize,
+		estimator:  estimator,
+		detector:   detector,
+		minFrames:  int(math.Ceil(minDuration.Seconds() / frameDuration)),
+		maxFrames:  int(math.Ceil(maxDuration.Seconds() / frameDuration)),
+		nextID:     1,
+	}
+
+	if d.minFrames < 1 {
+		d.minFrames = 1
+	}
+
+	if d.maxFrames < d.minFrames {
+		d.maxFrames = d.minFrames
+	}
+
+	return d
+}
+
+// AddDecoder registers a decoder that receives every burst
+func (d *BurstDetector) AddDecoder(decoder BurstDecoder) {
+	d.decoders = append(d.decoders, decoder)
+}
+
+// SetCenterFrequency sets the center frequency of the IQ in Hertz. Changing it drops the active bursts.
+func (d *BurstDetector) SetCenterFrequency(frequency float64) {
+	if frequency != d.centerFrequency {
+		d.active = d.active[:0]
+	}
+	d.centerFrequency = frequency
+}
+
+func (d *BurstDetector) GetCenterFrequency() float64 {
+	return d.centerFrequency
+}
+
+// SetTime sets the reception time of the next sample, used for the burst start times
+func (d *BurstDetector) SetTime(t time.Time) {
+	d.time = t
+	d.timeOffset = d.offset
+	d.timeValid = true
+}
+
+func (d *BurstDetector) GetSampleRate() float64 {
+	return d.sampleRate
+}
+
+// GetFrameDuration returns the time resolution of the detector
+func (d *BurstDetector) GetFrameDuration() time.Duration {
+	return time.Duration(float64(d.fftSize) / d.sampleRate * float64(time.Second))
+}
+
+// GetActiveBursts returns the number of bursts in progress
+func (d *BurstDetector) GetActiveBursts() int {
+	return len(d.active)
+}
+
+// Feed adds wideband IQ samples to the detector, and returns the bursts that ended on them
+// (after passing them to the decoders).
+func (d *BurstDetector) Feed(input []complex64) []Burst {
+	var bursts []Burst
+
+	for len(input) > 0 {
+		var n = d.fftSize - d.frameFill
+		if n > len(input) {
+			n = len(input)
+		}
+
+		d.estimator.Feed(input[:n])
+		d.history = append(d.history, input[:n]...)
+		d.offset += uint64(n)
+		d.frameFill += n
+		input = input[n:]
+
+		if d.frameFill == d.fftSize {
+			d.frameFill = 0
+			bursts = append(bursts, d.processFrame()...)
+			d.trimHistory()
+		}
+	}
+
+	for _, b := range bursts {
+		for _, decoder := range d.decoders {
+			decoder.DecodeBurst(b)
+		}
+	}
+
+	return bursts
+}
+
+// Flush ends all the active bursts, passing them to the decoders
+func (d *BurstDetector) Flush() []Burst {
+	var bursts []Burst
+
+	for _, b := range d.active {
+		if b.frames >= d.minFrames {
+			bursts = append(bursts, d.extract(b))
+		}
+	}
+
+	d.active = d.active[:0]
+
+	for _, b := range bursts {
+		for _, decoder := range d.decoders {
+			decoder.DecodeBurst(b)
+		}
+	}
+
+	return bursts
+}
+
+// processFrame tracks the detections of the last frame, returning the bursts that ended
+func (d *BurstDetector) processFrame() []Burst {
+	var frameStart = d.offset - uint64(d.fftSize)
+	var detected = d.detector.FindSignals(d.estimator.GetSpectrum(), d.centerFrequency, d.sampleRate)
+	var matched = make([]bool, len(d.active))
+
+	for _, s := range detected {
+		var burst *activeBurst
+
+		for i, b := range d.active {
+			// Overlapping (or adjacent) bins are the same burst
+			if s.StartBin <= b.endBin+1 && s.EndBin >= b.startBin-1 {
+				burst = b
+				matched[i] = true
+				break
+			}
+		}
+
+		if burst == nil {
+			burst = &activeBurst{
+				id:          d.nextID,
+				startOffset: frameStart,
+				startBin:    s.StartBin,
+				endBin:      s.EndBin,
+			}
+			d.nextID++
+			d.active = append(d.active, burst)
+			matched = append(matched, true)
+		}
+
+		if burst.endOffset != d.offset {
+			burst.frames++
+		}
+
+		burst.endOffset = d.offset
+		burst.missed = 0
+
+		if s.StartBin < burst.startBin {
+			burst.startBin = s.StartBin
+		}
+
+		if s.EndBin > burst.endBin {
+			burst.endBin = s.EndBin
+		}
+
+		if s.SNR > burst.snr {
+			burst.snr = s.SNR
+		}
+
+		var weight = math.Pow(10, float64(s.Power)/10)
+		burst.frequency += s.CenterFrequency * weight
+		burst.weight += weight
+	}
+
+	var bursts []Burst
+	var active = d.active[:0]
+
+	for i, b := range d.active {
+		if !matched[i] {
+			b.missed++
+		}
+
+		var length = int((d.offset - b.startOffset) / uint64(d.fftSize))
+		if b.missed < burstHoldFrames && length < d.maxFrames {
+			active = append(active, b)
+			continue
+		}
+
+		if b.frames >= d.minFrames {
+			bursts = append(bursts, d.extract(b))
+		}
+	}
+
+	d.active = active
+
+	return bursts
+}
+
+// extract cuts the burst (with padding) out of the history and shifts it to baseband
+func (d *BurstDetector) extract(b *activeBurst) Burst {
+	var binWidth = d.sampleRate / float64(d.fftSize)
+	var bandwidth = float64(b.endBin-b.startBin+1) * binWidth
+	var centerFrequency = b.frequency / b.weight
+
+	var start = b.startOffset - uint64(d.fftSize)
+	if b.startOffset < uint64(d.fftSize) || start < d.historyOffset {
+		start = d.historyOffset
+	}
+
+	var end = b.endOffset + uint64(d.fftSize)
+	if end > d.offset {
+		end = d.offset
+	}
+
+	var decimation = int(d.sampleRate / (bandwidth * burstOversampling))
+	if decimation < 1 {
+		decimation = 1
+	}
+
+	// One bin of margin at each side, as the detected bins do not cover the edges of the signal
+	var taps = MakeLowPass(1, d.sampleRate, bandwidth/2+binWidth, bandwidth/4+binWidth)
+	var translator = MakeFrequencyTranslator(decimation, float32(centerFrequency-d.centerFrequency), float32(d.sampleRate), taps)
+
+	var burst = Burst{
+		ID:              b.id,
+		Duration:        time.Duration(float64(b.endOffset-b.startOffset) / d.sampleRate * float64(time.Second)),
+		CenterFrequency: centerFrequency,
+		Bandwidth:       bandwidth,
+		SNR:             b.snr,
+		Offset:          start,
+		SampleRate:      d.sampleRate / float64(decimation),
+		Samples:         translator.Work(d.history[start-d.historyOffset : end-d.historyOffset]),
+	}
+
+	if d.timeValid {
+		var delta = (float64(b.startOffset) - float64(d.timeOffset)) / d.sampleRate
+		burst.Start = d.time.Add(time.Duration(delta * float64(time.Second)))
+	}
+
+	return burst
+}
+
+// trimHistory drops the samples that no burst needs anymore, once they are half of the history
+func (d *BurstDetector) trimHistory() {
+	// The next frame may start a burst, that needs a frame of padding before it
+	var keep = d.offset - uint64(d.fftSize)
+	for _, b := range d.active {
+		if b.startOffset < keep {
+			keep = b.startOffset
+		}
+	}
+
+	if keep < d.historyOffset+uint64(d.fftSize) {
+		return
+	}
+	keep -= uint64(d.fftSize)
+
+	var drop = int(keep - d.historyOffset)
+	if drop < len(d.history)/2 {
+		return
+	}
+
+	d.history = append(d.history[:0], d.history[drop:]...)
+	d.historyOffset = keep
+}
@@ -0,0 +1,109 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+type testBurstDecoder struct {
+	bursts []Burst
+}
+
+func (d *testBurstDecoder) DecodeBurst(burst Burst) {
+	d.bursts = append(d.bursts, burst)
+}
+
+func TestBurstDetector(t *testing.T) {
+	const sampleRate = 1e6
+	const center = 100e6
+	const fftSize = 256
+
+	var testBursts = []struct {
+		start     int
+		length    int
+		frequency float64
+	}{
+		{5000, 10000, 200e3},
+		{25000, 8000, -300e3},
+		{40000, 100, 100e3}, // Too short
+	}
+
+	var r = rand.New(rand.NewSource(1))
+	var input = make([]complex64, 50000)
+	for i := range input {
+		input[i] = complex(float32(r.NormFloat64()*0.05), float32(r.NormFloat64()*0.05))
+	}
+
+	for _, b := range testBursts {
+		for i := b.start; i < b.start+b.length; i++ {
+			input[i] += complex64(cmplx.Exp(complex(0, 2*math.Pi*b.frequency*float64(i)/sampleRate)))
+		}
+	}
+
+	var cfar = MakeCFARDetector(CFAROrderedStatistic, 2, 16, 15)
+	var detector = MakeBurstDetector(sampleRate, fftSize, cfar, time.Millisecond, time.Second)
+	var decoder = &testBurstDecoder{}
+	detector.AddDecoder(decoder)
+	detector.SetCenterFrequency(center)
+
+	var reference = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	detector.SetTime(reference)
+
+	// Split in uneven blocks to check the state between the calls
+	var bursts []Burst
+	for i := 0; i < len(input); i += 1000 {
+		bursts = append(bursts, detector.Feed(input[i:i+1000])...)
+	}
+	bursts = append(bursts, detector.Flush()...)
+
+	if len(bursts) != 2 || len(decoder.bursts) != 2 {
+		t.Fatalf("Expected 2 bursts (and 2 decoded) got %d (%d): %+v", len(bursts), len(decoder.bursts), bursts)
+	}
+
+	var frame = float64(fftSize) / sampleRate
+
+	for i, b := range bursts {
+		var expected = testBursts[i]
+
+		if math.Abs(b.CenterFrequency-(center+expected.frequency)) > sampleRate/fftSize {
+			t.Errorf("Burst %d: Expected center at %f got %f", i, center+expected.frequency, b.CenterFrequency)
+		}
+
+		var start = b.Start.Sub(reference).Seconds()
+		if math.Abs(start-float64(expected.start)/sampleRate) > frame {
+			t.Errorf("Burst %d: Expected start at %f s got %f s", i, float64(expected.start)/sampleRate, start)
+		}
+
+		if math.Abs(b.Duration.Seconds()-float64(expected.length)/sampleRate) > 2*frame {
+			t.Errorf("Burst %d: Expected duration of %f s got %f s", i, float64(expected.length)/sampleRate, b.Duration.Seconds())
+		}
+
+		if b.SNR < 20 || b.Bandwidth > 10*sampleRate/fftSize {
+			t.Errorf("Burst %d: Expected a narrow high SNR burst got %f Hz and %f dB", i, b.Bandwidth, b.SNR)
+		}
+
+		// The snippet covers the burst and its padding, shifted to baseband
+		var expectedLength = int((b.Duration.Seconds() + 2*frame) * b.SampleRate)
+		if b.SampleRate >= sampleRate || math.Abs(float64(len(b.Samples)-expectedLength)) > b.SampleRate*frame {
+			t.Errorf("Burst %d: Expected a decimated snippet of %d samples got %d at %f", i, expectedLength, len(b.Samples), b.SampleRate)
+		}
+
+		var middle = b.Samples[len(b.Samples)/2-10 : len(b.Samples)/2+10]
+		var rotation = complex64(0)
+		for j := 1; j < len(middle); j++ {
+			rotation += middle[j] * complex(real(middle[j-1]), -imag(middle[j-1]))
+		}
+
+		var residual = float64(cmplx.Phase(complex128(rotation))) * b.SampleRate / (2 * math.Pi)
+		if math.Abs(residual-(center+expected.frequency-b.CenterFrequency)) > 100 {
+			t.Errorf("Burst %d: Expected the tone at %f Hz in the snippet got %f Hz", i, center+expected.frequency-b.CenterFrequency, residual)
+		}
+	}
+
+	if bursts[0].ID == bursts[1].ID || detector.GetActiveBursts() != 0 {
+		t.Errorf("Expected unique burst IDs and no active bursts after the flush")
+	}
+}
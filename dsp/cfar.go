@@ -0,0 +1,234 @@
+package dsp
+
+import (
+	"math"
+	"sort"
+)
+
+type CFARMode int
+
+const (
+	// CFARCellAveraging estimates the noise as the mean of the reference cells
+	CFARCellAveraging CFARMode = iota
+	// CFAROrderedStatistic estimates the noise as the k-th smallest reference cell (robust against nearby signals)
+	CFAROrderedStatistic
+)
+
+// DetectedSignal is a group of adjacent spectrum bins over the CFAR threshold
+type DetectedSignal struct {
+	// CenterFrequency is the power weighted center of the signal in Hertz
+	CenterFrequency float64
+	// Bandwidth is the width of the detected bins in Hertz
+	Bandwidth float64
+	// SNR is the ratio (in dB) between the peak bin and the estimated noise at that bin
+	SNR float32
+	// Power is the peak bin power in dB
+	Power float32
+	// StartBin and EndBin are the first and last detected bins (inclusive)
+	StartBin int
+	EndBin   int
+}
+
+// CFARDetector is a Constant False Alarm Rate detector that runs over (linear scale, DC centered) power spectrum frames.
+// For each bin, the noise is estimated from referenceCells bins at each side, ignoring guardCells bins next to it.
+// A bin is detected when its power is threshold dB over the estimated noise.
+type CFARDetector struct {
+	mode           CFARMode
+	guardCells     int
+	referenceCells int
+	threshold      float32
+	thresholdRatio float32
+	rank           float32
+	mergeGap       int
+	noise          []float32
+	detections     []bool
+	cells          []float32
+}
+
+// MakeCFARDetector creates a CFAR detector. threshold is in dB
+func MakeCFARDetector(mode CFARMode, guardCells, referenceCells int, threshold float32) *CFARDetector {
+	if referenceCells < 1 {
+		panic("CFAR reference cells should be at least 1")
+	}
+
+	if guardCells < 0 {
+		panic("CFAR guard cells should not be negative")
+	}
+
+	var c = &CFARDetector{
+		mode:           mode,
+		guardCells:     guardCells,
+		referenceCells: referenceCells,
+		rank:           0.75,
+		mergeGap:       1,
+	}
+
+	c.SetThreshold(threshold)
+
+	return c
+}
+
+// SetThreshold sets the detection threshold in dB over the estimated noise
+func (c *CFARDetector) SetThreshold(threshold float32) {
+	c.threshold = threshold
+	c.thresholdRatio = float32(math.Pow(10, float64(threshold)/10))
+}
+
+func (c *CFARDetector) GetThreshold() float32 {
+	return c.threshold
+}
+
+// SetRank sets the ordered statistic rank as a fraction of the reference cells (0 to 1)
+func (c *CFARDetector) SetRank(rank float32) {
+	if rank < 0 || rank > 1 {
+		panic("CFAR rank should be between 0 and 1")
+	}
+	c.rank = rank
+}
+
+func (c *CFARDetector) GetRank() float32 {
+	return c.rank
+}
+
+// SetMergeGap sets the maximum number of undetected bins between two detected bins of the same signal
+func (c *CFARDetector) SetMergeGap(gap int) {
+	c.mergeGap = gap
+}
+
+func (c *CFARDetector) GetMergeGap() int {
+	return c.mergeGap
+}
+
+func (c *CFARDetector) GetMode() CFARMode {
+	return c.mode
+}
+
+// GetNoise returns the per bin noise estimation of the last frame
+func (c *CFARDetector) GetNoise() []float32 {
+	return c.noise
+}
+
+// GetNoiseFloor returns the median noise estimation of the last frame in dB
+func (c *CFARDetector) GetNoiseFloor() float32 {
+	if len(c.noise) == 0 {
+		return 0
+	}
+
+	var sorted = append([]float32(nil), c.noise...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return float32(10 * math.Log10(float64(sorted[len(sorted)/2])))
+}
+
+// Detect runs the detector over a spectrum frame and returns the detection mask
+func (c *CFARDetector) Detect(spectrum []float32) []bool {
+	var n = len(spectrum)
+
+	if len(c.noise) != n {
+		c.noise = make([]float32, n)
+		c.detections = make([]bool, n)
+	}
+
+	for i := 0; i < n; i++ {
+		c.cells = c.cells[:0]
+
+		for j := i - c.guardCells - c.referenceCells; j < i-c.guardCells; j++ {
+			if j >= 0 {
+				c.cells = append(c.cells, spectrum[j])
+			}
+		}
+
+		for j := i + c.guardCells + 1; j <= i+c.guardCells+c.referenceCells; j++ {
+			if j < n {
+				c.cells = append(c.cells, spectrum[j])
+			}
+		}
+
+		if len(c.cells) == 0 {
+			c.noise[i] = spectrum[i]
+			c.detections[i] = false
+			continue
+		}
+
+		c.noise[i] = c.estimate(c.cells)
+		c.detections[i] = spectrum[i] > c.noise[i]*c.thresholdRatio
+	}
+
+	return c.detections
+}
+
+func (c *CFARDetector) estimate(cells []float32) float32 {
+	if c.mode == CFAROrderedStatistic {
+		sort.Slice(cells, func(i, j int) bool { return cells[i] < cells[j] })
+		var k = int(c.rank * float32(len(cells)-1))
+		return cells[k]
+	}
+
+	var sum = float32(0)
+	for _, v := range cells {
+		sum += v
+	}
+
+	return sum / float32(len(cells))
+}
+
+// FindSignals runs the detector over a spectrum frame and merges the detected bins into signals.
+// centerFrequency and sampleRate are used to convert bins to Hertz.
+func (c *CFARDetector) FindSignals(spectrum []float32, centerFrequency, sampleRate float64) []DetectedSignal {
+	var detections = c.Detect(spectrum)
+	var n = len(spectrum)
+	var binWidth = sampleRate / float64(n)
+	var signals = make([]DetectedSignal, 0)
+
+	for i := 0; i < n; i++ {
+		if !detections[i] {
+			continue
+		}
+
+		var start = i
+		var end = i
+		var gap = 0
+
+		for i++; i < n; i++ {
+			if detections[i] {
+				end = i
+				gap = 0
+			} else {
+				gap++
+				if gap > c.mergeGap {
+					break
+				}
+			}
+		}
+
+		i = end
+
+		var peak = start
+		var weightedSum = 0.0
+		var powerSum = 0.0
+
+		for j := start; j <= end; j++ {
+			if spectrum[j] > spectrum[peak] {
+				peak = j
+			}
+			weightedSum += float64(j) * float64(spectrum[j])
+			powerSum += float64(spectrum[j])
+		}
+
+		var centerBin = float64(peak)
+		if powerSum > 0 {
+			centerBin = weightedSum / powerSum
+		}
+
+		signals = append(signals, DetectedSignal{
+			CenterFrequency: centerFrequency + (centerBin-float64(n/2))*binWidth,
+			Bandwidth:       float64(end-start+1) * binWidth,
+			SNR:             float32(10 * math.Log10(float64(spectrum[peak]/c.noise[peak]))),
+			Power:           float32(10 * math.Log10(float64(spectrum[peak]))),
+			StartBin:        start,
+			EndBin:          end,
+		})
+	}
+
+	return signals
+}
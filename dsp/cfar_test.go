@@ -0,0 +1,126 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+const cfarTestBins = 1024
+const cfarTestSampleRate = 1024e3
+const cfarTestCenter = 100e6
+
+func makeCFARTestSpectrum(r *rand.Rand, signals map[int]float32) []float32 {
+	var spectrum = make([]float32, cfarTestBins)
+
+	for i := range spectrum {
+		// Exponential distributed noise with mean 1 (like a periodogram bin of white noise)
+		spectrum[i] = float32(r.ExpFloat64()) * 0.2
+		spectrum[i] += 0.8
+	}
+
+	for bin, power := range signals {
+		for j := -2; j <= 2; j++ {
+			spectrum[bin+j] += power
+		}
+	}
+
+	return spectrum
+}
+
+func TestCFARFindSignals(t *testing.T) {
+	var r = rand.New(rand.NewSource(1))
+	var spectrum = makeCFARTestSpectrum(r, map[int]float32{
+		200: 100,
+		600: 1000,
+		610: 30, // Weak signal close to a strong one
+	})
+
+	// Cell Averaging gets the weak signal masked by the strong one, Ordered Statistic should find it.
+	var expectedBins = map[CFARMode][]int{
+		CFARCellAveraging:    {200, 600},
+		CFAROrderedStatistic: {200, 600, 610},
+	}
+
+	for mode, bins := range expectedBins {
+		var cfar = MakeCFARDetector(mode, 4, 16, 6)
+		var signals = cfar.FindSignals(spectrum, cfarTestCenter, cfarTestSampleRate)
+
+		if len(signals) != len(bins) {
+			t.Fatalf("Mode %d: Expected %d signals got %d: %+v", mode, len(bins), len(signals), signals)
+		}
+
+		for i, bin := range bins {
+			var expected = cfarTestCenter + float64(bin-cfarTestBins/2)*cfarTestSampleRate/cfarTestBins
+			if math.Abs(signals[i].CenterFrequency-expected) > cfarTestSampleRate/cfarTestBins {
+				t.Errorf("Mode %d: Expected signal at %f got %f", mode, expected, signals[i].CenterFrequency)
+			}
+			if signals[i].Bandwidth > 6*cfarTestSampleRate/cfarTestBins {
+				t.Errorf("Mode %d: Expected bandwidth of at most 6 bins, got %f", mode, signals[i].Bandwidth)
+			}
+		}
+
+		if signals[1].SNR < 20 {
+			t.Errorf("Mode %d: Expected SNR at least 20 dB got %f", mode, signals[1].SNR)
+		}
+
+		var floor = cfar.GetNoiseFloor()
+		if math.Abs(float64(floor)) > 0.5 {
+			t.Errorf("Mode %d: Expected noise floor around 0 dB got %f", mode, floor)
+		}
+	}
+}
+
+func TestSignalTrackerHysteresis(t *testing.T) {
+	var tracker = MakeSignalTracker(3, time.Second)
+	var now = time.Unix(0, 0)
+	var signal = []DetectedSignal{{CenterFrequency: 100e6, Bandwidth: 10e3, SNR: 20}}
+
+	var step = func(detected []DetectedSignal) (int, int) {
+		now = now.Add(100 * time.Millisecond)
+		var a, d = tracker.Update(detected, now)
+		return len(a), len(d)
+	}
+
+	// Single frame blip should never activate
+	if a, _ := step(signal); a != 0 {
+		t.Fatal("Signal activated on the first frame")
+	}
+	step(nil)
+
+	for i := 0; i < 2; i++ {
+		if a, _ := step(signal); a != 0 {
+			t.Fatal("Signal activated before 3 frames")
+		}
+	}
+
+	if a, _ := step(signal); a != 1 {
+		t.Fatal("Signal not activated after 3 frames")
+	}
+
+	// Short dropouts should not deactivate it
+	for i := 0; i < 5; i++ {
+		if _, d := step(nil); d != 0 {
+			t.Fatal("Signal deactivated before hold time")
+		}
+	}
+
+	if a, _ := step(signal); a != 0 {
+		t.Fatal("Signal activated twice")
+	}
+
+	var deactivated = 0
+	for i := 0; i < 12; i++ {
+		_, d := step(nil)
+		deactivated += d
+	}
+
+	if deactivated != 1 {
+		t.Fatalf("Expected one deactivation after hold time, got %d", deactivated)
+	}
+
+	if len(tracker.GetActiveSignals()) != 0 {
+		t.Fatal("Expected no active signals")
+	}
+}
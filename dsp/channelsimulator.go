@@ -0,0 +1,363 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+)
+
+// FadingModel is the statistical model of the multipath taps gain variation
+type FadingModel int
+
+const (
+	// FadingNone keeps the multipath taps static
+	FadingNone FadingModel = iota
+	// FadingRayleigh makes each tap a Rayleigh fading path (no line of sight)
+	FadingRayleigh
+	// FadingRician makes each tap a Rician fading path (line of sight plus scattering)
+	FadingRician
+)
+
+// clockTaps is the length of the fractional delay filters used for the sample clock offset
+const clockTaps = 16
+
+// clockPhases is the number of fractional delays in the sample clock offset filter table
+const clockPhases = 128
+
+// clockInterpolatorTaps are Blackman windowed sinc filters for clockPhases + 1 fractional delays from 0 to 1
+var clockInterpolatorTaps = makeClockInterpolatorTaps()
+
+func makeClockInterpolatorTaps() [][]float32 {
+	var taps = make([][]float32, clockPhases+1)
+
+	for p := range taps {
+		var delay = float64(p) / clockPhases
+		taps[p] = make([]float32, clockTaps)
+
+		for j := range taps[p] {
+			// Distance from sample j of the window to the interpolated position
+			var x = float64(j-clockTaps/2+1) - delay
+			var w = (x + clockTaps/2) / clockTaps
+			var window = 0.42 - 0.5*math.Cos(2*math.Pi*w) + 0.08*math.Cos(4*math.Pi*w)
+			taps[p][j] = float32(sinc(x) * window)
+		}
+	}
+
+	return taps
+}
+
+// fadingSinusoids is the number of sinusoids used to generate each fading path
+const fadingSinusoids = 16
+
+// fadingPath is a sum of sinusoids (Zheng & Xiao) fading process with unit average power
+type fadingPath struct {
+	dopplerI []float64
+	dopplerQ []float64
+	phaseI   []float64
+	phaseQ   []float64
+	losGain  float64
+	losFreq  float64
+	losPhase float64
+	nlosGain float64
+}
+
+func makeFadingPath(r *rand.Rand, model FadingModel, dopplerFrequency, kFactor float64) *fadingPath {
+	var p = &fadingPath{
+		dopplerI: make([]float64, fadingSinusoids),
+		dopplerQ: make([]float64, fadingSinusoids),
+		phaseI:   make([]float64, fadingSinusoids),
+		phaseQ:   make([]float64, fadingSinusoids),
+		nlosGain: math.Sqrt(1 / float64(fadingSinusoids)),
+	}
+
+	var theta = r.Float64()*2*math.Pi - math.Pi
+	for n := 0; n < fadingSinusoids; n++ {
+		var alpha = (2*math.Pi*float64(n+1) - math.Pi + theta) / (4 * fadingSinusoids)
+		p.dopplerI[n] = 2 * math.Pi * dopplerFrequency * math.Cos(alpha)
+		p.dopplerQ[n] = 2 * math.Pi * dopplerFrequency * math.Sin(alpha)
+		p.phaseI[n] = r.Float64()*2*math.Pi - math.Pi
+		p.phaseQ[n] = r.Float64()*2*math.Pi - math.Pi
+	}
+
+	if model == FadingRician {
+		p.losGain = math.Sqrt(kFactor / (kFactor + 1))
+		p.nlosGain *= math.Sqrt(1 / (kFactor + 1))
+		p.losFreq = 2 * math.Pi * dopplerFrequency * math.Cos(r.Float64()*2*math.Pi)
+		p.losPhase = r.Float64()*2*math.Pi - math.Pi
+	}
+
+	return p
+}
+
+// gain returns the path gain at the time t in seconds
+func (p *fadingPath) gain(t float64) complex64 {
+	var i, q float64
+
+	for n := 0; n < fadingSinusoids; n++ {
+		i += math.Cos(p.dopplerI[n]*t + p.phaseI[n])
+		q += math.Sin(p.dopplerQ[n]*t + p.phaseQ[n])
+	}
+
+	i *= p.nlosGain
+	q *= p.nlosGain
+
+	if p.losGain != 0 {
+		var s, c = math.Sincos(p.losFreq*t + p.losPhase)
+		i += p.losGain * c
+		q += p.losGain * s
+	}
+
+	return complex(float32(i), float32(q))
+}
+
+// ChannelSimulator applies radio channel impairments to a complex signal, to test receivers with repeatable bad signals.
+// The impairments are applied in order: multipath / fading, frequency offset and drift, phase noise,
+// sample clock offset and AWGN. All random processes come from the seed, so the same seed gives the same output.
+type ChannelSimulator struct {
+	sampleRate float64
+	rand       *rand.Rand
+	sampleTime int64
+
+	// multipath
+	taps          []complex64
+	paths         []*fadingPath
+	sampleHistory []complex64
+	fading        FadingModel
+
+	// frequency offset
+	phase            float64
+	phaseIncrement   float64
+	phaseIncrementD  float64
+	phaseNoiseStdDev float64
+
+	// sample clock offset
+	clockOffset   float32
+	clockStep     float64
+	clockPosition float64
+	clockHistory  []complex64
+
+	// awgn
+	snr         float32
+	noiseStdDev float64
+
+	buffer []complex64
+}
+
+// MakeChannelSimulator creates a ideal channel for signals at sampleRate. The impairments are enabled by the Set methods.
+func MakeChannelSimulator(sampleRate float32, seed int64) *ChannelSimulator {
+	return &ChannelSimulator{
+		sampleRate: float64(sampleRate),
+		rand:       rand.New(rand.NewSource(seed)),
+		snr:        float32(math.Inf(1)),
+	}
+}
+
+// SetSNR adds white gaussian noise at snr dB below signalPower (the average power of the input signal)
+func (c *ChannelSimulator) SetSNR(snr, signalPower float32) {
+	c.snr = snr
+	c.noiseStdDev = math.Sqrt(float64(signalPower) / math.Pow(10, float64(snr)/10) / 2)
+}
+
+// DisableNoise removes the white gaussian noise
+func (c *ChannelSimulator) DisableNoise() {
+	c.snr = float32(math.Inf(1))
+	c.noiseStdDev = 0
+}
+
+// GetSNR returns the SNR in dB, or +Inf if there is no noise
+func (c *ChannelSimulator) GetSNR() float32 {
+	return c.snr
+}
+
+// SetFrequencyOffset shifts the signal by offset Hertz, changing by drift Hertz / second
+func (c *ChannelSimulator) SetFrequencyOffset(offset, drift float32) {
+	c.phaseIncrement = 2 * math.Pi * float64(offset) / c.sampleRate
+	c.phaseIncrementD = 2 * math.Pi * float64(drift) / (c.sampleRate * c.sampleRate)
+}
+
+// GetFrequencyOffset returns the current frequency offset in Hertz, including the drift
+func (c *ChannelSimulator) GetFrequencyOffset() float32 {
+	return float32(c.phaseIncrement * c.sampleRate / (2 * math.Pi))
+}
+
+// SetPhaseNoise adds a random walk phase noise with stdDev radians of standard deviation per sample
+func (c *ChannelSimulator) SetPhaseNoise(stdDev float32) {
+	c.phaseNoiseStdDev = float64(stdDev)
+}
+
+// SetClockOffset makes the sample clock ppm parts per million faster (positive) or slower (negative) than the signal
+// sample rate. The signal is resampled, so the output length does not match the input length.
+func (c *ChannelSimulator) SetClockOffset(ppm float32) {
+	c.clockOffset = ppm
+
+	if ppm == 0 {
+		c.clockHistory = nil
+		return
+	}
+
+	// The output is clockStep input samples apart, starting at the first input sample
+	c.clockStep = 1 / (1 + float64(ppm)*1e-6)
+	c.clockHistory = make([]complex64, clockTaps/2-1)
+	c.clockPosition = clockTaps/2 - 1
+}
+
+// GetClockOffset returns the sample clock offset in parts per million
+func (c *ChannelSimulator) GetClockOffset() float32 {
+	return c.clockOffset
+}
+
+// SetMultipath sets the channel impulse response, with one tap per sample of delay.
+// With FadingRayleigh or FadingRician, the gain of each non zero tap is multiplied by an independent fading path
+// with dopplerFrequency Hertz of max doppler shift. kFactor is the Rician ratio between the line of sight and
+// the scattered power (linear).
+func (c *ChannelSimulator) SetMultipath(taps []complex64, model FadingModel, dopplerFrequency, kFactor float32) {
+	c.taps = make([]complex64, len(taps))
+	copy(c.taps, taps)
+
+	c.fading = model
+	c.paths = nil
+	c.sampleHistory = make([]complex64, len(taps)-1)
+
+	if model == FadingNone {
+		return
+	}
+
+	c.paths = make([]*fadingPath, len(taps))
+	for i, t := range taps {
+		if t != 0 {
+			c.paths[i] = makeFadingPath(c.rand, model, float64(dopplerFrequency), float64(kFactor))
+		}
+	}
+}
+
+// DisableMultipath removes the multipath and fading
+func (c *ChannelSimulator) DisableMultipath() {
+	c.taps = nil
+	c.paths = nil
+	c.sampleHistory = nil
+	c.fading = FadingNone
+}
+
+// GetFadingModel returns the current fading model
+func (c *ChannelSimulator) GetFadingModel() FadingModel {
+	return c.fading
+}
+
+func (c *ChannelSimulator) Work(data []complex64) []complex64 {
+	var output = make([]complex64, c.PredictOutputSize(len(data)))
+	var n = c.WorkBuffer(data, output)
+	return output[:n]
+}
+
+func (c *ChannelSimulator) WorkBuffer(input, output []complex64) int {
+	if len(output) < c.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	if len(c.buffer) < len(input) {
+		c.buffer = make([]complex64, len(input))
+	}
+
+	var buffer = c.buffer[:len(input)]
+
+	c.multipath(input, buffer)
+
+	for i, v := range buffer {
+		var s, co = math.Sincos(c.phase)
+		buffer[i] = v * complex(float32(co), float32(s))
+
+		c.phase += c.phaseIncrement
+		c.phaseIncrement += c.phaseIncrementD
+
+		if c.phaseNoiseStdDev != 0 {
+			c.phase += c.rand.NormFloat64() * c.phaseNoiseStdDev
+		}
+
+		if c.phase > math.Pi || c.phase < -math.Pi {
+			c.phase = math.Remainder(c.phase, 2*math.Pi)
+		}
+	}
+
+	var n = c.resample(buffer, output)
+
+	if c.noiseStdDev != 0 {
+		for i := 0; i < n; i++ {
+			output[i] += complex(
+				float32(c.rand.NormFloat64()*c.noiseStdDev),
+				float32(c.rand.NormFloat64()*c.noiseStdDev),
+			)
+		}
+	}
+
+	return n
+}
+
+func (c *ChannelSimulator) multipath(input, output []complex64) {
+	if len(c.taps) == 0 {
+		copy(output, input)
+		c.sampleTime += int64(len(input))
+		return
+	}
+
+	var samples = append(c.sampleHistory, input...)
+	var last = len(c.taps) - 1
+
+	for i := range input {
+		var t = float64(c.sampleTime) / c.sampleRate
+		var acc complex64
+
+		for k, tap := range c.taps {
+			if tap == 0 {
+				continue
+			}
+			if c.paths != nil {
+				tap *= c.paths[k].gain(t)
+			}
+			acc += tap * samples[i+last-k]
+		}
+
+		output[i] = acc
+		c.sampleTime++
+	}
+
+	c.sampleHistory = samples[len(input):]
+}
+
+func (c *ChannelSimulator) resample(input, output []complex64) int {
+	if c.clockHistory == nil {
+		copy(output, input)
+		return len(input)
+	}
+
+	var samples = append(c.clockHistory, input...)
+	var n = 0
+
+	for int(c.clockPosition)+clockTaps/2 < len(samples) {
+		var index = int(c.clockPosition)
+		var phase = (c.clockPosition - float64(index)) * clockPhases
+		var row = int(phase)
+		var frac = float32(phase - float64(row))
+
+		var window = samples[index-clockTaps/2+1:]
+		var a = DotProductResult(window, clockInterpolatorTaps[row])
+		var b = DotProductResult(window, clockInterpolatorTaps[row+1])
+
+		output[n] = a + (b-a)*complex(frac, 0)
+		n++
+
+		c.clockPosition += c.clockStep
+	}
+
+	var keep = int(c.clockPosition) - clockTaps/2 + 1
+	c.clockHistory = append(c.clockHistory[:0], samples[keep:]...)
+	c.clockPosition -= float64(keep)
+
+	return n
+}
+
+func (c *ChannelSimulator) PredictOutputSize(inputLength int) int {
+	if c.clockHistory != nil {
+		return int(float64(inputLength+len(c.clockHistory))/c.clockStep) + 1
+	}
+
+	return inputLength
+}
@@ -0,0 +1,262 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+func makeCarrier(length int) []complex64 {
+	var data = make([]complex64, length)
+	for i := range data {
+		data[i] = 1
+	}
+	return data
+}
+
+func TestChannelSimulatorIdeal(t *testing.T) {
+	var input = makeSymbols(1024, 256)
+	var signal = MakePSKModulator(3, 1, nil).Work(input)
+	var output = MakeChannelSimulator(48000, 1).Work(signal)
+
+	if !tools.Complex64ArrayEqual(signal, output) {
+		t.Fatal("Expected the ideal channel to not change the signal")
+	}
+}
+
+func TestChannelSimulatorDeterministic(t *testing.T) {
+	var makeChannel = func(seed int64) *ChannelSimulator {
+		var c = MakeChannelSimulator(48000, seed)
+		c.SetSNR(10, 1)
+		c.SetPhaseNoise(0.01)
+		c.SetMultipath([]complex64{1, 0, 0.5}, FadingRayleigh, 50, 0)
+		return c
+	}
+
+	var input = makeCarrier(4096)
+	var a = makeChannel(1).Work(input)
+	var b = makeChannel(1).Work(input)
+	var c = makeChannel(2).Work(input)
+
+	if !tools.Complex64ArrayEqual(a, b) {
+		t.Fatal("Expected the same output for the same seed")
+	}
+	var equal = 0
+	for i := range a {
+		if a[i] == c[i] {
+			equal++
+		}
+	}
+	if equal == len(a) {
+		t.Fatal("Expected a different output for a different seed")
+	}
+}
+
+func TestChannelSimulatorSNR(t *testing.T) {
+	const length = 65536
+
+	for _, snr := range []float32{0, 10, 20} {
+		var c = MakeChannelSimulator(48000, 1)
+		c.SetSNR(snr, 1)
+
+		var input = makeCarrier(length)
+		var output = c.Work(input)
+
+		var noisePower = 0.0
+		for i, v := range output {
+			noisePower += float64(tools.ComplexAbsSquared(v - input[i]))
+		}
+		noisePower /= length
+
+		var measured = -10 * math.Log10(noisePower)
+		if math.Abs(measured-float64(snr)) > 0.2 {
+			t.Errorf("Expected SNR %f dB got %f dB", snr, measured)
+		}
+	}
+}
+
+func TestChannelSimulatorFrequencyOffset(t *testing.T) {
+	const sampleRate = 48000
+	const length = 48000
+
+	var c = MakeChannelSimulator(sampleRate, 1)
+	c.SetFrequencyOffset(1000, 500)
+
+	var output = c.Work(makeCarrier(length))
+	var qd = MakeQuadDemod(sampleRate / (2 * math.Pi)).Work(output)
+
+	for _, i := range []int{100, length / 2, length - 1} {
+		var expected = 1000 + 500*float32(i-1)/sampleRate
+		if math.Abs(float64(qd[i]-expected)) > 0.5 {
+			t.Errorf("Expected %f Hz got %f Hz at %d", expected, qd[i], i)
+		}
+	}
+
+	if math.Abs(float64(c.GetFrequencyOffset()-1500)) > 0.5 {
+		t.Errorf("Expected the offset to drift to 1500 Hz, got %f Hz", c.GetFrequencyOffset())
+	}
+}
+
+func TestChannelSimulatorPhaseNoise(t *testing.T) {
+	const length = 16384
+	const stdDev = 0.01
+
+	var c = MakeChannelSimulator(48000, 1)
+	c.SetPhaseNoise(stdDev)
+
+	var output = c.Work(makeCarrier(length))
+
+	var variance = 0.0
+	for i := 1; i < length; i++ {
+		if math.Abs(float64(tools.ComplexAbs(output[i]))-1) > 1e-4 {
+			t.Fatalf("Expected unit magnitude got %f at %d", tools.ComplexAbs(output[i]), i)
+		}
+		var d = float64(tools.ComplexPhase(output[i] * tools.Conj(output[i-1])))
+		variance += d * d
+	}
+	variance /= length - 1
+
+	if math.Abs(math.Sqrt(variance)-stdDev)/stdDev > 0.05 {
+		t.Errorf("Expected phase step standard deviation %f got %f", stdDev, math.Sqrt(variance))
+	}
+}
+
+func TestChannelSimulatorClockOffset(t *testing.T) {
+	const length = 100000
+
+	for _, ppm := range []float32{-1000, 1000} {
+		var c = MakeChannelSimulator(48000, 1)
+		c.SetClockOffset(ppm)
+
+		var total = 0
+		var input = makeCarrier(length / 10)
+		for i := 0; i < 10; i++ {
+			total += len(c.Work(input))
+		}
+
+		var expected = length * (1 + float64(ppm)*1e-6)
+		if math.Abs(float64(total)-expected) > 64 {
+			t.Errorf("%f ppm: Expected about %f samples got %d", ppm, expected, total)
+		}
+	}
+
+	// The interpolation should not change the amplitude along the fractional delays
+	var c = MakeChannelSimulator(48000, 1)
+	c.SetClockOffset(500)
+
+	var tone = make([]complex64, length)
+	for i := range tone {
+		var s, co = math.Sincos(2 * math.Pi * 0.1 * float64(i))
+		tone[i] = complex(float32(co), float32(s))
+	}
+
+	var output = c.Work(tone)
+	for i := 100; i < len(output)-100; i++ {
+		var amplitude = tools.ComplexAbs(output[i])
+		if math.Abs(float64(amplitude)-1) > 0.01 {
+			t.Fatalf("Expected amplitude 1 got %f at %d", amplitude, i)
+		}
+	}
+}
+
+func TestChannelSimulatorStaticMultipath(t *testing.T) {
+	var taps = []complex64{1, 0, complex(0, 0.5), -0.25}
+	var c = MakeChannelSimulator(48000, 1)
+	c.SetMultipath(taps, FadingNone, 0, 0)
+
+	// Split the impulse in two calls to check the history
+	var impulse = make([]complex64, 8)
+	impulse[0] = 1
+	var output = append(c.Work(impulse[:2]), c.Work(impulse[2:])...)
+
+	for i, v := range output {
+		var expected complex64
+		if i < len(taps) {
+			expected = taps[i]
+		}
+		if v != expected {
+			t.Fatalf("Expected %v got %v at %d", expected, v, i)
+		}
+	}
+}
+
+func TestChannelSimulatorFading(t *testing.T) {
+	const sampleRate = 10000
+	const length = 200000
+
+	var testCases = []struct {
+		name    string
+		model   FadingModel
+		kFactor float32
+	}{
+		{"Rayleigh", FadingRayleigh, 0},
+		{"Rician", FadingRician, 10},
+	}
+
+	for _, tc := range testCases {
+		var c = MakeChannelSimulator(sampleRate, 1)
+		c.SetMultipath([]complex64{1}, tc.model, 100, tc.kFactor)
+
+		var output = c.Work(makeCarrier(length))
+
+		var power = 0.0
+		var deepFades = 0
+		for _, v := range output {
+			var p = float64(tools.ComplexAbsSquared(v))
+			power += p
+			// 20 dB below the average power
+			if p < 0.01 {
+				deepFades++
+			}
+		}
+		power /= length
+
+		if math.Abs(power-1) > 0.15 {
+			t.Errorf("%s: Expected unit average power got %f", tc.name, power)
+		}
+
+		// A Rayleigh channel stays 20 dB below the average about 1% of the time, a strong line of sight almost never
+		var fadeRatio = float64(deepFades) / length
+		if tc.model == FadingRayleigh && (fadeRatio < 0.002 || fadeRatio > 0.03) {
+			t.Errorf("%s: Expected about 1%% of deep fades got %f%%", tc.name, fadeRatio*100)
+		}
+		if tc.model == FadingRician && fadeRatio > 0.001 {
+			t.Errorf("%s: Expected no deep fades got %f%%", tc.name, fadeRatio*100)
+		}
+	}
+}
+
+func TestChannelSimulatorBPSKBitErrorRate(t *testing.T) {
+	const length = 200000
+
+	var symbols = makeSymbols(length, 2)
+	var bpsk = MakePSKModulator(1, 1, nil)
+
+	for _, ebn0 := range []float32{4, 6, 8} {
+		var c = MakeChannelSimulator(48000, 1)
+		// BPSK carries one bit per symbol, so Eb/N0 = Es/N0 = SNR at one sample per symbol
+		c.SetSNR(ebn0, 1)
+
+		var output = c.Work(bpsk.Work(symbols))
+		var constellation = bpsk.GetConstellation()
+
+		var errors = 0
+		for i, v := range output {
+			var decided = byte(0)
+			if real(v)*real(constellation[1]) > 0 {
+				decided = 1
+			}
+			if decided != symbols[i] {
+				errors++
+			}
+		}
+
+		var ber = float64(errors) / length
+		var expected = 0.5 * math.Erfc(math.Sqrt(math.Pow(10, float64(ebn0)/10)))
+
+		if math.Abs(ber-expected)/expected > 0.2 {
+			t.Errorf("Eb/N0 %f dB: Expected BER %e got %e", ebn0, expected, ber)
+		}
+	}
+}
@@ -24,6 +24,10 @@ func (f *Decimator) PredictOutputSize(inputLength int) int {
 	return f.fir.PredictOutputSize(inputLength)
 }
 
+func (f *Decimator) MapOffset(offset int) int {
+	return f.fir.MapOffset(offset)
+}
+
 type FloatDecimator struct {
 	fir             *FloatFirFilter
 	decimationRatio int
@@ -47,3 +51,7 @@ func (f *FloatDecimator) WorkBuffer(input, output []float32) int {
 func (f *FloatDecimator) PredictOutputSize(inputLength int) int {
 	return f.fir.PredictOutputSize(inputLength)
 }
+
+func (f *FloatDecimator) MapOffset(offset int) int {
+	return f.fir.MapOffset(offset)
+}
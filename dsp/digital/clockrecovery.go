@@ -1,12 +1,16 @@
 package digital
 
 import (
+	"github.com/racerxdl/segdsp/dsp"
 	"github.com/racerxdl/segdsp/tools"
 )
 
 const ccHistoryLength = 3
 const fudgeFactor = 16
 
+// mmseCenter is the sample after which the MMSE interpolator interpolates, from the first sample of its window
+const mmseCenter = iNTAPS/2 - 1
+
 func complexSlicer(sample complex64) complex64 {
 	var r = 0.0
 	var i = 0.0
@@ -35,6 +39,8 @@ type ComplexClockRecovery struct {
 	consumed           int
 	sampleHistoryCount int
 	sampleHistory      []complex64
+	lastHistory        int
+	tags               dsp.TagPositions
 
 	p2T complex64
 	p1T complex64
@@ -130,6 +136,8 @@ func (ccr *ComplexClockRecovery) internalWorkBuffer(input, output []complex64) i
 	var u, x, y complex64
 	var outputIndex = 0
 
+	ccr.tags.Reset()
+
 	for inputIndex < nInput && outputIndex < nOutput {
 		ccr.p2T = ccr.p1T
 		ccr.p1T = ccr.p0T
@@ -146,6 +154,7 @@ func (ccr *ComplexClockRecovery) internalWorkBuffer(input, output []complex64) i
 		mmVal = real(u)
 		output[outputIndex] = ccr.p0T
 		outputIndex++
+		ccr.tags.Add(inputIndex + mmseCenter - ccr.lastHistory)
 
 		mmVal = tools.Clip(mmVal, 1.0)
 
@@ -177,6 +186,7 @@ func (ccr *ComplexClockRecovery) Work(input []complex64) []complex64 {
 }
 
 func (ccr *ComplexClockRecovery) WorkBuffer(input, output []complex64) int {
+	ccr.lastHistory = len(ccr.sampleHistory)
 	var s = append(ccr.sampleHistory, input...)
 	var symbols = ccr.internalWorkBuffer(s, output)
 
@@ -199,6 +209,10 @@ func (ccr *ComplexClockRecovery) PredictOutputSize(inputLength int) int {
 	return inputLength + ccr.sampleHistoryCount
 }
 
+func (ccr *ComplexClockRecovery) MapOffset(offset int) int {
+	return ccr.tags.MapOffset(offset)
+}
+
 // endregion
 // region Float Clock Recovery
 
@@ -207,6 +221,8 @@ type FloatClockRecovery struct {
 	consumed           int
 	sampleHistoryCount int
 	sampleHistory      []float32
+	lastHistory        int
+	tags               dsp.TagPositions
 
 	mu                 float32
 	omega              float32
@@ -287,10 +303,13 @@ func (ccr *FloatClockRecovery) internalWorkBuffer(input, output []float32) int {
 	var nOutput = len(output)
 	var outputIndex = 0
 
+	ccr.tags.Reset()
+
 	for inputIndex < nInput && outputIndex < nOutput {
 		var o = ccr.interp.Interpolate(input[inputIndex:], ccr.mu)
 		output[outputIndex] = o
 		outputIndex++
+		ccr.tags.Add(inputIndex + mmseCenter - ccr.lastHistory)
 
 		mmVal = floatSlicer(ccr.lastSample)*o - floatSlicer(o)*ccr.lastSample
 		ccr.lastSample = o
@@ -323,6 +342,7 @@ func (ccr *FloatClockRecovery) Work(input []float32) []float32 {
 }
 
 func (ccr *FloatClockRecovery) WorkBuffer(input, output []float32) int {
+	ccr.lastHistory = len(ccr.sampleHistory)
 	var s = append(ccr.sampleHistory, input...)
 	var symbols = ccr.internalWorkBuffer(s, output)
 
@@ -345,4 +365,8 @@ func (ccr *FloatClockRecovery) PredictOutputSize(inputLength int) int {
 	return inputLength + ccr.sampleHistoryCount
 }
 
+func (ccr *FloatClockRecovery) MapOffset(offset int) int {
+	return ccr.tags.MapOffset(offset)
+}
+
 // endregion
@@ -0,0 +1,267 @@
+package digital
+
+import (
+	"math"
+	"testing"
+
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/tools"
+)
+
+const clockSyncSps = 4
+const clockSyncSymbols = 6000
+const clockSyncSettle = 1000
+
+// clockSyncTestSignal makes pulse shaped QPSK at clockSyncSps samples per symbol, with a sample clock offset
+func clockSyncTestSignal(ppm float32) (symbols []byte, constellation []complex64, signal []complex64) {
+	var rrc = dsp.MakeRRC(1, clockSyncSps, 1, 0.35, 11*clockSyncSps)
+	var psk = dsp.MakePSKModulator(2, clockSyncSps, rrc)
+
+	symbols = makeTestBytes(clockSyncSymbols, 4)
+	signal = psk.Work(symbols)
+
+	var c = dsp.MakeChannelSimulator(1, 1)
+	c.SetClockOffset(ppm)
+	c.SetSNR(25, 1.0/clockSyncSps)
+
+	return symbols, psk.GetConstellation(), c.Work(signal)
+}
+
+func makeTestBytes(length, order int) []byte {
+	var indexes, _ = makeTestSymbols(length, make(Constellation, order))
+	var symbols = make([]byte, length)
+	for i, v := range indexes {
+		symbols[i] = byte(v)
+	}
+	return symbols
+}
+
+// bestAlignmentErrors returns the lowest number of wrong decisions of the settled output, over the possible delays
+func bestAlignmentErrors(output []complex64, symbols []byte, constellation []complex64) int {
+	var best = len(output)
+
+	for delay := -20; delay <= 40; delay++ {
+		var errors = 0
+		for n := clockSyncSettle; n < len(output)-40; n++ {
+			if n-delay < 0 || n-delay >= len(symbols) {
+				errors++
+				continue
+			}
+			if byte(Constellation(constellation).Decide(output[n])) != symbols[n-delay] {
+				errors++
+			}
+		}
+		if errors < best {
+			best = errors
+		}
+	}
+
+	return best
+}
+
+func TestComplexGardnerClockRecovery(t *testing.T) {
+	for _, ppm := range []float32{0, 500, -500} {
+		var symbols, constellation, signal = clockSyncTestSignal(ppm)
+		var matched = dsp.MakeFirFilter(dsp.MakeRRC(1, clockSyncSps, 1, 0.35, 11*clockSyncSps)).FilterOut(signal)
+
+		var g = MakeComplexGardnerClockRecovery(clockSyncSps, 0.02, 0.01)
+
+		// Split in blocks to check the sample history
+		var output []complex64
+		for i := 0; i < len(matched); i += 1000 {
+			var end = i + 1000
+			if end > len(matched) {
+				end = len(matched)
+			}
+			output = append(output, g.Work(matched[i:end])...)
+		}
+
+		if math.Abs(float64(len(output)-clockSyncSymbols)) > 10 {
+			t.Errorf("%f ppm: Expected about %d symbols got %d", ppm, clockSyncSymbols, len(output))
+		}
+
+		var expectedOmega = clockSyncSps * (1 + float64(ppm)*1e-6)
+		if math.Abs(float64(g.GetOmega())-expectedOmega) > 0.005 {
+			t.Errorf("%f ppm: Expected omega %f got %f", ppm, expectedOmega, g.GetOmega())
+		}
+
+		if errors := bestAlignmentErrors(output, symbols, constellation); errors != 0 {
+			t.Errorf("%f ppm: Expected no symbol errors after the loop settles got %d", ppm, errors)
+		}
+	}
+}
+
+func TestFloatGardnerClockRecovery(t *testing.T) {
+	const sps = 8
+	const deviation = 1200
+	const sampleRate = 9600 * sps
+
+	var symbols = makeTestBytes(clockSyncSymbols, 2)
+	var fsk = dsp.MakeGFSKModulator(2, sps, deviation, sampleRate, 0.5)
+	var c = dsp.MakeChannelSimulator(sampleRate, 1)
+	c.SetClockOffset(300)
+
+	var frequency = dsp.MakeQuadDemod(sampleRate / (2 * math.Pi * deviation)).Work(c.Work(fsk.Work(symbols)))
+
+	var g = MakeFloatGardnerClockRecovery(sps, 0.02, 0.01)
+	var output = g.Work(frequency)
+
+	var complexOutput = tools.ToComplex64Array(output)
+	var levels = []complex64{complex(fsk.GetLevels()[0], 0), complex(fsk.GetLevels()[1], 0)}
+
+	if errors := bestAlignmentErrors(complexOutput, symbols, levels); errors != 0 {
+		t.Errorf("Expected no symbol errors after the loop settles got %d", errors)
+	}
+}
+
+func TestComplexPFBClockSync(t *testing.T) {
+	const nFilters = 32
+
+	for _, ppm := range []float32{0, 500, -500} {
+		var symbols, constellation, signal = clockSyncTestSignal(ppm)
+		var taps = dsp.MakeRRC(nFilters, nFilters*clockSyncSps, 1, 0.35, 11*nFilters*clockSyncSps)
+
+		var c = MakeComplexPFBClockSync(clockSyncSps, 0.02, 0.01, taps, nFilters)
+		if c.GetNFilters() != nFilters {
+			t.Fatalf("Expected %d filters got %d", nFilters, c.GetNFilters())
+		}
+
+		var output []complex64
+		for i := 0; i < len(signal); i += 1000 {
+			var end = i + 1000
+			if end > len(signal) {
+				end = len(signal)
+			}
+			output = append(output, c.Work(signal[i:end])...)
+		}
+
+		var expectedOmega = clockSyncSps * (1 + float64(ppm)*1e-6)
+		if math.Abs(float64(c.GetOmega())-expectedOmega) > 0.005 {
+			t.Errorf("%f ppm: Expected omega %f got %f", ppm, expectedOmega, c.GetOmega())
+		}
+
+		if errors := bestAlignmentErrors(output, symbols, constellation); errors != 0 {
+			t.Errorf("%f ppm: Expected no symbol errors after the loop settles got %d", ppm, errors)
+		}
+	}
+}
+
+func TestFloatPFBClockSync(t *testing.T) {
+	const nFilters = 32
+
+	var symbols, constellation, signal = clockSyncTestSignal(200)
+
+	// Real PAM: the I branch of the QPSK signal carries one bit per symbol
+	var inPhase = make([]float32, len(signal))
+	for i, v := range signal {
+		inPhase[i] = real(v)
+	}
+
+	var taps = dsp.MakeRRC(nFilters, nFilters*clockSyncSps, 1, 0.35, 11*nFilters*clockSyncSps)
+	var c = MakeFloatPFBClockSync(clockSyncSps, 0.02, 0.01, taps, nFilters)
+	var output = tools.ToComplex64Array(c.Work(inPhase))
+
+	var bits = make([]byte, len(symbols))
+	for i, s := range symbols {
+		if real(constellation[s]) > 0 {
+			bits[i] = 1
+		}
+	}
+
+	if errors := bestAlignmentErrors(output, bits, []complex64{-1, 1}); errors != 0 {
+		t.Errorf("Expected no symbol errors after the loop settles got %d", errors)
+	}
+}
+
+func TestClockRecoveryMapOffset(t *testing.T) {
+	const nFilters = 32
+
+	var _, _, signal = clockSyncTestSignal(0)
+	var taps = dsp.MakeRRC(nFilters, nFilters*clockSyncSps, 1, 0.35, 11*nFilters*clockSyncSps)
+
+	var workers = map[string]interface {
+		Work(input []complex64) []complex64
+		MapOffset(offset int) int
+	}{
+		"Gardner": MakeComplexGardnerClockRecovery(clockSyncSps, 0.02, 0.01),
+		"PFB":     MakeComplexPFBClockSync(clockSyncSps, 0.02, 0.01, taps, nFilters),
+		"M&M":     NewComplexClockRecovery(clockSyncSps, 0.25*0.175*0.175, 0.5, 0.175, 0.005),
+	}
+
+	for name, w := range workers {
+		// The symbol of each input sample should be at the same delay, whatever the block it is in
+		var minDelay, maxDelay = math.Inf(1), math.Inf(-1)
+		var outputs = 0
+
+		for i := 0; i < len(signal); i += 1000 {
+			var end = i + 1000
+			if end > len(signal) {
+				end = len(signal)
+			}
+			var output = w.Work(signal[i:end])
+
+			for offset := 0; offset < end-i && i >= clockSyncSettle*clockSyncSps; offset += 97 {
+				var delay = float64(outputs+w.MapOffset(offset)) - float64(i+offset)/clockSyncSps
+				minDelay = math.Min(minDelay, delay)
+				maxDelay = math.Max(maxDelay, delay)
+			}
+
+			outputs += len(output)
+		}
+
+		if maxDelay-minDelay > 1.5 {
+			t.Errorf("%s: Expected a constant symbol delay, got from %f to %f", name, minDelay, maxDelay)
+		}
+	}
+}
+
+func TestTimingLoopGains(t *testing.T) {
+	var g = MakeComplexGardnerClockRecovery(4, 0.02, 0.01)
+
+	var gainMu = g.GetGainMu()
+	var gainOmega = g.GetGainOmega()
+
+	g.SetDampingFactor(1)
+	if g.GetGainMu() <= gainMu {
+		t.Errorf("Expected a higher damping factor to raise gain mu")
+	}
+
+	g.SetLoopBandwidth(0.04)
+	if g.GetGainOmega() <= gainOmega {
+		t.Errorf("Expected a higher loop bandwidth to raise gain omega")
+	}
+
+	g.SetGainMu(0.1)
+	g.SetGainOmega(0.01)
+	if g.GetGainMu() != 0.1 || g.GetGainOmega() != 0.01 {
+		t.Errorf("Expected the gains to be set directly")
+	}
+
+	g.SetOmega(8)
+	if g.GetOmega() != 8 {
+		t.Errorf("Expected omega 8 got %f", g.GetOmega())
+	}
+}
+
+func TestMMSEInterpolatorSteps(t *testing.T) {
+	var interp = MakeFloatMMSEFirInterpolator()
+	var complexInterp = MakeComplexMMSEFirInterpolator()
+
+	// A ramp is interpolated exactly, including the last step (mu = 1)
+	var ramp = make([]float32, interp.GetNTaps())
+	for i := range ramp {
+		ramp[i] = float32(i)
+	}
+	var complexRamp = tools.ToComplex64Array(ramp)
+
+	for _, mu := range []float32{0, 0.25, 0.5, 1} {
+		var expected = float32(interp.GetNTaps()/2) - mu
+
+		if v := interp.Interpolate(ramp, mu); math.Abs(float64(v-expected)) > 1e-3 {
+			t.Errorf("Expected %f got %f for mu %f", expected, v, mu)
+		}
+		if v := complexInterp.Interpolate(complexRamp, mu); math.Abs(float64(real(v)-expected)) > 1e-3 {
+			t.Errorf("Expected %f got %f for mu %f", expected, real(v), mu)
+		}
+	}
+}
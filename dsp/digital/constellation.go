@@ -0,0 +1,67 @@
+package digital
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+// Constellation is a list of the ideal symbol points of a modulation
+type Constellation []complex64
+
+// BPSKConstellation is the BPSK constellation with unit energy
+var BPSKConstellation = Constellation{-1, 1}
+
+// QPSKConstellation is the QPSK (Gray coded) constellation with unit energy
+var QPSKConstellation = makePSKConstellation(4, math.Pi/4)
+
+// PSK8Constellation is the 8PSK constellation with unit energy
+var PSK8Constellation = makePSKConstellation(8, 0)
+
+// QAM16Constellation is the 16QAM constellation with unit average energy
+var QAM16Constellation = makeQAM16Constellation()
+
+func makePSKConstellation(order int, offset float64) Constellation {
+	var c = make(Constellation, order)
+
+	for i := 0; i < order; i++ {
+		var s, co = math.Sincos(2*math.Pi*float64(i)/float64(order) + offset)
+		c[i] = complex(float32(co), float32(s))
+	}
+
+	return c
+}
+
+func makeQAM16Constellation() Constellation {
+	var c = make(Constellation, 0, 16)
+	var scale = float32(1 / math.Sqrt(10))
+
+	for _, i := range []float32{-3, -1, 1, 3} {
+		for _, q := range []float32{-3, -1, 1, 3} {
+			c = append(c, complex(i*scale, q*scale))
+		}
+	}
+
+	return c
+}
+
+// Decide returns the index of the nearest constellation point to sample
+func (c Constellation) Decide(sample complex64) int {
+	var best = 0
+	var bestDistance = float32(math.MaxFloat32)
+
+	for i, p := range c {
+		var d = tools.ComplexAbsSquared(sample - p)
+		if d < bestDistance {
+			best = i
+			bestDistance = d
+		}
+	}
+
+	return best
+}
+
+// Nearest returns the nearest constellation point to sample
+func (c Constellation) Nearest(sample complex64) complex64 {
+	return c[c.Decide(sample)]
+}
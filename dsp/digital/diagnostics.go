@@ -0,0 +1,272 @@
+package digital
+
+import (
+	"sync"
+)
+
+// EyeTracePoints is the number of points of each eye diagram trace
+const EyeTracePoints = 32
+
+// EyeTraceSymbols is the number of symbols covered by each eye diagram trace
+const EyeTraceSymbols = 2
+
+// region Constellation Tap
+
+// ConstellationTap keeps the latest symbol points for a constellation display.
+// It should run after the clock and carrier recovery (one sample per symbol). The symbols pass through unchanged, and
+// nothing is kept while the tap is disabled, so it can stay in the flowgraph when no one is looking at it.
+// The points are read from another goroutine with GetPoints.
+type ConstellationTap struct {
+	lock       sync.Mutex
+	enabled    bool
+	decimation int
+	skip       int
+	points     []complex64
+	position   int
+	count      int
+}
+
+// MakeConstellationTap creates a constellation tap that keeps the last maxPoints symbols, one of every decimation symbols
+func MakeConstellationTap(maxPoints, decimation int) *ConstellationTap {
+	if maxPoints < 1 {
+		panic("Constellation tap should keep at least one point")
+	}
+
+	if decimation < 1 {
+		panic("Constellation tap decimation should be at least 1")
+	}
+
+	return &ConstellationTap{
+		decimation: decimation,
+		points:     make([]complex64, maxPoints),
+	}
+}
+
+// SetEnabled starts or stops keeping the symbols. The kept points are dropped when the tap is enabled.
+func (c *ConstellationTap) SetEnabled(enabled bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if enabled && !c.enabled {
+		c.position = 0
+		c.count = 0
+		c.skip = 0
+	}
+
+	c.enabled = enabled
+}
+
+// IsEnabled returns if the tap is keeping the symbols
+func (c *ConstellationTap) IsEnabled() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.enabled
+}
+
+// GetPoints returns a copy of the kept symbols, the oldest first
+func (c *ConstellationTap) GetPoints() []complex64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var points = make([]complex64, c.count)
+	var start = c.position - c.count
+	if start < 0 {
+		start += len(c.points)
+	}
+
+	for i := range points {
+		points[i] = c.points[(start+i)%len(c.points)]
+	}
+
+	return points
+}
+
+func (c *ConstellationTap) Work(input []complex64) []complex64 {
+	var output = make([]complex64, c.PredictOutputSize(len(input)))
+	c.WorkBuffer(input, output)
+	return output
+}
+
+func (c *ConstellationTap) WorkBuffer(input, output []complex64) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	copy(output, input)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.enabled {
+		return len(input)
+	}
+
+	for _, v := range input {
+		if c.skip > 0 {
+			c.skip--
+			continue
+		}
+
+		c.points[c.position] = v
+		c.position = (c.position + 1) % len(c.points)
+		if c.count < len(c.points) {
+			c.count++
+		}
+		c.skip = c.decimation - 1
+	}
+
+	return len(input)
+}
+
+func (c *ConstellationTap) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+// region Eye Tap
+
+// EyeTap keeps the latest eye diagram traces of a baseband signal.
+// It should run after the matched filter, before the clock recovery. Each trace has EyeTracePoints points over
+// EyeTraceSymbols symbols, linearly interpolated from the samples, so the traces look the same for any number of
+// samples per symbol. The samples pass through unchanged, and nothing is kept while the tap is disabled.
+// The traces are read from another goroutine with GetTraces.
+type EyeTap struct {
+	lock       sync.Mutex
+	enabled    bool
+	step       float64
+	gap        float64
+	next       float64
+	last       complex64
+	pointIndex int
+	traces     [][]complex64
+	position   int
+	count      int
+}
+
+// MakeEyeTap creates an eye tap that keeps the last maxTraces traces, one of every decimation traces of the signal
+// (1 keeps contiguous traces)
+func MakeEyeTap(samplesPerSymbol float32, maxTraces, decimation int) *EyeTap {
+	if samplesPerSymbol < 1 {
+		panic("Eye tap needs at least one sample per symbol")
+	}
+
+	if maxTraces < 1 {
+		panic("Eye tap should keep at least one trace")
+	}
+
+	if decimation < 1 {
+		panic("Eye tap decimation should be at least 1")
+	}
+
+	var span = float64(samplesPerSymbol) * EyeTraceSymbols
+
+	var e = &EyeTap{
+		step:   span / (EyeTracePoints - 1),
+		gap:    span * float64(decimation-1),
+		traces: make([][]complex64, maxTraces),
+	}
+
+	for i := range e.traces {
+		e.traces[i] = make([]complex64, EyeTracePoints)
+	}
+
+	return e
+}
+
+// SetEnabled starts or stops keeping the traces. The kept traces are dropped when the tap is enabled.
+func (e *EyeTap) SetEnabled(enabled bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if enabled && !e.enabled {
+		e.position = 0
+		e.count = 0
+		e.pointIndex = 0
+		e.next = 0
+		e.last = 0
+	}
+
+	e.enabled = enabled
+}
+
+// IsEnabled returns if the tap is keeping the traces
+func (e *EyeTap) IsEnabled() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.enabled
+}
+
+// GetTraces returns a copy of the kept traces, the oldest first
+func (e *EyeTap) GetTraces() [][]complex64 {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	var traces = make([][]complex64, e.count)
+	var start = e.position - e.count
+	if start < 0 {
+		start += len(e.traces)
+	}
+
+	for i := range traces {
+		traces[i] = append([]complex64{}, e.traces[(start+i)%len(e.traces)]...)
+	}
+
+	return traces
+}
+
+func (e *EyeTap) Work(input []complex64) []complex64 {
+	var output = make([]complex64, e.PredictOutputSize(len(input)))
+	e.WorkBuffer(input, output)
+	return output
+}
+
+func (e *EyeTap) WorkBuffer(input, output []complex64) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	copy(output, input)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if !e.enabled {
+		return len(input)
+	}
+
+	for _, v := range input {
+		// e.next is the time of the next point relative to the current sample, the previous one being at -1
+		for e.next <= 0 {
+			var mu = float32(e.next + 1)
+			e.traces[e.position][e.pointIndex] = e.last*complex(1-mu, 0) + v*complex(mu, 0)
+			e.pointIndex++
+
+			if e.pointIndex < EyeTracePoints {
+				e.next += e.step
+				continue
+			}
+
+			e.pointIndex = 0
+			e.position = (e.position + 1) % len(e.traces)
+			if e.count < len(e.traces) {
+				e.count++
+			}
+
+			// The next trace starts where this one ends, plus the skipped traces
+			e.next += e.gap
+		}
+
+		e.next--
+		e.last = v
+	}
+
+	return len(input)
+}
+
+func (e *EyeTap) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
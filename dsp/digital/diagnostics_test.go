@@ -0,0 +1,96 @@
+package digital
+
+import (
+	"testing"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+func TestConstellationTap(t *testing.T) {
+	var tap = MakeConstellationTap(4, 2)
+
+	var input = make([]complex64, 20)
+	for i := range input {
+		input[i] = complex(float32(i), -float32(i))
+	}
+
+	// Disabled, nothing is kept
+	var output = tap.Work(input)
+	if len(tap.GetPoints()) != 0 {
+		t.Fatalf("Expected no points from a disabled tap got %d", len(tap.GetPoints()))
+	}
+
+	for i := range input {
+		if output[i] != input[i] {
+			t.Fatalf("Expected the symbols to pass through at %d", i)
+		}
+	}
+
+	tap.SetEnabled(true)
+	tap.Work(input[:3])
+	var points = tap.GetPoints()
+	if len(points) != 2 || points[0] != input[0] || points[1] != input[2] {
+		t.Fatalf("Expected the points 0 and 2 got %v", points)
+	}
+
+	// The decimation continues between the calls, and only the last points are kept
+	tap.Work(input[3:])
+	points = tap.GetPoints()
+	var expected = []complex64{input[12], input[14], input[16], input[18]}
+	for i := range expected {
+		if points[i] != expected[i] {
+			t.Fatalf("Expected %v got %v", expected, points)
+		}
+	}
+
+	// Enabling again drops the kept points
+	tap.SetEnabled(false)
+	tap.SetEnabled(true)
+	if len(tap.GetPoints()) != 0 || !tap.IsEnabled() {
+		t.Fatalf("Expected the points to be dropped")
+	}
+}
+
+func TestEyeTap(t *testing.T) {
+	const samplesPerSymbol = 3.5
+	var span = float32(samplesPerSymbol * EyeTraceSymbols)
+
+	// A ramp makes the interpolated points equal to their time
+	var input = make([]complex64, 200)
+	for i := range input {
+		input[i] = complex(float32(i), 1)
+	}
+
+	for _, decimation := range []int{1, 3} {
+		var tap = MakeEyeTap(samplesPerSymbol, 100, decimation)
+		tap.SetEnabled(true)
+
+		// Split in uneven blocks to check the state between the calls
+		for i := 0; i < len(input); i += 13 {
+			var end = i + 13
+			if end > len(input) {
+				end = len(input)
+			}
+			var output = tap.Work(input[i:end])
+			if len(output) != end-i || output[0] != input[i] {
+				t.Fatalf("Expected the samples to pass through")
+			}
+		}
+
+		var traces = tap.GetTraces()
+		var expectedTraces = int((float32(len(input)-1)-span)/(span*float32(decimation))) + 1
+		if len(traces) != expectedTraces {
+			t.Fatalf("Decimation %d: Expected %d traces got %d", decimation, expectedTraces, len(traces))
+		}
+
+		for i, trace := range traces {
+			var start = float32(i*decimation) * span
+			for j, v := range trace {
+				var expected = start + float32(j)*span/(EyeTracePoints-1)
+				if tools.Abs(real(v)-expected) > 1e-3 || imag(v) != 1 {
+					t.Fatalf("Decimation %d: Expected %f at point %d of trace %d got %v", decimation, expected, j, i, v)
+				}
+			}
+		}
+	}
+}
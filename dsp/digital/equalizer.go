@@ -0,0 +1,237 @@
+package digital
+
+import (
+	"github.com/racerxdl/segdsp/tools"
+)
+
+const equalizerErrorAlpha = 0.01
+
+// EqualizerAlgorithm is the error criteria used to adapt the equalizer taps
+type EqualizerAlgorithm int
+
+const (
+	// EqualizerCMA is the Constant Modulus Algorithm. It does not need the carrier phase or training (blind acquisition).
+	EqualizerCMA EqualizerAlgorithm = iota
+	// EqualizerLMS adapts with the training sequence and then switches to EqualizerDecisionDirected
+	EqualizerLMS
+	// EqualizerDecisionDirected adapts with the error to the nearest constellation point
+	EqualizerDecisionDirected
+)
+
+func (a EqualizerAlgorithm) String() string {
+	switch a {
+	case EqualizerCMA:
+		return "CMA"
+	case EqualizerLMS:
+		return "LMS"
+	case EqualizerDecisionDirected:
+		return "DecisionDirected"
+	}
+
+	return "Unknown"
+}
+
+// LinearEqualizer is a fractionally spaced adaptive FIR equalizer. It takes samplesPerSymbol samples for each symbol
+// and outputs one sample per symbol, so it can run after the clock recovery (samplesPerSymbol = 1) or take the
+// clock recovery output at twice the symbol rate (samplesPerSymbol = 2). The output still has the carrier phase error
+// for CMA, so a Costas Loop should follow it.
+type LinearEqualizer struct {
+	algorithm        EqualizerAlgorithm
+	taps             []complex64
+	samplesPerSymbol int
+	stepSize         float32
+	constellation    Constellation
+	modulus          float32
+	training         []complex64
+	trainingIndex    int
+	sampleHistory    []complex64
+	errorPower       float32
+}
+
+// MakeCMAEqualizer creates a blind equalizer with nTaps, adapting with stepSize to the modulus of the constellation
+func MakeCMAEqualizer(nTaps, samplesPerSymbol int, stepSize float32, constellation Constellation) *LinearEqualizer {
+	return makeLinearEqualizer(EqualizerCMA, nTaps, samplesPerSymbol, stepSize, constellation, nil)
+}
+
+// MakeLMSEqualizer creates a equalizer with nTaps, adapting with stepSize to the training symbols and then to the
+// decisions on the constellation. training[n] is the expected symbol of the output n.
+func MakeLMSEqualizer(nTaps, samplesPerSymbol int, stepSize float32, constellation Constellation, training []complex64) *LinearEqualizer {
+	return makeLinearEqualizer(EqualizerLMS, nTaps, samplesPerSymbol, stepSize, constellation, training)
+}
+
+// MakeDecisionDirectedEqualizer creates a equalizer with nTaps, adapting with stepSize to the decisions on the constellation
+func MakeDecisionDirectedEqualizer(nTaps, samplesPerSymbol int, stepSize float32, constellation Constellation) *LinearEqualizer {
+	return makeLinearEqualizer(EqualizerDecisionDirected, nTaps, samplesPerSymbol, stepSize, constellation, nil)
+}
+
+func makeLinearEqualizer(algorithm EqualizerAlgorithm, nTaps, samplesPerSymbol int, stepSize float32, constellation Constellation, training []complex64) *LinearEqualizer {
+	if nTaps < 1 {
+		panic("Equalizer should have at least one tap")
+	}
+
+	if samplesPerSymbol < 1 {
+		panic("Equalizer samples per symbol should be at least 1")
+	}
+
+	var e = &LinearEqualizer{
+		algorithm:        algorithm,
+		samplesPerSymbol: samplesPerSymbol,
+		stepSize:         stepSize,
+		constellation:    constellation,
+		modulus:          constellationModulus(constellation),
+		sampleHistory:    make([]complex64, nTaps-1),
+	}
+
+	e.Reset(nTaps)
+	e.SetTraining(training)
+
+	return e
+}
+
+// constellationModulus returns the CMA dispersion constant E[|a|^4] / E[|a|^2]
+func constellationModulus(constellation Constellation) float32 {
+	var p2, p4 float32
+
+	for _, p := range constellation {
+		var m = tools.ComplexAbsSquared(p)
+		p2 += m
+		p4 += m * m
+	}
+
+	if p2 == 0 {
+		return 1
+	}
+
+	return p4 / p2
+}
+
+// Reset sets nTaps taps to a single unit center tap (a pass-through filter)
+func (e *LinearEqualizer) Reset(nTaps int) {
+	e.taps = make([]complex64, nTaps)
+	e.taps[nTaps/2] = 1
+	e.errorPower = 0
+
+	if len(e.sampleHistory) != nTaps-1 {
+		e.sampleHistory = make([]complex64, nTaps-1)
+	}
+}
+
+// GetAlgorithm returns the error criteria in use
+func (e *LinearEqualizer) GetAlgorithm() EqualizerAlgorithm {
+	return e.algorithm
+}
+
+// SetAlgorithm changes the error criteria, like switching from CMA to decision directed after the acquisition
+func (e *LinearEqualizer) SetAlgorithm(algorithm EqualizerAlgorithm) {
+	e.algorithm = algorithm
+}
+
+// SetTraining restarts the training with the training symbols. training[0] is the expected symbol of the next output.
+func (e *LinearEqualizer) SetTraining(training []complex64) {
+	e.training = training
+	e.trainingIndex = 0
+}
+
+// IsTraining returns true while the training symbols are being used
+func (e *LinearEqualizer) IsTraining() bool {
+	return e.algorithm == EqualizerLMS && e.trainingIndex < len(e.training)
+}
+
+// GetTaps returns a copy of the current taps. The first tap is applied to the oldest sample.
+func (e *LinearEqualizer) GetTaps() []complex64 {
+	var taps = make([]complex64, len(e.taps))
+	copy(taps, e.taps)
+	return taps
+}
+
+// SetTaps replaces the current taps
+func (e *LinearEqualizer) SetTaps(taps []complex64) {
+	if len(taps) != len(e.taps) {
+		e.Reset(len(taps))
+	}
+	copy(e.taps, taps)
+}
+
+// GetNTaps returns the number of taps
+func (e *LinearEqualizer) GetNTaps() int {
+	return len(e.taps)
+}
+
+// GetStepSize returns the adaptation step size
+func (e *LinearEqualizer) GetStepSize() float32 {
+	return e.stepSize
+}
+
+// SetStepSize changes the adaptation step size
+func (e *LinearEqualizer) SetStepSize(stepSize float32) {
+	e.stepSize = stepSize
+}
+
+// GetSamplesPerSymbol returns the number of input samples for each output symbol
+func (e *LinearEqualizer) GetSamplesPerSymbol() int {
+	return e.samplesPerSymbol
+}
+
+// GetError returns the average squared adaptation error
+func (e *LinearEqualizer) GetError() float32 {
+	return e.errorPower
+}
+
+// equalize filters window (the last len(taps) samples) and adapts the taps
+func (e *LinearEqualizer) equalize(window []complex64) complex64 {
+	var y complex64
+	for k, w := range e.taps {
+		y += w * window[k]
+	}
+
+	var err complex64
+
+	switch {
+	case e.algorithm == EqualizerCMA:
+		err = y * complex(e.modulus-tools.ComplexAbsSquared(y), 0)
+	case e.IsTraining():
+		err = e.training[e.trainingIndex] - y
+		e.trainingIndex++
+	default:
+		err = e.constellation.Nearest(y) - y
+	}
+
+	var mu = complex(e.stepSize, 0) * err
+	for k := range e.taps {
+		e.taps[k] += mu * tools.Conj(window[k])
+	}
+
+	e.errorPower = equalizerErrorAlpha*tools.ComplexAbsSquared(err) + (1-equalizerErrorAlpha)*e.errorPower
+
+	return y
+}
+
+func (e *LinearEqualizer) Work(input []complex64) []complex64 {
+	var output = make([]complex64, e.PredictOutputSize(len(input)))
+	var n = e.WorkBuffer(input, output)
+	return output[:n]
+}
+
+func (e *LinearEqualizer) WorkBuffer(input, output []complex64) int {
+	if len(output) < e.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var nTaps = len(e.taps)
+	var samples = append(e.sampleHistory, input...)
+	var n = 0
+	var i = 0
+
+	for ; i+nTaps <= len(samples); i += e.samplesPerSymbol {
+		output[n] = e.equalize(samples[i : i+nTaps])
+		n++
+	}
+
+	e.sampleHistory = append(e.sampleHistory[:0], samples[i:]...)
+
+	return n
+}
+
+func (e *LinearEqualizer) PredictOutputSize(inputLength int) int {
+	return (len(e.sampleHistory)+inputLength)/e.samplesPerSymbol + 1
+}
@@ -0,0 +1,190 @@
+package digital
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/tools"
+)
+
+var equalizerTestChannel = []complex64{1, complex(0.35, 0.2), complex(-0.15, 0.1)}
+
+func makeTestSymbols(length int, constellation Constellation) ([]int, []complex64) {
+	var r = rand.New(rand.NewSource(1234))
+	var indexes = make([]int, length)
+	var symbols = make([]complex64, length)
+
+	for i := range symbols {
+		indexes[i] = r.Intn(len(constellation))
+		symbols[i] = constellation[indexes[i]]
+	}
+
+	return indexes, symbols
+}
+
+// symbolErrors counts the wrong decisions of output against the symbols delayed by delay, from start
+func symbolErrors(output []complex64, indexes []int, constellation Constellation, delay, start int) int {
+	var errors = 0
+
+	for n := start; n < len(output); n++ {
+		if n-delay < 0 || n-delay >= len(indexes) {
+			continue
+		}
+		if constellation.Decide(output[n]) != indexes[n-delay] {
+			errors++
+		}
+	}
+
+	return errors
+}
+
+func makeMultipathChannel() *dsp.ChannelSimulator {
+	var c = dsp.MakeChannelSimulator(1, 1)
+	c.SetMultipath(equalizerTestChannel, dsp.FadingNone, 0, 0)
+	c.SetSNR(30, 1)
+	return c
+}
+
+func TestLinearEqualizerUnequalized(t *testing.T) {
+	// Make sure the test channel actually breaks 16QAM without equalization
+	var indexes, symbols = makeTestSymbols(4000, QAM16Constellation)
+	var received = makeMultipathChannel().Work(symbols)
+
+	if symbolErrors(received, indexes, QAM16Constellation, 0, 0) == 0 {
+		t.Fatal("Expected symbol errors without equalization")
+	}
+}
+
+func TestLMSEqualizer(t *testing.T) {
+	const nTaps = 11
+	const delay = (nTaps - 1) / 2
+	const trainingLength = 1000
+
+	for name, constellation := range map[string]Constellation{
+		"BPSK":  BPSKConstellation,
+		"QPSK":  QPSKConstellation,
+		"8PSK":  PSK8Constellation,
+		"16QAM": QAM16Constellation,
+	} {
+		var indexes, symbols = makeTestSymbols(8000, constellation)
+		var received = makeMultipathChannel().Work(symbols)
+
+		// Nothing is expected until the first symbol reaches the center tap
+		var training = append(make([]complex64, delay), symbols[:trainingLength]...)
+
+		var eq = MakeLMSEqualizer(nTaps, 1, 0.01, constellation, training)
+		var output = eq.Work(received)
+
+		if eq.IsTraining() {
+			t.Errorf("%s: Expected the training to be over", name)
+		}
+		if eq.GetAlgorithm() != EqualizerLMS {
+			t.Errorf("%s: Expected the algorithm to stay LMS", name)
+		}
+
+		var errors = symbolErrors(output, indexes, constellation, delay, trainingLength)
+		if errors != 0 {
+			t.Errorf("%s: Expected no symbol errors after the training got %d", name, errors)
+		}
+
+		if eq.GetError() > 0.01 {
+			t.Errorf("%s: Expected the error to converge got %f", name, eq.GetError())
+		}
+	}
+}
+
+func TestDecisionDirectedEqualizer(t *testing.T) {
+	const nTaps = 11
+	const delay = (nTaps - 1) / 2
+
+	var indexes, symbols = makeTestSymbols(8000, QPSKConstellation)
+	var received = makeMultipathChannel().Work(symbols)
+
+	var eq = MakeDecisionDirectedEqualizer(nTaps, 1, 0.01, QPSKConstellation)
+
+	// Split in blocks to check the sample history
+	var output []complex64
+	for i := 0; i < len(received); i += 1000 {
+		output = append(output, eq.Work(received[i:i+1000])...)
+	}
+
+	if len(output) != len(received) {
+		t.Fatalf("Expected %d symbols got %d", len(received), len(output))
+	}
+
+	var errors = symbolErrors(output, indexes, QPSKConstellation, delay, 2000)
+	if errors != 0 {
+		t.Errorf("Expected no symbol errors after convergence got %d", errors)
+	}
+}
+
+func TestCMAEqualizer(t *testing.T) {
+	const nTaps = 11
+
+	var _, symbols = makeTestSymbols(20000, QPSKConstellation)
+	var received = makeMultipathChannel().Work(symbols)
+
+	var dispersion = func(data []complex64) float32 {
+		var d float32
+		for _, v := range data {
+			var m = tools.ComplexAbsSquared(v) - 1
+			d += m * m
+		}
+		return d / float32(len(data))
+	}
+
+	var eq = MakeCMAEqualizer(nTaps, 1, 0.002, QPSKConstellation)
+	var output = eq.Work(received)
+
+	var before = dispersion(received[len(received)-2000:])
+	var after = dispersion(output[len(output)-2000:])
+
+	if after > before/10 {
+		t.Errorf("Expected the modulus dispersion to drop at least 10 times, got %f before and %f after", before, after)
+	}
+
+	var taps = eq.GetTaps()
+	if len(taps) != nTaps || eq.GetNTaps() != nTaps {
+		t.Fatalf("Expected %d taps got %d", nTaps, len(taps))
+	}
+
+	// The taps are a copy
+	taps[0] = 100
+	if eq.GetTaps()[0] == 100 {
+		t.Fatal("Expected GetTaps to return a copy")
+	}
+}
+
+func TestFractionallySpacedEqualizer(t *testing.T) {
+	const sps = 2
+	const nTaps = 17
+	const rrcTaps = 8*sps + 1
+	const trainingLength = 2000
+
+	var indexes, symbols = makeTestSymbols(10000, QPSKConstellation)
+
+	// Pulse shaped at 2 samples per symbol. The equalizer learns the matched filter and the channel.
+	var signal = make([]complex64, len(symbols)*sps)
+	for i, s := range symbols {
+		signal[i*sps] = s
+	}
+	signal = dsp.MakeFirFilter(dsp.MakeRRC(1, sps, 1, 0.35, rrcTaps)).FilterOut(signal)
+	var received = makeMultipathChannel().Work(signal)
+
+	// Half of the pulse shaping and equalizer spans, the training moves the symbol peak to it
+	var delay = (nTaps + rrcTaps) / (2 * sps)
+	var training = append(make([]complex64, delay), symbols[:trainingLength]...)
+
+	var eq = MakeLMSEqualizer(nTaps, sps, 0.005, QPSKConstellation, training)
+	var output = eq.Work(received)
+
+	if len(output) != len(symbols) {
+		t.Fatalf("Expected %d symbols got %d", len(symbols), len(output))
+	}
+
+	var errors = symbolErrors(output, indexes, QPSKConstellation, delay, trainingLength)
+	if errors != 0 {
+		t.Errorf("Expected no symbol errors after the training got %d", errors)
+	}
+}
@@ -0,0 +1,163 @@
+package fec
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/dsp/digital/framing"
+)
+
+// bpskChannel sends the coded bits as BPSK through the channel simulator, at ebN0 dB for the information bits, and
+// returns the soft bits (the real part of the received symbols)
+func bpskChannel(coded []byte, rate float32, ebN0 float32, seed int64) []float32 {
+	var c = dsp.MakeChannelSimulator(1, seed)
+	c.SetSNR(ebN0+10*float32(math.Log10(float64(rate))), 1)
+
+	var received = c.Work(dsp.MakePSKModulator(1, 1, nil).Work(coded))
+
+	var soft = make([]float32, len(received))
+	for i, v := range received {
+		soft[i] = real(v)
+	}
+
+	return soft
+}
+
+func hardDecisions(soft []float32) []byte {
+	var bits = make([]byte, len(soft))
+	for i, v := range soft {
+		if v < 0 {
+			bits[i] = 1
+		}
+	}
+	return bits
+}
+
+// viterbiBER returns the bit error rate of the code at ebN0 dB
+func viterbiBER(code ConvolutionalCode, length int, ebN0 float32, seed int64) float64 {
+	// K - 1 zeros at the end take the encoder back to the state 0
+	var bits = append(makeTestBits(length, seed), make([]byte, code.K-1)...)
+	var soft = bpskChannel(MakeConvolutionalEncoder(code).Work(bits), code.GetRate(), ebN0, seed)
+
+	var decoder = MakeViterbiDecoder(code, 7*code.K)
+	var decoded = append(decoder.Work(soft), decoder.Flush()...)
+
+	return float64(bitErrors(decoded[:length], bits[:length])) / float64(length)
+}
+
+func TestViterbiBER(t *testing.T) {
+	const length = 50000
+
+	var testCases = []struct {
+		name   string
+		code   ConvolutionalCode
+		ebN0   float32
+		maxBER float64
+	}{
+		// Uncoded BPSK has a BER of 1.3e-2 at 4 dB and 2.4e-3 at 6 dB
+		{"CCSDS rate 1/2", CCSDSConvolutionalCode, 4, 2e-4},
+		{"CCSDS rate 3/4", ccsdsRate34, 6, 2e-4},
+	}
+
+	for _, tc := range testCases {
+		var ber = viterbiBER(tc.code, length, tc.ebN0, 1)
+		if ber > tc.maxBER {
+			t.Errorf("%s: Expected BER below %e at %f dB got %e", tc.name, tc.maxBER, tc.ebN0, ber)
+		}
+	}
+}
+
+func TestConcatenatedBER(t *testing.T) {
+	const interleave = 4
+	const blocks = 10
+
+	// CCSDS concatenated code: RS(255, 223) with interleave 4 and the K = 7 rate 1/2 convolutional code.
+	// At 2.5 dB the Viterbi decoder alone leaves a BER of a few 1e-3 in bursts, the Reed-Solomon code fixes them.
+	const ebN0 = 2.5
+	var rate = float32(223) / 255 * CCSDSConvolutionalCode.GetRate()
+
+	var encoder = MakeReedSolomonEncoder(CCSDSReedSolomonCode, interleave)
+	var data = makeTestBytes(223*interleave*blocks, 5)
+
+	var coded []byte
+	for i := 0; i < blocks; i++ {
+		coded = append(coded, encoder.Work(data[i*223*interleave:(i+1)*223*interleave])...)
+	}
+
+	var bits = append(framing.UnpackBits(coded, false), make([]byte, 6)...)
+	var soft = bpskChannel(MakeConvolutionalEncoder(CCSDSConvolutionalCode).Work(bits), rate, ebN0, 2)
+
+	var viterbi = MakeViterbiDecoder(CCSDSConvolutionalCode, 42)
+	var received = framing.PackBits(append(viterbi.Work(soft), viterbi.Flush()...), false)
+
+	var viterbiErrors = bitErrors(framing.UnpackBits(received[:len(coded)], false), bits[:len(coded)*8])
+	if viterbiErrors == 0 {
+		t.Fatal("Expected errors after the Viterbi decoder, the test is not testing the Reed-Solomon code")
+	}
+
+	var decoder = MakeReedSolomonDecoder(CCSDSReedSolomonCode, interleave)
+	var blockSize = 255 * interleave
+
+	var decoded []byte
+	for i := 0; i < blocks; i++ {
+		decoded = append(decoded, decoder.Work(received[i*blockSize:(i+1)*blockSize])...)
+	}
+
+	if decoder.GetUncorrectable() != 0 {
+		t.Fatalf("Expected all codewords to be corrected, %d were not", decoder.GetUncorrectable())
+	}
+
+	if errors := bitErrors(framing.UnpackBits(decoded, false), framing.UnpackBits(data, false)); errors != 0 {
+		t.Fatalf("Expected no errors after the Reed-Solomon decoder got %d (%d after Viterbi)", errors, viterbiErrors)
+	}
+}
+
+func BenchmarkViterbiBER(b *testing.B) {
+	const length = 100000
+
+	for _, ebN0 := range []float32{1, 2, 3, 4, 5} {
+		b.Run(fmt.Sprintf("EbN0=%.0fdB", ebN0), func(b *testing.B) {
+			var ber float64
+			for i := 0; i < b.N; i++ {
+				ber = viterbiBER(CCSDSConvolutionalCode, length, ebN0, int64(i))
+			}
+			b.Logf("CCSDS K=7 rate 1/2 at %.0f dB: BER %e", ebN0, ber)
+		})
+	}
+}
+
+func BenchmarkReedSolomonBER(b *testing.B) {
+	const blocks = 20
+
+	var encoder = MakeReedSolomonEncoder(CCSDSReedSolomonCode, 1)
+	var decoder = MakeReedSolomonDecoder(CCSDSReedSolomonCode, 1)
+	var data = makeTestBytes(223*blocks, 6)
+
+	var coded []byte
+	for i := 0; i < blocks; i++ {
+		coded = append(coded, encoder.Work(data[i*223:(i+1)*223])...)
+	}
+
+	var bits = framing.UnpackBits(coded, false)
+
+	// Hard decisions of uncoded BPSK
+	for _, ebN0 := range []float32{5, 6, 7} {
+		b.Run(fmt.Sprintf("EbN0=%.0fdB", ebN0), func(b *testing.B) {
+			var inputErrors, outputErrors int
+			for i := 0; i < b.N; i++ {
+				var received = framing.PackBits(hardDecisions(bpskChannel(bits, 223.0/255, ebN0, int64(i))), false)
+				inputErrors = bitErrors(framing.UnpackBits(received, false), bits)
+
+				var decoded []byte
+				for j := 0; j < blocks; j++ {
+					decoded = append(decoded, decoder.Work(received[j*255:(j+1)*255])...)
+				}
+				outputErrors = bitErrors(framing.UnpackBits(decoded, false), framing.UnpackBits(data, false))
+			}
+			b.Logf("CCSDS RS(255, 223) at %.0f dB: BER %e before and %e after decoding", ebN0,
+				float64(inputErrors)/float64(len(bits)), float64(outputErrors)/float64(len(data)*8))
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package fec
+
+import (
+	"math/bits"
+)
+
+// ConvolutionalCode describes a rate 1/n convolutional code, optionally punctured to a higher rate
+type ConvolutionalCode struct {
+	// K is the constraint length (from 2 to 16)
+	K int
+	// Polynomials has the generator polynomials, one per output bit, in the usual octal form: the most significant
+	// bit (K - 1) is the tap of the current input bit, like 0171 and 0133 for the K = 7 NASA code
+	Polynomials []uint32
+	// Inverted has the outputs that are inverted (optional), like the second output of the CCSDS code
+	Inverted []bool
+	// Puncturing is the puncturing pattern (optional) over a whole number of input bits, in the order the coded bits
+	// come out of the encoder: 1 for the bits that are sent and 0 for the removed ones. For example {1, 1, 0, 1}
+	// makes the rate 1/2 code a rate 2/3 code.
+	Puncturing []byte
+}
+
+// CCSDSConvolutionalCode is the CCSDS (and NASA / Voyager) K = 7 rate 1/2 code, with the second output inverted
+var CCSDSConvolutionalCode = ConvolutionalCode{
+	K:           7,
+	Polynomials: []uint32{0171, 0133},
+	Inverted:    []bool{false, true},
+}
+
+// check panics if the code is not valid
+func (c ConvolutionalCode) check() {
+	if c.K < 2 || c.K > 16 {
+		panic("Convolutional code constraint length should be between 2 and 16")
+	}
+
+	if len(c.Polynomials) < 2 || len(c.Polynomials) > 8 {
+		panic("Convolutional code needs from two to eight polynomials")
+	}
+
+	if len(c.Inverted) != 0 && len(c.Inverted) != len(c.Polynomials) {
+		panic("Convolutional code needs one inversion flag per polynomial")
+	}
+
+	if len(c.Puncturing) != 0 {
+		if len(c.Puncturing)%len(c.Polynomials) != 0 {
+			panic("Convolutional code puncturing pattern should cover a whole number of input bits")
+		}
+
+		var sent = 0
+		for _, v := range c.Puncturing {
+			sent += int(v & 1)
+		}
+
+		if sent == 0 {
+			panic("Convolutional code puncturing pattern should send at least one bit")
+		}
+	}
+}
+
+// outputs returns the coded bits (one per output, inversion included) of each encoder register value
+func (c ConvolutionalCode) outputs() [][]byte {
+	var outputs = make([][]byte, 1<<uint(c.K))
+
+	for reg := range outputs {
+		outputs[reg] = make([]byte, len(c.Polynomials))
+		for i, poly := range c.Polynomials {
+			var bit = byte(bits.OnesCount32(uint32(reg)&poly) & 1)
+			if len(c.Inverted) != 0 && c.Inverted[i] {
+				bit ^= 1
+			}
+			outputs[reg][i] = bit
+		}
+	}
+
+	return outputs
+}
+
+// puncturing returns the puncturing pattern, or a pattern that sends everything
+func (c ConvolutionalCode) puncturing() []byte {
+	if len(c.Puncturing) != 0 {
+		return c.Puncturing
+	}
+
+	var pattern = make([]byte, len(c.Polynomials))
+	for i := range pattern {
+		pattern[i] = 1
+	}
+
+	return pattern
+}
+
+// GetRate returns the code rate (input bits per coded bit), including the puncturing
+func (c ConvolutionalCode) GetRate() float32 {
+	var pattern = c.puncturing()
+	var sent = 0
+	for _, v := range pattern {
+		sent += int(v & 1)
+	}
+
+	var inputBits = len(pattern) / len(c.Polynomials)
+
+	return float32(inputBits) / float32(sent)
+}
+
+// ConvolutionalEncoder encodes bits (one per byte) with a convolutional code
+type ConvolutionalEncoder struct {
+	code         ConvolutionalCode
+	outputs      [][]byte
+	puncturing   []byte
+	punctureStep int
+	register     uint32
+}
+
+// MakeConvolutionalEncoder creates a convolutional encoder, with the register cleared
+func MakeConvolutionalEncoder(code ConvolutionalCode) *ConvolutionalEncoder {
+	code.check()
+
+	return &ConvolutionalEncoder{
+		code:       code,
+		outputs:    code.outputs(),
+		puncturing: code.puncturing(),
+	}
+}
+
+// GetCode returns the convolutional code
+func (e *ConvolutionalEncoder) GetCode() ConvolutionalCode {
+	return e.code
+}
+
+// Reset clears the register and restarts the puncturing pattern
+func (e *ConvolutionalEncoder) Reset() {
+	e.register = 0
+	e.punctureStep = 0
+}
+
+func (e *ConvolutionalEncoder) Work(input []byte) []byte {
+	var output = make([]byte, e.PredictOutputSize(len(input)))
+	l := e.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (e *ConvolutionalEncoder) WorkBuffer(input, output []byte) int {
+	if len(output) < e.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var k = uint(e.code.K)
+	var outputIndex = 0
+
+	for _, v := range input {
+		// The newest bit is the most significant one
+		e.register = (e.register >> 1) | (uint32(v&1) << (k - 1))
+
+		for _, bit := range e.outputs[e.register] {
+			if e.puncturing[e.punctureStep] != 0 {
+				output[outputIndex] = bit
+				outputIndex++
+			}
+			e.punctureStep = (e.punctureStep + 1) % len(e.puncturing)
+		}
+	}
+
+	return outputIndex
+}
+
+func (e *ConvolutionalEncoder) PredictOutputSize(inputLength int) int {
+	return inputLength * len(e.code.Polynomials)
+}
@@ -0,0 +1,159 @@
+package fec
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// ccsdsRate34 punctures the CCSDS code to rate 3/4 (C1: 1 0 1, C2: 1 1 0)
+var ccsdsRate34 = ConvolutionalCode{
+	K:           7,
+	Polynomials: []uint32{0171, 0133},
+	Inverted:    []bool{false, true},
+	Puncturing:  []byte{1, 1, 0, 1, 1, 0},
+}
+
+func makeTestBits(length int, seed int64) []byte {
+	var r = rand.New(rand.NewSource(seed))
+	var data = make([]byte, length)
+	for i := range data {
+		data[i] = byte(r.Intn(2))
+	}
+	return data
+}
+
+// toSoft maps the coded bits to ideal soft bits (+1 for 0 and -1 for 1)
+func toSoft(bits []byte) []float32 {
+	var soft = make([]float32, len(bits))
+	for i, v := range bits {
+		soft[i] = 1 - 2*float32(v)
+	}
+	return soft
+}
+
+func bitErrors(a, b []byte) int {
+	var errors = 0
+	for i := range a {
+		if a[i] != b[i] {
+			errors++
+		}
+	}
+	return errors
+}
+
+func TestConvolutionalEncoder(t *testing.T) {
+	// The impulse response is the polynomials taps, from the current input bit
+	var impulse = make([]byte, 7)
+	impulse[0] = 1
+
+	var output = MakeConvolutionalEncoder(CCSDSConvolutionalCode).Work(impulse)
+	var g1 = []byte{1, 1, 1, 1, 0, 0, 1}
+	var g2 = []byte{1, 0, 1, 1, 0, 1, 1}
+
+	for i := range impulse {
+		if output[2*i] != g1[i] || output[2*i+1] != g2[i]^1 {
+			t.Fatalf("Expected %v and inverted %v got %v", g1, g2, output)
+		}
+	}
+
+	if CCSDSConvolutionalCode.GetRate() != 0.5 || ccsdsRate34.GetRate() != 0.75 {
+		t.Fatalf("Expected rates 1/2 and 3/4 got %f and %f", CCSDSConvolutionalCode.GetRate(), ccsdsRate34.GetRate())
+	}
+
+	var punctured = MakeConvolutionalEncoder(ccsdsRate34).Work(make([]byte, 300))
+	if len(punctured) != 400 {
+		t.Fatalf("Expected 400 coded bits got %d", len(punctured))
+	}
+}
+
+func TestConvolutionalEncoderASM(t *testing.T) {
+	// The CCSDS attached sync marker 0x1ACFFC1D encodes from the cleared register to 0x035D49C24FF2686B with the
+	// NASA code (0xFCA2B63DB00D9794 inverted, as the Meteor LRPT decoders look for it). The CCSDS code inverts
+	// every second bit of it.
+	var testCases = []struct {
+		name     string
+		inverted []bool
+		expected uint64
+	}{
+		{"NASA", nil, 0x035D49C24FF2686B},
+		{"CCSDS", []bool{false, true}, 0x035D49C24FF2686B ^ 0x5555555555555555},
+	}
+
+	var marker uint32 = 0x1ACFFC1D
+	var asm = make([]byte, 32)
+	for i := range asm {
+		asm[i] = byte(marker>>uint(31-i)) & 1
+	}
+
+	for _, tc := range testCases {
+		var code = ConvolutionalCode{K: 7, Polynomials: []uint32{0171, 0133}, Inverted: tc.inverted}
+		var output = MakeConvolutionalEncoder(code).Work(asm)
+
+		var encoded uint64
+		for _, v := range output {
+			encoded = encoded<<1 | uint64(v)
+		}
+
+		if encoded != tc.expected {
+			t.Errorf("%s: Expected the encoded sync marker %016X got %016X", tc.name, tc.expected, encoded)
+		}
+	}
+}
+
+func TestViterbiDecoder(t *testing.T) {
+	var testCases = []struct {
+		name string
+		code ConvolutionalCode
+	}{
+		{"K=3 rate 1/2", ConvolutionalCode{K: 3, Polynomials: []uint32{07, 05}}},
+		{"K=7 rate 1/3", ConvolutionalCode{K: 7, Polynomials: []uint32{0133, 0171, 0165}}},
+		{"CCSDS rate 1/2", CCSDSConvolutionalCode},
+		{"CCSDS rate 3/4", ccsdsRate34},
+	}
+
+	for _, tc := range testCases {
+		var bits = makeTestBits(3000, 1)
+		var coded = MakeConvolutionalEncoder(tc.code).Work(bits)
+		var soft = toSoft(coded)
+
+		var decoder = MakeViterbiDecoder(tc.code, 8*tc.code.K)
+
+		// Split in uneven blocks to check the state between the calls
+		var decoded []byte
+		for i := 0; i < len(soft); i += 97 {
+			var end = i + 97
+			if end > len(soft) {
+				end = len(soft)
+			}
+			decoded = append(decoded, decoder.Work(soft[i:end])...)
+		}
+		decoded = append(decoded, decoder.Flush()...)
+
+		if len(decoded) != len(bits) {
+			t.Errorf("%s: Expected %d bits got %d", tc.name, len(bits), len(decoded))
+			continue
+		}
+
+		if errors := bitErrors(decoded, bits); errors != 0 {
+			t.Errorf("%s: Expected no errors got %d", tc.name, errors)
+		}
+	}
+}
+
+func TestViterbiDecoderCorrection(t *testing.T) {
+	var bits = makeTestBits(2000, 2)
+	var soft = toSoft(MakeConvolutionalEncoder(CCSDSConvolutionalCode).Work(bits))
+
+	// Wrong bits spread apart, and weak (low confidence) wrong bits
+	for i := 50; i < len(soft); i += 40 {
+		soft[i] = -soft[i]
+		soft[i+11] *= -0.3
+	}
+
+	var decoder = MakeViterbiDecoder(CCSDSConvolutionalCode, 42)
+	var decoded = append(decoder.Work(soft), decoder.Flush()...)
+
+	if errors := bitErrors(decoded, bits); errors != 0 {
+		t.Fatalf("Expected the decoder to correct all errors got %d", errors)
+	}
+}
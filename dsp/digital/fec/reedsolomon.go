@@ -0,0 +1,556 @@
+package fec
+
+// rsSymbols is the number of non zero symbols of GF(2^8), and the maximum codeword length
+const rsSymbols = 255
+
+// ReedSolomonCode describes a Reed-Solomon code over GF(2^8)
+type ReedSolomonCode struct {
+	// FieldPolynomial is the primitive polynomial of the field, with the x^8 term, like 0x11D or 0x187 (CCSDS)
+	FieldPolynomial uint
+	// FirstRoot is the first consecutive root of the generator polynomial, in index form
+	FirstRoot int
+	// Primitive is the primitive element used to make the roots, in index form
+	Primitive int
+	// Roots is the number of parity symbols. The code corrects up to Roots / 2 symbol errors.
+	Roots int
+	// DualBasis converts the symbols from and to the Berlekamp dual basis representation, as CCSDS does
+	DualBasis bool
+}
+
+// CCSDSReedSolomonCode is the CCSDS RS(255, 223) code, with dual basis symbols
+var CCSDSReedSolomonCode = ReedSolomonCode{
+	FieldPolynomial: 0x187,
+	FirstRoot:       112,
+	Primitive:       11,
+	Roots:           32,
+	DualBasis:       true,
+}
+
+// ccsdsDualBasisMatrix converts from the conventional to the dual basis representation
+var ccsdsDualBasisMatrix = [8]byte{0x8d, 0xef, 0xec, 0x86, 0xfa, 0x99, 0xaf, 0x7b}
+
+// reedSolomon has the field tables and the generator polynomial of a code
+type reedSolomon struct {
+	code ReedSolomonCode
+
+	alphaTo   [rsSymbols + 1]byte
+	indexOf   [rsSymbols + 1]int
+	generator []int
+	iPrim     int
+
+	toDual   [256]byte
+	fromDual [256]byte
+}
+
+func modnn(x int) int {
+	for x >= rsSymbols {
+		x -= rsSymbols
+		x = (x >> 8) + (x & rsSymbols)
+	}
+	return x
+}
+
+func makeReedSolomon(code ReedSolomonCode) *reedSolomon {
+	if code.FieldPolynomial < 0x100 || code.FieldPolynomial > 0x1FF {
+		panic("Reed-Solomon field polynomial should have the x^8 term")
+	}
+
+	if code.FirstRoot < 0 || code.FirstRoot >= rsSymbols {
+		panic("Reed-Solomon first root should be between 0 and 254")
+	}
+
+	if code.Primitive < 1 || code.Primitive >= rsSymbols {
+		panic("Reed-Solomon primitive element should be between 1 and 254")
+	}
+
+	if code.Roots < 1 || code.Roots >= rsSymbols {
+		panic("Reed-Solomon number of roots should be between 1 and 254")
+	}
+
+	var rs = &reedSolomon{
+		code:      code,
+		generator: make([]int, code.Roots+1),
+	}
+
+	// Field tables. The zero is at index rsSymbols (the log of 0).
+	rs.indexOf[0] = rsSymbols
+	rs.alphaTo[rsSymbols] = 0
+
+	var sr uint = 1
+	for i := 0; i < rsSymbols; i++ {
+		rs.indexOf[sr] = i
+		rs.alphaTo[i] = byte(sr)
+		sr <<= 1
+		if sr&0x100 != 0 {
+			sr ^= code.FieldPolynomial
+		}
+		sr &= rsSymbols
+	}
+
+	if sr != 1 {
+		panic("Reed-Solomon field polynomial is not primitive")
+	}
+
+	// The primitive-th root of 1, used to find the error locations
+	rs.iPrim = 1
+	for rs.iPrim%code.Primitive != 0 {
+		rs.iPrim += rsSymbols
+	}
+	rs.iPrim /= code.Primitive
+
+	// Generator polynomial, the product of (x - alpha^(prim * (FirstRoot + i)))
+	var generator = rs.generator
+	generator[0] = 1
+	for i, root := 0, code.FirstRoot*code.Primitive; i < code.Roots; i, root = i+1, root+code.Primitive {
+		generator[i+1] = 1
+		for j := i; j > 0; j-- {
+			if generator[j] != 0 {
+				generator[j] = generator[j-1] ^ int(rs.alphaTo[modnn(rs.indexOf[generator[j]]+root)])
+			} else {
+				generator[j] = generator[j-1]
+			}
+		}
+		generator[0] = int(rs.alphaTo[modnn(rs.indexOf[generator[0]]+root)])
+	}
+
+	// Index form is faster for the encoder
+	for i := range generator {
+		generator[i] = rs.indexOf[generator[i]]
+	}
+
+	for i := 0; i < 256; i++ {
+		var v byte
+		for j := uint(0); j < 8; j++ {
+			if i&(1<<j) != 0 {
+				v ^= ccsdsDualBasisMatrix[7-j]
+			}
+		}
+		rs.toDual[i] = v
+		rs.fromDual[v] = byte(i)
+	}
+
+	return rs
+}
+
+// encode writes the parity of data (up to 255 - Roots symbols, shorter codes are shortened) to parity
+func (rs *reedSolomon) encode(data, parity []byte) {
+	var nroots = rs.code.Roots
+
+	for i := range parity {
+		parity[i] = 0
+	}
+
+	for _, v := range data {
+		var feedback = rs.indexOf[v^parity[0]]
+
+		if feedback != rsSymbols {
+			for j := 1; j < nroots; j++ {
+				parity[j] ^= rs.alphaTo[modnn(feedback+rs.generator[nroots-j])]
+			}
+		}
+
+		copy(parity, parity[1:])
+
+		if feedback != rsSymbols {
+			parity[nroots-1] = rs.alphaTo[modnn(feedback+rs.generator[0])]
+		} else {
+			parity[nroots-1] = 0
+		}
+	}
+}
+
+// decode corrects the codeword (data followed by parity, shortened codes are shortened) in place.
+// It returns the number of corrected symbols, or -1 if the codeword can't be corrected.
+func (rs *reedSolomon) decode(codeword []byte) int {
+	var nroots = rs.code.Roots
+	var fcr = rs.code.FirstRoot
+	var prim = rs.code.Primitive
+	var pad = rsSymbols - len(codeword)
+
+	// Syndromes
+	var s = make([]int, nroots)
+	for i := range s {
+		s[i] = int(codeword[0])
+	}
+
+	for _, v := range codeword[1:] {
+		for i := range s {
+			if s[i] == 0 {
+				s[i] = int(v)
+			} else {
+				s[i] = int(v) ^ int(rs.alphaTo[modnn(rs.indexOf[s[i]]+(fcr+i)*prim)])
+			}
+		}
+	}
+
+	var synError = 0
+	for i := range s {
+		synError |= s[i]
+		s[i] = rs.indexOf[s[i]]
+	}
+
+	if synError == 0 {
+		return 0
+	}
+
+	// Berlekamp-Massey for the error locator polynomial lambda
+	var lambda = make([]int, nroots+1)
+	var b = make([]int, nroots+1)
+	var t = make([]int, nroots+1)
+
+	lambda[0] = 1
+	for i := range b {
+		b[i] = rs.indexOf[lambda[i]]
+	}
+
+	var el = 0
+	for r := 1; r <= nroots; r++ {
+		var discrepancy = 0
+		for i := 0; i < r; i++ {
+			if lambda[i] != 0 && s[r-i-1] != rsSymbols {
+				discrepancy ^= int(rs.alphaTo[modnn(rs.indexOf[lambda[i]]+s[r-i-1])])
+			}
+		}
+		discrepancy = rs.indexOf[discrepancy]
+
+		if discrepancy == rsSymbols {
+			copy(b[1:], b[:nroots])
+			b[0] = rsSymbols
+			continue
+		}
+
+		t[0] = lambda[0]
+		for i := 0; i < nroots; i++ {
+			if b[i] != rsSymbols {
+				t[i+1] = lambda[i+1] ^ int(rs.alphaTo[modnn(discrepancy+b[i])])
+			} else {
+				t[i+1] = lambda[i+1]
+			}
+		}
+
+		if 2*el <= r-1 {
+			el = r - el
+			for i := range b {
+				if lambda[i] == 0 {
+					b[i] = rsSymbols
+				} else {
+					b[i] = modnn(rs.indexOf[lambda[i]] - discrepancy + rsSymbols)
+				}
+			}
+		} else {
+			copy(b[1:], b[:nroots])
+			b[0] = rsSymbols
+		}
+
+		copy(lambda, t)
+	}
+
+	var degLambda = 0
+	for i := range lambda {
+		lambda[i] = rs.indexOf[lambda[i]]
+		if lambda[i] != rsSymbols {
+			degLambda = i
+		}
+	}
+
+	if degLambda == 0 {
+		return -1
+	}
+
+	// Chien search for the roots of lambda (the error locations)
+	var reg = make([]int, nroots+1)
+	copy(reg[1:], lambda[1:])
+
+	var roots = make([]int, 0, degLambda)
+	var locations = make([]int, 0, degLambda)
+
+	for i, k := 1, rs.iPrim-1; i <= rsSymbols; i, k = i+1, modnn(k+rs.iPrim) {
+		var q = 1
+		for j := degLambda; j > 0; j-- {
+			if reg[j] != rsSymbols {
+				reg[j] = modnn(reg[j] + j)
+				q ^= int(rs.alphaTo[reg[j]])
+			}
+		}
+
+		if q != 0 {
+			continue
+		}
+
+		roots = append(roots, i)
+		locations = append(locations, k)
+
+		if len(roots) == degLambda {
+			break
+		}
+	}
+
+	if len(roots) != degLambda {
+		return -1
+	}
+
+	// Error evaluator polynomial omega = s * lambda mod x^nroots
+	var degOmega = degLambda - 1
+	var omega = make([]int, degOmega+1)
+	for i := 0; i <= degOmega; i++ {
+		var tmp = 0
+		for j := i; j >= 0; j-- {
+			if s[i-j] != rsSymbols && lambda[j] != rsSymbols {
+				tmp ^= int(rs.alphaTo[modnn(s[i-j]+lambda[j])])
+			}
+		}
+		omega[i] = rs.indexOf[tmp]
+	}
+
+	// Forney algorithm for the error values
+	for j := len(roots) - 1; j >= 0; j-- {
+		// The error is in the padding of a shortened code, so the decoding is wrong
+		if locations[j] < pad {
+			return -1
+		}
+	}
+
+	for j := len(roots) - 1; j >= 0; j-- {
+		var num1 = 0
+		for i := degOmega; i >= 0; i-- {
+			if omega[i] != rsSymbols {
+				num1 ^= int(rs.alphaTo[modnn(omega[i]+i*roots[j])])
+			}
+		}
+
+		var num2 = int(rs.alphaTo[modnn(roots[j]*(fcr-1)+rsSymbols)])
+
+		var den = 0
+		// lambda[i + 1] for i even is the formal derivative of lambda
+		var start = degLambda
+		if start > nroots-1 {
+			start = nroots - 1
+		}
+		for i := start &^ 1; i >= 0; i -= 2 {
+			if lambda[i+1] != rsSymbols {
+				den ^= int(rs.alphaTo[modnn(lambda[i+1]+i*roots[j])])
+			}
+		}
+
+		if num1 != 0 && den != 0 {
+			codeword[locations[j]-pad] ^= rs.alphaTo[modnn(rs.indexOf[num1]+rs.indexOf[num2]+rsSymbols-rs.indexOf[den])]
+		}
+	}
+
+	return len(roots)
+}
+
+// codewordLength checks the block length and returns the length of each codeword in it, extra is the parity added
+func (rs *reedSolomon) codewordLength(blockLength, interleave, extra int) int {
+	if blockLength%interleave != 0 {
+		panic("Reed-Solomon block length should be a multiple of the interleave depth")
+	}
+
+	var length = blockLength / interleave
+	if length+extra > rsSymbols || length <= rs.code.Roots-extra {
+		panic("Reed-Solomon block is too long or too short for the code")
+	}
+
+	return length
+}
+
+// region Encoder
+
+// ReedSolomonEncoder encodes blocks of packed bytes with a Reed-Solomon code. Each call (Work / WorkBuffer) takes one
+// block of interleave codewords data, and outputs the codewords with the parity. The codewords are interleaved
+// symbol by symbol (the symbol j of the codeword i is at j * interleave + i), like the CCSDS codeblocks.
+// Blocks shorter than interleave * (255 - Roots) make shortened codewords.
+type ReedSolomonEncoder struct {
+	rs         *reedSolomon
+	interleave int
+	data       []byte
+	parity     []byte
+}
+
+// MakeReedSolomonEncoder creates a Reed-Solomon encoder with interleave codewords per block (1 for no interleaving)
+func MakeReedSolomonEncoder(code ReedSolomonCode, interleave int) *ReedSolomonEncoder {
+	if interleave < 1 {
+		panic("Reed-Solomon interleave depth should be at least 1")
+	}
+
+	return &ReedSolomonEncoder{
+		rs:         makeReedSolomon(code),
+		interleave: interleave,
+		data:       make([]byte, rsSymbols),
+		parity:     make([]byte, code.Roots),
+	}
+}
+
+// GetCode returns the Reed-Solomon code
+func (e *ReedSolomonEncoder) GetCode() ReedSolomonCode {
+	return e.rs.code
+}
+
+// GetInterleave returns the interleave depth
+func (e *ReedSolomonEncoder) GetInterleave() int {
+	return e.interleave
+}
+
+func (e *ReedSolomonEncoder) Work(input []byte) []byte {
+	var output = make([]byte, e.PredictOutputSize(len(input)))
+	l := e.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (e *ReedSolomonEncoder) WorkBuffer(input, output []byte) int {
+	if len(output) < e.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var length = e.rs.codewordLength(len(input), e.interleave, e.rs.code.Roots)
+	var data = e.data[:length]
+
+	copy(output, input)
+
+	for i := 0; i < e.interleave; i++ {
+		for j := range data {
+			data[j] = input[j*e.interleave+i]
+			if e.rs.code.DualBasis {
+				data[j] = e.rs.fromDual[data[j]]
+			}
+		}
+
+		e.rs.encode(data, e.parity)
+
+		for j, v := range e.parity {
+			if e.rs.code.DualBasis {
+				v = e.rs.toDual[v]
+			}
+			output[(length+j)*e.interleave+i] = v
+		}
+	}
+
+	return e.PredictOutputSize(len(input))
+}
+
+func (e *ReedSolomonEncoder) PredictOutputSize(inputLength int) int {
+	return inputLength + e.rs.code.Roots*e.interleave
+}
+
+// endregion
+// region Decoder
+
+// ReedSolomonDecoder corrects blocks of packed bytes with a Reed-Solomon code. Each call (Work / WorkBuffer) takes
+// one block of interleave codewords (interleaved like the ReedSolomonEncoder does), and outputs the corrected data
+// without the parity. The data of the codewords that can't be corrected is output as received.
+type ReedSolomonDecoder struct {
+	rs         *reedSolomon
+	interleave int
+	codeword   []byte
+
+	lastCorrected     int
+	lastUncorrectable int
+	corrected         int
+	uncorrectable     int
+}
+
+// MakeReedSolomonDecoder creates a Reed-Solomon decoder with interleave codewords per block (1 for no interleaving)
+func MakeReedSolomonDecoder(code ReedSolomonCode, interleave int) *ReedSolomonDecoder {
+	if interleave < 1 {
+		panic("Reed-Solomon interleave depth should be at least 1")
+	}
+
+	return &ReedSolomonDecoder{
+		rs:         makeReedSolomon(code),
+		interleave: interleave,
+		codeword:   make([]byte, rsSymbols),
+	}
+}
+
+// GetCode returns the Reed-Solomon code
+func (d *ReedSolomonDecoder) GetCode() ReedSolomonCode {
+	return d.rs.code
+}
+
+// GetInterleave returns the interleave depth
+func (d *ReedSolomonDecoder) GetInterleave() int {
+	return d.interleave
+}
+
+// GetLastCorrected returns the number of symbols corrected in the last block
+func (d *ReedSolomonDecoder) GetLastCorrected() int {
+	return d.lastCorrected
+}
+
+// GetLastUncorrectable returns the number of codewords of the last block that could not be corrected
+func (d *ReedSolomonDecoder) GetLastUncorrectable() int {
+	return d.lastUncorrectable
+}
+
+// GetCorrected returns the number of symbols corrected since the creation
+func (d *ReedSolomonDecoder) GetCorrected() int {
+	return d.corrected
+}
+
+// GetUncorrectable returns the number of codewords that could not be corrected since the creation
+func (d *ReedSolomonDecoder) GetUncorrectable() int {
+	return d.uncorrectable
+}
+
+func (d *ReedSolomonDecoder) Work(input []byte) []byte {
+	var output = make([]byte, d.PredictOutputSize(len(input)))
+	l := d.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (d *ReedSolomonDecoder) WorkBuffer(input, output []byte) int {
+	if len(output) < d.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var length = d.rs.codewordLength(len(input), d.interleave, 0)
+	var dataLength = length - d.rs.code.Roots
+	var codeword = d.codeword[:length]
+
+	d.lastCorrected = 0
+	d.lastUncorrectable = 0
+
+	for i := 0; i < d.interleave; i++ {
+		for j := range codeword {
+			codeword[j] = input[j*d.interleave+i]
+			if d.rs.code.DualBasis {
+				codeword[j] = d.rs.fromDual[codeword[j]]
+			}
+		}
+
+		var corrected = d.rs.decode(codeword)
+
+		if corrected < 0 {
+			d.lastUncorrectable++
+			for j := 0; j < dataLength; j++ {
+				output[j*d.interleave+i] = input[j*d.interleave+i]
+			}
+			continue
+		}
+
+		d.lastCorrected += corrected
+
+		for j := 0; j < dataLength; j++ {
+			var v = codeword[j]
+			if d.rs.code.DualBasis {
+				v = d.rs.toDual[v]
+			}
+			output[j*d.interleave+i] = v
+		}
+	}
+
+	d.corrected += d.lastCorrected
+	d.uncorrectable += d.lastUncorrectable
+
+	return d.PredictOutputSize(len(input))
+}
+
+func (d *ReedSolomonDecoder) PredictOutputSize(inputLength int) int {
+	var length = inputLength - d.rs.code.Roots*d.interleave
+	if length < 0 {
+		return 0
+	}
+	return length
+}
+
+// endregion
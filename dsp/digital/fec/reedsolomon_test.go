@@ -0,0 +1,140 @@
+package fec
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func makeTestBytes(length int, seed int64) []byte {
+	var r = rand.New(rand.NewSource(seed))
+	var data = make([]byte, length)
+	r.Read(data)
+	return data
+}
+
+// corrupt changes count different symbols of each codeword of the block
+func corrupt(block []byte, interleave, count int, r *rand.Rand) {
+	var length = len(block) / interleave
+	for i := 0; i < interleave; i++ {
+		for _, j := range r.Perm(length)[:count] {
+			block[j*interleave+i] ^= byte(1 + r.Intn(255))
+		}
+	}
+}
+
+func TestReedSolomonGenerator(t *testing.T) {
+	// The CCSDS generator polynomial is symmetric
+	var rs = makeReedSolomon(CCSDSReedSolomonCode)
+	for i := range rs.generator {
+		if rs.generator[i] != rs.generator[len(rs.generator)-1-i] {
+			t.Fatalf("Expected a symmetric generator polynomial got %v", rs.generator)
+		}
+	}
+
+	// The dual basis conversion is a bijection
+	for i := 0; i < 256; i++ {
+		if rs.fromDual[rs.toDual[i]] != byte(i) {
+			t.Fatalf("Expected the dual basis conversion to be reversible at %d", i)
+		}
+	}
+}
+
+func TestReedSolomonCCSDSKnownAnswer(t *testing.T) {
+	var rs = makeReedSolomon(CCSDSReedSolomonCode)
+
+	// CCSDS_poly from libfec, the generator polynomial in index form
+	var generator = []int{
+		0, 249, 59, 66, 4, 43, 126, 251, 97, 30, 3, 213, 50, 66, 170, 5, 24,
+		5, 170, 66, 50, 213, 3, 30, 97, 251, 126, 43, 4, 66, 59, 249, 0,
+	}
+	for i := range generator {
+		if rs.generator[i] != generator[i] {
+			t.Fatalf("Expected the generator polynomial %v got %v", generator, rs.generator)
+		}
+	}
+
+	// The start of Taltab from libfec, the conventional to dual basis table
+	var taltab = []byte{0x00, 0x7b, 0xaf, 0xd4, 0x99, 0xe2, 0x36, 0x4d, 0xfa, 0x81, 0x55, 0x2e, 0x63, 0x18, 0xcc, 0xb7}
+	if !bytes.Equal(rs.toDual[:len(taltab)], taltab) {
+		t.Fatalf("Expected the dual basis table to start with % x got % x", taltab, rs.toDual[:len(taltab)])
+	}
+
+	// The parity of the dual basis message 0, 1, ..., 222, from a separate GF(2^8) encoder of the CCSDS definition
+	var parity = []byte{
+		0x4f, 0xfb, 0x92, 0xdd, 0x55, 0x7e, 0xc6, 0x7f, 0x27, 0xfb, 0x89, 0x82, 0xcf, 0x58, 0xf8, 0xfd,
+		0x02, 0x8a, 0xd1, 0x17, 0xfc, 0xef, 0x6b, 0x27, 0x93, 0xd0, 0x41, 0x88, 0x26, 0x57, 0x86, 0x51,
+	}
+
+	var data = make([]byte, 223)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	var codeword = MakeReedSolomonEncoder(CCSDSReedSolomonCode, 1).Work(data)
+	if !bytes.Equal(codeword[len(data):], parity) {
+		t.Fatalf("Expected the parity % x got % x", parity, codeword[len(data):])
+	}
+
+	var decoder = MakeReedSolomonDecoder(CCSDSReedSolomonCode, 1)
+	if !bytes.Equal(decoder.Work(codeword), data) || decoder.GetLastCorrected() != 0 {
+		t.Fatalf("Expected the known codeword to decode without corrections")
+	}
+}
+
+func TestReedSolomon(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		code       ReedSolomonCode
+		interleave int
+		dataLength int
+	}{
+		{"CCSDS I=1", CCSDSReedSolomonCode, 1, 223},
+		{"CCSDS I=5", CCSDSReedSolomonCode, 5, 223},
+		{"CCSDS shortened I=2", CCSDSReedSolomonCode, 2, 100},
+		{"RS(255,239) conventional", ReedSolomonCode{FieldPolynomial: 0x11D, FirstRoot: 0, Primitive: 1, Roots: 16}, 1, 239},
+		{"RS(64,48) conventional I=3", ReedSolomonCode{FieldPolynomial: 0x11D, FirstRoot: 1, Primitive: 1, Roots: 16}, 3, 48},
+	}
+
+	var r = rand.New(rand.NewSource(3))
+
+	for _, tc := range testCases {
+		var maxErrors = tc.code.Roots / 2
+		var encoder = MakeReedSolomonEncoder(tc.code, tc.interleave)
+		var decoder = MakeReedSolomonDecoder(tc.code, tc.interleave)
+
+		var data = makeTestBytes(tc.dataLength*tc.interleave, 4)
+		var block = encoder.Work(data)
+
+		if len(block) != (tc.dataLength+tc.code.Roots)*tc.interleave {
+			t.Fatalf("%s: Expected a %d bytes block got %d", tc.name, (tc.dataLength+tc.code.Roots)*tc.interleave, len(block))
+		}
+
+		if !bytes.Equal(block[:len(data)/tc.interleave], data[:len(data)/tc.interleave]) {
+			t.Fatalf("%s: Expected a systematic code", tc.name)
+		}
+
+		for errors := 0; errors <= maxErrors; errors++ {
+			var received = append([]byte{}, block...)
+			corrupt(received, tc.interleave, errors, r)
+
+			var decoded = decoder.Work(received)
+			if !bytes.Equal(decoded, data) {
+				t.Errorf("%s: Expected %d errors per codeword to be corrected", tc.name, errors)
+			}
+
+			if decoder.GetLastCorrected() != errors*tc.interleave || decoder.GetLastUncorrectable() != 0 {
+				t.Errorf("%s: Expected %d corrected symbols got %d", tc.name, errors*tc.interleave, decoder.GetLastCorrected())
+			}
+		}
+
+		// Too many errors are detected (with a very high probability)
+		var received = append([]byte{}, block...)
+		corrupt(received, tc.interleave, maxErrors+1, r)
+
+		decoder.Work(received)
+		if decoder.GetLastUncorrectable() != tc.interleave {
+			t.Errorf("%s: Expected %d uncorrectable codewords got %d", tc.name, tc.interleave, decoder.GetLastUncorrectable())
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package fec
+
+import (
+	"github.com/racerxdl/segdsp/dsp"
+	"testing"
+)
+
+func TestByteWorkers(t *testing.T) {
+	var byteWorkersType = []interface{}{
+		&ConvolutionalEncoder{},
+		&ReedSolomonEncoder{},
+		&ReedSolomonDecoder{},
+	}
+
+	for _, v := range byteWorkersType {
+		_, ok := v.(dsp.ByteWorker)
+		if !ok {
+			t.Fatalf("Type %T does not implement ByteWorker type!\n", v)
+		}
+	}
+}
+
+func TestFloat322ByteWorkers(t *testing.T) {
+	var fbWorkersType = []interface{}{
+		&ViterbiDecoder{},
+	}
+
+	for _, v := range fbWorkersType {
+		_, ok := v.(dsp.Float322ByteWorker)
+		if !ok {
+			t.Fatalf("Type %T does not implement Float322ByteWorkers type!\n", v)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package fec
+
+import (
+	"math"
+)
+
+// ViterbiDecoder is a soft decision Viterbi decoder for convolutional codes.
+// The input is soft bits, positive for 0 and negative for 1 (like the real part of BPSK symbols from the PSK
+// demodulator, where bit 0 is +1). The magnitude is the confidence, and 0 means erasure (no information).
+// The output is the decoded bits (one per byte), delayed by up to twice the traceback depth.
+type ViterbiDecoder struct {
+	code           ConvolutionalCode
+	tracebackDepth int
+	nStates        int
+	nOutputs       int
+	patterns       []int
+	puncturing     []byte
+	punctureStep   int
+
+	symbols       []float32
+	symbolCount   int
+	branchMetrics []float32
+
+	metrics    []float32
+	newMetrics []float32
+	decisions  [][]byte
+	tmp        [][]byte
+	steps      int
+}
+
+// MakeViterbiDecoder creates a Viterbi decoder for the code. tracebackDepth is the number of steps the survivor paths
+// are traced back before a bit is decided, usually 5 to 7 times the constraint length (more for punctured codes).
+// The decoder starts at the state 0, like the encoder.
+func MakeViterbiDecoder(code ConvolutionalCode, tracebackDepth int) *ViterbiDecoder {
+	code.check()
+
+	if tracebackDepth < code.K {
+		panic("Viterbi traceback depth should not be lower than the constraint length")
+	}
+
+	var nOutputs = len(code.Polynomials)
+	var outputs = code.outputs()
+
+	// The branch metrics only depend on the coded bits, so each register maps to a pattern of the coded bits
+	var patterns = make([]int, len(outputs))
+	for reg, o := range outputs {
+		for i, bit := range o {
+			patterns[reg] |= int(bit) << uint(i)
+		}
+	}
+
+	var v = &ViterbiDecoder{
+		code:           code,
+		tracebackDepth: tracebackDepth,
+		nStates:        1 << uint(code.K-1),
+		nOutputs:       nOutputs,
+		patterns:       patterns,
+		puncturing:     code.puncturing(),
+		symbols:        make([]float32, nOutputs),
+		branchMetrics:  make([]float32, 1<<uint(nOutputs)),
+		decisions:      make([][]byte, 2*tracebackDepth),
+		tmp:            make([][]byte, tracebackDepth),
+	}
+
+	v.metrics = make([]float32, v.nStates)
+	v.newMetrics = make([]float32, v.nStates)
+
+	for i := range v.decisions {
+		v.decisions[i] = make([]byte, v.nStates)
+	}
+
+	v.Reset()
+
+	return v
+}
+
+// GetCode returns the convolutional code
+func (v *ViterbiDecoder) GetCode() ConvolutionalCode {
+	return v.code
+}
+
+// GetTracebackDepth returns the traceback depth
+func (v *ViterbiDecoder) GetTracebackDepth() int {
+	return v.tracebackDepth
+}
+
+// Reset drops the pending bits and restarts the decoder at the state 0
+func (v *ViterbiDecoder) Reset() {
+	for i := range v.metrics {
+		v.metrics[i] = -math.MaxFloat32 / 2
+	}
+	v.metrics[0] = 0
+
+	v.steps = 0
+	v.symbolCount = 0
+	v.punctureStep = 0
+}
+
+// Flush decides the bits still in the decoder (from the best state) and resets it.
+// It should be called at the end of a frame, ideally after K - 1 tail bits.
+func (v *ViterbiDecoder) Flush() []byte {
+	var output = make([]byte, v.steps)
+	v.traceback(v.steps, output)
+	v.Reset()
+	return output
+}
+
+// step runs one trellis step with the symbols of one input bit
+func (v *ViterbiDecoder) step() {
+	for p := range v.branchMetrics {
+		var m float32
+		for i := 0; i < v.nOutputs; i++ {
+			if p&(1<<uint(i)) == 0 {
+				m += v.symbols[i]
+			} else {
+				m -= v.symbols[i]
+			}
+		}
+		v.branchMetrics[p] = m
+	}
+
+	var decisions = v.decisions[v.steps]
+	var topShift = uint(v.code.K - 2)
+	var stateMask = v.nStates - 1
+	var best = float32(-math.MaxFloat32)
+
+	for next := 0; next < v.nStates; next++ {
+		// The next state has the input bit on top, and comes from two states that differ on the oldest bit
+		var bit = next >> topShift
+		var previous = (next << 1) & stateMask
+		var reg = bit<<uint(v.code.K-1) | previous
+
+		var m0 = v.metrics[previous] + v.branchMetrics[v.patterns[reg]]
+		var m1 = v.metrics[previous|1] + v.branchMetrics[v.patterns[reg|1]]
+
+		if m1 > m0 {
+			v.newMetrics[next] = m1
+			decisions[next] = 1
+		} else {
+			v.newMetrics[next] = m0
+			decisions[next] = 0
+		}
+
+		if v.newMetrics[next] > best {
+			best = v.newMetrics[next]
+		}
+	}
+
+	// Keep the metrics close to 0
+	for i := range v.newMetrics {
+		v.metrics[i] = v.newMetrics[i] - best
+	}
+
+	v.steps++
+}
+
+// traceback follows the survivor path of the best state back from the last step, writing the first len(output) bits
+func (v *ViterbiDecoder) traceback(count int, output []byte) {
+	var state = 0
+	for i, m := range v.metrics {
+		if m > v.metrics[state] {
+			state = i
+		}
+	}
+
+	var topShift = uint(v.code.K - 2)
+	var stateMask = v.nStates - 1
+
+	for t := count - 1; t >= 0; t-- {
+		if t < len(output) {
+			output[t] = byte(state >> topShift)
+		}
+		state = ((state << 1) & stateMask) | int(v.decisions[t][state])
+	}
+}
+
+// pushSymbol adds a soft bit to the current step, running it when complete
+func (v *ViterbiDecoder) pushSymbol(symbol float32, output []byte) int {
+	v.symbols[v.symbolCount] = symbol
+	v.symbolCount++
+	v.punctureStep = (v.punctureStep + 1) % len(v.puncturing)
+
+	if v.symbolCount < v.nOutputs {
+		return 0
+	}
+
+	v.symbolCount = 0
+	v.step()
+
+	if v.steps < len(v.decisions) {
+		return 0
+	}
+
+	// Decide the oldest half of the path and reuse its decisions
+	v.traceback(v.steps, output[:v.tracebackDepth])
+
+	copy(v.tmp, v.decisions[:v.tracebackDepth])
+	copy(v.decisions, v.decisions[v.tracebackDepth:])
+	copy(v.decisions[v.tracebackDepth:], v.tmp)
+	v.steps -= v.tracebackDepth
+
+	return v.tracebackDepth
+}
+
+// fillPunctured adds erasures for the punctured bits
+func (v *ViterbiDecoder) fillPunctured(output []byte) int {
+	var n = 0
+	for v.puncturing[v.punctureStep] == 0 {
+		n += v.pushSymbol(0, output[n:])
+	}
+	return n
+}
+
+func (v *ViterbiDecoder) Work(input []float32) []byte {
+	var output = make([]byte, v.PredictOutputSize(len(input)))
+	l := v.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (v *ViterbiDecoder) WorkBuffer(input []float32, output []byte) int {
+	if len(output) < v.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var outputIndex = v.fillPunctured(output)
+
+	for _, s := range input {
+		outputIndex += v.pushSymbol(s, output[outputIndex:])
+		outputIndex += v.fillPunctured(output[outputIndex:])
+	}
+
+	return outputIndex
+}
+
+func (v *ViterbiDecoder) PredictOutputSize(inputLength int) int {
+	var sent = 0
+	for _, p := range v.puncturing {
+		sent += int(p & 1)
+	}
+
+	var symbols = v.symbolCount + (inputLength+1)*len(v.puncturing)/sent + len(v.puncturing)
+
+	return v.steps + symbols/v.nOutputs + 1
+}
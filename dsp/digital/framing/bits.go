@@ -0,0 +1,39 @@
+package framing
+
+// PackBits packs unpacked bits (one per byte) in bytes. The bits of the last incomplete byte are discarded.
+// HDLC sends the least significant bit first, most other protocols send the most significant bit first.
+func PackBits(bits []byte, lsbFirst bool) []byte {
+	var output = make([]byte, len(bits)/8)
+
+	for i := range output {
+		var v byte
+		for j := 0; j < 8; j++ {
+			var bit = bits[i*8+j] & 1
+			if lsbFirst {
+				v |= bit << uint(j)
+			} else {
+				v |= bit << uint(7-j)
+			}
+		}
+		output[i] = v
+	}
+
+	return output
+}
+
+// UnpackBits unpacks the bytes in bits (one per byte), in the order given by lsbFirst
+func UnpackBits(data []byte, lsbFirst bool) []byte {
+	var output = make([]byte, len(data)*8)
+
+	for i, v := range data {
+		for j := 0; j < 8; j++ {
+			if lsbFirst {
+				output[i*8+j] = (v >> uint(j)) & 1
+			} else {
+				output[i*8+j] = (v >> uint(7-j)) & 1
+			}
+		}
+	}
+
+	return output
+}
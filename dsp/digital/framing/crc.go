@@ -0,0 +1,187 @@
+package framing
+
+// CRC is a table driven CRC engine of 8, 16 or 32 bits, configured with the usual parameters of the CRC catalogues
+// (polynomial, initial value, input and output reflection and final XOR).
+// As a worker it takes packed bytes, one frame per call, and appends the CRC to it. The CRC is appended least
+// significant byte first when the input is reflected (like the HDLC FCS) and most significant byte first otherwise.
+type CRC struct {
+	width      uint
+	polynomial uint32
+	init       uint32
+	xorOut     uint32
+	reflectIn  bool
+	reflectOut bool
+	mask       uint32
+	table      [256]uint32
+}
+
+// MakeCRC creates a CRC engine of width bits (8, 16 or 32). The polynomial is in the normal (not reflected) form
+// without the highest term, like 0x1021 for x^16 + x^12 + x^5 + 1.
+func MakeCRC(width uint, polynomial, init, xorOut uint32, reflectIn, reflectOut bool) *CRC {
+	if width != 8 && width != 16 && width != 32 {
+		panic("CRC width should be 8, 16 or 32 bits")
+	}
+
+	var c = &CRC{
+		width:      width,
+		reflectIn:  reflectIn,
+		reflectOut: reflectOut,
+		mask:       uint32(1<<width - 1),
+	}
+
+	c.polynomial = polynomial & c.mask
+	c.init = init & c.mask
+	c.xorOut = xorOut & c.mask
+
+	c.makeTable()
+
+	return c
+}
+
+// MakeCRC8 creates the CRC-8 (SMBus) engine: polynomial 0x07, init 0x00
+func MakeCRC8() *CRC {
+	return MakeCRC(8, 0x07, 0x00, 0x00, false, false)
+}
+
+// MakeCRC16CCITT creates the CRC-16/CCITT-FALSE engine: polynomial 0x1021, init 0xFFFF
+func MakeCRC16CCITT() *CRC {
+	return MakeCRC(16, 0x1021, 0xFFFF, 0x0000, false, false)
+}
+
+// MakeCRC16X25 creates the CRC-16/X-25 engine, the HDLC / AX.25 frame check sequence
+func MakeCRC16X25() *CRC {
+	return MakeCRC(16, 0x1021, 0xFFFF, 0xFFFF, true, true)
+}
+
+// MakeCRC32 creates the CRC-32 engine of Ethernet, zip and PNG
+func MakeCRC32() *CRC {
+	return MakeCRC(32, 0x04C11DB7, 0xFFFFFFFF, 0xFFFFFFFF, true, true)
+}
+
+func reflectBits(v uint32, width uint) uint32 {
+	var r uint32
+	for i := uint(0); i < width; i++ {
+		if v&(1<<i) != 0 {
+			r |= 1 << (width - 1 - i)
+		}
+	}
+	return r
+}
+
+func (c *CRC) makeTable() {
+	// The table is for the reflected algorithm when the input is reflected, so no byte needs to be reflected
+	if c.reflectIn {
+		var polynomial = reflectBits(c.polynomial, c.width)
+		for i := range c.table {
+			var v = uint32(i)
+			for j := 0; j < 8; j++ {
+				if v&1 != 0 {
+					v = (v >> 1) ^ polynomial
+				} else {
+					v >>= 1
+				}
+			}
+			c.table[i] = v
+		}
+		return
+	}
+
+	var top = uint32(1) << (c.width - 1)
+	for i := range c.table {
+		var v = uint32(i) << (c.width - 8)
+		for j := 0; j < 8; j++ {
+			if v&top != 0 {
+				v = (v << 1) ^ c.polynomial
+			} else {
+				v <<= 1
+			}
+		}
+		c.table[i] = v & c.mask
+	}
+}
+
+// GetWidth returns the number of bits of the CRC
+func (c *CRC) GetWidth() uint {
+	return c.width
+}
+
+// Checksum returns the CRC of data
+func (c *CRC) Checksum(data []byte) uint32 {
+	var crc = c.init
+
+	if c.reflectIn {
+		crc = reflectBits(crc, c.width)
+		for _, v := range data {
+			crc = (crc >> 8) ^ c.table[byte(crc)^v]
+		}
+	} else {
+		for _, v := range data {
+			crc = ((crc << 8) ^ c.table[byte(crc>>(c.width-8))^v]) & c.mask
+		}
+	}
+
+	// The register is reflected already when the input is reflected
+	if c.reflectIn != c.reflectOut {
+		crc = reflectBits(crc, c.width)
+	}
+
+	return (crc ^ c.xorOut) & c.mask
+}
+
+// Check returns true if frame ends with the right CRC (appended like Work does)
+func (c *CRC) Check(frame []byte) bool {
+	var size = int(c.width / 8)
+	if len(frame) < size {
+		return false
+	}
+
+	var data = frame[:len(frame)-size]
+	return c.readCRC(frame[len(data):]) == c.Checksum(data)
+}
+
+func (c *CRC) writeCRC(crc uint32, output []byte) {
+	var size = int(c.width / 8)
+	for i := 0; i < size; i++ {
+		if c.reflectIn {
+			output[i] = byte(crc >> uint(8*i))
+		} else {
+			output[i] = byte(crc >> uint(8*(size-1-i)))
+		}
+	}
+}
+
+func (c *CRC) readCRC(input []byte) uint32 {
+	var crc uint32
+	var size = len(input)
+	for i, v := range input {
+		if c.reflectIn {
+			crc |= uint32(v) << uint(8*i)
+		} else {
+			crc |= uint32(v) << uint(8*(size-1-i))
+		}
+	}
+	return crc
+}
+
+func (c *CRC) Work(input []byte) []byte {
+	var output = make([]byte, c.PredictOutputSize(len(input)))
+	l := c.WorkBuffer(input, output)
+	return output[:l]
+}
+
+// WorkBuffer copies the frame in input to output and appends the CRC
+func (c *CRC) WorkBuffer(input, output []byte) int {
+	if len(output) < c.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var crc = c.Checksum(input)
+	copy(output, input)
+	c.writeCRC(crc, output[len(input):])
+
+	return c.PredictOutputSize(len(input))
+}
+
+func (c *CRC) PredictOutputSize(inputLength int) int {
+	return inputLength + int(c.width/8)
+}
@@ -0,0 +1,60 @@
+package framing
+
+import (
+	"testing"
+)
+
+var crcCheckInput = []byte("123456789")
+
+func TestCRCCheckValues(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		crc      *CRC
+		expected uint32
+	}{
+		{"CRC-8", MakeCRC8(), 0xF4},
+		{"CRC-16/CCITT-FALSE", MakeCRC16CCITT(), 0x29B1},
+		{"CRC-16/X-25", MakeCRC16X25(), 0x906E},
+		{"CRC-32", MakeCRC32(), 0xCBF43926},
+		{"CRC-16/ARC", MakeCRC(16, 0x8005, 0, 0, true, true), 0xBB3D},
+		{"CRC-32/MPEG-2", MakeCRC(32, 0x04C11DB7, 0xFFFFFFFF, 0, false, false), 0x0376E6E7},
+		{"CRC-8/MAXIM", MakeCRC(8, 0x31, 0, 0, true, true), 0xA1},
+	}
+
+	for _, tc := range testCases {
+		if v := tc.crc.Checksum(crcCheckInput); v != tc.expected {
+			t.Errorf("%s: Expected %x got %x", tc.name, tc.expected, v)
+		}
+	}
+}
+
+func TestCRCWork(t *testing.T) {
+	for _, crc := range []*CRC{MakeCRC8(), MakeCRC16CCITT(), MakeCRC16X25(), MakeCRC32()} {
+		var size = int(crc.GetWidth() / 8)
+		var frame = crc.Work(crcCheckInput)
+
+		if len(frame) != len(crcCheckInput)+size {
+			t.Fatalf("Expected %d bytes got %d", len(crcCheckInput)+size, len(frame))
+		}
+
+		if !crc.Check(frame) {
+			t.Errorf("%d bits: Expected the frame to check", crc.GetWidth())
+		}
+
+		frame[3] ^= 0x10
+		if crc.Check(frame) {
+			t.Errorf("%d bits: Expected the corrupted frame to fail", crc.GetWidth())
+		}
+	}
+
+	// The HDLC FCS goes least significant byte first
+	var frame = MakeCRC16X25().Work(crcCheckInput)
+	if frame[9] != 0x6E || frame[10] != 0x90 {
+		t.Fatalf("Expected the FCS least significant byte first got %x", frame[9:])
+	}
+
+	frame = MakeCRC16CCITT().Work(crcCheckInput)
+	if frame[9] != 0x29 || frame[10] != 0xB1 {
+		t.Fatalf("Expected the CRC most significant byte first got %x", frame[9:])
+	}
+}
@@ -0,0 +1,9 @@
+// Package framing has the bit level blocks used by the packet decoders: line codes, scramblers, HDLC deframing,
+// sync word detection and CRC.
+//
+// All workers (except the CRC) take unpacked bits: one bit per byte, with value 0 or 1, like the output of
+// binarySlicer.Float2LevelSlicer.
+//
+// The scrambler polynomials have bit k set for the x^k term, including x^0 and the highest one (the register length),
+// like 0x21001 for the G3RUH scrambler (1 + x^12 + x^17) or 0x1A9 for the CCSDS randomizer (x^8 + x^7 + x^5 + x^3 + 1).
+package framing
@@ -0,0 +1,146 @@
+package framing
+
+// hdlcFlagBits is the number of bits of the flag (01111110) pushed before it is detected (the 0 and the six 1s)
+const hdlcFlagBits = 7
+
+// hdlcFCSSize is the size of the frame check sequence in bytes
+const hdlcFCSSize = 2
+
+// HDLCDeframer finds the HDLC frames (between 0x7E flags) in the bits, removes the bit stuffing and checks the
+// frame check sequence (CRC-16/X-25). The bits should be NRZI decoded already (NRZISpace for HDLC and AX.25).
+// The output has the bytes of the good frames, without the FCS. Since the frame boundaries are lost in the output,
+// SetOnFrame can be used to get each frame.
+type HDLCDeframer struct {
+	minLength int
+	maxLength int
+	fcs       *CRC
+	onFrame   func(frame []byte)
+
+	ones      int
+	inFrame   bool
+	frameBits []byte
+
+	frameCount    int
+	crcErrorCount int
+}
+
+// MakeHDLCDeframer creates a HDLC deframer for frames from minLength to maxLength bytes (without the FCS)
+func MakeHDLCDeframer(minLength, maxLength int) *HDLCDeframer {
+	if minLength < 1 || maxLength < minLength {
+		panic("HDLC frame lengths should be at least 1 and maxLength should not be lower than minLength")
+	}
+
+	return &HDLCDeframer{
+		minLength: minLength,
+		maxLength: maxLength,
+		fcs:       MakeCRC16X25(),
+		frameBits: make([]byte, 0, (maxLength+hdlcFCSSize)*8+hdlcFlagBits),
+	}
+}
+
+// SetOnFrame sets a function called with each good frame (without the FCS). The frame is a new slice.
+func (h *HDLCDeframer) SetOnFrame(cb func(frame []byte)) {
+	h.onFrame = cb
+}
+
+// GetFrameCount returns the number of good frames found
+func (h *HDLCDeframer) GetFrameCount() int {
+	return h.frameCount
+}
+
+// GetCRCErrorCount returns the number of frames dropped because of a wrong FCS
+func (h *HDLCDeframer) GetCRCErrorCount() int {
+	return h.crcErrorCount
+}
+
+func (h *HDLCDeframer) Work(input []byte) []byte {
+	var output = make([]byte, h.PredictOutputSize(len(input)))
+	l := h.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (h *HDLCDeframer) WorkBuffer(input, output []byte) int {
+	if len(output) < h.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var outputIndex = 0
+
+	for _, bit := range input {
+		bit &= 1
+
+		if bit == 1 {
+			h.ones++
+			if h.ones > 6 {
+				// Abort or idle line, wait for the next flag
+				h.inFrame = false
+				h.frameBits = h.frameBits[:0]
+				continue
+			}
+		} else {
+			switch h.ones {
+			case 5:
+				// Stuffed bit
+				h.ones = 0
+				continue
+			case 6:
+				// Flag
+				h.ones = 0
+				if h.inFrame {
+					outputIndex += h.endFrame(output[outputIndex:])
+				}
+				h.inFrame = true
+				h.frameBits = h.frameBits[:0]
+				continue
+			}
+			h.ones = 0
+		}
+
+		if !h.inFrame {
+			continue
+		}
+
+		if len(h.frameBits) == cap(h.frameBits) {
+			// Too long for a frame
+			h.inFrame = false
+			h.frameBits = h.frameBits[:0]
+			continue
+		}
+
+		h.frameBits = append(h.frameBits, bit)
+	}
+
+	return outputIndex
+}
+
+// endFrame checks the bits before the flag and writes the frame to output if it is good
+func (h *HDLCDeframer) endFrame(output []byte) int {
+	var length = len(h.frameBits) - hdlcFlagBits
+	if length <= 0 || length%8 != 0 {
+		return 0
+	}
+
+	var bytesLength = length/8 - hdlcFCSSize
+	if bytesLength < h.minLength || bytesLength > h.maxLength {
+		return 0
+	}
+
+	var frame = PackBits(h.frameBits[:length], true)
+	if !h.fcs.Check(frame) {
+		h.crcErrorCount++
+		return 0
+	}
+
+	h.frameCount++
+	frame = frame[:bytesLength]
+
+	if h.onFrame != nil {
+		h.onFrame(frame)
+	}
+
+	return copy(output, frame)
+}
+
+func (h *HDLCDeframer) PredictOutputSize(inputLength int) int {
+	return (len(h.frameBits) + inputLength) / 8
+}
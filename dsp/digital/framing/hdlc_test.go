@@ -0,0 +1,108 @@
+package framing
+
+import (
+	"bytes"
+	"testing"
+)
+
+var hdlcFlag = []byte{0, 1, 1, 1, 1, 1, 1, 0}
+
+// makeHDLCBits makes the stuffed bits of a frame with its FCS, between flags
+func makeHDLCBits(data []byte) []byte {
+	var frameBits = UnpackBits(MakeCRC16X25().Work(data), true)
+	var output = append([]byte{}, hdlcFlag...)
+
+	var ones = 0
+	for _, bit := range frameBits {
+		output = append(output, bit)
+		if bit == 1 {
+			ones++
+			if ones == 5 {
+				output = append(output, 0)
+				ones = 0
+			}
+		} else {
+			ones = 0
+		}
+	}
+
+	return append(output, hdlcFlag...)
+}
+
+func TestHDLCDeframer(t *testing.T) {
+	var frames = [][]byte{
+		[]byte("CQ CQ DE PY2SDR"),
+		// Lots of ones to test the stuffing
+		{0xFF, 0xFF, 0x7E, 0x7E, 0xFC, 0x3F, 0x1F},
+		[]byte("A"),
+	}
+
+	// Idle flags, frames sharing flags, and some noise between frames
+	var bits = append([]byte{}, hdlcFlag...)
+	bits = append(bits, hdlcFlag...)
+	for _, f := range frames {
+		bits = append(bits, makeHDLCBits(f)...)
+		bits = append(bits, 1, 1, 1, 1, 1, 1, 1, 1, 0, 1)
+	}
+
+	// Over the air with NRZI
+	var levels = MakeNRZIEncoder(NRZISpace).Work(bits)
+
+	var decoder = MakeNRZIDecoder(NRZISpace)
+	var deframer = MakeHDLCDeframer(1, 64)
+
+	var got [][]byte
+	deframer.SetOnFrame(func(frame []byte) {
+		got = append(got, frame)
+	})
+
+	var output []byte
+	for i := 0; i < len(levels); i += 13 {
+		var end = i + 13
+		if end > len(levels) {
+			end = len(levels)
+		}
+		output = append(output, deframer.Work(decoder.Work(levels[i:end]))...)
+	}
+
+	if len(got) != len(frames) || deframer.GetFrameCount() != len(frames) {
+		t.Fatalf("Expected %d frames got %d", len(frames), len(got))
+	}
+
+	for i := range frames {
+		if !bytes.Equal(got[i], frames[i]) {
+			t.Errorf("Expected frame %x got %x", frames[i], got[i])
+		}
+	}
+
+	if !bytes.Equal(output, bytes.Join(frames, nil)) {
+		t.Errorf("Expected the output to have the frames got %x", output)
+	}
+}
+
+func TestHDLCDeframerErrors(t *testing.T) {
+	var good = makeHDLCBits([]byte("good frame"))
+
+	var corrupted = makeHDLCBits([]byte("bad frame"))
+	corrupted[20] ^= 1
+
+	var short = makeHDLCBits([]byte("s"))
+	var long = makeHDLCBits(make([]byte, 100))
+
+	var deframer = MakeHDLCDeframer(2, 64)
+
+	var bits []byte
+	for _, b := range [][]byte{corrupted, short, long, good} {
+		bits = append(bits, b...)
+	}
+
+	var output = deframer.Work(bits)
+
+	if string(output) != "good frame" {
+		t.Fatalf("Expected only the good frame got %q", output)
+	}
+
+	if deframer.GetCRCErrorCount() != 1 {
+		t.Fatalf("Expected 1 CRC error got %d", deframer.GetCRCErrorCount())
+	}
+}
@@ -0,0 +1,270 @@
+package framing
+
+// NRZIMode selects which bit value makes the NRZI level change
+type NRZIMode int
+
+const (
+	// NRZISpace changes the level on 0 and keeps it on 1 (NRZ-S), as HDLC / AX.25 and USB
+	NRZISpace NRZIMode = iota
+	// NRZIMark changes the level on 1 and keeps it on 0 (NRZ-M), as CCSDS
+	NRZIMark
+)
+
+func (m NRZIMode) String() string {
+	switch m {
+	case NRZISpace:
+		return "NRZ-S"
+	case NRZIMark:
+		return "NRZ-M"
+	}
+	return "Unknown"
+}
+
+// region NRZ
+
+// NRZ converts between bits and NRZ-L levels (0 or 1). It does nothing unless inverted, when it fixes (or makes)
+// a signal with swapped polarity, like after a BPSK phase ambiguity or an inverted FSK deviation.
+// The same worker encodes and decodes.
+type NRZ struct {
+	inverted bool
+}
+
+// MakeNRZEncoder creates a NRZ-L encoder, that inverts the bits if inverted is true
+func MakeNRZEncoder(inverted bool) *NRZ {
+	return &NRZ{
+		inverted: inverted,
+	}
+}
+
+// MakeNRZDecoder creates a NRZ-L decoder, that inverts the bits if inverted is true
+func MakeNRZDecoder(inverted bool) *NRZ {
+	return MakeNRZEncoder(inverted)
+}
+
+// IsInverted returns true if the polarity is inverted
+func (n *NRZ) IsInverted() bool {
+	return n.inverted
+}
+
+// SetInverted sets the polarity inversion
+func (n *NRZ) SetInverted(inverted bool) {
+	n.inverted = inverted
+}
+
+func (n *NRZ) Work(input []byte) []byte {
+	var output = make([]byte, n.PredictOutputSize(len(input)))
+	l := n.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (n *NRZ) WorkBuffer(input, output []byte) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var flip byte
+	if n.inverted {
+		flip = 1
+	}
+
+	for i, v := range input {
+		output[i] = (v & 1) ^ flip
+	}
+
+	return len(input)
+}
+
+func (n *NRZ) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+// region NRZI
+
+// NRZIEncoder encodes the bits as level changes
+type NRZIEncoder struct {
+	mode  NRZIMode
+	level byte
+}
+
+// MakeNRZIEncoder creates a NRZI encoder. The level starts at 0.
+func MakeNRZIEncoder(mode NRZIMode) *NRZIEncoder {
+	return &NRZIEncoder{
+		mode: mode,
+	}
+}
+
+// GetMode returns the NRZI mode
+func (n *NRZIEncoder) GetMode() NRZIMode {
+	return n.mode
+}
+
+func (n *NRZIEncoder) Work(input []byte) []byte {
+	var output = make([]byte, n.PredictOutputSize(len(input)))
+	l := n.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (n *NRZIEncoder) WorkBuffer(input, output []byte) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var changeOn = byte(n.mode)
+
+	for i, v := range input {
+		if v&1 == changeOn {
+			n.level ^= 1
+		}
+		output[i] = n.level
+	}
+
+	return len(input)
+}
+
+func (n *NRZIEncoder) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// NRZIDecoder decodes the level changes to bits. It does not depend on the signal polarity.
+type NRZIDecoder struct {
+	mode      NRZIMode
+	lastLevel byte
+}
+
+// MakeNRZIDecoder creates a NRZI decoder. The level before the first bit is 0.
+func MakeNRZIDecoder(mode NRZIMode) *NRZIDecoder {
+	return &NRZIDecoder{
+		mode: mode,
+	}
+}
+
+// GetMode returns the NRZI mode
+func (n *NRZIDecoder) GetMode() NRZIMode {
+	return n.mode
+}
+
+func (n *NRZIDecoder) Work(input []byte) []byte {
+	var output = make([]byte, n.PredictOutputSize(len(input)))
+	l := n.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (n *NRZIDecoder) WorkBuffer(input, output []byte) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	// A level change is a 1 on NRZ-M and a 0 on NRZ-S
+	var flip = 1 - byte(n.mode)
+
+	for i, v := range input {
+		v &= 1
+		output[i] = (v ^ n.lastLevel) ^ flip
+		n.lastLevel = v
+	}
+
+	return len(input)
+}
+
+func (n *NRZIDecoder) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+// region Differential
+
+// DifferentialEncoder encodes symbols (0 to modulus - 1) as the difference to the previous output symbol:
+// out[n] = (in[n] + out[n - 1]) mod modulus. With modulus 2 it is the same as NRZ-M.
+type DifferentialEncoder struct {
+	modulus    int
+	lastSymbol int
+}
+
+// MakeDifferentialEncoder creates a differential encoder for symbols from 0 to modulus - 1 (like 4 for DQPSK)
+func MakeDifferentialEncoder(modulus int) *DifferentialEncoder {
+	if modulus < 2 || modulus > 256 {
+		panic("Differential modulus should be between 2 and 256")
+	}
+
+	return &DifferentialEncoder{
+		modulus: modulus,
+	}
+}
+
+// GetModulus returns the number of symbols
+func (d *DifferentialEncoder) GetModulus() int {
+	return d.modulus
+}
+
+func (d *DifferentialEncoder) Work(input []byte) []byte {
+	var output = make([]byte, d.PredictOutputSize(len(input)))
+	l := d.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (d *DifferentialEncoder) WorkBuffer(input, output []byte) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i, v := range input {
+		d.lastSymbol = (int(v) + d.lastSymbol) % d.modulus
+		output[i] = byte(d.lastSymbol)
+	}
+
+	return len(input)
+}
+
+func (d *DifferentialEncoder) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// DifferentialDecoder decodes symbols (0 to modulus - 1) from the difference to the previous input symbol:
+// out[n] = (in[n] - in[n - 1]) mod modulus. It removes the symbol ambiguity of PSK demodulators.
+type DifferentialDecoder struct {
+	modulus    int
+	lastSymbol int
+}
+
+// MakeDifferentialDecoder creates a differential decoder for symbols from 0 to modulus - 1 (like 4 for DQPSK)
+func MakeDifferentialDecoder(modulus int) *DifferentialDecoder {
+	if modulus < 2 || modulus > 256 {
+		panic("Differential modulus should be between 2 and 256")
+	}
+
+	return &DifferentialDecoder{
+		modulus: modulus,
+	}
+}
+
+// GetModulus returns the number of symbols
+func (d *DifferentialDecoder) GetModulus() int {
+	return d.modulus
+}
+
+func (d *DifferentialDecoder) Work(input []byte) []byte {
+	var output = make([]byte, d.PredictOutputSize(len(input)))
+	l := d.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (d *DifferentialDecoder) WorkBuffer(input, output []byte) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i, v := range input {
+		var symbol = int(v) % d.modulus
+		output[i] = byte((symbol - d.lastSymbol + d.modulus) % d.modulus)
+		d.lastSymbol = symbol
+	}
+
+	return len(input)
+}
+
+func (d *DifferentialDecoder) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
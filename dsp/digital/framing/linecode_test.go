@@ -0,0 +1,145 @@
+package framing
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func makeTestBits(length int, seed int64) []byte {
+	var r = rand.New(rand.NewSource(seed))
+	var data = make([]byte, length)
+	for i := range data {
+		data[i] = byte(r.Intn(2))
+	}
+	return data
+}
+
+func bitErrors(a, b []byte) int {
+	var errors = 0
+	for i := range a {
+		if a[i]&1 != b[i]&1 {
+			errors++
+		}
+	}
+	return errors
+}
+
+func TestNRZ(t *testing.T) {
+	var data = makeTestBits(100, 1)
+
+	var output = MakeNRZEncoder(false).Work(data)
+	if bitErrors(output, data) != 0 {
+		t.Fatal("Expected NRZ to keep the bits")
+	}
+
+	var decoder = MakeNRZDecoder(true)
+	output = decoder.Work(data)
+	if bitErrors(output, data) != len(data) {
+		t.Fatal("Expected inverted NRZ to invert all bits")
+	}
+
+	decoder.SetInverted(false)
+	if decoder.IsInverted() {
+		t.Fatal("Expected the inversion to be disabled")
+	}
+}
+
+func TestNRZI(t *testing.T) {
+	// From level 0: NRZ-S changes on the zeros, NRZ-M on the ones
+	var data = []byte{0, 1, 0, 0, 1, 1}
+	var expected = map[NRZIMode][]byte{
+		NRZISpace: {1, 1, 0, 1, 1, 1},
+		NRZIMark:  {0, 1, 1, 1, 0, 1},
+	}
+
+	for mode, levels := range expected {
+		var output = MakeNRZIEncoder(mode).Work(data)
+		for i := range levels {
+			if output[i] != levels[i] {
+				t.Fatalf("%s: Expected %v got %v", mode, levels, output)
+			}
+		}
+	}
+
+	for _, mode := range []NRZIMode{NRZISpace, NRZIMark} {
+		var bits = makeTestBits(1000, 2)
+		var encoder = MakeNRZIEncoder(mode)
+		var encoded = append(encoder.Work(bits[:333]), encoder.Work(bits[333:])...)
+
+		var decoder = MakeNRZIDecoder(mode)
+		var decoded = append(decoder.Work(encoded[:500]), decoder.Work(encoded[500:])...)
+
+		if bitErrors(decoded, bits) != 0 {
+			t.Errorf("%s: Expected the decoded bits to match", mode)
+		}
+
+		// Inverted levels only break the first bit
+		var inverted = MakeNRZEncoder(true).Work(encoded)
+		decoded = MakeNRZIDecoder(mode).Work(inverted)
+		if bitErrors(decoded[1:], bits[1:]) != 0 {
+			t.Errorf("%s: Expected NRZI to not depend on the polarity", mode)
+		}
+	}
+}
+
+func TestDifferential(t *testing.T) {
+	const modulus = 4
+
+	var r = rand.New(rand.NewSource(3))
+	var symbols = make([]byte, 1000)
+	for i := range symbols {
+		symbols[i] = byte(r.Intn(modulus))
+	}
+
+	var encoded = MakeDifferentialEncoder(modulus).Work(symbols)
+
+	// A constant rotation (like a PSK phase ambiguity) only breaks the first symbol
+	for rotation := 0; rotation < modulus; rotation++ {
+		var rotated = make([]byte, len(encoded))
+		for i, v := range encoded {
+			rotated[i] = byte((int(v) + rotation) % modulus)
+		}
+
+		var decoder = MakeDifferentialDecoder(modulus)
+		var decoded = append(decoder.Work(rotated[:10]), decoder.Work(rotated[10:])...)
+
+		for i := 1; i < len(decoded); i++ {
+			if decoded[i] != symbols[i] {
+				t.Fatalf("Rotation %d: Expected %d got %d at %d", rotation, symbols[i], decoded[i], i)
+			}
+		}
+	}
+
+	// Binary differential encoding is NRZ-M
+	var bits = makeTestBits(100, 4)
+	if bitErrors(MakeDifferentialEncoder(2).Work(bits), MakeNRZIEncoder(NRZIMark).Work(bits)) != 0 {
+		t.Fatal("Expected binary differential encoding to match NRZ-M")
+	}
+}
+
+func TestBitPacking(t *testing.T) {
+	var data = []byte{0x1A, 0xCF, 0xFC, 0x1D}
+
+	var msb = UnpackBits(data, false)
+	if msb[0] != 0 || msb[3] != 1 || msb[4] != 1 {
+		t.Fatalf("Expected the most significant bit first got %v", msb[:8])
+	}
+
+	var lsb = UnpackBits(data, true)
+	if lsb[0] != 0 || lsb[1] != 1 || lsb[3] != 1 {
+		t.Fatalf("Expected the least significant bit first got %v", lsb[:8])
+	}
+
+	for _, lsbFirst := range []bool{false, true} {
+		var packed = PackBits(UnpackBits(data, lsbFirst), lsbFirst)
+		for i := range data {
+			if packed[i] != data[i] {
+				t.Fatalf("Expected %x got %x", data, packed)
+			}
+		}
+	}
+
+	if len(PackBits(make([]byte, 15), false)) != 1 {
+		t.Fatal("Expected the incomplete byte to be discarded")
+	}
+}
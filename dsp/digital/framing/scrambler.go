@@ -0,0 +1,187 @@
+package framing
+
+import (
+	"math/bits"
+)
+
+// polynomialDegree returns the degree of the polynomial, panicking if it can't be used for a LFSR
+func polynomialDegree(polynomial uint64) uint {
+	if polynomial < 2 {
+		panic("The scrambler polynomial should have a degree of at least 1")
+	}
+	return uint(63 - bits.LeadingZeros64(polynomial))
+}
+
+// region Additive
+
+// AdditiveScrambler XORs the bits with the sequence of a free running LFSR. It is also its own descrambler, and needs
+// to be synchronized with the other side (with Reset at the start of each frame, like after the sync word).
+type AdditiveScrambler struct {
+	polynomial uint64
+	degree     uint
+	taps       uint64
+	seed       uint64
+	register   uint64
+}
+
+// MakeAdditiveScrambler creates an additive scrambler. The register holds the next degree bits of the sequence,
+// with the first one at bit 0, and starts at seed.
+func MakeAdditiveScrambler(polynomial, seed uint64) *AdditiveScrambler {
+	var degree = polynomialDegree(polynomial)
+
+	var s = &AdditiveScrambler{
+		polynomial: polynomial,
+		degree:     degree,
+		// The sequence follows the recurrence a[n + degree] = XOR of a[n + k] for each lower term x^k
+		taps: polynomial &^ (1 << degree),
+		seed: seed & (1<<degree - 1),
+	}
+
+	s.Reset()
+
+	return s
+}
+
+// MakeAdditiveDescrambler creates an additive descrambler. It is the same as the scrambler.
+func MakeAdditiveDescrambler(polynomial, seed uint64) *AdditiveScrambler {
+	return MakeAdditiveScrambler(polynomial, seed)
+}
+
+// Reset restarts the sequence from the seed
+func (s *AdditiveScrambler) Reset() {
+	s.register = s.seed
+}
+
+// GetPolynomial returns the LFSR polynomial
+func (s *AdditiveScrambler) GetPolynomial() uint64 {
+	return s.polynomial
+}
+
+func (s *AdditiveScrambler) Work(input []byte) []byte {
+	var output = make([]byte, s.PredictOutputSize(len(input)))
+	l := s.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (s *AdditiveScrambler) WorkBuffer(input, output []byte) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i, v := range input {
+		output[i] = (v & 1) ^ byte(s.register&1)
+
+		var next = uint64(bits.OnesCount64(s.register&s.taps) & 1)
+		s.register = (s.register >> 1) | (next << (s.degree - 1))
+	}
+
+	return len(input)
+}
+
+func (s *AdditiveScrambler) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+// region Multiplicative
+
+// MultiplicativeScrambler is a self synchronizing scrambler: each output bit is the input bit XOR the output bits
+// delayed by each term of the polynomial. y[n] = x[n] XOR y[n - k] for each term x^k (k > 0).
+type MultiplicativeScrambler struct {
+	polynomial uint64
+	taps       uint64
+	mask       uint64
+	register   uint64
+}
+
+// MakeMultiplicativeScrambler creates a self synchronizing scrambler, with the register cleared
+func MakeMultiplicativeScrambler(polynomial uint64) *MultiplicativeScrambler {
+	var degree = polynomialDegree(polynomial)
+
+	return &MultiplicativeScrambler{
+		polynomial: polynomial,
+		// Bit k - 1 of the register holds y[n - k]
+		taps: polynomial >> 1,
+		mask: 1<<degree - 1,
+	}
+}
+
+// GetPolynomial returns the LFSR polynomial
+func (s *MultiplicativeScrambler) GetPolynomial() uint64 {
+	return s.polynomial
+}
+
+func (s *MultiplicativeScrambler) Work(input []byte) []byte {
+	var output = make([]byte, s.PredictOutputSize(len(input)))
+	l := s.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (s *MultiplicativeScrambler) WorkBuffer(input, output []byte) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i, v := range input {
+		var bit = (v & 1) ^ byte(bits.OnesCount64(s.register&s.taps)&1)
+		output[i] = bit
+		s.register = ((s.register << 1) | uint64(bit)) & s.mask
+	}
+
+	return len(input)
+}
+
+func (s *MultiplicativeScrambler) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// MultiplicativeDescrambler undoes the MultiplicativeScrambler: x[n] = y[n] XOR y[n - k] for each term x^k (k > 0).
+// It synchronizes by itself after degree bits, and each wrong input bit makes one wrong output bit per term.
+type MultiplicativeDescrambler struct {
+	polynomial uint64
+	taps       uint64
+	mask       uint64
+	register   uint64
+}
+
+// MakeMultiplicativeDescrambler creates a self synchronizing descrambler
+func MakeMultiplicativeDescrambler(polynomial uint64) *MultiplicativeDescrambler {
+	var degree = polynomialDegree(polynomial)
+
+	return &MultiplicativeDescrambler{
+		polynomial: polynomial,
+		taps:       polynomial >> 1,
+		mask:       1<<degree - 1,
+	}
+}
+
+// GetPolynomial returns the LFSR polynomial
+func (s *MultiplicativeDescrambler) GetPolynomial() uint64 {
+	return s.polynomial
+}
+
+func (s *MultiplicativeDescrambler) Work(input []byte) []byte {
+	var output = make([]byte, s.PredictOutputSize(len(input)))
+	l := s.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (s *MultiplicativeDescrambler) WorkBuffer(input, output []byte) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i, v := range input {
+		var bit = v & 1
+		output[i] = bit ^ byte(bits.OnesCount64(s.register&s.taps)&1)
+		s.register = ((s.register << 1) | uint64(bit)) & s.mask
+	}
+
+	return len(input)
+}
+
+func (s *MultiplicativeDescrambler) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
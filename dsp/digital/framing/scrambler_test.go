@@ -0,0 +1,88 @@
+package framing
+
+import (
+	"testing"
+)
+
+const g3ruhPolynomial = 0x21001
+const ccsdsPolynomial = 0x1A9
+
+func TestAdditiveScramblerSequence(t *testing.T) {
+	// CCSDS pseudo randomizer sequence
+	var expected = []byte{0xFF, 0x48, 0x0E, 0xC0, 0x9A, 0x0D, 0x70, 0xBC}
+
+	var s = MakeAdditiveScrambler(ccsdsPolynomial, 0xFF)
+	var sequence = PackBits(s.Work(make([]byte, len(expected)*8)), false)
+
+	for i := range expected {
+		if sequence[i] != expected[i] {
+			t.Fatalf("Expected %x got %x", expected, sequence)
+		}
+	}
+
+	// The sequence restarts after Reset
+	s.Reset()
+	sequence = PackBits(s.Work(make([]byte, 8)), false)
+	if sequence[0] != 0xFF {
+		t.Fatalf("Expected the sequence to restart got %x", sequence[0])
+	}
+
+	if s.GetPolynomial() != ccsdsPolynomial {
+		t.Fatalf("Expected polynomial %x got %x", ccsdsPolynomial, s.GetPolynomial())
+	}
+}
+
+func TestAdditiveScrambler(t *testing.T) {
+	var bits = makeTestBits(2000, 5)
+
+	var scrambler = MakeAdditiveScrambler(ccsdsPolynomial, 0xFF)
+	var scrambled = append(scrambler.Work(bits[:777]), scrambler.Work(bits[777:])...)
+
+	if bitErrors(scrambled, bits) == 0 {
+		t.Fatal("Expected the scrambler to change the bits")
+	}
+
+	var descrambled = MakeAdditiveDescrambler(ccsdsPolynomial, 0xFF).Work(scrambled)
+	if bitErrors(descrambled, bits) != 0 {
+		t.Fatal("Expected the descrambled bits to match")
+	}
+}
+
+func TestMultiplicativeScrambler(t *testing.T) {
+	const degree = 17
+
+	var bits = makeTestBits(5000, 6)
+
+	var scrambler = MakeMultiplicativeScrambler(g3ruhPolynomial)
+	var scrambled = append(scrambler.Work(bits[:1234]), scrambler.Work(bits[1234:])...)
+
+	// A long run of zeros should not stay a run of zeros
+	var zeros = scrambler.Work(make([]byte, 1000))
+	var ones = 0
+	for _, v := range zeros {
+		ones += int(v)
+	}
+	if ones < 400 || ones > 600 {
+		t.Fatalf("Expected about half of the scrambled zeros to be ones got %d", ones)
+	}
+
+	var descrambler = MakeMultiplicativeDescrambler(g3ruhPolynomial)
+	var descrambled = append(descrambler.Work(scrambled[:100]), descrambler.Work(scrambled[100:])...)
+	if bitErrors(descrambled, bits) != 0 {
+		t.Fatal("Expected the descrambled bits to match")
+	}
+
+	// Self synchronization: start in the middle of the stream
+	descrambled = MakeMultiplicativeDescrambler(g3ruhPolynomial).Work(scrambled[1000:])
+	if bitErrors(descrambled[degree:], bits[1000+degree:]) != 0 {
+		t.Fatal("Expected the descrambler to synchronize by itself")
+	}
+
+	// One wrong bit makes one wrong bit per polynomial term
+	var corrupted = append([]byte{}, scrambled...)
+	corrupted[2500] ^= 1
+	descrambled = MakeMultiplicativeDescrambler(g3ruhPolynomial).Work(corrupted)
+	if errors := bitErrors(descrambled, bits); errors != 3 {
+		t.Fatalf("Expected 3 wrong bits got %d", errors)
+	}
+}
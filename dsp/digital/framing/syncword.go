@@ -0,0 +1,118 @@
+package framing
+
+import (
+	"math/bits"
+)
+
+const (
+	// SyncWordFlag is set on the first bit after a sync word
+	SyncWordFlag = 0x02
+	// SyncWordInvertedFlag is set on the first bit after an inverted sync word, when enabled
+	SyncWordInvertedFlag = 0x04
+)
+
+// SyncWordDetector correlates the bits with a sync word (like the CCSDS ASM 0x1ACFFC1D), allowing some bit errors.
+// The bits pass through, with SyncWordFlag set on the first bit after each sync word, so the bit value is bit & 1.
+type SyncWordDetector struct {
+	syncWord       uint64
+	length         uint
+	mask           uint64
+	allowedErrors  int
+	detectInverted bool
+
+	register    uint64
+	bitCount    uint
+	pendingFlag byte
+	lastErrors  int
+	detections  int
+}
+
+// MakeSyncWordDetector creates a detector for the length bits (up to 64) sync word, sent most significant bit first.
+// The sync word is detected with up to allowedErrors wrong bits.
+func MakeSyncWordDetector(syncWord uint64, length uint, allowedErrors int) *SyncWordDetector {
+	if length < 1 || length > 64 {
+		panic("Sync Word length should be between 1 and 64 bits")
+	}
+
+	if allowedErrors < 0 || allowedErrors >= int(length) {
+		panic("Sync Word allowed errors should be positive and lower than the length")
+	}
+
+	var mask = ^uint64(0)
+	if length < 64 {
+		mask = 1<<length - 1
+	}
+
+	return &SyncWordDetector{
+		syncWord:      syncWord & mask,
+		length:        length,
+		mask:          mask,
+		allowedErrors: allowedErrors,
+	}
+}
+
+// SetDetectInverted enables the detection of the inverted sync word, flagged with SyncWordInvertedFlag.
+// It finds the frames of signals with a polarity (or BPSK phase) ambiguity.
+func (s *SyncWordDetector) SetDetectInverted(detectInverted bool) {
+	s.detectInverted = detectInverted
+}
+
+// GetLastErrors returns the number of wrong bits of the last detected sync word
+func (s *SyncWordDetector) GetLastErrors() int {
+	return s.lastErrors
+}
+
+// GetDetections returns the number of detected sync words
+func (s *SyncWordDetector) GetDetections() int {
+	return s.detections
+}
+
+func (s *SyncWordDetector) Work(input []byte) []byte {
+	var output = make([]byte, s.PredictOutputSize(len(input)))
+	l := s.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (s *SyncWordDetector) WorkBuffer(input, output []byte) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	// The flag of a sync word at the end of the last call goes to the first bit
+	var flag = s.pendingFlag
+
+	for i, v := range input {
+		v &= 1
+		output[i] = v | flag
+		flag = 0
+
+		s.register = ((s.register << 1) | uint64(v)) & s.mask
+		if s.bitCount < s.length {
+			s.bitCount++
+			if s.bitCount < s.length {
+				continue
+			}
+		}
+
+		var errors = bits.OnesCount64(s.register ^ s.syncWord)
+		if errors <= s.allowedErrors {
+			flag = SyncWordFlag
+		} else if s.detectInverted && int(s.length)-errors <= s.allowedErrors {
+			flag = SyncWordInvertedFlag
+			errors = int(s.length) - errors
+		}
+
+		if flag != 0 {
+			s.lastErrors = errors
+			s.detections++
+		}
+	}
+
+	s.pendingFlag = flag
+
+	return len(input)
+}
+
+func (s *SyncWordDetector) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
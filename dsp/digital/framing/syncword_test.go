@@ -0,0 +1,62 @@
+package framing
+
+import (
+	"testing"
+)
+
+const ccsdsASM = 0x1ACFFC1D
+
+func TestSyncWordDetector(t *testing.T) {
+	var asm = UnpackBits([]byte{0x1A, 0xCF, 0xFC, 0x1D}, false)
+	var bits = makeTestBits(2000, 7)
+
+	// Sync words with 0, 2 and 5 wrong bits, and an inverted one
+	var positions = []int{100, 600, 1100, 1600}
+	var wrongBits = []int{0, 2, 5, 0}
+
+	for i, p := range positions {
+		copy(bits[p:], asm)
+		for j := 0; j < wrongBits[i]; j++ {
+			bits[p+j*3] ^= 1
+		}
+	}
+	for j := range asm {
+		bits[1600+j] ^= 1
+	}
+
+	var detector = MakeSyncWordDetector(ccsdsASM, 32, 3)
+	detector.SetDetectInverted(true)
+
+	// The split at 132 puts the flag of the first sync word on the first bit of the second call
+	var output = append(detector.Work(bits[:132]), detector.Work(bits[132:])...)
+
+	var flags = map[int]byte{}
+	for i, v := range output {
+		if v&1 != bits[i] {
+			t.Fatalf("Expected the bits to pass through")
+		}
+		if v&^1 != 0 {
+			flags[i] = v &^ 1
+		}
+	}
+
+	var expected = map[int]byte{
+		100 + 32:  SyncWordFlag,
+		600 + 32:  SyncWordFlag,
+		1600 + 32: SyncWordInvertedFlag,
+	}
+
+	if len(flags) != len(expected) {
+		t.Fatalf("Expected flags at %v got %v", expected, flags)
+	}
+
+	for p, flag := range expected {
+		if flags[p] != flag {
+			t.Errorf("Expected flag %d at %d got %d", flag, p, flags[p])
+		}
+	}
+
+	if detector.GetDetections() != 3 {
+		t.Errorf("Expected 3 detections got %d", detector.GetDetections())
+	}
+}
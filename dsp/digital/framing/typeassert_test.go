@@ -0,0 +1,29 @@
+package framing
+
+import (
+	"github.com/racerxdl/segdsp/dsp"
+	"testing"
+)
+
+func TestByteWorkers(t *testing.T) {
+	var byteWorkersType = []interface{}{
+		&NRZ{},
+		&NRZIEncoder{},
+		&NRZIDecoder{},
+		&DifferentialEncoder{},
+		&DifferentialDecoder{},
+		&AdditiveScrambler{},
+		&MultiplicativeScrambler{},
+		&MultiplicativeDescrambler{},
+		&HDLCDeframer{},
+		&SyncWordDetector{},
+		&CRC{},
+	}
+
+	for _, v := range byteWorkersType {
+		_, ok := v.(dsp.ByteWorker)
+		if !ok {
+			t.Fatalf("Type %T does not implement ByteWorker type!\n", v)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package digital
+
+import (
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/tools"
+)
+
+// gardnerHistoryMargin is the number of samples the MMSE interpolator needs around the interpolated position
+const gardnerHistoryMargin = iNTAPS / 2
+
+// region Complex Gardner Clock Recovery
+
+// ComplexGardnerClockRecovery recovers the symbol timing with the Gardner timing error detector.
+// It uses the samples at the symbol and half way to the previous symbol, so it does not need the carrier lock and works
+// before the Costas Loop. It needs at least 2 samples per symbol.
+type ComplexGardnerClockRecovery struct {
+	timingLoop
+	interp        *ComplexMMSEFirInterpolator
+	sampleHistory []complex64
+	index         int
+	lastSymbol    complex64
+	tags          dsp.TagPositions
+}
+
+// MakeComplexGardnerClockRecovery creates a Gardner clock recovery for omega samples per symbol, with loopBandwidth
+// (normalized per symbol) and the symbol period allowed to change by omegaRelativeLimit
+func MakeComplexGardnerClockRecovery(omega, loopBandwidth, omegaRelativeLimit float32) *ComplexGardnerClockRecovery {
+	if omega < 2 {
+		panic("Gardner Clock Recovery needs at least 2 samples per symbol")
+	}
+
+	var g = &ComplexGardnerClockRecovery{
+		timingLoop: makeTimingLoop(omega, loopBandwidth, omegaRelativeLimit),
+		interp:     MakeComplexMMSEFirInterpolator(),
+	}
+
+	// Start with a full symbol of history, so the first half symbol can be interpolated
+	g.index = int(g.maxOmega()/2) + gardnerHistoryMargin
+	g.sampleHistory = make([]complex64, g.index)
+
+	return g
+}
+
+// interpolate returns samples[index + mu] for 0 <= mu < 1
+func (g *ComplexGardnerClockRecovery) interpolate(samples []complex64, index int, mu float32) complex64 {
+	return g.interp.Interpolate(samples[index-gardnerHistoryMargin+1:], 1-mu)
+}
+
+func (g *ComplexGardnerClockRecovery) Work(input []complex64) []complex64 {
+	var buff = make([]complex64, g.PredictOutputSize(len(input)))
+	l := g.WorkBuffer(input, buff)
+	return buff[:l]
+}
+
+func (g *ComplexGardnerClockRecovery) WorkBuffer(input, output []complex64) int {
+	var history = len(g.sampleHistory)
+	var samples = append(g.sampleHistory, input...)
+	var outputIndex = 0
+
+	g.tags.Reset()
+
+	for g.index+gardnerHistoryMargin < len(samples) && outputIndex < len(output) {
+		var midPosition = float32(g.index) + g.mu - g.omega/2
+		var midIndex = tools.Floor(midPosition)
+
+		var symbol = g.interpolate(samples, g.index, g.mu)
+		var mid = g.interpolate(samples, int(midIndex), midPosition-midIndex)
+
+		var ted = real((g.lastSymbol - symbol) * tools.Conj(mid))
+		g.lastSymbol = symbol
+
+		output[outputIndex] = symbol
+		outputIndex++
+		g.tags.Add(g.index - history)
+
+		g.index += g.advance(ted)
+	}
+
+	// Keep enough samples for the next mid symbol interpolation
+	var keep = g.index - int(g.maxOmega()/2) - gardnerHistoryMargin
+	if keep < 0 {
+		keep = 0
+	}
+	if keep > len(samples) {
+		keep = len(samples)
+	}
+
+	g.sampleHistory = append(g.sampleHistory[:0], samples[keep:]...)
+	g.index -= keep
+
+	return outputIndex
+}
+
+func (g *ComplexGardnerClockRecovery) PredictOutputSize(inputLength int) int {
+	return inputLength + len(g.sampleHistory)
+}
+
+func (g *ComplexGardnerClockRecovery) MapOffset(offset int) int {
+	return g.tags.MapOffset(offset)
+}
+
+// endregion
+// region Float Gardner Clock Recovery
+
+// FloatGardnerClockRecovery recovers the symbol timing of real symbols (like FSK after the quadrature demodulator)
+// with the Gardner timing error detector. It needs at least 2 samples per symbol.
+type FloatGardnerClockRecovery struct {
+	timingLoop
+	interp        *FloatMMSEFirInterpolator
+	sampleHistory []float32
+	index         int
+	lastSymbol    float32
+	tags          dsp.TagPositions
+}
+
+// MakeFloatGardnerClockRecovery creates a Gardner clock recovery for omega samples per symbol, with loopBandwidth
+// (normalized per symbol) and the symbol period allowed to change by omegaRelativeLimit
+func MakeFloatGardnerClockRecovery(omega, loopBandwidth, omegaRelativeLimit float32) *FloatGardnerClockRecovery {
+	if omega < 2 {
+		panic("Gardner Clock Recovery needs at least 2 samples per symbol")
+	}
+
+	var g = &FloatGardnerClockRecovery{
+		timingLoop: makeTimingLoop(omega, loopBandwidth, omegaRelativeLimit),
+		interp:     MakeFloatMMSEFirInterpolator(),
+	}
+
+	g.index = int(g.maxOmega()/2) + gardnerHistoryMargin
+	g.sampleHistory = make([]float32, g.index)
+
+	return g
+}
+
+// interpolate returns samples[index + mu] for 0 <= mu < 1
+func (g *FloatGardnerClockRecovery) interpolate(samples []float32, index int, mu float32) float32 {
+	return g.interp.Interpolate(samples[index-gardnerHistoryMargin+1:], 1-mu)
+}
+
+func (g *FloatGardnerClockRecovery) Work(input []float32) []float32 {
+	var buff = make([]float32, g.PredictOutputSize(len(input)))
+	l := g.WorkBuffer(input, buff)
+	return buff[:l]
+}
+
+func (g *FloatGardnerClockRecovery) WorkBuffer(input, output []float32) int {
+	var history = len(g.sampleHistory)
+	var samples = append(g.sampleHistory, input...)
+	var outputIndex = 0
+
+	g.tags.Reset()
+
+	for g.index+gardnerHistoryMargin < len(samples) && outputIndex < len(output) {
+		var midPosition = float32(g.index) + g.mu - g.omega/2
+		var midIndex = tools.Floor(midPosition)
+
+		var symbol = g.interpolate(samples, g.index, g.mu)
+		var mid = g.interpolate(samples, int(midIndex), midPosition-midIndex)
+
+		var ted = (g.lastSymbol - symbol) * mid
+		g.lastSymbol = symbol
+
+		output[outputIndex] = symbol
+		outputIndex++
+		g.tags.Add(g.index - history)
+
+		g.index += g.advance(ted)
+	}
+
+	var keep = g.index - int(g.maxOmega()/2) - gardnerHistoryMargin
+	if keep < 0 {
+		keep = 0
+	}
+	if keep > len(samples) {
+		keep = len(samples)
+	}
+
+	g.sampleHistory = append(g.sampleHistory[:0], samples[keep:]...)
+	g.index -= keep
+
+	return outputIndex
+}
+
+func (g *FloatGardnerClockRecovery) PredictOutputSize(inputLength int) int {
+	return inputLength + len(g.sampleHistory)
+}
+
+func (g *FloatGardnerClockRecovery) MapOffset(offset int) int {
+	return g.tags.MapOffset(offset)
+}
+
+// endregion
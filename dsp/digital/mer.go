@@ -0,0 +1,153 @@
+package digital
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+const merDefaultAlpha = 0.01
+
+// region Complex MER Meter
+
+// ComplexMERMeter measures the Modulation Error Ratio and Error Vector Magnitude of PSK / QAM symbols against the decided constellation points.
+// It should run after the clock recovery (one sample per symbol). The symbols pass through unchanged.
+type ComplexMERMeter struct {
+	constellation Constellation
+	alpha         float32
+	errorPower    float32
+	symbolPower   float32
+}
+
+// MakeComplexMERMeter creates a MER meter for the specified constellation
+func MakeComplexMERMeter(constellation Constellation) *ComplexMERMeter {
+	return &ComplexMERMeter{
+		constellation: constellation,
+		alpha:         merDefaultAlpha,
+	}
+}
+
+// SetAlpha sets the averaging factor applied on each symbol
+func (m *ComplexMERMeter) SetAlpha(alpha float32) {
+	m.alpha = alpha
+}
+
+// GetMER returns the current Modulation Error Ratio in dB (0 before the first symbol)
+func (m *ComplexMERMeter) GetMER() float32 {
+	return merFromPower(m.symbolPower, m.errorPower)
+}
+
+// GetEVM returns the current RMS Error Vector Magnitude in percent
+func (m *ComplexMERMeter) GetEVM() float32 {
+	return evmFromPower(m.symbolPower, m.errorPower)
+}
+
+func (m *ComplexMERMeter) Work(input []complex64) []complex64 {
+	var output = make([]complex64, m.PredictOutputSize(len(input)))
+	m.WorkBuffer(input, output)
+	return output
+}
+
+func (m *ComplexMERMeter) WorkBuffer(input, output []complex64) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i, v := range input {
+		var point = m.constellation.Nearest(v)
+		m.errorPower = m.alpha*tools.ComplexAbsSquared(v-point) + (1-m.alpha)*m.errorPower
+		m.symbolPower = m.alpha*tools.ComplexAbsSquared(point) + (1-m.alpha)*m.symbolPower
+		output[i] = v
+	}
+
+	return len(input)
+}
+
+func (m *ComplexMERMeter) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+// region Float MER Meter
+
+// FloatMERMeter measures the Modulation Error Ratio and Error Vector Magnitude of FSK soft symbols against the decided levels.
+// It should run after the clock recovery (one sample per symbol). The symbols pass through unchanged.
+type FloatMERMeter struct {
+	levels      []float32
+	alpha       float32
+	errorPower  float32
+	symbolPower float32
+}
+
+// MakeFloatMERMeter creates a MER meter for the specified symbol levels (for example [-1, 1] for 2FSK)
+func MakeFloatMERMeter(levels []float32) *FloatMERMeter {
+	return &FloatMERMeter{
+		levels: levels,
+		alpha:  merDefaultAlpha,
+	}
+}
+
+// SetAlpha sets the averaging factor applied on each symbol
+func (m *FloatMERMeter) SetAlpha(alpha float32) {
+	m.alpha = alpha
+}
+
+// GetMER returns the current Modulation Error Ratio in dB (0 before the first symbol)
+func (m *FloatMERMeter) GetMER() float32 {
+	return merFromPower(m.symbolPower, m.errorPower)
+}
+
+// GetEVM returns the current RMS Error Vector Magnitude in percent
+func (m *FloatMERMeter) GetEVM() float32 {
+	return evmFromPower(m.symbolPower, m.errorPower)
+}
+
+func (m *FloatMERMeter) Work(input []float32) []float32 {
+	var output = make([]float32, m.PredictOutputSize(len(input)))
+	m.WorkBuffer(input, output)
+	return output
+}
+
+func (m *FloatMERMeter) WorkBuffer(input, output []float32) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i, v := range input {
+		var level = m.levels[0]
+		for _, l := range m.levels[1:] {
+			if tools.Abs(v-l) < tools.Abs(v-level) {
+				level = l
+			}
+		}
+
+		var e = v - level
+		m.errorPower = m.alpha*e*e + (1-m.alpha)*m.errorPower
+		m.symbolPower = m.alpha*level*level + (1-m.alpha)*m.symbolPower
+		output[i] = v
+	}
+
+	return len(input)
+}
+
+func (m *FloatMERMeter) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+
+// merFromPower returns 0 instead of an infinite MER (before the first symbol, or without any error), as the
+// measurements are sent as JSON
+func merFromPower(symbolPower, errorPower float32) float32 {
+	if errorPower == 0 || symbolPower == 0 {
+		return 0
+	}
+	return float32(10 * math.Log10(float64(symbolPower/errorPower)))
+}
+
+func evmFromPower(symbolPower, errorPower float32) float32 {
+	if symbolPower == 0 {
+		return 0
+	}
+	return tools.Sqrt(errorPower/symbolPower) * 100
+}
@@ -0,0 +1,74 @@
+package digital
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestComplexMERMeter(t *testing.T) {
+	var r = rand.New(rand.NewSource(1))
+
+	for _, c := range []Constellation{BPSKConstellation, QPSKConstellation, PSK8Constellation, QAM16Constellation} {
+		// 25 dB MER: error power is 1/316 of the unit symbol power
+		var noiseStd = math.Sqrt(math.Pow(10, -2.5) / 2)
+		var symbols = make([]complex64, 20000)
+
+		for i := range symbols {
+			symbols[i] = c[r.Intn(len(c))] + complex(float32(r.NormFloat64()*noiseStd), float32(r.NormFloat64()*noiseStd))
+		}
+
+		var meter = MakeComplexMERMeter(c)
+		meter.SetAlpha(0.001)
+		var output = meter.Work(symbols)
+
+		if output[100] != symbols[100] {
+			t.Fatal("Expected symbols to pass through the meter")
+		}
+
+		if math.Abs(float64(meter.GetMER()-25)) > 1 {
+			t.Errorf("%d points: Expected MER around 25 dB got %f", len(c), meter.GetMER())
+		}
+
+		var expectedEVM = 100 * math.Pow(10, -25.0/20)
+		if math.Abs(float64(meter.GetEVM())-expectedEVM) > 1 {
+			t.Errorf("%d points: Expected EVM around %f%% got %f", len(c), expectedEVM, meter.GetEVM())
+		}
+	}
+}
+
+func TestFloatMERMeter(t *testing.T) {
+	var r = rand.New(rand.NewSource(1))
+	var levels = []float32{-1, 1}
+	var symbols = make([]float32, 20000)
+
+	for i := range symbols {
+		symbols[i] = levels[r.Intn(2)] + float32(r.NormFloat64()*0.1)
+	}
+
+	var meter = MakeFloatMERMeter(levels)
+	meter.SetAlpha(0.001)
+	meter.Work(symbols)
+
+	if math.Abs(float64(meter.GetMER()-20)) > 1 {
+		t.Fatalf("Expected MER around 20 dB got %f", meter.GetMER())
+	}
+}
+
+func TestMERMeterFinite(t *testing.T) {
+	// Before the first symbol, and over perfect symbols, the MER should stay finite (it is sent as JSON)
+	var complexMeter = MakeComplexMERMeter(QPSKConstellation)
+	var floatMeter = MakeFloatMERMeter([]float32{-1, 1})
+
+	for step := 0; step < 2; step++ {
+		var mer = []float32{complexMeter.GetMER(), floatMeter.GetMER()}
+		for _, v := range mer {
+			if math.IsInf(float64(v), 0) || math.IsNaN(float64(v)) {
+				t.Fatalf("Step %d: Expected a finite MER got %f", step, v)
+			}
+		}
+
+		complexMeter.Work([]complex64{QPSKConstellation[0], QPSKConstellation[1]})
+		floatMeter.Work([]float32{-1, 1})
+	}
+}
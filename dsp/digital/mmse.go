@@ -12,7 +12,7 @@ type ComplexMMSEFirInterpolator struct {
 
 func MakeComplexMMSEFirInterpolator() *ComplexMMSEFirInterpolator {
 	var filters = make([]dsp.FirFilter, iNSTEPS+1)
-	for i := 0; i < iNSTEPS; i++ {
+	for i := 0; i <= iNSTEPS; i++ {
 		var t = make([]float32, len(interpTaps[i]))
 		copy(t, interpTaps[i])
 		filters[i] = *dsp.MakeFirFilter(t)
@@ -50,7 +50,7 @@ type FloatMMSEFirInterpolator struct {
 
 func MakeFloatMMSEFirInterpolator() *FloatMMSEFirInterpolator {
 	var filters = make([]dsp.FloatFirFilter, iNSTEPS+1)
-	for i := 0; i < iNSTEPS; i++ {
+	for i := 0; i <= iNSTEPS; i++ {
 		var t = make([]float32, len(interpTaps[i]))
 		copy(t, interpTaps[i])
 		filters[i] = *dsp.MakeFloatFirFilter(t)
@@ -0,0 +1,252 @@
+package digital
+
+import (
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/tools"
+)
+
+// makePolyphaseTaps splits the prototype taps (at nFilters times the sample rate) into nFilters branches, and the
+// derivative of the prototype into other nFilters branches. Branch q interpolates the sample at q / nFilters.
+// The branch taps are reversed, ready for the dot product with the oldest sample first.
+func makePolyphaseTaps(taps []float32, nFilters int) (filters, diffFilters [][]float32) {
+	var tapsPerFilter = (len(taps) + nFilters - 1) / nFilters
+
+	var prototype = make([]float32, tapsPerFilter*nFilters)
+	copy(prototype, taps)
+
+	var diff = make([]float32, len(prototype))
+	for i := range prototype {
+		var next, previous float32
+		if i+1 < len(prototype) {
+			next = prototype[i+1]
+		}
+		if i > 0 {
+			previous = prototype[i-1]
+		}
+		// Derivative per input sample
+		diff[i] = (next - previous) * float32(nFilters) / 2
+	}
+
+	filters = make([][]float32, nFilters)
+	diffFilters = make([][]float32, nFilters)
+
+	for q := 0; q < nFilters; q++ {
+		filters[q] = make([]float32, tapsPerFilter)
+		diffFilters[q] = make([]float32, tapsPerFilter)
+		for k := 0; k < tapsPerFilter; k++ {
+			filters[q][tapsPerFilter-1-k] = prototype[q+k*nFilters]
+			diffFilters[q][tapsPerFilter-1-k] = diff[q+k*nFilters]
+		}
+	}
+
+	return filters, diffFilters
+}
+
+// region Complex Polyphase Clock Sync
+
+// ComplexPFBClockSync recovers the symbol timing with a polyphase filterbank of the matched filter and its derivative
+// (maximum likelihood timing error detector), so it does both the matched filtering and the interpolation.
+// It should be fed with the samples before the matched filter.
+type ComplexPFBClockSync struct {
+	timingLoop
+	nFilters      int
+	filters       [][]float32
+	diffFilters   [][]float32
+	sampleHistory []complex64
+	index         int
+	tags          dsp.TagPositions
+}
+
+// MakeComplexPFBClockSync creates a polyphase clock sync for omega samples per symbol, with loopBandwidth
+// (normalized per symbol) and the symbol period allowed to change by omegaRelativeLimit.
+// taps is the matched filter designed at nFilters * omega samples per symbol, like
+// dsp.MakeRRC(nFilters, nFilters * omega, 1, 0.35, 11 * nFilters * omega).
+func MakeComplexPFBClockSync(omega, loopBandwidth, omegaRelativeLimit float32, taps []float32, nFilters int) *ComplexPFBClockSync {
+	if nFilters < 1 {
+		panic("PFB Clock Sync needs at least one filter")
+	}
+
+	var filters, diffFilters = makePolyphaseTaps(taps, nFilters)
+
+	var c = &ComplexPFBClockSync{
+		timingLoop:  makeTimingLoop(omega, loopBandwidth, omegaRelativeLimit),
+		nFilters:    nFilters,
+		filters:     filters,
+		diffFilters: diffFilters,
+	}
+
+	c.index = len(filters[0]) - 1
+	c.sampleHistory = make([]complex64, c.index)
+
+	return c
+}
+
+// GetNFilters returns the number of filters in the filterbank
+func (c *ComplexPFBClockSync) GetNFilters() int {
+	return c.nFilters
+}
+
+func (c *ComplexPFBClockSync) Work(input []complex64) []complex64 {
+	var buff = make([]complex64, c.PredictOutputSize(len(input)))
+	l := c.WorkBuffer(input, buff)
+	return buff[:l]
+}
+
+func (c *ComplexPFBClockSync) WorkBuffer(input, output []complex64) int {
+	var history = len(c.sampleHistory)
+	var samples = append(c.sampleHistory, input...)
+	var tapsPerFilter = len(c.filters[0])
+	var outputIndex = 0
+
+	c.tags.Reset()
+
+	for outputIndex < len(output) {
+		var index = c.index
+		var q = int(c.mu*float32(c.nFilters) + 0.5)
+		if q == c.nFilters {
+			q = 0
+			index++
+		}
+
+		if index >= len(samples) {
+			break
+		}
+
+		var window = samples[index-tapsPerFilter+1:]
+		var symbol = dsp.DotProductResult(window, c.filters[q])
+		var diff = dsp.DotProductResult(window, c.diffFilters[q])
+
+		output[outputIndex] = symbol
+		outputIndex++
+		// The matched filter is centered half the window before the newest sample
+		c.tags.Add(index - (tapsPerFilter-1)/2 - history)
+
+		c.index += c.advance(real(symbol * tools.Conj(diff)))
+	}
+
+	var keep = c.index - tapsPerFilter + 1
+	if keep < 0 {
+		keep = 0
+	}
+	if keep > len(samples) {
+		keep = len(samples)
+	}
+
+	c.sampleHistory = append(c.sampleHistory[:0], samples[keep:]...)
+	c.index -= keep
+
+	return outputIndex
+}
+
+func (c *ComplexPFBClockSync) PredictOutputSize(inputLength int) int {
+	return inputLength + len(c.sampleHistory)
+}
+
+func (c *ComplexPFBClockSync) MapOffset(offset int) int {
+	return c.tags.MapOffset(offset)
+}
+
+// endregion
+// region Float Polyphase Clock Sync
+
+// FloatPFBClockSync recovers the symbol timing of real symbols with a polyphase filterbank of the matched filter
+// and its derivative. It should be fed with the samples before the matched filter.
+type FloatPFBClockSync struct {
+	timingLoop
+	nFilters      int
+	filters       [][]float32
+	diffFilters   [][]float32
+	sampleHistory []float32
+	index         int
+	tags          dsp.TagPositions
+}
+
+// MakeFloatPFBClockSync creates a polyphase clock sync for omega samples per symbol, with loopBandwidth
+// (normalized per symbol) and the symbol period allowed to change by omegaRelativeLimit.
+// taps is the matched filter designed at nFilters * omega samples per symbol.
+func MakeFloatPFBClockSync(omega, loopBandwidth, omegaRelativeLimit float32, taps []float32, nFilters int) *FloatPFBClockSync {
+	if nFilters < 1 {
+		panic("PFB Clock Sync needs at least one filter")
+	}
+
+	var filters, diffFilters = makePolyphaseTaps(taps, nFilters)
+
+	var c = &FloatPFBClockSync{
+		timingLoop:  makeTimingLoop(omega, loopBandwidth, omegaRelativeLimit),
+		nFilters:    nFilters,
+		filters:     filters,
+		diffFilters: diffFilters,
+	}
+
+	c.index = len(filters[0]) - 1
+	c.sampleHistory = make([]float32, c.index)
+
+	return c
+}
+
+// GetNFilters returns the number of filters in the filterbank
+func (c *FloatPFBClockSync) GetNFilters() int {
+	return c.nFilters
+}
+
+func (c *FloatPFBClockSync) Work(input []float32) []float32 {
+	var buff = make([]float32, c.PredictOutputSize(len(input)))
+	l := c.WorkBuffer(input, buff)
+	return buff[:l]
+}
+
+func (c *FloatPFBClockSync) WorkBuffer(input, output []float32) int {
+	var history = len(c.sampleHistory)
+	var samples = append(c.sampleHistory, input...)
+	var tapsPerFilter = len(c.filters[0])
+	var outputIndex = 0
+
+	c.tags.Reset()
+
+	for outputIndex < len(output) {
+		var index = c.index
+		var q = int(c.mu*float32(c.nFilters) + 0.5)
+		if q == c.nFilters {
+			q = 0
+			index++
+		}
+
+		if index >= len(samples) {
+			break
+		}
+
+		var window = samples[index-tapsPerFilter+1:]
+		var symbol = dsp.DotProductFloatResult(window, c.filters[q])
+		var diff = dsp.DotProductFloatResult(window, c.diffFilters[q])
+
+		output[outputIndex] = symbol
+		outputIndex++
+		// The matched filter is centered half the window before the newest sample
+		c.tags.Add(index - (tapsPerFilter-1)/2 - history)
+
+		c.index += c.advance(symbol * diff)
+	}
+
+	var keep = c.index - tapsPerFilter + 1
+	if keep < 0 {
+		keep = 0
+	}
+	if keep > len(samples) {
+		keep = len(samples)
+	}
+
+	c.sampleHistory = append(c.sampleHistory[:0], samples[keep:]...)
+	c.index -= keep
+
+	return outputIndex
+}
+
+func (c *FloatPFBClockSync) PredictOutputSize(inputLength int) int {
+	return inputLength + len(c.sampleHistory)
+}
+
+func (c *FloatPFBClockSync) MapOffset(offset int) int {
+	return c.tags.MapOffset(offset)
+}
+
+// endregion
@@ -0,0 +1,135 @@
+package digital
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+const defaultTimingDampingFactor = math.Sqrt2 / 2
+
+// timingLoop is the second order loop of the symbol synchronizers. omega is the symbol period in samples and
+// mu the fractional sample position of the next symbol. A positive timing error means the symbol was sampled early.
+type timingLoop struct {
+	mu                 float32
+	omega              float32
+	gainMu             float32
+	gainOmega          float32
+	omegaRelativeLimit float32
+	omegaMidValue      float32
+	omegaLimit         float32
+	loopBandwidth      float32
+	dampingFactor      float32
+}
+
+func makeTimingLoop(omega, loopBandwidth, omegaRelativeLimit float32) timingLoop {
+	if omega <= 0 {
+		panic("Clock Recovery Rate (omega) must be higher than 0")
+	}
+
+	if loopBandwidth < 0 {
+		panic("Clock Recovery loop bandwidth should be positive.")
+	}
+
+	var l = timingLoop{
+		omegaRelativeLimit: omegaRelativeLimit,
+		loopBandwidth:      loopBandwidth,
+		dampingFactor:      defaultTimingDampingFactor,
+	}
+
+	l.SetOmega(omega)
+	l.updateGains()
+
+	return l
+}
+
+func (l *timingLoop) updateGains() {
+	var denom = 1 + 2*l.dampingFactor*l.loopBandwidth + l.loopBandwidth*l.loopBandwidth
+	l.gainMu = (4 * l.dampingFactor * l.loopBandwidth) / denom
+	l.gainOmega = (4 * l.loopBandwidth * l.loopBandwidth) / denom
+}
+
+func (l *timingLoop) GetMu() float32 {
+	return l.mu
+}
+
+func (l *timingLoop) GetOmega() float32 {
+	return l.omega
+}
+
+func (l *timingLoop) GetGainMu() float32 {
+	return l.gainMu
+}
+
+func (l *timingLoop) GetGainOmega() float32 {
+	return l.gainOmega
+}
+
+func (l *timingLoop) SetGainMu(gain float32) {
+	l.gainMu = gain
+}
+
+func (l *timingLoop) SetGainOmega(gain float32) {
+	l.gainOmega = gain
+}
+
+func (l *timingLoop) SetMu(mu float32) {
+	l.mu = mu
+}
+
+func (l *timingLoop) SetOmega(omega float32) {
+	l.omega = omega
+	l.omegaMidValue = omega
+	l.omegaLimit = l.omegaRelativeLimit * omega
+}
+
+// GetLoopBandwidth returns the normalized loop bandwidth (per symbol)
+func (l *timingLoop) GetLoopBandwidth() float32 {
+	return l.loopBandwidth
+}
+
+// SetLoopBandwidth sets the normalized loop bandwidth (per symbol) and recomputes the gains
+func (l *timingLoop) SetLoopBandwidth(bw float32) {
+	if bw < 0 {
+		panic("Clock Recovery loop bandwidth should be positive.")
+	}
+
+	l.loopBandwidth = bw
+	l.updateGains()
+}
+
+// GetDampingFactor returns the loop damping factor
+func (l *timingLoop) GetDampingFactor() float32 {
+	return l.dampingFactor
+}
+
+// SetDampingFactor sets the loop damping factor (sqrt(2) / 2 by default) and recomputes the gains
+func (l *timingLoop) SetDampingFactor(df float32) {
+	if df <= 0 {
+		panic("Clock Recovery damping factor should be higher than 0.")
+	}
+
+	l.dampingFactor = df
+	l.updateGains()
+}
+
+// maxOmega returns the longest symbol period the loop can reach
+func (l *timingLoop) maxOmega() float32 {
+	return l.omegaMidValue + l.omegaLimit
+}
+
+// advance updates the loop with the timing error and moves mu to the next symbol.
+// It returns how many whole samples the next symbol is ahead.
+func (l *timingLoop) advance(err float32) int {
+	err = tools.Clip(err, 1)
+
+	l.omega = l.omega + l.gainOmega*err
+	l.omega = l.omegaMidValue + tools.Clip(l.omega-l.omegaMidValue, l.omegaLimit)
+
+	l.mu = l.mu + l.omega + l.gainMu*err
+
+	var step = tools.Floor(l.mu)
+	l.mu -= step
+
+	return int(step)
+}
@@ -9,6 +9,12 @@ import (
 func TestComplexWorkers(t *testing.T) {
 	var complexWorkersType = []interface{}{
 		&ComplexClockRecovery{},
+		&ComplexMERMeter{},
+		&LinearEqualizer{},
+		&ComplexGardnerClockRecovery{},
+		&ComplexPFBClockSync{},
+		&ConstellationTap{},
+		&EyeTap{},
 	}
 
 	for _, v := range complexWorkersType {
@@ -22,6 +28,9 @@ func TestComplexWorkers(t *testing.T) {
 func TestFloat32Workers(t *testing.T) {
 	var floatWorkersType = []interface{}{
 		&FloatClockRecovery{},
+		&FloatMERMeter{},
+		&FloatGardnerClockRecovery{},
+		&FloatPFBClockSync{},
 	}
 
 	for _, v := range floatWorkersType {
@@ -56,3 +65,21 @@ func TestFloat322ByteWorkers(t *testing.T) {
 		}
 	}
 }
+
+func TestTagOffsetMappers(t *testing.T) {
+	var mappersType = []interface{}{
+		&ComplexClockRecovery{},
+		&ComplexGardnerClockRecovery{},
+		&ComplexPFBClockSync{},
+		&FloatClockRecovery{},
+		&FloatGardnerClockRecovery{},
+		&FloatPFBClockSync{},
+	}
+
+	for _, v := range mappersType {
+		_, ok := v.(dsp.TagOffsetMapper)
+		if !ok {
+			t.Fatalf("Type %T does not implement TagOffsetMapper type!\n", v)
+		}
+	}
+}
@@ -24,3 +24,60 @@ func BenchmarkFFT(b *testing.B) {
 		FFT(a)
 	}
 }
+
+func benchmarkNonPowerOf2(b *testing.B, fft func([]complex64) []complex64) {
+	b.StopTimer()
+
+	N := 3000
+	a := make([]complex64, N)
+	for i := 0; i < N; i++ {
+		a[i] = complex(float32(i)/float32(N), 0)
+	}
+
+	fft(a)
+
+	b.StartTimer()
+
+	for i := 0; i < b.N; i++ {
+		fft(a)
+	}
+}
+
+func BenchmarkMixedRadixFFT(b *testing.B) {
+	benchmarkNonPowerOf2(b, mixedRadixFFT)
+}
+
+func BenchmarkBluesteinFFT(b *testing.B) {
+	benchmarkNonPowerOf2(b, bluesteinFFT)
+}
+
+func BenchmarkPlan(b *testing.B) {
+	N := 4096
+	a := make([]complex64, N)
+	for i := 0; i < N; i++ {
+		a[i] = complex(float32(i)/float32(N), 0)
+	}
+
+	plan := MakePlan(N, Forward)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		plan.ExecuteInPlace(a)
+	}
+}
+
+func BenchmarkRealPlan(b *testing.B) {
+	N := 4096
+	a := make([]float32, N)
+	for i := 0; i < N; i++ {
+		a[i] = float32(i) / float32(N)
+	}
+
+	plan := MakeRealPlan(N)
+	output := make([]complex64, plan.GetOutputLength())
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		plan.Execute(a, output)
+	}
+}
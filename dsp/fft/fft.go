@@ -21,7 +21,22 @@ import "github.com/racerxdl/segdsp/tools"
 
 // FFTReal returns the forward FFT of the real-valued slice.
 func FFTReal(x []float32) []complex64 {
-	return FFT(tools.ToComplex64Array(x))
+	lx := len(x)
+	r := make([]complex64, lx)
+
+	if lx == 0 {
+		return r
+	}
+
+	// Only the first half is computed, the rest is the conjugate of it
+	p := acquireRealPlan(lx)
+	p.Execute(x, r)
+	releaseRealPlan(p)
+	for i := lx/2 + 1; i < lx; i++ {
+		r[i] = complex(real(r[lx-i]), -imag(r[lx-i]))
+	}
+
+	return r
 }
 
 // IFFTReal returns the inverse FFT of the real-valued slice.
@@ -31,22 +46,7 @@ func IFFTReal(x []float32) []complex64 {
 
 // IFFT returns the inverse FFT of the complex-valued slice.
 func IFFT(x []complex64) []complex64 {
-	lx := len(x)
-	r := make([]complex64, lx)
-
-	// Reverse inputs, which is calculated with modulo N, hence x[0] as an outlier
-	r[0] = x[0]
-	for i := 1; i < lx; i++ {
-		r[i] = x[lx-i]
-	}
-
-	r = FFT(r)
-
-	N := complex(float32(lx), 0)
-	for n := range r {
-		r[n] /= N
-	}
-	return r
+	return executePlan(x, Backward)
 }
 
 // Convolve returns the convolution of x ∗ y.
@@ -68,20 +68,23 @@ func Convolve(x, y []complex64) []complex64 {
 
 // FFT returns the forward FFT of the complex-valued slice.
 func FFT(x []complex64) []complex64 {
+	return executePlan(x, Forward)
+}
+
+// executePlan returns the transform of x with a cached plan
+func executePlan(x []complex64, direction Direction) []complex64 {
 	lx := len(x)
+	r := make([]complex64, lx)
 
-	// todo: non-hack handling length <= 1 cases
-	if lx <= 1 {
-		r := make([]complex64, lx)
-		copy(r, x)
+	if lx == 0 {
 		return r
 	}
 
-	if tools.IsPowerOf2(lx) {
-		return radix2FFT(x)
-	}
+	p := acquirePlan(lx, direction)
+	p.Execute(x, r)
+	releasePlan(p)
 
-	return bluesteinFFT(x)
+	return r
 }
 
 // FFT2Real returns the 2-dimensional, forward FFT of the real-valued matrix.
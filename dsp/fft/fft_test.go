@@ -17,8 +17,12 @@
 package fft
 
 import (
-	"github.com/racerxdl/segdsp/tools"
+	"fmt"
+	"math"
+	"math/rand"
 	"testing"
+
+	"github.com/racerxdl/segdsp/tools"
 )
 
 // region FFT Test Helper Structs
@@ -158,7 +162,7 @@ var fft2Tests = []fft2Test{
 		[][]float32{{0.1, 0.2, 0.3, 0.4, 0.5}, {1, 2, 3, 4, 5}, {3, 2, 1, 0, -1}},
 		[][]complex64{
 			{complex(21.5, 0), complex(-0.25, 0.34409547), complex(-0.25, 0.08122992), complex(-0.25, -0.08122992), complex(-0.25, -0.34409548)},
-			{complex(-8.5, -8.66025404), complex(5.70990854, 4.6742225), complex(1.156942, 4.411357), complex(-1.65694356, 4.24889709), complex(-6.20990854, 3.98603154)},
+			{complex(-8.5, -8.66025404), complex(5.70990854, 4.6742225), complex(1.15694356, 4.41135694), complex(-1.65694356, 4.24889709), complex(-6.20990854, 3.98603154)},
 			{complex(-8.5, 8.66025404), complex(-6.2099085, -3.9860315), complex(-1.65694356, -4.24889709), complex(1.15694356, -4.411357), complex(5.70990854, -4.6742225)}},
 	},
 }
@@ -222,3 +226,212 @@ func TestFFTMulti(t *testing.T) {
 
 	FFT(a)
 }
+
+// mixedRadixLengths are non power of two lengths (with 2, 3, 4, 5 and generic prime radices) cross-checked against Bluestein
+var mixedRadixLengths = []int{3, 5, 6, 7, 9, 10, 12, 15, 18, 30, 49, 96, 100, 121, 360, 1000, 1536, 3000, 29 * 31}
+
+func TestFactorize(t *testing.T) {
+	for _, length := range append(mixedRadixLengths, 37, 2*37, 1009) {
+		var factors, smooth = factorize(length)
+		var product = 1
+		var maxFactor = 0
+
+		for _, f := range factors {
+			product *= f
+			if f > maxFactor {
+				maxFactor = f
+			}
+		}
+
+		if product != length {
+			t.Errorf("Factors %v of %d multiply to %d", factors, length, product)
+		}
+
+		if smooth != (maxFactor <= maxMixedRadixPrime) {
+			t.Errorf("Length %d (factors %v): expected smooth %t", length, factors, !smooth)
+		}
+	}
+}
+
+func TestMixedRadixFFT(t *testing.T) {
+	for _, length := range mixedRadixLengths {
+		if !isMixedRadixLength(length) {
+			t.Fatalf("Expected %d to be a mixed-radix length", length)
+		}
+
+		var x = make([]complex64, length)
+		for i := range x {
+			x[i] = complex(rand.Float32()*2-1, rand.Float32()*2-1)
+		}
+
+		var expected = bluesteinFFT(x)
+		var got = FFT(x)
+
+		// float32 rounding grows with the transform size
+		var tolerance = 1e-6 * float64(length)
+		for i := range expected {
+			var err = math.Hypot(float64(real(expected[i]-got[i])), float64(imag(expected[i]-got[i])))
+			if err > tolerance {
+				t.Fatalf("Length %d: expected %v got %v at %d", length, expected[i], got[i], i)
+			}
+		}
+
+		var inverse = IFFT(got)
+		for i := range x {
+			var err = math.Hypot(float64(real(x[i]-inverse[i])), float64(imag(x[i]-inverse[i])))
+			if err > 1e-5 {
+				t.Fatalf("Length %d: IFFT expected %v got %v at %d", length, x[i], inverse[i], i)
+			}
+		}
+	}
+}
+
+// dft returns the direct DFT of x, computed in float64
+func dft(x []complex64) []complex128 {
+	var n = len(x)
+	var r = make([]complex128, n)
+
+	for k := range r {
+		for i, v := range x {
+			var sin, cos = math.Sincos(-2 * math.Pi * float64((i*k)%n) / float64(n))
+			r[k] += complex128(v) * complex(cos, sin)
+		}
+	}
+
+	return r
+}
+
+func makeRandomComplex(length int) []complex64 {
+	var x = make([]complex64, length)
+	for i := range x {
+		x[i] = complex(rand.Float32()*2-1, rand.Float32()*2-1)
+	}
+	return x
+}
+
+func checkClose(t *testing.T, name string, expected []complex128, got []complex64, tolerance float64) {
+	for i := range expected {
+		var err = math.Hypot(real(expected[i])-float64(real(got[i])), imag(expected[i])-float64(imag(got[i])))
+		if err > tolerance {
+			t.Fatalf("%s: expected %v got %v at %d", name, expected[i], got[i], i)
+		}
+	}
+}
+
+// planLengths covers the trivial, radix 2, mixed-radix and Bluestein plans
+var planLengths = []int{1, 2, 4, 8, 256, 3, 12, 1000, 37, 2 * 37, 1009}
+
+func TestPlan(t *testing.T) {
+	for _, length := range planLengths {
+		var x = makeRandomComplex(length)
+		var expected = dft(x)
+		var tolerance = 1e-6 * float64(length+1)
+
+		var forward = MakePlan(length, Forward)
+		var output = make([]complex64, length)
+		forward.Execute(x, output)
+		checkClose(t, fmt.Sprintf("Forward %d", length), expected, output, tolerance)
+
+		var inPlace = make([]complex64, length)
+		copy(inPlace, x)
+		forward.ExecuteInPlace(inPlace)
+		checkClose(t, fmt.Sprintf("In place %d", length), expected, inPlace, tolerance)
+
+		var backward = MakePlan(length, Backward)
+		backward.ExecuteInPlace(inPlace)
+
+		var original = make([]complex128, length)
+		for i, v := range x {
+			original[i] = complex128(v)
+		}
+		checkClose(t, fmt.Sprintf("Backward %d", length), original, inPlace, 1e-5)
+	}
+}
+
+func TestPlanMatchesRadix2(t *testing.T) {
+	var x = makeRandomComplex(1 << 12)
+	var expected = make([]complex128, len(x))
+	var got = make([]complex64, len(x))
+
+	for i, v := range radix2FFT(x) {
+		expected[i] = complex128(v)
+	}
+
+	MakePlan(len(x), Forward).Execute(x, got)
+	checkClose(t, "Radix 2", expected, got, 1e-6*float64(len(x)))
+}
+
+func TestRealPlan(t *testing.T) {
+	for _, length := range []int{1, 2, 7, 8, 1000, 1001, 2 * 37, 4096} {
+		var x = make([]float32, length)
+		var xc = make([]complex64, length)
+		for i := range x {
+			x[i] = rand.Float32()*2 - 1
+			xc[i] = complex(x[i], 0)
+		}
+
+		var plan = MakeRealPlan(length)
+		if plan.GetOutputLength() != length/2+1 {
+			t.Fatalf("Expected %d bins got %d", length/2+1, plan.GetOutputLength())
+		}
+
+		var output = make([]complex64, plan.GetOutputLength())
+		plan.Execute(x, output)
+
+		var expected = dft(xc)[:plan.GetOutputLength()]
+		checkClose(t, fmt.Sprintf("Real %d", length), expected, output, 1e-6*float64(length+1))
+	}
+}
+
+func TestPlanBatch(t *testing.T) {
+	const length = 1000
+	const frames = 17
+
+	var input = makeRandomComplex(length * frames)
+	var output = make([]complex64, len(input))
+	var plan = MakePlan(length, Forward)
+
+	defer SetWorkerPoolSize(0)
+
+	for _, workers := range []int{1, 4} {
+		SetWorkerPoolSize(workers)
+		plan.ExecuteBatch(input, output)
+
+		var expected = make([]complex64, length)
+		for f := 0; f < frames; f++ {
+			plan.Execute(input[f*length:(f+1)*length], expected)
+			for i := range expected {
+				if expected[i] != output[f*length+i] {
+					t.Fatalf("%d workers: frame %d expected %v got %v at %d", workers, f, expected[i], output[f*length+i], i)
+				}
+			}
+		}
+	}
+}
+
+func TestPlanAllocations(t *testing.T) {
+	for _, length := range []int{1024, 1000, 1009} {
+		for _, direction := range []Direction{Forward, Backward} {
+			var plan = MakePlan(length, direction)
+			var data = makeRandomComplex(length)
+			var output = make([]complex64, length)
+
+			var allocs = testing.AllocsPerRun(10, func() {
+				plan.Execute(data, output)
+				plan.ExecuteInPlace(output)
+			})
+
+			if allocs != 0 {
+				t.Errorf("%s plan of %d: expected no allocations got %f", direction, length, allocs)
+			}
+		}
+	}
+
+	var realPlan = MakeRealPlan(1000)
+	var input = make([]float32, 1000)
+	var output = make([]complex64, realPlan.GetOutputLength())
+
+	if allocs := testing.AllocsPerRun(10, func() { realPlan.Execute(input, output) }); allocs != 0 {
+		t.Errorf("Real plan: expected no allocations got %f", allocs)
+	}
+}
@@ -0,0 +1,266 @@
+package fft
+
+import (
+	"math"
+	"sync"
+)
+
+// maxMixedRadixPrime is the largest prime factor the mixed-radix FFT handles with the generic butterfly.
+// Lengths with larger prime factors use Bluestein, as the generic butterfly costs O(p) per sample for each prime p.
+const maxMixedRadixPrime = 31
+
+var (
+	mixedRadixLock  sync.RWMutex
+	mixedRadixPlans = map[int]*mixedRadixPlan{}
+)
+
+// mixedRadixPlan holds the factors and twiddles of a mixed-radix FFT length
+type mixedRadixPlan struct {
+	length   int
+	factors  []int
+	twiddles []complex64
+}
+
+// factorize returns the radices for length (4s first, then 2, 3, 5 and the remaining primes) and if all of them are
+// at most maxMixedRadixPrime
+func factorize(length int) ([]int, bool) {
+	var factors []int
+	var smooth = true
+
+	for length%4 == 0 && length > 4 {
+		factors = append(factors, 4)
+		length /= 4
+	}
+
+	for p := 2; length > 1; {
+		if length%p == 0 {
+			factors = append(factors, p)
+			length /= p
+			continue
+		}
+
+		if p == 2 {
+			p = 3
+		} else {
+			p += 2
+		}
+
+		if p*p > length {
+			p = length
+		}
+	}
+
+	for _, f := range factors {
+		if f > maxMixedRadixPrime {
+			smooth = false
+		}
+	}
+
+	return factors, smooth
+}
+
+// isMixedRadixLength returns if length factors into primes up to maxMixedRadixPrime
+func isMixedRadixLength(length int) bool {
+	var _, smooth = factorize(length)
+	return smooth
+}
+
+func getMixedRadixPlan(length int) *mixedRadixPlan {
+	mixedRadixLock.RLock()
+	var plan = mixedRadixPlans[length]
+	mixedRadixLock.RUnlock()
+
+	if plan != nil {
+		return plan
+	}
+
+	mixedRadixLock.Lock()
+	defer mixedRadixLock.Unlock()
+
+	if plan = mixedRadixPlans[length]; plan == nil {
+		plan = &mixedRadixPlan{
+			length:   length,
+			twiddles: make([]complex64, length),
+		}
+		plan.factors, _ = factorize(length)
+
+		for i := range plan.twiddles {
+			var sin, cos = math.Sincos(-2 * math.Pi * float64(i) / float64(length))
+			plan.twiddles[i] = complex(float32(cos), float32(sin))
+		}
+
+		mixedRadixPlans[length] = plan
+	}
+
+	return plan
+}
+
+// mixedRadixFFT returns the FFT calculated using the mixed-radix (2, 3, 4, 5 and generic prime) Cooley-Tukey algorithm.
+func mixedRadixFFT(x []complex64) []complex64 {
+	var plan = getMixedRadixPlan(len(x))
+	var r = make([]complex64, len(x))
+	var scratch = make([]complex64, plan.factors[0])
+
+	for _, f := range plan.factors {
+		if f > len(scratch) {
+			scratch = make([]complex64, f)
+		}
+	}
+
+	plan.work(r, x, 1, 1, plan.factors, scratch)
+
+	return r
+}
+
+// work computes the DFT of x[0], x[stride], x[2*stride] ... into output.
+// fstride is the twiddle step for the current sub-transform.
+func (p *mixedRadixPlan) work(output, x []complex64, stride, fstride int, factors []int, scratch []complex64) {
+	var radix = factors[0]
+	var m = len(output) / radix
+
+	if m == 1 {
+		for q := 0; q < radix; q++ {
+			output[q] = x[q*stride]
+		}
+	} else {
+		// Decimation in time: each of the radix sub-transforms takes every radix-th sample
+		for q := 0; q < radix; q++ {
+			p.work(output[q*m:(q+1)*m], x[q*stride:], stride*radix, fstride*radix, factors[1:], scratch)
+		}
+	}
+
+	switch radix {
+	case 2:
+		p.butterfly2(output, fstride, m)
+	case 3:
+		p.butterfly3(output, fstride, m)
+	case 4:
+		p.butterfly4(output, fstride, m)
+	case 5:
+		p.butterfly5(output, fstride, m)
+	default:
+		p.butterflyGeneric(output, fstride, m, radix, scratch)
+	}
+}
+
+func (p *mixedRadixPlan) butterfly2(output []complex64, fstride, m int) {
+	var tw = p.twiddles
+
+	for k := 0; k < m; k++ {
+		var t = output[k+m] * tw[k*fstride]
+		output[k+m] = output[k] - t
+		output[k] += t
+	}
+}
+
+func (p *mixedRadixPlan) butterfly3(output []complex64, fstride, m int) {
+	var tw = p.twiddles
+	var epi3 = imag(tw[fstride*m])
+
+	for k := 0; k < m; k++ {
+		var s1 = output[k+m] * tw[k*fstride]
+		var s2 = output[k+2*m] * tw[2*k*fstride]
+		var s3 = s1 + s2
+		var s0 = s1 - s2
+
+		var a = output[k] - s3*0.5
+		s0 *= complex(epi3, 0)
+		output[k] += s3
+
+		output[k+2*m] = complex(real(a)+imag(s0), imag(a)-real(s0))
+		output[k+m] = complex(real(a)-imag(s0), imag(a)+real(s0))
+	}
+}
+
+func (p *mixedRadixPlan) butterfly4(output []complex64, fstride, m int) {
+	var tw = p.twiddles
+
+	for k := 0; k < m; k++ {
+		var s0 = output[k+m] * tw[k*fstride]
+		var s1 = output[k+2*m] * tw[2*k*fstride]
+		var s2 = output[k+3*m] * tw[3*k*fstride]
+
+		var s5 = output[k] - s1
+		var f0 = output[k] + s1
+		var s3 = s0 + s2
+		var s4 = s0 - s2
+
+		output[k+2*m] = f0 - s3
+		output[k] = f0 + s3
+		output[k+m] = complex(real(s5)+imag(s4), imag(s5)-real(s4))
+		output[k+3*m] = complex(real(s5)-imag(s4), imag(s5)+real(s4))
+	}
+}
+
+func (p *mixedRadixPlan) butterfly5(output []complex64, fstride, m int) {
+	var tw = p.twiddles
+	var ya = tw[fstride*m]
+	var yb = tw[fstride*2*m]
+
+	for k := 0; k < m; k++ {
+		var s0 = output[k]
+		var s1 = output[k+m] * tw[k*fstride]
+		var s2 = output[k+2*m] * tw[2*k*fstride]
+		var s3 = output[k+3*m] * tw[3*k*fstride]
+		var s4 = output[k+4*m] * tw[4*k*fstride]
+
+		var s7 = s1 + s4
+		var s10 = s1 - s4
+		var s8 = s2 + s3
+		var s9 = s2 - s3
+
+		output[k] = s0 + s7 + s8
+
+		var s5 = complex(
+			real(s0)+real(s7)*real(ya)+real(s8)*real(yb),
+			imag(s0)+imag(s7)*real(ya)+imag(s8)*real(yb),
+		)
+		var s6 = complex(
+			imag(s10)*imag(ya)+imag(s9)*imag(yb),
+			-real(s10)*imag(ya)-real(s9)*imag(yb),
+		)
+
+		output[k+m] = s5 - s6
+		output[k+4*m] = s5 + s6
+
+		var s11 = complex(
+			real(s0)+real(s7)*real(yb)+real(s8)*real(ya),
+			imag(s0)+imag(s7)*real(yb)+imag(s8)*real(ya),
+		)
+		var s12 = complex(
+			-imag(s10)*imag(yb)+imag(s9)*imag(ya),
+			real(s10)*imag(yb)-real(s9)*imag(ya),
+		)
+
+		output[k+2*m] = s11 + s12
+		output[k+3*m] = s11 - s12
+	}
+}
+
+// butterflyGeneric computes a radix butterfly with the direct DFT, for prime radices without a specialized version
+func (p *mixedRadixPlan) butterflyGeneric(output []complex64, fstride, m, radix int, scratch []complex64) {
+	var tw = p.twiddles
+	var length = p.length
+
+	for u := 0; u < m; u++ {
+		for q := 0; q < radix; q++ {
+			scratch[q] = output[u+q*m]
+		}
+
+		for q1 := 0; q1 < radix; q1++ {
+			var k = u + q1*m
+			var sum = scratch[0]
+			var twIdx = 0
+
+			for q := 1; q < radix; q++ {
+				twIdx += fstride * k
+				if twIdx >= length {
+					twIdx -= length
+				}
+				sum += scratch[q] * tw[twIdx]
+			}
+
+			output[k] = sum
+		}
+	}
+}
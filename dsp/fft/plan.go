@@ -0,0 +1,477 @@
+package fft
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+// Direction is the direction of a Plan transform
+type Direction int
+
+const (
+	// Forward is the forward transform, like FFT
+	Forward Direction = iota
+	// Backward is the inverse transform normalized by 1/N, like IFFT
+	Backward
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Forward:
+		return "Forward"
+	case Backward:
+		return "Backward"
+	}
+
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+type planAlgorithm int
+
+const (
+	planTrivial planAlgorithm = iota
+	planRadix2
+	planMixedRadix
+	planBluestein
+)
+
+// Plan is a FFT of a fixed size and direction. All tables and work buffers are allocated when the plan is created,
+// so executing it does not allocate. A Plan is not safe for concurrent use, except through ExecuteBatch.
+type Plan struct {
+	length    int
+	direction Direction
+	algorithm planAlgorithm
+
+	// radix 2
+	twiddles []complex64
+	bitrev   []int
+
+	// mixed radix
+	mixed     *mixedRadixPlan
+	butterfly []complex64
+
+	// bluestein
+	chirp        []complex64
+	chirpFFT     []complex64
+	bluesteinFFT *Plan
+
+	scratch []complex64
+	workers []*Plan
+}
+
+// MakePlan creates a FFT plan of length samples in the direction
+func MakePlan(length int, direction Direction) *Plan {
+	if length < 1 {
+		panic("FFT Plan length should be at least 1")
+	}
+
+	var p = &Plan{
+		length:    length,
+		direction: direction,
+	}
+
+	switch {
+	case length == 1:
+		p.algorithm = planTrivial
+	case tools.IsPowerOf2(length):
+		p.algorithm = planRadix2
+		p.initRadix2()
+	case isMixedRadixLength(length):
+		p.algorithm = planMixedRadix
+		p.initMixedRadix()
+	default:
+		p.algorithm = planBluestein
+		p.initBluestein()
+	}
+
+	return p
+}
+
+// GetLength returns the transform length
+func (p *Plan) GetLength() int {
+	return p.length
+}
+
+// GetDirection returns the transform direction
+func (p *Plan) GetDirection() Direction {
+	return p.direction
+}
+
+// Execute computes the transform of the first GetLength() samples of input into output.
+// input and output can be the same slice.
+func (p *Plan) Execute(input, output []complex64) {
+	if len(input) < p.length || len(output) < p.length {
+		panic("There is not enough samples for the FFT Plan length")
+	}
+
+	input = input[:p.length]
+	output = output[:p.length]
+
+	if p.direction == Forward {
+		p.forward(input, output)
+		return
+	}
+
+	// The inverse is the forward transform of the conjugate, conjugated and scaled
+	for i, v := range input {
+		output[i] = complex(real(v), -imag(v))
+	}
+
+	p.forward(output, output)
+
+	var scale = 1 / float32(p.length)
+	for i, v := range output {
+		output[i] = complex(real(v)*scale, -imag(v)*scale)
+	}
+}
+
+// ExecuteInPlace computes the transform of the first GetLength() samples of data in place
+func (p *Plan) ExecuteInPlace(data []complex64) {
+	p.Execute(data, data)
+}
+
+// ExecuteBatch computes the transform of each GetLength() frame of input into the same frame of output, in parallel.
+// The number of workers follows SetWorkerPoolSize. input and output can be the same slice.
+func (p *Plan) ExecuteBatch(input, output []complex64) {
+	var frames = len(input) / p.length
+	if len(output) < frames*p.length {
+		panic("There is not enough space in output buffer")
+	}
+
+	var numWorkers = workerPoolSize
+	if numWorkers == 0 {
+		numWorkers = runtime.GOMAXPROCS(0)
+	}
+	if numWorkers > frames {
+		numWorkers = frames
+	}
+
+	if numWorkers <= 1 {
+		for f := 0; f < frames; f++ {
+			var start = f * p.length
+			p.Execute(input[start:start+p.length], output[start:start+p.length])
+		}
+		return
+	}
+
+	for len(p.workers) < numWorkers {
+		p.workers = append(p.workers, p.clone())
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+
+	for w := 0; w < numWorkers; w++ {
+		go func(worker *Plan, first int) {
+			defer wg.Done()
+			for f := first; f < frames; f += numWorkers {
+				var start = f * p.length
+				worker.Execute(input[start:start+p.length], output[start:start+p.length])
+			}
+		}(p.workers[w], w)
+	}
+
+	wg.Wait()
+}
+
+// clone returns a plan that shares the tables of p, with its own work buffers
+func (p *Plan) clone() *Plan {
+	var c = *p
+	c.workers = nil
+
+	if p.scratch != nil {
+		c.scratch = make([]complex64, len(p.scratch))
+	}
+	if p.butterfly != nil {
+		c.butterfly = make([]complex64, len(p.butterfly))
+	}
+	if p.bluesteinFFT != nil {
+		c.bluesteinFFT = p.bluesteinFFT.clone()
+	}
+
+	return &c
+}
+
+func (p *Plan) forward(input, output []complex64) {
+	switch p.algorithm {
+	case planTrivial:
+		output[0] = input[0]
+	case planRadix2:
+		p.radix2(input, output)
+	case planMixedRadix:
+		if &input[0] == &output[0] {
+			copy(p.scratch, input)
+			input = p.scratch
+		}
+		p.mixed.work(output, input, 1, 1, p.mixed.factors, p.butterfly)
+	case planBluestein:
+		p.bluestein(input, output)
+	}
+}
+
+// region Radix 2
+
+func (p *Plan) initRadix2() {
+	var bits = log2(uint(p.length))
+
+	p.twiddles = make([]complex64, p.length/2)
+	for i := range p.twiddles {
+		var sin, cos = math.Sincos(-2 * math.Pi * float64(i) / float64(p.length))
+		p.twiddles[i] = complex(float32(cos), float32(sin))
+	}
+
+	p.bitrev = make([]int, p.length)
+	for i := range p.bitrev {
+		p.bitrev[i] = int(reverseBits(uint(i), bits))
+	}
+}
+
+// radix2 computes the iterative radix-2 DIT Cooley-Tukey FFT
+func (p *Plan) radix2(input, output []complex64) {
+	var n = p.length
+
+	if &input[0] == &output[0] {
+		for i, j := range p.bitrev {
+			if i < j {
+				output[i], output[j] = output[j], output[i]
+			}
+		}
+	} else {
+		for i, j := range p.bitrev {
+			output[j] = input[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		var half = size / 2
+		var step = n / size
+
+		for start := 0; start < n; start += size {
+			for j := 0; j < half; j++ {
+				var a = output[start+j]
+				var b = output[start+j+half] * p.twiddles[j*step]
+				output[start+j] = a + b
+				output[start+j+half] = a - b
+			}
+		}
+	}
+}
+
+// endregion
+// region Mixed Radix
+
+func (p *Plan) initMixedRadix() {
+	p.mixed = getMixedRadixPlan(p.length)
+	p.scratch = make([]complex64, p.length)
+
+	var maxFactor = 0
+	for _, f := range p.mixed.factors {
+		if f > maxFactor {
+			maxFactor = f
+		}
+	}
+	p.butterfly = make([]complex64, maxFactor)
+}
+
+// endregion
+// region Bluestein
+
+func (p *Plan) initBluestein() {
+	var n = p.length
+	var m = tools.NextPowerOf2(n*2 - 1)
+
+	p.chirp = make([]complex64, n)
+	p.chirpFFT = make([]complex64, m)
+	p.scratch = make([]complex64, m)
+	p.bluesteinFFT = MakePlan(m, Forward)
+
+	for i := 0; i < n; i++ {
+		// i² mod 2n keeps the phase argument small for large lengths
+		var sin, cos = math.Sincos(math.Pi * float64((i*i)%(2*n)) / float64(n))
+		p.chirp[i] = complex(float32(cos), float32(-sin))
+		p.chirpFFT[i] = complex(float32(cos), float32(sin))
+		if i != 0 {
+			p.chirpFFT[m-i] = p.chirpFFT[i]
+		}
+	}
+
+	p.bluesteinFFT.ExecuteInPlace(p.chirpFFT)
+}
+
+// bluestein computes the FFT as the convolution of the input with a chirp, using power of two FFTs
+func (p *Plan) bluestein(input, output []complex64) {
+	var a = p.scratch
+	var m = len(a)
+
+	for i, v := range input {
+		a[i] = v * p.chirp[i]
+	}
+	for i := p.length; i < m; i++ {
+		a[i] = 0
+	}
+
+	p.bluesteinFFT.ExecuteInPlace(a)
+
+	// Inverse of the product through the conjugate forward transform
+	for i, v := range a {
+		var c = v * p.chirpFFT[i]
+		a[i] = complex(real(c), -imag(c))
+	}
+
+	p.bluesteinFFT.ExecuteInPlace(a)
+
+	var scale = 1 / float32(m)
+	for i := range output {
+		var c = complex(real(a[i])*scale, -imag(a[i])*scale)
+		output[i] = c * p.chirp[i]
+	}
+}
+
+// endregion
+// region Real Plan
+
+// RealPlan is a forward FFT of real samples. It computes only the GetOutputLength() = N/2+1 non redundant bins,
+// with a complex FFT of half the length for even N.
+type RealPlan struct {
+	length   int
+	plan     *Plan
+	buffer   []complex64
+	twiddles []complex64
+}
+
+// MakeRealPlan creates a real input FFT plan of length samples
+func MakeRealPlan(length int) *RealPlan {
+	if length < 1 {
+		panic("FFT Plan length should be at least 1")
+	}
+
+	var p = &RealPlan{
+		length: length,
+	}
+
+	if length%2 != 0 {
+		p.plan = MakePlan(length, Forward)
+		p.buffer = make([]complex64, length)
+		return p
+	}
+
+	var half = length / 2
+
+	p.plan = MakePlan(half, Forward)
+	p.buffer = make([]complex64, half)
+	p.twiddles = make([]complex64, half+1)
+
+	for i := range p.twiddles {
+		var sin, cos = math.Sincos(-2 * math.Pi * float64(i) / float64(length))
+		p.twiddles[i] = complex(float32(cos), float32(sin))
+	}
+
+	return p
+}
+
+// GetLength returns the number of input samples
+func (p *RealPlan) GetLength() int {
+	return p.length
+}
+
+// GetOutputLength returns the number of output bins (N/2+1)
+func (p *RealPlan) GetOutputLength() int {
+	return p.length/2 + 1
+}
+
+// Execute computes the bins 0 to N/2 of the FFT of the first GetLength() samples of input into output
+func (p *RealPlan) Execute(input []float32, output []complex64) {
+	if len(input) < p.length || len(output) < p.GetOutputLength() {
+		panic("There is not enough samples for the FFT Plan length")
+	}
+
+	if p.length%2 != 0 {
+		for i := 0; i < p.length; i++ {
+			p.buffer[i] = complex(input[i], 0)
+		}
+		p.plan.ExecuteInPlace(p.buffer)
+		copy(output, p.buffer[:p.GetOutputLength()])
+		return
+	}
+
+	var half = p.length / 2
+
+	// Even samples in the real part, odd samples in the imaginary part
+	for i := 0; i < half; i++ {
+		p.buffer[i] = complex(input[2*i], input[2*i+1])
+	}
+
+	p.plan.ExecuteInPlace(p.buffer)
+
+	for k := 0; k <= half; k++ {
+		var z = p.buffer[k%half]
+		var zc = p.buffer[(half-k)%half]
+		zc = complex(real(zc), -imag(zc))
+
+		var even = (z + zc) * 0.5
+		var odd = (z - zc) * complex(0, -0.5)
+
+		output[k] = even + p.twiddles[k]*odd
+	}
+}
+
+// endregion
+// region Plan Cache
+
+type planKey struct {
+	length    int
+	direction Direction
+}
+
+var planPools sync.Map
+
+// acquirePlan returns a cached plan for length and direction, that should be released with releasePlan
+func acquirePlan(length int, direction Direction) *Plan {
+	var key = planKey{length, direction}
+	var pool, ok = planPools.Load(key)
+
+	if !ok {
+		pool, _ = planPools.LoadOrStore(key, &sync.Pool{
+			New: func() interface{} {
+				return MakePlan(length, direction)
+			},
+		})
+	}
+
+	return pool.(*sync.Pool).Get().(*Plan)
+}
+
+func releasePlan(p *Plan) {
+	if pool, ok := planPools.Load(planKey{p.length, p.direction}); ok {
+		pool.(*sync.Pool).Put(p)
+	}
+}
+
+var realPlanPools sync.Map
+
+// acquireRealPlan returns a cached real plan for length, that should be released with releaseRealPlan
+func acquireRealPlan(length int) *RealPlan {
+	var pool, ok = realPlanPools.Load(length)
+
+	if !ok {
+		pool, _ = realPlanPools.LoadOrStore(length, &sync.Pool{
+			New: func() interface{} {
+				return MakeRealPlan(length)
+			},
+		})
+	}
+
+	return pool.(*sync.Pool).Get().(*RealPlan)
+}
+
+func releaseRealPlan(p *RealPlan) {
+	if pool, ok := realPlanPools.Load(p.length); ok {
+		pool.(*sync.Pool).Put(p)
+	}
+}
+
+// endregion
@@ -1,5 +1,22 @@
 package dsp
 
+// mapFirOffset maps an input offset of a FIR filter call to its output. The output sample i is centered at the input
+// sample decimation * i + (tapsLen - 1) / 2 - history, where history is the number of samples kept from the previous
+// calls, so the tags go to the first output sample centered at or after them.
+func mapFirOffset(offset, history, tapsLen, decimation int) int {
+	if decimation < 1 {
+		decimation = 1
+	}
+
+	var position = offset + history - (tapsLen-1)/2
+	if position <= 0 {
+		// The division truncates to zero, that is the ceiling of the negative ones
+		return position / decimation
+	}
+
+	return (position + decimation - 1) / decimation
+}
+
 // region Complex Fir Filter
 
 type FirFilter struct {
@@ -7,6 +24,7 @@ type FirFilter struct {
 	sampleHistory []complex64
 	tapsLen       int
 	decimation    int
+	lastHistory   int
 }
 
 func MakeFirFilter(taps []float32) *FirFilter {
@@ -23,6 +41,7 @@ func MakeDecimationFirFilter(decimation int, taps []float32) *FirFilter {
 }
 
 func (f *FirFilter) Filter(data []complex64, length int) {
+	f.lastHistory = len(f.sampleHistory)
 	var samples = append(f.sampleHistory, data...)
 	for i := 0; i < length; i++ {
 		DotProduct(&data[i], samples[i:], f.taps)
@@ -42,6 +61,7 @@ func (f *FirFilter) FilterOut(data []complex64) []complex64 {
 }
 
 func (f *FirFilter) FilterBuffer(input, output []complex64) int {
+	f.lastHistory = len(f.sampleHistory)
 	var samples = append(f.sampleHistory, input...)
 	var length = len(samples) - f.tapsLen
 
@@ -73,6 +93,7 @@ func (f *FirFilter) FilterDecimate(data []complex64, decimate int, length int) {
 }
 
 func (f *FirFilter) FilterDecimateBuffer(input, output []complex64, decimate int) int {
+	f.lastHistory = len(f.sampleHistory)
 	var samples = append(f.sampleHistory, input...)
 	var length = len(samples) / decimate
 	var remainder = len(samples) % decimate
@@ -142,9 +163,17 @@ func (f *FirFilter) WorkBuffer(input, output []complex64) int {
 }
 
 func (f *FirFilter) PredictOutputSize(inputLength int) int {
+	if f.decimation > 1 {
+		// The decimation keeps the remainder samples in history
+		return (inputLength+len(f.sampleHistory))/f.decimation + 2
+	}
 	return inputLength/f.decimation + 2
 }
 
+func (f *FirFilter) MapOffset(offset int) int {
+	return mapFirOffset(offset, f.lastHistory, len(f.taps), f.decimation)
+}
+
 // endregion
 // region Float Fir Filter
 
@@ -153,6 +182,7 @@ type FloatFirFilter struct {
 	sampleHistory []float32
 	tapsLen       int
 	decimation    int
+	lastHistory   int
 }
 
 func MakeFloatFirFilter(taps []float32) *FloatFirFilter {
@@ -173,6 +203,7 @@ func MakeDecimationFloatFirFilter(decimation int, taps []float32) *FloatFirFilte
 }
 
 func (f *FloatFirFilter) Filter(data []float32, length int) {
+	f.lastHistory = len(f.sampleHistory)
 	var samples = append(f.sampleHistory, data...)
 	for i := 0; i < length; i++ {
 		DotProductFloat(&data[i], samples[i:], f.taps)
@@ -181,6 +212,7 @@ func (f *FloatFirFilter) Filter(data []float32, length int) {
 }
 
 func (f *FloatFirFilter) FilterBuffer(input, output []float32) int {
+	f.lastHistory = len(f.sampleHistory)
 	var samples = append(f.sampleHistory, input...)
 	var length = len(samples) - f.tapsLen
 
@@ -237,6 +269,7 @@ func (f *FloatFirFilter) FilterDecimateOut(data []float32, decimate int) []float
 }
 
 func (f *FloatFirFilter) FilterDecimateBuffer(input, output []float32, decimate int) int {
+	f.lastHistory = len(f.sampleHistory)
 	var samples = append(f.sampleHistory, input...)
 	var length = len(samples) / decimate
 	var remainder = len(samples) % decimate
@@ -283,6 +316,9 @@ func (f *FloatFirFilter) Work(data []float32) []float32 {
 }
 
 func (f *FloatFirFilter) WorkBuffer(input, output []float32) int {
+	if f.decimation > 1 {
+		return f.FilterDecimateBuffer(input, output, f.decimation)
+	}
 	return f.FilterBuffer(input, output)
 }
 
@@ -294,7 +330,15 @@ func (f *FloatFirFilter) PredictOutputSize(inputLength int) int {
 	if f.decimation == 0 {
 		f.decimation = 1
 	}
+	if f.decimation > 1 {
+		// The decimation keeps the remainder samples in history
+		return (inputLength+len(f.sampleHistory))/f.decimation + 2
+	}
 	return inputLength/f.decimation + 2
 }
 
+func (f *FloatFirFilter) MapOffset(offset int) int {
+	return mapFirOffset(offset, f.lastHistory, len(f.taps), f.decimation)
+}
+
 // endregion
@@ -0,0 +1,182 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+// FLLBandEdge is a Band-Edge Frequency Locked Loop based on GNU Radio Implementation.
+// It measures the energy at both band edges of the signal and steers the loop frequency
+// until both edges have the same energy (centered signal). The output is the frequency corrected input.
+type FLLBandEdge struct {
+	controlLoop
+	samplesPerSymbol float32
+	rollOff          float32
+	filterSize       int
+	tapsUpper        []complex64
+	tapsLower        []complex64
+	sampleHistory    []complex64
+	workBuffer       []complex64
+	error            float32
+}
+
+// MakeFLLBandEdge creates a Band-Edge FLL with the default frequency range of +- 2 / samplesPerSymbol
+func MakeFLLBandEdge(samplesPerSymbol, rollOff float32, filterSize int, loopBandwidth float32) *FLLBandEdge {
+	var maxFreq = TwoPi * (2 / samplesPerSymbol)
+	return MakeFLLBandEdgeWithFrequencyRange(samplesPerSymbol, rollOff, filterSize, loopBandwidth, -maxFreq, maxFreq)
+}
+
+// MakeFLLBandEdgeWithFrequencyRange creates a Band-Edge FLL limited to the specified range (in radians / sample)
+func MakeFLLBandEdgeWithFrequencyRange(samplesPerSymbol, rollOff float32, filterSize int, loopBandwidth, minRelativeFrequency, maxRelativeFrequency float32) *FLLBandEdge {
+	if samplesPerSymbol <= 0 {
+		panic("Band-Edge FLL samples per symbol should be higher than 0")
+	}
+
+	if rollOff < 0 || rollOff > 1 {
+		panic("Band-Edge FLL roll-off should be between 0 and 1")
+	}
+
+	if filterSize <= 0 {
+		panic("Band-Edge FLL filter size should be higher than 0")
+	}
+
+	var cl = makeControlLoop(loopBandwidth, minRelativeFrequency, maxRelativeFrequency)
+
+	var fll = &FLLBandEdge{
+		controlLoop:      *cl,
+		samplesPerSymbol: samplesPerSymbol,
+		rollOff:          rollOff,
+		filterSize:       filterSize,
+	}
+
+	fll.designFilter()
+
+	return fll
+}
+
+func (fll *FLLBandEdge) designFilter() {
+	var sps = float64(fll.samplesPerSymbol)
+	var rollOff = float64(fll.rollOff)
+	var M = math.Round(float64(fll.filterSize) / sps)
+	var power = 0.0
+	var bbTaps = make([]float64, fll.filterSize)
+
+	for i := 0; i < fll.filterSize; i++ {
+		var k = -M + float64(i)*2.0/sps
+		bbTaps[i] = sinc(rollOff*k-0.5) + sinc(rollOff*k+0.5)
+		power += bbTaps[i]
+	}
+
+	fll.tapsUpper = make([]complex64, fll.filterSize)
+	fll.tapsLower = make([]complex64, fll.filterSize)
+
+	// Our Fir filters correlates instead of convolving, so the taps that select the upper band edge
+	// are the ones that brings it down to DC.
+	for i := 0; i < fll.filterSize; i++ {
+		var tap = bbTaps[i] / power
+		var k = (-float64(fll.filterSize-1)/2.0 + float64(i)) / sps
+		var t = cmplx.Exp(complex(0, -math.Pi*(1+rollOff)*k))
+		fll.tapsUpper[i] = complex64(complex(tap, 0) * t)
+		fll.tapsLower[i] = complex64(complex(tap, 0) * cmplx.Conj(t))
+	}
+
+	fll.sampleHistory = make([]complex64, fll.filterSize-1)
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// SetSamplesPerSymbol changes the samples per symbol and redesigns the band edge filters
+func (fll *FLLBandEdge) SetSamplesPerSymbol(samplesPerSymbol float32) {
+	if samplesPerSymbol <= 0 {
+		panic("Band-Edge FLL samples per symbol should be higher than 0")
+	}
+	fll.samplesPerSymbol = samplesPerSymbol
+	fll.designFilter()
+}
+
+// SetRollOff changes the filter roll-off and redesigns the band edge filters
+func (fll *FLLBandEdge) SetRollOff(rollOff float32) {
+	if rollOff < 0 || rollOff > 1 {
+		panic("Band-Edge FLL roll-off should be between 0 and 1")
+	}
+	fll.rollOff = rollOff
+	fll.designFilter()
+}
+
+// SetFilterSize changes the number of taps of the band edge filters
+func (fll *FLLBandEdge) SetFilterSize(filterSize int) {
+	if filterSize <= 0 {
+		panic("Band-Edge FLL filter size should be higher than 0")
+	}
+	fll.filterSize = filterSize
+	fll.designFilter()
+}
+
+func (fll *FLLBandEdge) GetSamplesPerSymbol() float32 {
+	return fll.samplesPerSymbol
+}
+
+func (fll *FLLBandEdge) GetRollOff() float32 {
+	return fll.rollOff
+}
+
+func (fll *FLLBandEdge) GetFilterSize() int {
+	return fll.filterSize
+}
+
+func (fll *FLLBandEdge) GetError() float32 {
+	return fll.error
+}
+
+func (fll *FLLBandEdge) Work(input []complex64) []complex64 {
+	var output = make([]complex64, fll.PredictOutputSize(len(input)))
+	fll.WorkBuffer(input, output)
+	return output
+}
+
+func (fll *FLLBandEdge) WorkBuffer(input, output []complex64) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var historyLen = len(fll.sampleHistory)
+	var totalLen = historyLen + len(input)
+
+	if len(fll.workBuffer) < totalLen {
+		fll.workBuffer = make([]complex64, totalLen)
+	}
+
+	var samples = fll.workBuffer[:totalLen]
+	copy(samples, fll.sampleHistory)
+
+	for i := 0; i < len(input); i++ {
+		var n = tools.PhaseToComplex(-fll.phase)
+		var o = input[i] * n
+		output[i] = o
+		samples[historyLen+i] = o
+
+		var upper = ComplexDotProductResult(samples[i:], fll.tapsUpper)
+		var lower = ComplexDotProductResult(samples[i:], fll.tapsLower)
+
+		fll.error = tools.ComplexAbsSquared(upper) - tools.ComplexAbsSquared(lower)
+		fll.AdvanceLoop(fll.error)
+		fll.phaseWrap()
+		fll.frequencyLimit()
+	}
+
+	copy(fll.sampleHistory, samples[len(input):])
+
+	return len(input)
+}
+
+func (fll *FLLBandEdge) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
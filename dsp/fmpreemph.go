@@ -0,0 +1,52 @@
+package dsp
+
+import "math"
+
+// FMPreemph is the transmit side of FMDeemph: a high shelf that boosts the audio above 1 / (2 * pi * tau) Hz.
+// The shelf is closed at 92.5% of the Nyquist frequency to keep the filter stable.
+type FMPreemph struct {
+	tau        float32
+	sampleRate float32
+	iir        *IIRFilter
+}
+
+// MakeFMPreemph creates a FM Pre-Emphasis filter with the time constant tau (in seconds) at sampleRate
+func MakeFMPreemph(tau, sampleRate float32) *FMPreemph {
+	var fs = float64(sampleRate)
+	var wl = 1 / float64(tau)
+	var wh = 2 * math.Pi * 0.925 * fs / 2
+
+	var wla = 2 * fs * math.Tan(wl/(2*fs))
+	var wha = 2 * fs * math.Tan(wh/(2*fs))
+
+	var kl = -wla / (2 * fs)
+	var kh = -wha / (2 * fs)
+
+	var z1 = (1 + kl) / (1 - kl)
+	var p1 = (1 + kh) / (1 - kh)
+	var b0 = (1 - kl) / (1 - kh)
+
+	// Unity gain at DC
+	var g = math.Abs(1-p1) / (b0 * math.Abs(1-z1))
+
+	var btaps = []float32{float32(g * b0), float32(-g * b0 * z1)}
+	var ataps = []float32{1, float32(-p1)}
+
+	return &FMPreemph{
+		tau:        tau,
+		sampleRate: sampleRate,
+		iir:        MakeIIRFilter(btaps, ataps),
+	}
+}
+
+func (f *FMPreemph) Work(data []float32) []float32 {
+	return f.iir.FilterArray(data)
+}
+
+func (f *FMPreemph) WorkBuffer(input, output []float32) int {
+	return f.iir.FilterArrayBuffer(input, output)
+}
+
+func (f *FMPreemph) PredictOutputSize(inputSize int) int {
+	return inputSize
+}
@@ -43,9 +43,11 @@ func (ft *FrequencyTranslator) updateFilter() {
 
 	var shift = float64((2 * math.Pi * ft.centerFrequency) / ft.sampleRate)
 
+	// The filter correlates the samples with the taps (it does not reverse them), so the band pass taps rotate
+	// backwards to pass the band at +centerFrequency
 	for i := 0; i < len(newTaps); i++ {
 		var fi = float64(i)
-		newTaps[i] = complex64(complex128(ft.baseTaps[i]) * cmplx.Exp(complex(0, fi*shift)))
+		newTaps[i] = complex64(complex128(ft.baseTaps[i]) * cmplx.Exp(complex(0, -fi*shift)))
 	}
 
 	var fDecimation = float64(ft.decimation)
@@ -69,8 +71,7 @@ func (ft *FrequencyTranslator) WorkBuffer(input, output []complex64) int {
 		ft.updateFilter()
 	}
 
-	var outLength = len(input) / ft.decimation
-	if len(output) < outLength {
+	if len(output) < ft.PredictOutputSize(len(input)) {
 		panic("There is not enough space in output buffer")
 	}
 
@@ -78,13 +79,17 @@ func (ft *FrequencyTranslator) WorkBuffer(input, output []complex64) int {
 	output = output[:l]
 	ft.rotator.WorkInline(output)
 
-	return outLength
+	return l
 }
 
 func (ft *FrequencyTranslator) PredictOutputSize(inputLength int) int {
 	return ft.filter.PredictOutputSize(inputLength)
 }
 
+func (ft *FrequencyTranslator) MapOffset(offset int) int {
+	return ft.filter.MapOffset(offset)
+}
+
 func (ft *FrequencyTranslator) SetFrequency(frequency float32) {
 	ft.centerFrequency = frequency
 	ft.needsUpdate = true
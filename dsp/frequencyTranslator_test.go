@@ -0,0 +1,54 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// toneFrequency estimates the frequency of a tone from its mean phase rotation
+func toneFrequency(samples []complex64, sampleRate float64) (frequency float64, power float32) {
+	var rotation = complex64(0)
+	for i := 1; i < len(samples); i++ {
+		rotation += samples[i] * complex(real(samples[i-1]), -imag(samples[i-1]))
+		power += real(samples[i])*real(samples[i]) + imag(samples[i])*imag(samples[i])
+	}
+
+	return cmplx.Phase(complex128(rotation)) * sampleRate / (2 * math.Pi), power / float32(len(samples)-1)
+}
+
+func TestFrequencyTranslator(t *testing.T) {
+	const sampleRate = 1e6
+	const shift = 50e3
+
+	for _, decimation := range []int{1, 4} {
+		for _, offset := range []float64{1e3, -1e3, -2 * shift} {
+			var input = make([]complex64, 8000)
+			for i := range input {
+				input[i] = complex64(cmplx.Exp(complex(0, 2*math.Pi*(shift+offset)*float64(i)/sampleRate)))
+			}
+
+			var taps = MakeLowPass(1, sampleRate, 10e3, 5e3)
+			var translator = MakeFrequencyTranslator(decimation, shift, sampleRate, taps)
+			var output = translator.Work(input)
+
+			if len(output) > translator.PredictOutputSize(len(input)) || len(output) < len(input)/decimation-len(taps) {
+				t.Fatalf("Decimation %d: Unexpected output length %d", decimation, len(output))
+			}
+
+			// Skip the filter start
+			var frequency, power = toneFrequency(output[len(taps):], sampleRate/float64(decimation))
+
+			if math.Abs(offset) > 10e3 {
+				if power > 1e-3 {
+					t.Errorf("Decimation %d: Expected the tone at %f Hz to be rejected got power %f", decimation, offset, power)
+				}
+				continue
+			}
+
+			if math.Abs(frequency-offset) > 1 || power < 0.9 {
+				t.Errorf("Decimation %d: Expected the tone at %f Hz got %f Hz (power %f)", decimation, offset, frequency, power)
+			}
+		}
+	}
+}
@@ -14,6 +14,13 @@ func Min(a, b int) int {
 	return b
 }
 
+func Max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 // ComplexDotProductResult performs the Dot Product between two complex vectors and returns result
 var ComplexDotProductResult func(input []complex64, taps []complex64) complex64
 
@@ -73,6 +80,24 @@ var AddComplexComplexVectors func(A, B []complex64)
 // A[i] = A[i] - B[i]
 var SubtractComplexComplexVectors func(A, B []complex64)
 
+// ConvertCU8ToComplex converts unsigned 8 bit IQ samples to complex, returning the number of samples converted
+var ConvertCU8ToComplex func(input []byte, output []complex64) int
+
+// ConvertComplexToCU8 converts complex samples to unsigned 8 bit IQ, clipping at [-1, 1] and returning the number of samples converted
+var ConvertComplexToCU8 func(input []complex64, output []byte) int
+
+// ConvertCS8ToComplex converts signed 8 bit IQ samples to complex, returning the number of samples converted
+var ConvertCS8ToComplex func(input []byte, output []complex64) int
+
+// ConvertComplexToCS8 converts complex samples to signed 8 bit IQ, clipping at [-1, 1] and returning the number of samples converted
+var ConvertComplexToCS8 func(input []complex64, output []byte) int
+
+// ConvertCS16ToComplex converts signed 16 bit little endian IQ samples to complex, returning the number of samples converted
+var ConvertCS16ToComplex func(input []byte, output []complex64) int
+
+// ConvertComplexToCS16 converts complex samples to signed 16 bit little endian IQ, clipping at [-1, 1] and returning the number of samples converted
+var ConvertComplexToCS16 func(input []complex64, output []byte) int
+
 // ComplexDotProduct performs the Dot Product between two complex vectors and store the result at *result
 func ComplexDotProduct(result *complex64, input []complex64, taps []complex64) {
 	*result = ComplexDotProductResult(input, taps)
@@ -280,6 +305,108 @@ func genericRotateComplexBuffer(input, output []complex64, phase *complex64, pha
 	return len(input)
 }
 
+// genericConvertCU8ToComplex converts unsigned 8 bit IQ samples to complex
+// This is the Generic Function in case no SIMD alternative is available
+func genericConvertCU8ToComplex(input []byte, output []complex64) int {
+	const scale = float32(1 / 127.5)
+	var length = Min(len(input)/2, len(output))
+
+	for i := 0; i < length; i++ {
+		var r = (float32(input[i*2]) - 127.5) * scale
+		var q = (float32(input[i*2+1]) - 127.5) * scale
+		output[i] = complex(r, q)
+	}
+
+	return length
+}
+
+// genericConvertCS8ToComplex converts signed 8 bit IQ samples to complex
+// This is the Generic Function in case no SIMD alternative is available
+func genericConvertCS8ToComplex(input []byte, output []complex64) int {
+	const scale = float32(1.0 / 128)
+	var length = Min(len(input)/2, len(output))
+
+	for i := 0; i < length; i++ {
+		var r = float32(int8(input[i*2])) * scale
+		var q = float32(int8(input[i*2+1])) * scale
+		output[i] = complex(r, q)
+	}
+
+	return length
+}
+
+// genericConvertCS16ToComplex converts signed 16 bit little endian IQ samples to complex
+// This is the Generic Function in case no SIMD alternative is available
+func genericConvertCS16ToComplex(input []byte, output []complex64) int {
+	const scale = float32(1.0 / 32768)
+	var length = Min(len(input)/4, len(output))
+
+	for i := 0; i < length; i++ {
+		var r = int16(uint16(input[i*4]) | uint16(input[i*4+1])<<8)
+		var q = int16(uint16(input[i*4+2]) | uint16(input[i*4+3])<<8)
+		output[i] = complex(float32(r)*scale, float32(q)*scale)
+	}
+
+	return length
+}
+
+// quantize clips v to [-1, 1] (NaN goes to -1, as in the SIMD versions) and returns round(v * scale + offset)
+func quantize(v, scale, offset float32) int {
+	if !(v >= -1) {
+		v = -1
+	}
+	if v > 1 {
+		v = 1
+	}
+
+	v = float32(v*scale) + offset
+
+	return int(math.RoundToEven(float64(v)))
+}
+
+// genericConvertComplexToCU8 converts complex samples to unsigned 8 bit IQ
+// This is the Generic Function in case no SIMD alternative is available
+func genericConvertComplexToCU8(input []complex64, output []byte) int {
+	var length = Min(len(output)/2, len(input))
+
+	for i := 0; i < length; i++ {
+		output[i*2] = byte(quantize(real(input[i]), 127.5, 127.5))
+		output[i*2+1] = byte(quantize(imag(input[i]), 127.5, 127.5))
+	}
+
+	return length
+}
+
+// genericConvertComplexToCS8 converts complex samples to signed 8 bit IQ
+// This is the Generic Function in case no SIMD alternative is available
+func genericConvertComplexToCS8(input []complex64, output []byte) int {
+	var length = Min(len(output)/2, len(input))
+
+	for i := 0; i < length; i++ {
+		output[i*2] = byte(int8(Min(quantize(real(input[i]), 128, 0), math.MaxInt8)))
+		output[i*2+1] = byte(int8(Min(quantize(imag(input[i]), 128, 0), math.MaxInt8)))
+	}
+
+	return length
+}
+
+// genericConvertComplexToCS16 converts complex samples to signed 16 bit little endian IQ
+// This is the Generic Function in case no SIMD alternative is available
+func genericConvertComplexToCS16(input []complex64, output []byte) int {
+	var length = Min(len(output)/4, len(input))
+
+	for i := 0; i < length; i++ {
+		var r = uint16(int16(Min(quantize(real(input[i]), 32768, 0), math.MaxInt16)))
+		var q = uint16(int16(Min(quantize(imag(input[i]), 32768, 0), math.MaxInt16)))
+		output[i*4] = byte(r)
+		output[i*4+1] = byte(r >> 8)
+		output[i*4+2] = byte(q)
+		output[i*4+3] = byte(q >> 8)
+	}
+
+	return length
+}
+
 // init initializes the Helper function placeholders with SIMD Alternatives when available
 func init() {
 	if native.GetNativeDotProductComplex() != nil {
@@ -378,6 +505,43 @@ func init() {
 		DivideComplexComplexVectors = genericDivideComplexComplexVectors
 	}
 	// endregion
+	// region Sample Format Conversion
+	if native.GetNativeConvertCU8ToComplex() != nil {
+		ConvertCU8ToComplex = native.GetNativeConvertCU8ToComplex()
+	} else {
+		ConvertCU8ToComplex = genericConvertCU8ToComplex
+	}
+
+	if native.GetNativeConvertComplexToCU8() != nil {
+		ConvertComplexToCU8 = native.GetNativeConvertComplexToCU8()
+	} else {
+		ConvertComplexToCU8 = genericConvertComplexToCU8
+	}
+
+	if native.GetNativeConvertCS8ToComplex() != nil {
+		ConvertCS8ToComplex = native.GetNativeConvertCS8ToComplex()
+	} else {
+		ConvertCS8ToComplex = genericConvertCS8ToComplex
+	}
+
+	if native.GetNativeConvertComplexToCS8() != nil {
+		ConvertComplexToCS8 = native.GetNativeConvertComplexToCS8()
+	} else {
+		ConvertComplexToCS8 = genericConvertComplexToCS8
+	}
+
+	if native.GetNativeConvertCS16ToComplex() != nil {
+		ConvertCS16ToComplex = native.GetNativeConvertCS16ToComplex()
+	} else {
+		ConvertCS16ToComplex = genericConvertCS16ToComplex
+	}
+
+	if native.GetNativeConvertComplexToCS16() != nil {
+		ConvertComplexToCS16 = native.GetNativeConvertComplexToCS16()
+	} else {
+		ConvertComplexToCS16 = genericConvertComplexToCS16
+	}
+	// endregion
 }
 
 // endregion
@@ -0,0 +1,156 @@
+package dsp
+
+import "github.com/racerxdl/segdsp/tools"
+
+// Sideband selects which side of a complex (analytic) signal should be recovered
+type Sideband int
+
+const (
+	SidebandUpper Sideband = iota
+	SidebandLower
+)
+
+// region Float to Complex Hilbert
+
+// Float2ComplexHilbert converts a real signal to its analytic representation.
+// The real branch is delayed by the filter group delay, so both branches are aligned.
+type Float2ComplexHilbert struct {
+	taps          []float32
+	sampleHistory []float32
+	delay         int
+}
+
+// MakeFloat2ComplexHilbert creates a Float to Analytic Signal converter using a Hilbert Transformer with nTaps
+func MakeFloat2ComplexHilbert(nTaps int) *Float2ComplexHilbert {
+	var taps = tools.ReverseFloat32Taps(MakeHilbert(nTaps))
+
+	return &Float2ComplexHilbert{
+		taps:          taps,
+		sampleHistory: make([]float32, len(taps)-1),
+		delay:         (len(taps) - 1) / 2,
+	}
+}
+
+// GetDelay returns the delay in samples introduced by the converter
+func (h *Float2ComplexHilbert) GetDelay() int {
+	return h.delay
+}
+
+func (h *Float2ComplexHilbert) Work(data []float32) []complex64 {
+	var output = make([]complex64, h.PredictOutputSize(len(data)))
+	h.WorkBuffer(data, output)
+	return output
+}
+
+func (h *Float2ComplexHilbert) WorkBuffer(input []float32, output []complex64) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var samples = append(h.sampleHistory, input...)
+
+	for i := 0; i < len(input); i++ {
+		output[i] = complex(samples[i+h.delay], DotProductFloatResult(samples[i:], h.taps))
+	}
+
+	h.sampleHistory = samples[len(input):]
+
+	return len(input)
+}
+
+func (h *Float2ComplexHilbert) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+// region Complex to Float Hilbert
+
+// Complex2FloatHilbert recovers a real signal from one of the sidebands of a complex signal (phasing method).
+// Feeding it with the output of Float2ComplexHilbert and SidebandUpper gives back the original real signal.
+type Complex2FloatHilbert struct {
+	taps          []float32
+	iHistory      []float32
+	qHistory      []float32
+	delay         int
+	sideband      Sideband
+	inBuffI       []float32
+	inBuffQ       []float32
+	sidebandScale float32
+}
+
+// MakeComplex2FloatHilbert creates a Complex to Float converter that keeps only the selected sideband
+func MakeComplex2FloatHilbert(nTaps int, sideband Sideband) *Complex2FloatHilbert {
+	var taps = tools.ReverseFloat32Taps(MakeHilbert(nTaps))
+
+	var h = &Complex2FloatHilbert{
+		taps:     taps,
+		iHistory: make([]float32, len(taps)-1),
+		qHistory: make([]float32, len(taps)-1),
+		delay:    (len(taps) - 1) / 2,
+	}
+
+	h.SetSideband(sideband)
+
+	return h
+}
+
+// SetSideband changes the sideband that will be recovered
+func (h *Complex2FloatHilbert) SetSideband(sideband Sideband) {
+	h.sideband = sideband
+	if sideband == SidebandLower {
+		h.sidebandScale = 0.5
+	} else {
+		h.sidebandScale = -0.5
+	}
+}
+
+// GetSideband returns the sideband that is being recovered
+func (h *Complex2FloatHilbert) GetSideband() Sideband {
+	return h.sideband
+}
+
+// GetDelay returns the delay in samples introduced by the converter
+func (h *Complex2FloatHilbert) GetDelay() int {
+	return h.delay
+}
+
+func (h *Complex2FloatHilbert) Work(data []complex64) []float32 {
+	var output = make([]float32, h.PredictOutputSize(len(data)))
+	h.WorkBuffer(data, output)
+	return output
+}
+
+func (h *Complex2FloatHilbert) WorkBuffer(input []complex64, output []float32) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	if len(h.inBuffI) < len(input) {
+		h.inBuffI = make([]float32, len(input))
+		h.inBuffQ = make([]float32, len(input))
+	}
+
+	for i, v := range input {
+		h.inBuffI[i] = real(v)
+		h.inBuffQ[i] = imag(v)
+	}
+
+	var iSamples = append(h.iHistory, h.inBuffI[:len(input)]...)
+	var qSamples = append(h.qHistory, h.inBuffQ[:len(input)]...)
+
+	for i := 0; i < len(input); i++ {
+		var hq = DotProductFloatResult(qSamples[i:], h.taps)
+		output[i] = 0.5*iSamples[i+h.delay] + h.sidebandScale*hq
+	}
+
+	h.iHistory = iSamples[len(input):]
+	h.qHistory = qSamples[len(input):]
+
+	return len(input)
+}
+
+func (h *Complex2FloatHilbert) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
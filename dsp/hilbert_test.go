@@ -0,0 +1,48 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloat2ComplexHilbert(t *testing.T) {
+	const nTaps = 65
+	const length = 4096
+	var input = make([]float32, length)
+	var omega = 2 * math.Pi * 0.05
+
+	for i := range input {
+		input[i] = float32(math.Cos(omega * float64(i)))
+	}
+
+	var h = MakeFloat2ComplexHilbert(nTaps)
+	var analytic = h.Work(input)
+	var delay = h.GetDelay()
+
+	// Skip the filter startup. The analytic signal of cos(wt) is exp(jwt)
+	for i := nTaps; i < length; i++ {
+		var n = float64(i - delay)
+		var expected = complex(float32(math.Cos(omega*n)), float32(math.Sin(omega*n)))
+		var diff = analytic[i] - expected
+		if math.Hypot(float64(real(diff)), float64(imag(diff))) > 1e-2 {
+			t.Fatalf("Expected %v got %v at %d", expected, analytic[i], i)
+		}
+	}
+
+	var usb = MakeComplex2FloatHilbert(nTaps, SidebandUpper)
+	var lsb = MakeComplex2FloatHilbert(nTaps, SidebandLower)
+
+	var usbOut = usb.Work(analytic)
+	var lsbOut = lsb.Work(analytic)
+	var totalDelay = delay + usb.GetDelay()
+
+	for i := 2 * nTaps; i < length; i++ {
+		var expected = input[i-totalDelay]
+		if math.Abs(float64(usbOut[i]-expected)) > 1e-2 {
+			t.Fatalf("Upper Sideband: Expected %f got %f at %d", expected, usbOut[i], i)
+		}
+		if math.Abs(float64(lsbOut[i])) > 1e-2 {
+			t.Fatalf("Lower Sideband: Expected 0 got %f at %d", lsbOut[i], i)
+		}
+	}
+}
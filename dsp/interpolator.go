@@ -4,6 +4,8 @@ type Interpolator struct {
 	sampleHistory      []complex64
 	fir                *FirFilter
 	interpolationRatio int
+	// firstSample is the interpolated position of the first input sample of the last call
+	firstSample int
 }
 
 func MakeInterpolator(interpolationRatio int) *Interpolator {
@@ -16,6 +18,8 @@ func MakeInterpolator(interpolationRatio int) *Interpolator {
 
 func (f *Interpolator) Work(data []complex64) []complex64 {
 	samples := append(f.sampleHistory, data...)
+	// Each sample is output one input sample late, after the interpolation from the previous one
+	f.firstSample = f.interpolationRatio
 
 	var output = make([]complex64, len(data)*f.interpolationRatio)
 
@@ -45,6 +49,7 @@ func (f *Interpolator) WorkBuffer(input, output []complex64) int {
 	if len(output) < oLen {
 		panic("Output buffer does not have enough length")
 	}
+	f.firstSample = 0
 	for i := 0; i < len(input); i++ {
 		var idx = i * f.interpolationRatio
 		output[idx] = input[i]
@@ -62,6 +67,10 @@ func (f *Interpolator) PredictOutputSize(inputLength int) int {
 	return inputLength * f.interpolationRatio
 }
 
+func (f *Interpolator) MapOffset(offset int) int {
+	return f.fir.MapOffset(f.firstSample + offset*f.interpolationRatio)
+}
+
 type FloatInterpolator struct {
 	fir                *FloatFirFilter
 	interpolationRatio int
@@ -109,3 +118,7 @@ func (f *FloatInterpolator) WorkBuffer(input, output []float32) int {
 func (f *FloatInterpolator) PredictOutputSize(inputLength int) int {
 	return inputLength * f.interpolationRatio
 }
+
+func (f *FloatInterpolator) MapOffset(offset int) int {
+	return f.fir.MapOffset(offset * f.interpolationRatio)
+}
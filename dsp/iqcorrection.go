@@ -0,0 +1,175 @@
+package dsp
+
+import (
+	"math"
+	"sync"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+const iqCorrectionDefaultDCAlpha = dcFilterAlpha
+const iqCorrectionDefaultImbalanceAlpha = 1e-4
+
+// IQCorrector blindly estimates and removes the DC Offset and the IQ gain / phase imbalance of a zero-IF receiver.
+// The DC Offset is tracked by a single pole IIR per branch. The imbalance is estimated from the I², Q² and IQ moments,
+// that for a circular signal should be E[I²] = E[Q²] and E[IQ] = 0. The Q branch is then corrected to be
+// orthogonal to I and with the same power, which removes the mirror images.
+// The estimations can be read from another goroutine.
+type IQCorrector struct {
+	lock           sync.Mutex
+	dcAlpha        float32
+	imbalanceAlpha float32
+	iAverage       float32
+	qAverage       float32
+	iPower         float32
+	qPower         float32
+	iqCorrelation  float32
+	gain           float32
+	sinPhase       float32
+	cosPhase       float32
+	imbalance      bool
+}
+
+// MakeIQCorrector creates an IQ Corrector that corrects both DC Offset and IQ imbalance
+func MakeIQCorrector() *IQCorrector {
+	return &IQCorrector{
+		dcAlpha:        iqCorrectionDefaultDCAlpha,
+		imbalanceAlpha: iqCorrectionDefaultImbalanceAlpha,
+		gain:           1,
+		cosPhase:       1,
+		imbalance:      true,
+	}
+}
+
+// SetDCAlpha sets the DC Offset estimator alpha
+func (c *IQCorrector) SetDCAlpha(alpha float32) {
+	c.dcAlpha = alpha
+}
+
+// SetImbalanceAlpha sets the IQ imbalance estimator alpha
+func (c *IQCorrector) SetImbalanceAlpha(alpha float32) {
+	c.imbalanceAlpha = alpha
+}
+
+// SetImbalanceCorrection enables / disables the IQ imbalance correction (DC Offset is always corrected)
+func (c *IQCorrector) SetImbalanceCorrection(enabled bool) {
+	c.imbalance = enabled
+}
+
+// GetDCOffset returns the current DC Offset estimation
+func (c *IQCorrector) GetDCOffset() complex64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return complex(c.iAverage, c.qAverage)
+}
+
+// GetGainImbalance returns the current Q / I gain imbalance estimation in dB
+func (c *IQCorrector) GetGainImbalance() float32 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return float32(20 * math.Log10(float64(c.gain)))
+}
+
+// GetPhaseImbalance returns the current phase imbalance estimation in degrees
+func (c *IQCorrector) GetPhaseImbalance() float32 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return float32(math.Asin(float64(c.sinPhase)) * 180 / math.Pi)
+}
+
+// GetImageRejection returns the image rejection ratio (in dB) of the uncorrected input, given the current estimation
+func (c *IQCorrector) GetImageRejection() float32 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var g = float64(c.gain)
+	var cosPhase = float64(c.cosPhase)
+	var image = 1 - 2*g*cosPhase + g*g
+
+	if image <= 0 {
+		return float32(math.Inf(1))
+	}
+
+	return float32(10 * math.Log10((1+2*g*cosPhase+g*g)/image))
+}
+
+func (c *IQCorrector) updateImbalance() {
+	if c.iPower <= 0 || c.qPower <= 0 {
+		return
+	}
+
+	c.gain = tools.Sqrt(c.qPower / c.iPower)
+	c.sinPhase = c.iqCorrelation / tools.Sqrt(c.iPower*c.qPower)
+
+	if c.sinPhase > 0.5 {
+		c.sinPhase = 0.5
+	} else if c.sinPhase < -0.5 {
+		c.sinPhase = -0.5
+	}
+
+	c.cosPhase = tools.Sqrt(1 - c.sinPhase*c.sinPhase)
+}
+
+func (c *IQCorrector) Work(input []complex64) []complex64 {
+	var output = make([]complex64, c.PredictOutputSize(len(input)))
+	c.WorkBuffer(input, output)
+	return output
+}
+
+func (c *IQCorrector) WorkBuffer(input, output []complex64) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	// The correction coefficients are updated once per block
+	var qScale = 1 / (c.gain * c.cosPhase)
+	var iScale = -c.sinPhase / c.cosPhase
+
+	var iAvg = c.iAverage
+	var qAvg = c.qAverage
+	var iPower = c.iPower
+	var qPower = c.qPower
+	var iqCorrelation = c.iqCorrelation
+
+	for n, s := range input {
+		iAvg = c.dcAlpha*(real(s)-iAvg) + iAvg
+		qAvg = c.dcAlpha*(imag(s)-qAvg) + qAvg
+
+		var i = real(s) - iAvg
+		var q = imag(s) - qAvg
+
+		if !c.imbalance {
+			output[n] = complex(i, q)
+			continue
+		}
+
+		iPower = c.imbalanceAlpha*(i*i-iPower) + iPower
+		qPower = c.imbalanceAlpha*(q*q-qPower) + qPower
+		iqCorrelation = c.imbalanceAlpha*(i*q-iqCorrelation) + iqCorrelation
+
+		output[n] = complex(i, q*qScale+i*iScale)
+	}
+
+	// WorkBuffer is the only writer of the estimations, so they are only locked here (and in the getters)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.iAverage = iAvg
+	c.qAverage = qAvg
+	c.iPower = iPower
+	c.qPower = qPower
+	c.iqCorrelation = iqCorrelation
+
+	if c.imbalance {
+		c.updateImbalance()
+	}
+
+	return len(input)
+}
+
+func (c *IQCorrector) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
@@ -0,0 +1,75 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+// imageRatio measures the power ratio (dB) between the tone at frequency and its mirror image at -frequency
+func imageRatio(data []complex64, frequency float64) float64 {
+	var tone, image complex128
+	for n, v := range data {
+		var s, c = math.Sincos(2 * math.Pi * frequency * float64(n))
+		tone += complex128(v) * complex(c, -s)
+		image += complex128(v) * complex(c, s)
+	}
+
+	var tonePower = real(tone)*real(tone) + imag(tone)*imag(tone)
+	var imagePower = real(image)*real(image) + imag(image)*imag(image)
+
+	return 10 * math.Log10(tonePower/imagePower)
+}
+
+func TestIQCorrector(t *testing.T) {
+	const length = 200000
+	const frequency = 0.0123
+	const gain = 1.1
+	const phase = 5 * math.Pi / 180
+	const dcI = 0.1
+	const dcQ = -0.05
+
+	var input = make([]complex64, length)
+	for n := range input {
+		var theta = 2 * math.Pi * frequency * float64(n)
+		input[n] = complex(
+			float32(math.Cos(theta)+dcI),
+			float32(gain*math.Sin(theta+phase)+dcQ),
+		)
+	}
+
+	var c = MakeIQCorrector()
+	c.SetDCAlpha(1e-4)
+	c.SetImbalanceAlpha(1e-3)
+
+	var output = make([]complex64, 0, length)
+	for i := 0; i < length; i += 4096 {
+		var end = Min(i+4096, length)
+		output = append(output, c.Work(input[i:end])...)
+	}
+
+	var settled = length / 2
+	var before = imageRatio(input[settled:], frequency)
+	var after = imageRatio(output[settled:], frequency)
+
+	if after-before < 20 {
+		t.Fatalf("Expected at least 20 dB of image rejection improvement. Before: %f dB After: %f dB", before, after)
+	}
+
+	var dc = c.GetDCOffset()
+	if math.Abs(float64(real(dc))-dcI) > 0.01 || math.Abs(float64(imag(dc))-dcQ) > 0.01 {
+		t.Fatalf("Expected DC Offset (%f, %f) got %v", dcI, dcQ, dc)
+	}
+
+	var expectedGain = 20 * math.Log10(gain)
+	if math.Abs(float64(c.GetGainImbalance())-expectedGain) > 0.1 {
+		t.Fatalf("Expected gain imbalance %f dB got %f dB", expectedGain, c.GetGainImbalance())
+	}
+
+	if math.Abs(float64(c.GetPhaseImbalance())-5) > 0.5 {
+		t.Fatalf("Expected phase imbalance 5 degrees got %f", c.GetPhaseImbalance())
+	}
+
+	if math.Abs(float64(c.GetImageRejection())-before) > 1 {
+		t.Fatalf("Expected estimated image rejection to be %f dB got %f dB", before, c.GetImageRejection())
+	}
+}
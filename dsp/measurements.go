@@ -0,0 +1,269 @@
+package dsp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+const measurementDefaultAlpha = 0.1
+
+// region Channel SNR
+
+const channelSNRMinNoiseBins = 8
+const channelSNRNoisePercentile = 0.1
+
+// ChannelSNR estimates the in-channel Signal to Noise Ratio.
+// The noise density is estimated from the spectrum bins outside the signal bandwidth (or from the lowest bins inside it, if
+// there are not enough bins outside), and multiplied by the signal (noise) bandwidth to get the in-channel noise power.
+// It should be fed with samples before the channel filter. To save CPU, only one FFT frame is computed for each interval samples.
+type ChannelSNR struct {
+	estimator       *SpectrumEstimator
+	sampleRate      float32
+	signalBandwidth float32
+	interval        int
+	skip            int
+	collected       int
+	snr             float32
+	noiseBins       []float32
+}
+
+// MakeChannelSNR creates a Channel SNR estimator for a signal with signalBandwidth centered in the input samples
+func MakeChannelSNR(sampleRate, signalBandwidth float32, fftSize int) *ChannelSNR {
+	var estimator = MakeSpectrumEstimator(fftSize, WindowBlackmanHarris, 0)
+	estimator.SetAlpha(measurementDefaultAlpha)
+
+	return &ChannelSNR{
+		estimator:       estimator,
+		sampleRate:      sampleRate,
+		signalBandwidth: signalBandwidth,
+		interval:        int(sampleRate / 20),
+	}
+}
+
+// SetInterval sets the number of samples skipped after each FFT frame
+func (c *ChannelSNR) SetInterval(interval int) {
+	c.interval = interval
+}
+
+func (c *ChannelSNR) GetInterval() int {
+	return c.interval
+}
+
+// GetSNR returns the current SNR estimation in dB
+func (c *ChannelSNR) GetSNR() float32 {
+	return c.snr
+}
+
+// Feed adds samples to the estimator
+func (c *ChannelSNR) Feed(input []complex64) {
+	var fftSize = c.estimator.GetFFTSize()
+
+	for len(input) > 0 {
+		if c.skip > 0 {
+			var n = Min(c.skip, len(input))
+			c.skip -= n
+			input = input[n:]
+			continue
+		}
+
+		var n = Min(fftSize-c.collected, len(input))
+		c.estimator.Feed(input[:n])
+		c.collected += n
+		input = input[n:]
+
+		if c.collected == fftSize {
+			c.collected = 0
+			c.skip = c.interval
+			c.update()
+		}
+	}
+}
+
+func (c *ChannelSNR) update() {
+	var spectrum = c.estimator.GetSpectrum()
+	var n = len(spectrum)
+	var binWidth = c.sampleRate / float32(n)
+	var halfBins = int(c.signalBandwidth / 2 / binWidth)
+	var start = Max(n/2-halfBins, 0)
+	var end = Min(n/2+halfBins, n-1)
+
+	var total = float32(0)
+	c.noiseBins = c.noiseBins[:0]
+
+	for i, v := range spectrum {
+		if i >= start && i <= end {
+			total += v
+		} else {
+			c.noiseBins = append(c.noiseBins, v)
+		}
+	}
+
+	var inBand = end - start + 1
+	var percentile = float32(0.5)
+
+	if len(c.noiseBins) < channelSNRMinNoiseBins {
+		c.noiseBins = append(c.noiseBins[:0], spectrum[start:end+1]...)
+		percentile = channelSNRNoisePercentile
+	}
+
+	sort.Slice(c.noiseBins, func(i, j int) bool { return c.noiseBins[i] < c.noiseBins[j] })
+
+	var noise = c.noiseBins[int(percentile*float32(len(c.noiseBins)-1))] * float32(inBand)
+	var signal = total - noise
+
+	if noise <= 0 {
+		return
+	}
+
+	if signal < noise*1e-6 {
+		signal = noise * 1e-6
+	}
+
+	c.snr = float32(10 * math.Log10(float64(signal/noise)))
+}
+
+// endregion
+// region Frequency Error
+
+// FrequencyErrorMeter estimates the carrier frequency error by averaging the phase difference between consecutive samples.
+// It should be fed with the channel filtered samples.
+type FrequencyErrorMeter struct {
+	sampleRate  float32
+	alpha       float32
+	lastSample  complex64
+	error       float32
+	initialized bool
+}
+
+func MakeFrequencyErrorMeter(sampleRate float32) *FrequencyErrorMeter {
+	return &FrequencyErrorMeter{
+		sampleRate: sampleRate,
+		alpha:      measurementDefaultAlpha,
+	}
+}
+
+// SetAlpha sets the averaging factor applied on each block
+func (f *FrequencyErrorMeter) SetAlpha(alpha float32) {
+	f.alpha = alpha
+}
+
+// GetFrequencyError returns the current frequency error in Hertz
+func (f *FrequencyErrorMeter) GetFrequencyError() float32 {
+	return f.error
+}
+
+// Feed adds samples to the meter
+func (f *FrequencyErrorMeter) Feed(input []complex64) {
+	if len(input) == 0 {
+		return
+	}
+
+	var acc complex64
+	var last = f.lastSample
+
+	for _, v := range input {
+		acc += v * tools.Conj(last)
+		last = v
+	}
+
+	f.lastSample = last
+
+	if acc == 0 {
+		return
+	}
+
+	var blockError = tools.ComplexPhase(acc) * f.sampleRate / TwoPi
+
+	if !f.initialized {
+		f.error = blockError
+		f.initialized = true
+		return
+	}
+
+	f.error = f.alpha*blockError + (1-f.alpha)*f.error
+}
+
+// endregion
+// region FM Deviation
+
+// DeviationMeter measures the peak and RMS deviation of a FM signal from the Quadrature Demodulator output.
+// scale is the deviation (in Hertz) that gives 1.0 at the demodulator output. The carrier offset (mean) is removed.
+type DeviationMeter struct {
+	scale float32
+	alpha float32
+	decay float32
+	peak  float32
+	rms   float32
+
+	initialized bool
+}
+
+func MakeDeviationMeter(scale float32) *DeviationMeter {
+	return &DeviationMeter{
+		scale: scale,
+		alpha: measurementDefaultAlpha,
+		decay: 0.95,
+	}
+}
+
+// SetAlpha sets the RMS averaging factor applied on each block
+func (d *DeviationMeter) SetAlpha(alpha float32) {
+	d.alpha = alpha
+}
+
+// SetDecay sets the peak decay factor applied on each block
+func (d *DeviationMeter) SetDecay(decay float32) {
+	d.decay = decay
+}
+
+// GetPeakDeviation returns the peak deviation in Hertz
+func (d *DeviationMeter) GetPeakDeviation() float32 {
+	return d.peak
+}
+
+// GetRMSDeviation returns the RMS deviation in Hertz
+func (d *DeviationMeter) GetRMSDeviation() float32 {
+	return d.rms
+}
+
+// Feed adds demodulated samples to the meter
+func (d *DeviationMeter) Feed(input []float32) {
+	if len(input) == 0 {
+		return
+	}
+
+	var mean = float32(0)
+	for _, v := range input {
+		mean += v
+	}
+	mean /= float32(len(input))
+
+	var peak = float32(0)
+	var power = float32(0)
+
+	for _, v := range input {
+		var dev = v - mean
+		power += dev * dev
+		if tools.Abs(dev) > peak {
+			peak = tools.Abs(dev)
+		}
+	}
+
+	var rms = tools.Sqrt(power/float32(len(input))) * d.scale
+	peak *= d.scale
+
+	if !d.initialized {
+		d.rms = rms
+		d.initialized = true
+	}
+
+	d.rms = d.alpha*rms + (1-d.alpha)*d.rms
+	d.peak *= d.decay
+	if peak > d.peak {
+		d.peak = peak
+	}
+}
+
+// endregion
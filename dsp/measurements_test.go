@@ -0,0 +1,89 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func makeNoisyTone(length int, frequency, amplitude, noiseStd float32) []complex64 {
+	var r = rand.New(rand.NewSource(1))
+	var nco = MakeNCO()
+	nco.SetFrequency(TwoPi * frequency)
+	var samples = nco.Complex64SinCos(length, amplitude)
+
+	for i := range samples {
+		samples[i] += complex(float32(r.NormFloat64())*noiseStd, float32(r.NormFloat64())*noiseStd)
+	}
+
+	return samples
+}
+
+func TestChannelSNR(t *testing.T) {
+	const sampleRate = 100e3
+	// Band limited signal: noise filtered to 10 kHz, over white noise
+	var r = rand.New(rand.NewSource(2))
+	var signal = make([]complex64, 1<<16)
+	for i := range signal {
+		signal[i] = complex(float32(r.NormFloat64()), float32(r.NormFloat64()))
+	}
+	signal = MakeFirFilter(MakeLowPassFixed(1, sampleRate, 5e3, 255)).Work(signal)
+
+	var signalPower = float32(0)
+	for _, v := range signal[1000:] {
+		signalPower += real(v)*real(v) + imag(v)*imag(v)
+	}
+	signalPower /= float32(len(signal) - 1000)
+
+	// 20 dB SNR in the 10 kHz channel: Noise density = signalPower / 100 / 10 kHz
+	var noiseStd = float32(math.Sqrt(float64(signalPower/100*sampleRate/10e3) / 2))
+	for i := range signal {
+		signal[i] += complex(float32(r.NormFloat64())*noiseStd, float32(r.NormFloat64())*noiseStd)
+	}
+
+	var snr = MakeChannelSNR(sampleRate, 10e3, 512)
+	snr.SetInterval(0)
+	snr.Feed(signal[1000:])
+
+	if math.Abs(float64(snr.GetSNR()-20)) > 2 {
+		t.Fatalf("Expected SNR around 20 dB, got %f", snr.GetSNR())
+	}
+}
+
+func TestFrequencyErrorMeter(t *testing.T) {
+	const sampleRate = 48000
+	var samples = makeNoisyTone(48000, 1234.0/sampleRate, 1, 0.1)
+	var meter = MakeFrequencyErrorMeter(sampleRate)
+
+	for i := 0; i < len(samples); i += 1000 {
+		meter.Feed(samples[i : i+1000])
+	}
+
+	if math.Abs(float64(meter.GetFrequencyError()-1234)) > 5 {
+		t.Fatalf("Expected 1234 Hz got %f", meter.GetFrequencyError())
+	}
+}
+
+func TestDeviationMeter(t *testing.T) {
+	// Quad Demod output of a 3 kHz deviation FM tone with 500 Hz carrier offset, normalized to 5 kHz
+	var nco = MakeNCO()
+	nco.SetFrequency(TwoPi * 0.01)
+	var tone = nco.Float32Sin(10000, 3.0/5)
+	for i := range tone {
+		tone[i] += 0.5 / 5
+	}
+
+	var meter = MakeDeviationMeter(5000)
+	for i := 0; i < len(tone); i += 500 {
+		meter.Feed(tone[i : i+500])
+	}
+
+	if math.Abs(float64(meter.GetPeakDeviation()-3000)) > 30 {
+		t.Fatalf("Expected 3000 Hz peak deviation got %f", meter.GetPeakDeviation())
+	}
+
+	var expectedRMS = 3000 / math.Sqrt2
+	if math.Abs(float64(meter.GetRMSDeviation())-expectedRMS) > 30 {
+		t.Fatalf("Expected %f Hz RMS deviation got %f", expectedRMS, meter.GetRMSDeviation())
+	}
+}
@@ -0,0 +1,169 @@
+package dsp
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+// region FM Modulator
+
+// FMModulator converts a real baseband signal to a complex FM signal. An input of amplitude 1 gives the max deviation.
+type FMModulator struct {
+	sensitivity float32
+	phase       float32
+	preemph     *FMPreemph
+	buffer      []float32
+}
+
+// MakeFMModulator creates a FM Modulator with maxDeviation in Hertz at sampleRate.
+// If tau is not zero, a pre-emphasis with that time constant (in seconds) is applied before modulating.
+func MakeFMModulator(maxDeviation, sampleRate, tau float32) *FMModulator {
+	var f = &FMModulator{
+		sensitivity: float32(2 * math.Pi * float64(maxDeviation) / float64(sampleRate)),
+	}
+
+	if tau != 0 {
+		f.preemph = MakeFMPreemph(tau, sampleRate)
+	}
+
+	return f
+}
+
+// GetSensitivity returns the phase increment in radians / sample for a input of amplitude 1
+func (f *FMModulator) GetSensitivity() float32 {
+	return f.sensitivity
+}
+
+func (f *FMModulator) Work(data []float32) []complex64 {
+	var output = make([]complex64, f.PredictOutputSize(len(data)))
+	f.WorkBuffer(data, output)
+	return output
+}
+
+func (f *FMModulator) WorkBuffer(input []float32, output []complex64) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	if f.preemph != nil {
+		if len(f.buffer) < len(input) {
+			f.buffer = make([]float32, len(input))
+		}
+		f.preemph.WorkBuffer(input, f.buffer)
+		input = f.buffer[:len(input)]
+	}
+
+	for i, v := range input {
+		f.phase += f.sensitivity * v
+		if f.phase > math.Pi || f.phase < -math.Pi {
+			f.phase = float32(math.Remainder(float64(f.phase), 2*math.Pi))
+		}
+		output[i] = tools.PhaseToComplex(f.phase)
+	}
+
+	return len(input)
+}
+
+func (f *FMModulator) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+// region AM Modulator
+
+// AMModulator converts a real baseband signal to a complex AM signal. The DSB variant suppresses the carrier.
+type AMModulator struct {
+	modulationIndex float32
+	carrier         float32
+}
+
+// MakeAMModulator creates a AM (with carrier) Modulator. A input of amplitude 1 gives modulationIndex.
+func MakeAMModulator(modulationIndex float32) *AMModulator {
+	return &AMModulator{
+		modulationIndex: modulationIndex,
+		carrier:         1,
+	}
+}
+
+// MakeDSBModulator creates a Double Side Band (suppressed carrier) Modulator
+func MakeDSBModulator() *AMModulator {
+	return &AMModulator{
+		modulationIndex: 1,
+		carrier:         0,
+	}
+}
+
+func (f *AMModulator) Work(data []float32) []complex64 {
+	var output = make([]complex64, f.PredictOutputSize(len(data)))
+	f.WorkBuffer(data, output)
+	return output
+}
+
+func (f *AMModulator) WorkBuffer(input []float32, output []complex64) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i, v := range input {
+		output[i] = complex(f.carrier+f.modulationIndex*v, 0)
+	}
+
+	return len(input)
+}
+
+func (f *AMModulator) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
+// region SSB Modulator
+
+// SSBModulator converts a real baseband signal to a complex Single Side Band signal (phasing method).
+// The signal is delayed by the Hilbert Transformer group delay.
+type SSBModulator struct {
+	hilbert  *Float2ComplexHilbert
+	sideband Sideband
+}
+
+// MakeSSBModulator creates a SSB Modulator for sideband with a Hilbert Transformer of nTaps
+func MakeSSBModulator(sideband Sideband, nTaps int) *SSBModulator {
+	return &SSBModulator{
+		hilbert:  MakeFloat2ComplexHilbert(nTaps),
+		sideband: sideband,
+	}
+}
+
+// GetSideband returns the sideband that is being generated
+func (f *SSBModulator) GetSideband() Sideband {
+	return f.sideband
+}
+
+// GetDelay returns the delay in samples introduced by the modulator
+func (f *SSBModulator) GetDelay() int {
+	return f.hilbert.GetDelay()
+}
+
+func (f *SSBModulator) Work(data []float32) []complex64 {
+	var output = make([]complex64, f.PredictOutputSize(len(data)))
+	f.WorkBuffer(data, output)
+	return output
+}
+
+func (f *SSBModulator) WorkBuffer(input []float32, output []complex64) int {
+	var n = f.hilbert.WorkBuffer(input, output)
+
+	if f.sideband == SidebandLower {
+		for i := 0; i < n; i++ {
+			output[i] = tools.Conj(output[i])
+		}
+	}
+
+	return n
+}
+
+func (f *SSBModulator) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// endregion
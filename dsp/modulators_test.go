@@ -0,0 +1,259 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+func makeTone(length int, frequency, sampleRate float64) []float32 {
+	var tone = make([]float32, length)
+	var omega = 2 * math.Pi * frequency / sampleRate
+
+	for i := range tone {
+		tone[i] = float32(math.Sin(omega * float64(i)))
+	}
+
+	return tone
+}
+
+func rms(data []float32) float64 {
+	var acc = 0.0
+	for _, v := range data {
+		acc += float64(v) * float64(v)
+	}
+	return math.Sqrt(acc / float64(len(data)))
+}
+
+func makeSymbols(length, order int) []byte {
+	var r = rand.New(rand.NewSource(1234))
+	var symbols = make([]byte, length)
+
+	for i := range symbols {
+		symbols[i] = byte(r.Intn(order))
+	}
+
+	return symbols
+}
+
+func TestFMModulatorRoundTrip(t *testing.T) {
+	const sampleRate = 48000
+	const deviation = 5000
+	const length = 4096
+
+	var input = makeTone(length, 1000, sampleRate)
+	var fm = MakeFMModulator(deviation, sampleRate, 0)
+	var qd = MakeQuadDemod(sampleRate / (2 * math.Pi * deviation))
+
+	var modulated = fm.Work(input)
+	for i, v := range modulated {
+		if math.Abs(float64(real(v)*real(v)+imag(v)*imag(v))-1) > 1e-4 {
+			t.Fatalf("Expected constant envelope got %v at %d", v, i)
+		}
+	}
+
+	var output = qd.Work(modulated)
+
+	// The discriminator output is delayed by one sample
+	for i := 2; i < length; i++ {
+		if math.Abs(float64(output[i]-input[i-1])) > 1e-3 {
+			t.Fatalf("Expected %f got %f at %d", input[i-1], output[i], i)
+		}
+	}
+}
+
+func TestFMModulatorPreemphasis(t *testing.T) {
+	const sampleRate = 48000
+	const tau = 75e-6
+	const length = 8192
+	const skip = 1024
+
+	for _, frequency := range []float64{100, 1000, 3000} {
+		var input = makeTone(length, frequency, sampleRate)
+		var preemph = MakeFMPreemph(tau, sampleRate).Work(input)
+		var deemph = MakeFMDeemph(tau, sampleRate).Work(preemph)
+
+		var expectedBoost = math.Hypot(1, 2*math.Pi*frequency*tau)
+		var boost = rms(preemph[skip:]) / rms(input[skip:])
+
+		if math.Abs(boost-expectedBoost)/expectedBoost > 0.05 {
+			t.Errorf("%.0f Hz: Expected pre-emphasis gain %f got %f", frequency, expectedBoost, boost)
+		}
+
+		var gain = rms(deemph[skip:]) / rms(input[skip:])
+		if math.Abs(gain-1) > 0.05 {
+			t.Errorf("%.0f Hz: Expected pre-emphasis + de-emphasis gain 1 got %f", frequency, gain)
+		}
+	}
+}
+
+func TestAMModulatorRoundTrip(t *testing.T) {
+	const length = 4096
+	var input = makeTone(length, 1000, 48000)
+
+	var am = MakeAMModulator(0.5)
+	var envelope = MakeComplex2Magnitude().Work(am.Work(input))
+
+	for i, v := range envelope {
+		var expected = 1 + 0.5*input[i]
+		if math.Abs(float64(v-expected)) > 1e-5 {
+			t.Fatalf("AM: Expected %f got %f at %d", expected, v, i)
+		}
+	}
+
+	var dsb = MakeDSBModulator().Work(input)
+	for i, v := range dsb {
+		if real(v) != input[i] || imag(v) != 0 {
+			t.Fatalf("DSB: Expected %f got %v at %d", input[i], v, i)
+		}
+	}
+}
+
+func TestSSBModulatorRoundTrip(t *testing.T) {
+	const nTaps = 65
+	const length = 4096
+	var input = makeTone(length, 2400, 48000)
+
+	for _, sideband := range []Sideband{SidebandUpper, SidebandLower} {
+		var other = SidebandLower
+		if sideband == SidebandLower {
+			other = SidebandUpper
+		}
+
+		var ssb = MakeSSBModulator(sideband, nTaps)
+		var modulated = ssb.Work(input)
+
+		var demod = MakeComplex2FloatHilbert(nTaps, sideband)
+		var rejected = MakeComplex2FloatHilbert(nTaps, other)
+
+		var output = demod.Work(modulated)
+		var leak = rejected.Work(modulated)
+		var delay = ssb.GetDelay() + demod.GetDelay()
+
+		for i := 2 * nTaps; i < length; i++ {
+			var expected = input[i-delay]
+			if math.Abs(float64(output[i]-expected)) > 1e-2 {
+				t.Fatalf("Sideband %d: Expected %f got %f at %d", sideband, expected, output[i], i)
+			}
+			if math.Abs(float64(leak[i])) > 1e-2 {
+				t.Fatalf("Sideband %d: Expected 0 on the opposite sideband got %f at %d", sideband, leak[i], i)
+			}
+		}
+	}
+}
+
+func TestPSKModulatorRoundTrip(t *testing.T) {
+	const sps = 8
+	const nTaps = 11*sps + 1
+	const length = 512
+
+	for _, bps := range []int{1, 2, 3} {
+		var order = 1 << uint(bps)
+		var symbols = makeSymbols(length, order)
+		var taps = MakeRRC(1, sps, 1, 0.35, nTaps)
+
+		var psk = MakePSKModulator(bps, sps, taps)
+		var constellation = psk.GetConstellation()
+
+		var modulated = psk.Work(symbols)
+		if len(modulated) != length*sps {
+			t.Fatalf("%dPSK: Expected %d samples got %d", order, length*sps, len(modulated))
+		}
+
+		var matched = MakeFirFilter(taps).FilterOut(modulated)
+
+		// The transmit and matched filters have nTaps - 1 samples of delay.
+		// The FirFilter history has one extra sample.
+		var delay = nTaps
+
+		for n := 0; n*sps+delay < len(matched); n++ {
+			var sample = matched[n*sps+delay]
+			var best = 0
+			var bestDistance = math.MaxFloat64
+			for i, p := range constellation {
+				var d = math.Abs(float64(tools.ComplexPhase(sample * tools.Conj(p))))
+				if d < bestDistance {
+					best = i
+					bestDistance = d
+				}
+			}
+
+			if byte(best) != symbols[n] {
+				t.Fatalf("%dPSK: Expected symbol %d got %d at %d", order, symbols[n], best, n)
+			}
+		}
+	}
+}
+
+func TestFSKModulatorRoundTrip(t *testing.T) {
+	const sampleRate = 48000
+	const deviation = 2400
+	const sps = 10
+	const length = 512
+
+	var testCases = []struct {
+		name      string
+		levels    int
+		modulator *FSKModulator
+		delay     int
+	}{
+		{"2FSK", 2, MakeFSKModulator(2, sps, deviation, sampleRate), sps / 2},
+		{"4FSK", 4, MakeFSKModulator(4, sps, deviation, sampleRate), sps / 2},
+		{"GFSK", 2, MakeGFSKModulator(2, sps, deviation, sampleRate, 0.5), 5*sps/2 + 1},
+		{"4GFSK", 4, MakeGFSKModulator(4, sps, deviation, sampleRate, 0.5), 5*sps/2 + 1},
+	}
+
+	// delay is the middle of the first symbol: half the shaping filter plus one sample of the discriminator
+	for _, tc := range testCases {
+		var symbols = makeSymbols(length, tc.levels)
+		var levels = tc.modulator.GetLevels()
+
+		var qd = MakeQuadDemod(sampleRate / (2 * math.Pi * deviation))
+		var frequency = qd.Work(tc.modulator.Work(symbols))
+
+		for n := 0; n*sps+tc.delay < len(frequency); n++ {
+			var sample = frequency[n*sps+tc.delay]
+			var best = 0
+			for i, l := range levels {
+				if math.Abs(float64(sample-l)) < math.Abs(float64(sample-levels[best])) {
+					best = i
+				}
+			}
+
+			if byte(best) != symbols[n] {
+				t.Fatalf("%s: Expected symbol %d got %d (%f) at %d", tc.name, symbols[n], best, sample, n)
+			}
+		}
+	}
+}
+
+func TestGrayToIndex(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		var gray = i ^ (i >> 1)
+		if grayToIndex(gray) != i {
+			t.Fatalf("Expected %d got %d for gray code %d", i, grayToIndex(gray), gray)
+		}
+	}
+}
+
+func TestPulseShapingTapsSymmetry(t *testing.T) {
+	var testCases = map[string][]float32{
+		"RRC":      MakeRRC(1, 8, 1, 0.35, 89),
+		"Gaussian": MakeGaussian(1, 8, 0.5, 33),
+	}
+
+	for name, taps := range testCases {
+		var n = len(taps)
+		for i := 0; i < n/2; i++ {
+			if math.Abs(float64(taps[i]-taps[n-1-i])) > 1e-6 {
+				t.Fatalf("%s: Expected symmetric taps, got %f and %f at %d", name, taps[i], taps[n-1-i], i)
+			}
+		}
+
+		if taps[n/2] < taps[n/2-1] {
+			t.Fatalf("%s: Expected the peak at the center tap", name)
+		}
+	}
+}
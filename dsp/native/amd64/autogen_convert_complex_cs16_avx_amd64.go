@@ -0,0 +1,28 @@
+//+build !noasm
+//+build !appengine
+
+package amd64
+
+import (
+	"unsafe"
+)
+
+//go:noescape
+func _convertComplexToCS16AVX(input, output unsafe.Pointer, length uint)
+
+// ConvertComplexToCS16AVX converts complex samples to signed 16 bit (little endian) IQ, returning the number of samples converted
+func ConvertComplexToCS16AVX(input []complex64, output []byte) int {
+	var length = len(output) / 4
+
+	if length > len(input) {
+		length = len(input)
+	}
+
+	if length == 0 {
+		return 0
+	}
+
+	_convertComplexToCS16AVX(unsafe.Pointer(&input[0]), unsafe.Pointer(&output[0]), uint(length))
+
+	return length
+}
@@ -0,0 +1,65 @@
+//+build !noasm !appengine
+// Stand-in for the c2goasm output of c/convert_complex_cs16.c, written by hand. Run c/generate.sh (clang, c2goasm) to regenerate it
+
+// func _convertComplexToCS16SSE2(input, output unsafe.Pointer, length uint)
+TEXT ·_convertComplexToCS16SSE2(SB), $0-24
+
+    MOVQ input+0(FP), SI
+    MOVQ output+8(FP), DI
+    MOVQ length+16(FP), CX
+    SHLQ $1, CX                 // I and Q values
+
+    MOVL $0xbf800000, AX        // -1
+    MOVL AX, X8
+    SHUFPS $0, X8, X8
+    MOVL $0x3f800000, AX        // 1
+    MOVL AX, X9
+    SHUFPS $0, X9, X9
+    MOVL $0x47000000, AX        // 32768
+    MOVL AX, X10
+    SHUFPS $0, X10, X10
+
+LOOP8:
+    CMPQ CX, $8
+    JB TAIL
+
+    MOVUPS (SI), X0
+    MOVUPS 16(SI), X1
+    MAXPS X8, X0
+    MAXPS X8, X1
+    MINPS X9, X0
+    MINPS X9, X1
+    MULPS X10, X0
+    MULPS X10, X1
+    CVTPS2PL X0, X0
+    CVTPS2PL X1, X1
+
+    // The saturating pack clips 32768 to 32767
+    PACKSSLW X1, X0
+    MOVOU X0, (DI)
+
+    ADDQ $32, SI
+    ADDQ $16, DI
+    SUBQ $8, CX
+    JMP LOOP8
+
+TAIL:
+    TESTQ CX, CX
+    JE DONE
+    MOVSS (SI), X0
+    MAXSS X8, X0
+    MINSS X9, X0
+    MULSS X10, X0
+    CVTSS2SL X0, AX
+    CMPL AX, $32767
+    JLE STORE
+    MOVL $32767, AX
+STORE:
+    MOVW AX, (DI)
+    ADDQ $4, SI
+    ADDQ $2, DI
+    DECQ CX
+    JMP TAIL
+
+DONE:
+    RET
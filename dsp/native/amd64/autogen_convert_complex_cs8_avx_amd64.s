@@ -0,0 +1,75 @@
+//+build !noasm !appengine
+// Stand-in for the c2goasm output of c/convert_complex_cs8.c, written by hand. Run c/generate.sh (clang, c2goasm) to regenerate it
+
+// func _convertComplexToCS8AVX(input, output unsafe.Pointer, length uint)
+TEXT ·_convertComplexToCS8AVX(SB), $0-24
+
+    MOVQ input+0(FP), SI
+    MOVQ output+8(FP), DI
+    MOVQ length+16(FP), CX
+    SHLQ $1, CX                 // I and Q values
+
+    MOVL $0xbf800000, AX        // -1
+    MOVL AX, X8
+    VSHUFPS $0, X8, X8, X8
+    VINSERTF128 $1, X8, Y8, Y8
+    MOVL $0x3f800000, AX        // 1
+    MOVL AX, X9
+    VSHUFPS $0, X9, X9, X9
+    VINSERTF128 $1, X9, Y9, Y9
+    MOVL $0x43000000, AX        // 128
+    MOVL AX, X10
+    VSHUFPS $0, X10, X10, X10
+    VINSERTF128 $1, X10, Y10, Y10
+
+LOOP16:
+    CMPQ CX, $16
+    JB TAILSTART
+
+    VMOVUPS (SI), Y0
+    VMOVUPS 32(SI), Y1
+    VMAXPS Y8, Y0, Y0
+    VMAXPS Y8, Y1, Y1
+    VMINPS Y9, Y0, Y0
+    VMINPS Y9, Y1, Y1
+    VMULPS Y10, Y0, Y0
+    VMULPS Y10, Y1, Y1
+    VCVTPS2DQ Y0, Y0
+    VCVTPS2DQ Y1, Y1
+
+    // The saturating packs clip 128 to 127
+    VEXTRACTF128 $1, Y0, X2
+    VPACKSSDW X2, X0, X0
+    VEXTRACTF128 $1, Y1, X3
+    VPACKSSDW X3, X1, X1
+    VPACKSSWB X1, X0, X0
+    VMOVDQU X0, (DI)
+
+    ADDQ $64, SI
+    ADDQ $16, DI
+    SUBQ $16, CX
+    JMP LOOP16
+
+TAILSTART:
+    VZEROUPPER
+
+TAIL:
+    TESTQ CX, CX
+    JE DONE
+    MOVSS (SI), X0
+    MAXSS X8, X0
+    MINSS X9, X0
+    MULSS X10, X0
+    CVTSS2SL X0, AX
+    CMPL AX, $127
+    JLE STORE
+    MOVL $127, AX
+STORE:
+    MOVB AX, (DI)
+    ADDQ $4, SI
+    INCQ DI
+    DECQ CX
+    JMP TAIL
+
+DONE:
+    RET
@@ -0,0 +1,28 @@
+//+build !noasm
+//+build !appengine
+
+package amd64
+
+import (
+	"unsafe"
+)
+
+//go:noescape
+func _convertComplexToCS8SSE2(input, output unsafe.Pointer, length uint)
+
+// ConvertComplexToCS8SSE2 converts complex samples to signed 8 bit IQ, returning the number of samples converted
+func ConvertComplexToCS8SSE2(input []complex64, output []byte) int {
+	var length = len(output) / 2
+
+	if length > len(input) {
+		length = len(input)
+	}
+
+	if length == 0 {
+		return 0
+	}
+
+	_convertComplexToCS8SSE2(unsafe.Pointer(&input[0]), unsafe.Pointer(&output[0]), uint(length))
+
+	return length
+}
@@ -0,0 +1,77 @@
+//+build !noasm !appengine
+// Stand-in for the c2goasm output of c/convert_complex_cu8.c, written by hand. Run c/generate.sh (clang, c2goasm) to regenerate it
+
+// func _convertComplexToCU8SSE2(input, output unsafe.Pointer, length uint)
+TEXT ·_convertComplexToCU8SSE2(SB), $0-24
+
+    MOVQ input+0(FP), SI
+    MOVQ output+8(FP), DI
+    MOVQ length+16(FP), CX
+    SHLQ $1, CX                 // I and Q values
+
+    MOVL $0xbf800000, AX        // -1
+    MOVL AX, X8
+    SHUFPS $0, X8, X8
+    MOVL $0x3f800000, AX        // 1
+    MOVL AX, X9
+    SHUFPS $0, X9, X9
+    MOVL $0x42ff0000, AX        // 127.5
+    MOVL AX, X10
+    SHUFPS $0, X10, X10
+
+LOOP16:
+    CMPQ CX, $16
+    JB TAIL
+
+    MOVUPS (SI), X0
+    MOVUPS 16(SI), X1
+    MOVUPS 32(SI), X2
+    MOVUPS 48(SI), X3
+    MAXPS X8, X0
+    MAXPS X8, X1
+    MAXPS X8, X2
+    MAXPS X8, X3
+    MINPS X9, X0
+    MINPS X9, X1
+    MINPS X9, X2
+    MINPS X9, X3
+    MULPS X10, X0
+    MULPS X10, X1
+    MULPS X10, X2
+    MULPS X10, X3
+    ADDPS X10, X0
+    ADDPS X10, X1
+    ADDPS X10, X2
+    ADDPS X10, X3
+    CVTPS2PL X0, X0
+    CVTPS2PL X1, X1
+    CVTPS2PL X2, X2
+    CVTPS2PL X3, X3
+
+    PACKSSLW X1, X0
+    PACKSSLW X3, X2
+    PACKUSWB X2, X0
+    MOVOU X0, (DI)
+
+    ADDQ $64, SI
+    ADDQ $16, DI
+    SUBQ $16, CX
+    JMP LOOP16
+
+TAIL:
+    TESTQ CX, CX
+    JE DONE
+    MOVSS (SI), X0
+    MAXSS X8, X0
+    MINSS X9, X0
+    MULSS X10, X0
+    ADDSS X10, X0
+    CVTSS2SL X0, AX
+    MOVB AX, (DI)
+    ADDQ $4, SI
+    INCQ DI
+    DECQ CX
+    JMP TAIL
+
+DONE:
+    RET
@@ -0,0 +1,28 @@
+//+build !noasm
+//+build !appengine
+
+package amd64
+
+import (
+	"unsafe"
+)
+
+//go:noescape
+func _convertCS16ToComplexSSE2(input, output unsafe.Pointer, length uint)
+
+// ConvertCS16ToComplexSSE2 converts signed 16 bit (little endian) IQ samples to complex, returning the number of samples converted
+func ConvertCS16ToComplexSSE2(input []byte, output []complex64) int {
+	var length = len(input) / 4
+
+	if length > len(output) {
+		length = len(output)
+	}
+
+	if length == 0 {
+		return 0
+	}
+
+	_convertCS16ToComplexSSE2(unsafe.Pointer(&input[0]), unsafe.Pointer(&output[0]), uint(length))
+
+	return length
+}
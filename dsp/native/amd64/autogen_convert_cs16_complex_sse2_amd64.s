@@ -0,0 +1,54 @@
+//+build !noasm !appengine
+// Stand-in for the c2goasm output of c/convert_cs16_complex.c, written by hand. Run c/generate.sh (clang, c2goasm) to regenerate it
+
+// func _convertCS16ToComplexSSE2(input, output unsafe.Pointer, length uint)
+TEXT ·_convertCS16ToComplexSSE2(SB), $0-24
+
+    MOVQ input+0(FP), SI
+    MOVQ output+8(FP), DI
+    MOVQ length+16(FP), CX
+    SHLQ $1, CX                 // I and Q values
+
+    MOVL $0x38000000, AX        // 1 / 32768
+    MOVL AX, X7
+    SHUFPS $0, X7, X7
+
+LOOP8:
+    CMPQ CX, $8
+    JB TAIL
+
+    // Sign extend by unpacking each value with itself and shifting it back
+    MOVOU (SI), X0
+    MOVO X0, X1
+    PUNPCKLWL X0, X0
+    PUNPCKHWL X1, X1
+    PSRAL $16, X0
+    PSRAL $16, X1
+
+    CVTPL2PS X0, X0
+    CVTPL2PS X1, X1
+    MULPS X7, X0
+    MULPS X7, X1
+
+    MOVUPS X0, (DI)
+    MOVUPS X1, 16(DI)
+
+    ADDQ $16, SI
+    ADDQ $32, DI
+    SUBQ $8, CX
+    JMP LOOP8
+
+TAIL:
+    TESTQ CX, CX
+    JE DONE
+    MOVWLSX (SI), AX
+    CVTSL2SS AX, X0
+    MULSS X7, X0
+    MOVSS X0, (DI)
+    ADDQ $2, SI
+    ADDQ $4, DI
+    DECQ CX
+    JMP TAIL
+
+DONE:
+    RET
@@ -0,0 +1,28 @@
+//+build !noasm
+//+build !appengine
+
+package amd64
+
+import (
+	"unsafe"
+)
+
+//go:noescape
+func _convertCS8ToComplexAVX(input, output unsafe.Pointer, length uint)
+
+// ConvertCS8ToComplexAVX converts signed 8 bit IQ samples to complex, returning the number of samples converted
+func ConvertCS8ToComplexAVX(input []byte, output []complex64) int {
+	var length = len(input) / 2
+
+	if length > len(output) {
+		length = len(output)
+	}
+
+	if length == 0 {
+		return 0
+	}
+
+	_convertCS8ToComplexAVX(unsafe.Pointer(&input[0]), unsafe.Pointer(&output[0]), uint(length))
+
+	return length
+}
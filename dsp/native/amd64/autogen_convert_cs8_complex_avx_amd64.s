@@ -0,0 +1,57 @@
+//+build !noasm !appengine
+// Stand-in for the c2goasm output of c/convert_cs8_complex.c, written by hand. Run c/generate.sh (clang, c2goasm) to regenerate it
+
+// func _convertCS8ToComplexAVX(input, output unsafe.Pointer, length uint)
+TEXT ·_convertCS8ToComplexAVX(SB), $0-24
+
+    MOVQ input+0(FP), SI
+    MOVQ output+8(FP), DI
+    MOVQ length+16(FP), CX
+    SHLQ $1, CX                 // I and Q values
+
+    MOVL $0x3c000000, AX        // 1 / 128
+    MOVL AX, X7
+    VSHUFPS $0, X7, X7, X7
+    VINSERTF128 $1, X7, Y7, Y7
+
+LOOP16:
+    CMPQ CX, $16
+    JB TAILSTART
+
+    VPMOVSXBD (SI), X0
+    VPMOVSXBD 4(SI), X1
+    VINSERTF128 $1, X1, Y0, Y0
+    VPMOVSXBD 8(SI), X2
+    VPMOVSXBD 12(SI), X3
+    VINSERTF128 $1, X3, Y2, Y2
+
+    VCVTDQ2PS Y0, Y0
+    VCVTDQ2PS Y2, Y2
+    VMULPS Y7, Y0, Y0
+    VMULPS Y7, Y2, Y2
+
+    VMOVUPS Y0, (DI)
+    VMOVUPS Y2, 32(DI)
+
+    ADDQ $16, SI
+    ADDQ $64, DI
+    SUBQ $16, CX
+    JMP LOOP16
+
+TAILSTART:
+    VZEROUPPER
+
+TAIL:
+    TESTQ CX, CX
+    JE DONE
+    MOVBLSX (SI), AX
+    CVTSL2SS AX, X0
+    MULSS X7, X0
+    MOVSS X0, (DI)
+    INCQ SI
+    ADDQ $4, DI
+    DECQ CX
+    JMP TAIL
+
+DONE:
+    RET
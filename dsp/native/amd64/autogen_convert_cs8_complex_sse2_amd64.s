@@ -0,0 +1,70 @@
+//+build !noasm !appengine
+// Stand-in for the c2goasm output of c/convert_cs8_complex.c, written by hand. Run c/generate.sh (clang, c2goasm) to regenerate it
+
+// func _convertCS8ToComplexSSE2(input, output unsafe.Pointer, length uint)
+TEXT ·_convertCS8ToComplexSSE2(SB), $0-24
+
+    MOVQ input+0(FP), SI
+    MOVQ output+8(FP), DI
+    MOVQ length+16(FP), CX
+    SHLQ $1, CX                 // I and Q values
+
+    MOVL $0x3c000000, AX        // 1 / 128
+    MOVL AX, X7
+    SHUFPS $0, X7, X7
+
+LOOP16:
+    CMPQ CX, $16
+    JB TAIL
+
+    // Sign extend by unpacking each value with itself and shifting it back
+    MOVOU (SI), X0
+    MOVO X0, X1
+    PUNPCKLBW X0, X0
+    PUNPCKHBW X1, X1
+    PSRAW $8, X0
+    PSRAW $8, X1
+    MOVO X0, X2
+    PUNPCKLWL X0, X0
+    PUNPCKHWL X2, X2
+    PSRAL $16, X0
+    PSRAL $16, X2
+    MOVO X1, X3
+    PUNPCKLWL X1, X1
+    PUNPCKHWL X3, X3
+    PSRAL $16, X1
+    PSRAL $16, X3
+
+    CVTPL2PS X0, X0
+    CVTPL2PS X2, X2
+    CVTPL2PS X1, X1
+    CVTPL2PS X3, X3
+    MULPS X7, X0
+    MULPS X7, X2
+    MULPS X7, X1
+    MULPS X7, X3
+
+    MOVUPS X0, (DI)
+    MOVUPS X2, 16(DI)
+    MOVUPS X1, 32(DI)
+    MOVUPS X3, 48(DI)
+
+    ADDQ $16, SI
+    ADDQ $64, DI
+    SUBQ $16, CX
+    JMP LOOP16
+
+TAIL:
+    TESTQ CX, CX
+    JE DONE
+    MOVBLSX (SI), AX
+    CVTSL2SS AX, X0
+    MULSS X7, X0
+    MOVSS X0, (DI)
+    INCQ SI
+    ADDQ $4, DI
+    DECQ CX
+    JMP TAIL
+
+DONE:
+    RET
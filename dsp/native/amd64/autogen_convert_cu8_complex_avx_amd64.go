@@ -0,0 +1,28 @@
+//+build !noasm
+//+build !appengine
+
+package amd64
+
+import (
+	"unsafe"
+)
+
+//go:noescape
+func _convertCU8ToComplexAVX(input, output unsafe.Pointer, length uint)
+
+// ConvertCU8ToComplexAVX converts unsigned 8 bit IQ samples to complex, returning the number of samples converted
+func ConvertCU8ToComplexAVX(input []byte, output []complex64) int {
+	var length = len(input) / 2
+
+	if length > len(output) {
+		length = len(output)
+	}
+
+	if length == 0 {
+		return 0
+	}
+
+	_convertCU8ToComplexAVX(unsafe.Pointer(&input[0]), unsafe.Pointer(&output[0]), uint(length))
+
+	return length
+}
@@ -0,0 +1,64 @@
+//+build !noasm !appengine
+// Stand-in for the c2goasm output of c/convert_cu8_complex.c, written by hand. Run c/generate.sh (clang, c2goasm) to regenerate it
+
+// func _convertCU8ToComplexAVX(input, output unsafe.Pointer, length uint)
+TEXT ·_convertCU8ToComplexAVX(SB), $0-24
+
+    MOVQ input+0(FP), SI
+    MOVQ output+8(FP), DI
+    MOVQ length+16(FP), CX
+    SHLQ $1, CX                 // I and Q values
+
+    MOVL $0x42ff0000, AX        // 127.5
+    MOVL AX, X6
+    VSHUFPS $0, X6, X6, X6
+    VINSERTF128 $1, X6, Y6, Y6
+    MOVL $0x3c008081, AX        // 1 / 127.5
+    MOVL AX, X7
+    VSHUFPS $0, X7, X7, X7
+    VINSERTF128 $1, X7, Y7, Y7
+
+LOOP16:
+    CMPQ CX, $16
+    JB TAILSTART
+
+    VPMOVZXBD (SI), X0
+    VPMOVZXBD 4(SI), X1
+    VINSERTF128 $1, X1, Y0, Y0
+    VPMOVZXBD 8(SI), X2
+    VPMOVZXBD 12(SI), X3
+    VINSERTF128 $1, X3, Y2, Y2
+
+    VCVTDQ2PS Y0, Y0
+    VCVTDQ2PS Y2, Y2
+    VSUBPS Y6, Y0, Y0
+    VSUBPS Y6, Y2, Y2
+    VMULPS Y7, Y0, Y0
+    VMULPS Y7, Y2, Y2
+
+    VMOVUPS Y0, (DI)
+    VMOVUPS Y2, 32(DI)
+
+    ADDQ $16, SI
+    ADDQ $64, DI
+    SUBQ $16, CX
+    JMP LOOP16
+
+TAILSTART:
+    VZEROUPPER
+
+TAIL:
+    TESTQ CX, CX
+    JE DONE
+    MOVBLZX (SI), AX
+    CVTSL2SS AX, X0
+    SUBSS X6, X0
+    MULSS X7, X0
+    MOVSS X0, (DI)
+    INCQ SI
+    ADDQ $4, DI
+    DECQ CX
+    JMP TAIL
+
+DONE:
+    RET
@@ -0,0 +1,72 @@
+//+build !noasm !appengine
+// Stand-in for the c2goasm output of c/convert_cu8_complex.c, written by hand. Run c/generate.sh (clang, c2goasm) to regenerate it
+
+// func _convertCU8ToComplexSSE2(input, output unsafe.Pointer, length uint)
+TEXT ·_convertCU8ToComplexSSE2(SB), $0-24
+
+    MOVQ input+0(FP), SI
+    MOVQ output+8(FP), DI
+    MOVQ length+16(FP), CX
+    SHLQ $1, CX                 // I and Q values
+
+    MOVL $0x42ff0000, AX        // 127.5
+    MOVL AX, X6
+    SHUFPS $0, X6, X6
+    MOVL $0x3c008081, AX        // 1 / 127.5
+    MOVL AX, X7
+    SHUFPS $0, X7, X7
+    PXOR X5, X5
+
+LOOP16:
+    CMPQ CX, $16
+    JB TAIL
+
+    MOVOU (SI), X0
+    MOVO X0, X1
+    PUNPCKLBW X5, X0
+    PUNPCKHBW X5, X1
+    MOVO X0, X2
+    PUNPCKLWL X5, X0
+    PUNPCKHWL X5, X2
+    MOVO X1, X3
+    PUNPCKLWL X5, X1
+    PUNPCKHWL X5, X3
+
+    CVTPL2PS X0, X0
+    CVTPL2PS X2, X2
+    CVTPL2PS X1, X1
+    CVTPL2PS X3, X3
+    SUBPS X6, X0
+    SUBPS X6, X2
+    SUBPS X6, X1
+    SUBPS X6, X3
+    MULPS X7, X0
+    MULPS X7, X2
+    MULPS X7, X1
+    MULPS X7, X3
+
+    MOVUPS X0, (DI)
+    MOVUPS X2, 16(DI)
+    MOVUPS X1, 32(DI)
+    MOVUPS X3, 48(DI)
+
+    ADDQ $16, SI
+    ADDQ $64, DI
+    SUBQ $16, CX
+    JMP LOOP16
+
+TAIL:
+    TESTQ CX, CX
+    JE DONE
+    MOVBLZX (SI), AX
+    CVTSL2SS AX, X0
+    SUBSS X6, X0
+    MULSS X7, X0
+    MOVSS X0, (DI)
+    INCQ SI
+    ADDQ $4, DI
+    DECQ CX
+    JMP TAIL
+
+DONE:
+    RET
@@ -0,0 +1,17 @@
+#define _GENFUN(x, y) x##y
+#define GENFUN(x, y) _GENFUN(x, y)
+
+#include <xmmintrin.h>
+
+// Converts length complex samples to signed 16 bit (little endian) IQ, clipping at [-1, 1]
+void GENFUN(convertComplexToCS16, __SUBARCH__)(float *input, short *output, unsigned int length) {
+    unsigned int count = length * 2;
+
+    for (unsigned int i = 0; i < count; i++) {
+        float v = input[i];
+        v = v < -1.0f ? -1.0f : v;
+        v = v > 1.0f ? 1.0f : v;
+        int s = _mm_cvtss_si32(_mm_set_ss(v * 32768.0f));
+        output[i] = (short)(s > 32767 ? 32767 : s);
+    }
+}
@@ -0,0 +1,16 @@
+#define _GENFUN(x, y) x##y
+#define GENFUN(x, y) _GENFUN(x, y)
+
+#include <xmmintrin.h>
+
+// Converts length complex samples to unsigned 8 bit IQ, clipping at [-1, 1]
+void GENFUN(convertComplexToCU8, __SUBARCH__)(float *input, unsigned char *output, unsigned int length) {
+    unsigned int count = length * 2;
+
+    for (unsigned int i = 0; i < count; i++) {
+        float v = input[i];
+        v = v < -1.0f ? -1.0f : v;
+        v = v > 1.0f ? 1.0f : v;
+        output[i] = (unsigned char)_mm_cvtss_si32(_mm_set_ss(v * 127.5f + 127.5f));
+    }
+}
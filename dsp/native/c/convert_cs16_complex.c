@@ -0,0 +1,12 @@
+#define _GENFUN(x, y) x##y
+#define GENFUN(x, y) _GENFUN(x, y)
+
+// Converts length signed 16 bit (little endian) IQ samples to complex
+void GENFUN(convertCS16ToComplex, __SUBARCH__)(short *input, float *output, unsigned int length) {
+    const float scale = 1.0f / 32768.0f;
+    unsigned int count = length * 2;
+
+    for (unsigned int i = 0; i < count; i++) {
+        output[i] = (float)input[i] * scale;
+    }
+}
@@ -0,0 +1,12 @@
+#define _GENFUN(x, y) x##y
+#define GENFUN(x, y) _GENFUN(x, y)
+
+// Converts length unsigned 8 bit IQ samples to complex
+void GENFUN(convertCU8ToComplex, __SUBARCH__)(unsigned char *input, float *output, unsigned int length) {
+    const float scale = 1.0f / 127.5f;
+    unsigned int count = length * 2;
+
+    for (unsigned int i = 0; i < count; i++) {
+        output[i] = ((float)input[i] - 127.5f) * scale;
+    }
+}
@@ -94,6 +94,36 @@ sources = [
     "filename": "firfilter.c",
     "function": "FirFilter",
     "cFunction": "firFilter",
+  },
+  {
+    "filename": "convert_cu8_complex.c",
+    "function": "ConvertCU8ToComplex",
+    "cFunction": "convertCU8ToComplex",
+  },
+  {
+    "filename": "convert_complex_cu8.c",
+    "function": "ConvertComplexToCU8",
+    "cFunction": "convertComplexToCU8",
+  },
+  {
+    "filename": "convert_cs8_complex.c",
+    "function": "ConvertCS8ToComplex",
+    "cFunction": "convertCS8ToComplex",
+  },
+  {
+    "filename": "convert_complex_cs8.c",
+    "function": "ConvertComplexToCS8",
+    "cFunction": "convertComplexToCS8",
+  },
+  {
+    "filename": "convert_cs16_complex.c",
+    "function": "ConvertCS16ToComplex",
+    "cFunction": "convertCS16ToComplex",
+  },
+  {
+    "filename": "convert_complex_cs16.c",
+    "function": "ConvertComplexToCS16",
+    "cFunction": "convertComplexToCS16",
   }
 ]
 
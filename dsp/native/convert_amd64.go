@@ -0,0 +1,151 @@
+package native
+
+import "github.com/racerxdl/segdsp/dsp/native/amd64"
+
+// region Sample Format Conversion
+var nativeConvertCU8ToComplex func(input []byte, output []complex64) int
+var nativeConvertComplexToCU8 func(input []complex64, output []byte) int
+var nativeConvertCS8ToComplex func(input []byte, output []complex64) int
+var nativeConvertComplexToCS8 func(input []complex64, output []byte) int
+var nativeConvertCS16ToComplex func(input []byte, output []complex64) int
+var nativeConvertComplexToCS16 func(input []complex64, output []byte) int
+
+func ConvertCU8ToComplex(input []byte, output []complex64) int {
+	if nativeConvertCU8ToComplex == nil {
+		nativeConvertCU8ToComplex = GetNativeConvertCU8ToComplex()
+	}
+
+	if nativeConvertCU8ToComplex == nil {
+		panic("No native function available for arch")
+	}
+	return nativeConvertCU8ToComplex(input, output)
+}
+
+func ConvertComplexToCU8(input []complex64, output []byte) int {
+	if nativeConvertComplexToCU8 == nil {
+		nativeConvertComplexToCU8 = GetNativeConvertComplexToCU8()
+	}
+
+	if nativeConvertComplexToCU8 == nil {
+		panic("No native function available for arch")
+	}
+	return nativeConvertComplexToCU8(input, output)
+}
+
+func ConvertCS8ToComplex(input []byte, output []complex64) int {
+	if nativeConvertCS8ToComplex == nil {
+		nativeConvertCS8ToComplex = GetNativeConvertCS8ToComplex()
+	}
+
+	if nativeConvertCS8ToComplex == nil {
+		panic("No native function available for arch")
+	}
+	return nativeConvertCS8ToComplex(input, output)
+}
+
+func ConvertComplexToCS8(input []complex64, output []byte) int {
+	if nativeConvertComplexToCS8 == nil {
+		nativeConvertComplexToCS8 = GetNativeConvertComplexToCS8()
+	}
+
+	if nativeConvertComplexToCS8 == nil {
+		panic("No native function available for arch")
+	}
+	return nativeConvertComplexToCS8(input, output)
+}
+
+func ConvertCS16ToComplex(input []byte, output []complex64) int {
+	if nativeConvertCS16ToComplex == nil {
+		nativeConvertCS16ToComplex = GetNativeConvertCS16ToComplex()
+	}
+
+	if nativeConvertCS16ToComplex == nil {
+		panic("No native function available for arch")
+	}
+	return nativeConvertCS16ToComplex(input, output)
+}
+
+func ConvertComplexToCS16(input []complex64, output []byte) int {
+	if nativeConvertComplexToCS16 == nil {
+		nativeConvertComplexToCS16 = GetNativeConvertComplexToCS16()
+	}
+
+	if nativeConvertComplexToCS16 == nil {
+		panic("No native function available for arch")
+	}
+	return nativeConvertComplexToCS16(input, output)
+}
+
+func GetNativeConvertCU8ToComplex() func(input []byte, output []complex64) int {
+	if amd64.AVX {
+		return amd64.ConvertCU8ToComplexAVX
+	}
+
+	if amd64.SSE2 {
+		return amd64.ConvertCU8ToComplexSSE2
+	}
+
+	return nil
+}
+
+func GetNativeConvertComplexToCU8() func(input []complex64, output []byte) int {
+	if amd64.AVX {
+		return amd64.ConvertComplexToCU8AVX
+	}
+
+	if amd64.SSE2 {
+		return amd64.ConvertComplexToCU8SSE2
+	}
+
+	return nil
+}
+
+func GetNativeConvertCS8ToComplex() func(input []byte, output []complex64) int {
+	if amd64.AVX {
+		return amd64.ConvertCS8ToComplexAVX
+	}
+
+	if amd64.SSE2 {
+		return amd64.ConvertCS8ToComplexSSE2
+	}
+
+	return nil
+}
+
+func GetNativeConvertComplexToCS8() func(input []complex64, output []byte) int {
+	if amd64.AVX {
+		return amd64.ConvertComplexToCS8AVX
+	}
+
+	if amd64.SSE2 {
+		return amd64.ConvertComplexToCS8SSE2
+	}
+
+	return nil
+}
+
+func GetNativeConvertCS16ToComplex() func(input []byte, output []complex64) int {
+	if amd64.AVX {
+		return amd64.ConvertCS16ToComplexAVX
+	}
+
+	if amd64.SSE2 {
+		return amd64.ConvertCS16ToComplexSSE2
+	}
+
+	return nil
+}
+
+func GetNativeConvertComplexToCS16() func(input []complex64, output []byte) int {
+	if amd64.AVX {
+		return amd64.ConvertComplexToCS16AVX
+	}
+
+	if amd64.SSE2 {
+		return amd64.ConvertComplexToCS16SSE2
+	}
+
+	return nil
+}
+
+// endregion
@@ -105,3 +105,51 @@ func GetNativeAddComplexComplexVectors() func(A, B []complex64) {
 func GetNativeSubtractComplexComplexVectors() func(A, B []complex64) {
 	return nil
 }
+
+func ConvertCU8ToComplex(input []byte, output []complex64) int {
+	panic("No native function available for arch")
+}
+
+func GetNativeConvertCU8ToComplex() func(input []byte, output []complex64) int {
+	return nil
+}
+
+func ConvertComplexToCU8(input []complex64, output []byte) int {
+	panic("No native function available for arch")
+}
+
+func GetNativeConvertComplexToCU8() func(input []complex64, output []byte) int {
+	return nil
+}
+
+func ConvertCS8ToComplex(input []byte, output []complex64) int {
+	panic("No native function available for arch")
+}
+
+func GetNativeConvertCS8ToComplex() func(input []byte, output []complex64) int {
+	return nil
+}
+
+func ConvertComplexToCS8(input []complex64, output []byte) int {
+	panic("No native function available for arch")
+}
+
+func GetNativeConvertComplexToCS8() func(input []complex64, output []byte) int {
+	return nil
+}
+
+func ConvertCS16ToComplex(input []byte, output []complex64) int {
+	panic("No native function available for arch")
+}
+
+func GetNativeConvertCS16ToComplex() func(input []byte, output []complex64) int {
+	return nil
+}
+
+func ConvertComplexToCS16(input []complex64, output []byte) int {
+	panic("No native function available for arch")
+}
+
+func GetNativeConvertComplexToCS16() func(input []complex64, output []byte) int {
+	return nil
+}
@@ -39,11 +39,20 @@ func (nco *NCO) AdjustFrequency(deltaRate float32) {
 // Step makes a single step in NCO
 func (nco *NCO) Step() {
 	nco.phase += nco.phaseIncrement
+	nco.phaseWrap()
 }
 
 // StepN makes N steps in NCO
 func (nco *NCO) StepN(n int) {
 	nco.phase += nco.phaseIncrement * float32(n)
+	nco.phaseWrap()
+}
+
+// phaseWrap keeps the phase between -PI and PI to avoid losing float32 precision over long runs
+func (nco *NCO) phaseWrap() {
+	if nco.phase > math.Pi || nco.phase < -math.Pi {
+		nco.phase = float32(math.Remainder(float64(nco.phase), 2*math.Pi))
+	}
 }
 
 // GetPhase returns the current phase value in radians
@@ -61,6 +70,7 @@ func (nco *NCO) Float32Sin(n int, amplitude float32) []float32 {
 	var d = make([]float32, n)
 	for i := 0; i < n; i++ {
 		d[i] = float32(math.Sin(float64(nco.phase))) * amplitude
+		nco.Step()
 	}
 	return d
 }
@@ -70,16 +80,18 @@ func (nco *NCO) Float32Cos(n int, amplitude float32) []float32 {
 	var d = make([]float32, n)
 	for i := 0; i < n; i++ {
 		d[i] = float32(math.Cos(float64(nco.phase))) * amplitude
+		nco.Step()
 	}
 	return d
 }
 
-// Float32Sin Compute N elements for a float32 array sine wave
+// Complex64SinCos Compute N elements for a complex64 array with cos(phase) + j * sin(phase)
 func (nco *NCO) Complex64SinCos(n int, amplitude float32) []complex64 {
 	var d = make([]complex64, n)
 	for i := 0; i < n; i++ {
-		a, b := math.Sincos(float64(nco.phase))
-		d[i] = complex(float32(a)*amplitude, float32(b)*amplitude)
+		s, c := math.Sincos(float64(nco.phase))
+		d[i] = complex(float32(c)*amplitude, float32(s)*amplitude)
+		nco.Step()
 	}
 	return d
 }
@@ -0,0 +1,196 @@
+package dsp
+
+import "github.com/racerxdl/segdsp/tools"
+
+type NoiseBlankerMode int
+
+const (
+	// NoiseBlankerBlank replaces the impulses by zeroes
+	NoiseBlankerBlank NoiseBlankerMode = iota
+	// NoiseBlankerInterpolate replaces the impulses by a linear interpolation between the surrounding good samples
+	NoiseBlankerInterpolate
+)
+
+const noiseBlankerDefaultAlpha = 1e-3
+
+// NoiseBlanker detects impulses against the running average magnitude and blanks (or interpolates over) them.
+// It should run over raw IQ, before any channel filter spreads the impulses.
+// The output is delayed by the blanking width, so the samples before the impulse can also be blanked.
+type NoiseBlanker struct {
+	threshold      float32
+	blankingWidth  int
+	mode           NoiseBlankerMode
+	avg            *SinglePoleIIRFilter
+	sampleHistory  []complex64
+	maskHistory    []bool
+	pendingBlank   int
+	lastGood       complex64
+	runCount       int
+	blankedSamples uint64
+	warmUp         int
+}
+
+// MakeNoiseBlanker creates a Noise Blanker.
+// threshold is the ratio over the average magnitude that a sample should be to be considered an impulse.
+// blankingWidth is the number of samples blanked around each impulse.
+func MakeNoiseBlanker(threshold float32, blankingWidth int, mode NoiseBlankerMode) *NoiseBlanker {
+	if blankingWidth < 1 {
+		panic("Noise Blanker width should be at least 1")
+	}
+
+	return &NoiseBlanker{
+		threshold:     threshold,
+		blankingWidth: blankingWidth,
+		mode:          mode,
+		avg:           MakeSinglePoleIIRFilter(noiseBlankerDefaultAlpha),
+		sampleHistory: make([]complex64, blankingWidth),
+		maskHistory:   make([]bool, blankingWidth),
+		warmUp:        int(1 / noiseBlankerDefaultAlpha),
+	}
+}
+
+func (nb *NoiseBlanker) SetThreshold(threshold float32) {
+	nb.threshold = threshold
+}
+
+func (nb *NoiseBlanker) GetThreshold() float32 {
+	return nb.threshold
+}
+
+func (nb *NoiseBlanker) SetMode(mode NoiseBlankerMode) {
+	nb.mode = mode
+}
+
+func (nb *NoiseBlanker) GetMode() NoiseBlankerMode {
+	return nb.mode
+}
+
+// SetAlpha sets the running average magnitude filter alpha
+func (nb *NoiseBlanker) SetAlpha(alpha float32) {
+	nb.avg.SetTaps(alpha)
+	nb.warmUp = int(1 / alpha)
+}
+
+func (nb *NoiseBlanker) GetBlankingWidth() int {
+	return nb.blankingWidth
+}
+
+// GetBlankedSamples returns the number of samples that were blanked since the creation (or last reset)
+func (nb *NoiseBlanker) GetBlankedSamples() uint64 {
+	return nb.blankedSamples
+}
+
+// ResetBlankedSamples resets the blanked samples counter
+func (nb *NoiseBlanker) ResetBlankedSamples() {
+	nb.blankedSamples = 0
+}
+
+// GetAverageMagnitude returns the current running average magnitude
+func (nb *NoiseBlanker) GetAverageMagnitude() float32 {
+	return nb.avg.GetPreviousOutput()
+}
+
+func (nb *NoiseBlanker) Work(input []complex64) []complex64 {
+	var output = make([]complex64, nb.PredictOutputSize(len(input)))
+	nb.WorkBuffer(input, output)
+	return output
+}
+
+func (nb *NoiseBlanker) WorkBuffer(input, output []complex64) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var samples = append(nb.sampleHistory, input...)
+	var mask = append(nb.maskHistory, make([]bool, len(input))...)
+	var halfWidth = nb.blankingWidth / 2
+	var start = len(nb.sampleHistory)
+
+	// region Impulse Detection
+	for j := start; j < len(samples); j++ {
+		if nb.pendingBlank > 0 {
+			mask[j] = true
+			nb.pendingBlank--
+		}
+
+		var mag = tools.Sqrt(tools.ComplexAbsSquared(samples[j]))
+		var avg = nb.avg.GetPreviousOutput()
+
+		if nb.warmUp > 0 {
+			// Wait the average to settle before detecting anything
+			nb.warmUp--
+			nb.avg.Filter(mag)
+			continue
+		}
+
+		var limit = nb.threshold * avg
+
+		// The average follows the magnitude clipped at the threshold, so a single impulse barely moves it, but a
+		// signal that keys up over the threshold pulls it up (and stops being blanked) after a while
+		var clipped = mag
+		if clipped > limit {
+			clipped = limit
+		}
+		nb.avg.Filter(clipped)
+
+		if mag > limit {
+			for k := j - halfWidth; k < j+nb.blankingWidth-halfWidth; k++ {
+				if k >= len(samples) {
+					var pending = j + nb.blankingWidth - halfWidth - k
+					if pending > nb.pendingBlank {
+						nb.pendingBlank = pending
+					}
+					break
+				}
+				mask[k] = true
+			}
+		}
+	}
+	// endregion
+	// region Blanking
+	for i := 0; i < len(input); i++ {
+		if !mask[i] {
+			output[i] = samples[i]
+			nb.lastGood = samples[i]
+			nb.runCount = 0
+			continue
+		}
+
+		nb.blankedSamples++
+
+		if nb.mode == NoiseBlankerBlank {
+			output[i] = 0
+			continue
+		}
+
+		var target = nb.lastGood
+		var distance = 0
+		for e := i + 1; e < len(samples); e++ {
+			if !mask[e] {
+				target = samples[e]
+				distance = e - i
+				break
+			}
+		}
+
+		if distance == 0 {
+			// No good sample in sight, just hold the last one
+			output[i] = nb.lastGood
+		} else {
+			var t = float32(nb.runCount+1) / float32(nb.runCount+distance+1)
+			output[i] = nb.lastGood + (target-nb.lastGood)*complex(t, 0)
+		}
+
+		nb.runCount++
+	}
+	// endregion
+
+	nb.sampleHistory = samples[len(input):]
+	nb.maskHistory = mask[len(input):]
+
+	return len(input)
+}
+
+func (nb *NoiseBlanker) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
@@ -0,0 +1,79 @@
+package dsp
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+func TestNoiseBlanker(t *testing.T) {
+	const length = 1 << 16
+	const width = 8
+
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]complex64, length)
+	var impulses = []int{20000, 35000, 50000}
+
+	for i := range samples {
+		samples[i] = complex(float32(r.NormFloat64())*0.1, float32(r.NormFloat64())*0.1)
+	}
+
+	for _, v := range impulses {
+		samples[v] = complex(100, -100)
+	}
+
+	for _, mode := range []NoiseBlankerMode{NoiseBlankerBlank, NoiseBlankerInterpolate} {
+		var nb = MakeNoiseBlanker(10, width, mode)
+		var output = nb.Work(samples)
+		output = append(output, nb.Work(make([]complex64, width))...)
+
+		for _, v := range impulses {
+			// Output is delayed by the blanking width
+			var o = output[v+width]
+			if tools.ComplexAbs(o) > 1 {
+				t.Errorf("Mode %d: Expected impulse at %d to be blanked, got %v", mode, v, o)
+			}
+		}
+
+		if nb.GetBlankedSamples() < uint64(len(impulses)) || nb.GetBlankedSamples() > uint64(len(impulses)*width) {
+			t.Errorf("Mode %d: Expected between %d and %d blanked samples got %d", mode, len(impulses), len(impulses)*width, nb.GetBlankedSamples())
+		}
+	}
+}
+
+func TestNoiseBlankerStepUp(t *testing.T) {
+	const noiseLength = 20000
+	const carrierLength = 200000
+	const width = 8
+
+	var r = rand.New(rand.NewSource(1))
+	var samples = make([]complex64, noiseLength+carrierLength)
+
+	for i := range samples {
+		samples[i] = complex(float32(r.NormFloat64())*0.01, float32(r.NormFloat64())*0.01)
+	}
+
+	// A carrier keys up far over the threshold and stays
+	for i := noiseLength; i < len(samples); i++ {
+		samples[i] += 0.5
+	}
+
+	for _, mode := range []NoiseBlankerMode{NoiseBlankerBlank, NoiseBlankerInterpolate} {
+		var nb = MakeNoiseBlanker(10, width, mode)
+		var output = nb.Work(samples)
+
+		// The carrier is blanked only while the average catches up
+		var blanked = nb.GetBlankedSamples()
+		if blanked > 5000 {
+			t.Errorf("Mode %d: Expected the average to follow the carrier, got %d blanked samples", mode, blanked)
+		}
+
+		// Output is delayed by the blanking width
+		for i := len(output) - carrierLength/2; i < len(output); i++ {
+			if output[i] != samples[i-width] {
+				t.Fatalf("Mode %d: Expected the carrier to pass unchanged at %d, got %v", mode, i, output[i])
+			}
+		}
+	}
+}
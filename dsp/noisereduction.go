@@ -0,0 +1,181 @@
+package dsp
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/dsp/fft"
+	"github.com/racerxdl/segdsp/tools"
+)
+
+const nrDefaultSmoothing = 0.7
+const nrDefaultDecisionDirected = 0.98
+const nrDefaultNoiseRise = 1.004
+
+// SpectralNoiseReduction is a Wiener Filter noise reduction for audio.
+// It uses 50% overlapped STFT frames with a square-root Hann window (for both analysis and synthesis),
+// a minimum-tracking noise floor estimation per bin and a decision-directed a priori SNR estimation.
+// The output is delayed by half frame.
+type SpectralNoiseReduction struct {
+	frameSize   int
+	hopSize     int
+	reduction   float32
+	gainFloor   float32
+	noiseRise   float32
+	window      []float32
+	frame       []float32
+	framePos    int
+	overlap     []float32
+	fftBuffer   []complex64
+	forward     *fft.Plan
+	backward    *fft.Plan
+	smoothPower []float32
+	noiseFloor  []float32
+	lastGain    []float32
+	gain        []float32
+	initialized bool
+}
+
+// MakeSpectralNoiseReduction creates a new Noise Reduction block.
+// frameSize should be a power of two and reduction is the maximum attenuation (in dB) applied to the noise.
+func MakeSpectralNoiseReduction(frameSize int, reduction float32) *SpectralNoiseReduction {
+	if !tools.IsPowerOf2(frameSize) || frameSize < 4 {
+		panic("Noise Reduction frame size should be a power of two")
+	}
+
+	var window = make([]float32, frameSize)
+
+	for i := 0; i < frameSize; i++ {
+		window[i] = float32(math.Sin(math.Pi * float64(i) / float64(frameSize)))
+	}
+
+	var nr = &SpectralNoiseReduction{
+		frameSize:   frameSize,
+		hopSize:     frameSize / 2,
+		noiseRise:   nrDefaultNoiseRise,
+		window:      window,
+		frame:       make([]float32, frameSize),
+		framePos:    frameSize / 2,
+		overlap:     make([]float32, frameSize/2),
+		fftBuffer:   make([]complex64, frameSize),
+		forward:     fft.MakePlan(frameSize, fft.Forward),
+		backward:    fft.MakePlan(frameSize, fft.Backward),
+		smoothPower: make([]float32, frameSize),
+		noiseFloor:  make([]float32, frameSize),
+		lastGain:    make([]float32, frameSize),
+		gain:        make([]float32, frameSize),
+	}
+
+	nr.SetReduction(reduction)
+
+	return nr
+}
+
+// SetReduction sets the maximum attenuation (in dB) applied to the noise
+func (nr *SpectralNoiseReduction) SetReduction(reduction float32) {
+	if reduction < 0 {
+		reduction = 0
+	}
+	nr.reduction = reduction
+	nr.gainFloor = float32(math.Pow(10, -float64(reduction)/20))
+}
+
+// GetReduction returns the maximum attenuation (in dB) applied to the noise
+func (nr *SpectralNoiseReduction) GetReduction() float32 {
+	return nr.reduction
+}
+
+// SetNoiseRise sets how fast (as a power factor per frame) the noise floor estimation can rise
+func (nr *SpectralNoiseReduction) SetNoiseRise(rise float32) {
+	nr.noiseRise = rise
+}
+
+// GetFrameSize returns the STFT frame size
+func (nr *SpectralNoiseReduction) GetFrameSize() int {
+	return nr.frameSize
+}
+
+// GetNoiseFloor returns the current per-bin noise floor power estimation
+func (nr *SpectralNoiseReduction) GetNoiseFloor() []float32 {
+	return nr.noiseFloor
+}
+
+func (nr *SpectralNoiseReduction) processFrame(output []float32) {
+	for i := 0; i < nr.frameSize; i++ {
+		nr.fftBuffer[i] = complex(nr.frame[i]*nr.window[i], 0)
+	}
+
+	nr.forward.ExecuteInPlace(nr.fftBuffer)
+	var spectrum = nr.fftBuffer
+
+	for i, v := range spectrum {
+		var power = tools.ComplexAbsSquared(v)
+
+		if !nr.initialized {
+			nr.smoothPower[i] = power
+			nr.noiseFloor[i] = power
+			nr.lastGain[i] = 1
+		}
+
+		nr.smoothPower[i] = nrDefaultSmoothing*nr.smoothPower[i] + (1-nrDefaultSmoothing)*power
+
+		// Minimum tracking: Follows the smoothed power down immediately, rises slowly.
+		nr.noiseFloor[i] *= nr.noiseRise
+		if nr.smoothPower[i] < nr.noiseFloor[i] {
+			nr.noiseFloor[i] = nr.smoothPower[i]
+		}
+
+		var noise = nr.noiseFloor[i] + 1e-20
+		var postSNR = power / noise
+		var prioriSNR = nrDefaultDecisionDirected*nr.lastGain[i]*nr.lastGain[i]*postSNR + (1-nrDefaultDecisionDirected)*float32(math.Max(float64(postSNR-1), 0))
+		var gain = prioriSNR / (1 + prioriSNR)
+
+		if gain < nr.gainFloor {
+			gain = nr.gainFloor
+		}
+
+		nr.lastGain[i] = gain
+		spectrum[i] = complex(real(v)*gain, imag(v)*gain)
+	}
+
+	nr.initialized = true
+
+	nr.backward.ExecuteInPlace(spectrum)
+	var timeDomain = spectrum
+
+	for i := 0; i < nr.hopSize; i++ {
+		output[i] = nr.overlap[i] + real(timeDomain[i])*nr.window[i]
+		nr.overlap[i] = real(timeDomain[i+nr.hopSize]) * nr.window[i+nr.hopSize]
+	}
+}
+
+func (nr *SpectralNoiseReduction) Work(input []float32) []float32 {
+	var output = make([]float32, nr.PredictOutputSize(len(input)))
+	var l = nr.WorkBuffer(input, output)
+	return output[:l]
+}
+
+func (nr *SpectralNoiseReduction) WorkBuffer(input, output []float32) int {
+	if len(output) < nr.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var outputPos = 0
+
+	for _, v := range input {
+		nr.frame[nr.framePos] = v
+		nr.framePos++
+
+		if nr.framePos == nr.frameSize {
+			nr.processFrame(output[outputPos : outputPos+nr.hopSize])
+			outputPos += nr.hopSize
+			copy(nr.frame, nr.frame[nr.hopSize:])
+			nr.framePos = nr.hopSize
+		}
+	}
+
+	return outputPos
+}
+
+func (nr *SpectralNoiseReduction) PredictOutputSize(inputLength int) int {
+	return (inputLength/nr.hopSize + 1) * nr.hopSize
+}
@@ -0,0 +1,101 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSpectralNoiseReduction(t *testing.T) {
+	const frameSize = 256
+	const length = 1 << 15
+
+	var r = rand.New(rand.NewSource(1))
+	var noise = make([]float32, length)
+	for i := range noise {
+		noise[i] = float32(r.NormFloat64()) * 0.1
+	}
+
+	// No reduction should give back the input delayed by half frame
+	var nr = MakeSpectralNoiseReduction(frameSize, 0)
+	var output = nr.Work(noise)
+	var delay = frameSize / 2
+
+	for i := delay; i < len(output); i++ {
+		if math.Abs(float64(output[i]-noise[i-delay])) > 1e-4 {
+			t.Fatalf("Expected %f got %f at %d", noise[i-delay], output[i], i)
+		}
+	}
+
+	// With 20 dB of reduction, the noise power should drop at least 6 dB after settling
+	nr = MakeSpectralNoiseReduction(frameSize, 20)
+	output = nr.Work(noise)
+
+	var inPower, outPower float64
+	for i := length / 2; i < len(output); i++ {
+		inPower += float64(noise[i-delay] * noise[i-delay])
+		outPower += float64(output[i] * output[i])
+	}
+
+	var attenuation = 10 * math.Log10(inPower/outPower)
+	if attenuation < 6 {
+		t.Fatalf("Expected at least 6 dB of noise attenuation, got %f dB", attenuation)
+	}
+}
+
+func TestSpectralNoiseReductionKeepsSignal(t *testing.T) {
+	const frameSize = 256
+	const length = 1 << 16
+	const burstLength = 4096
+	const frequency = 0.05 // cycles per sample
+	const amplitude = 0.5
+
+	// Speech like tone bursts over noise, so the noise floor estimation has pauses to track
+	var r = rand.New(rand.NewSource(1))
+	var input = make([]float32, length)
+	var tone = make([]float32, length)
+	for i := range input {
+		if (i/burstLength)%2 == 1 {
+			tone[i] = amplitude * float32(math.Sin(2*math.Pi*frequency*float64(i)))
+		}
+		input[i] = tone[i] + float32(r.NormFloat64())*0.05
+	}
+
+	var nr = MakeSpectralNoiseReduction(frameSize, 20)
+	var output = nr.Work(input)
+	var delay = frameSize / 2
+
+	// Project the output over the tone, in the bursts after settling, so the remaining noise does not count
+	var toneAmplitude []float64
+	for start := length / 2; start+burstLength <= length; start += 2 * burstLength {
+		var burst = start + burstLength
+		var sin, cos float64
+		for i := burst + frameSize; i < burst+burstLength-frameSize; i++ {
+			var s, c = math.Sincos(2 * math.Pi * frequency * float64(i))
+			sin += float64(output[i+delay]) * s
+			cos += float64(output[i+delay]) * c
+		}
+		toneAmplitude = append(toneAmplitude, 2*math.Hypot(sin, cos)/float64(burstLength-2*frameSize))
+	}
+
+	for i, a := range toneAmplitude {
+		var loss = 20 * math.Log10(amplitude/a)
+		if math.Abs(loss) > 1 {
+			t.Errorf("Burst %d: Expected the tone to pass within 1 dB, got %f dB of loss", i, loss)
+		}
+	}
+
+	// And the pauses should still be attenuated
+	var inPower, outPower float64
+	for start := length / 2; start+burstLength <= length; start += 2 * burstLength {
+		for i := start + frameSize; i < start+burstLength-frameSize; i++ {
+			inPower += float64(input[i] * input[i])
+			outPower += float64(output[i+delay] * output[i+delay])
+		}
+	}
+
+	var attenuation = 10 * math.Log10(inPower/outPower)
+	if attenuation < 6 {
+		t.Errorf("Expected at least 6 dB of noise attenuation in the pauses, got %f dB", attenuation)
+	}
+}
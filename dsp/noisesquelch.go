@@ -0,0 +1,132 @@
+package dsp
+
+import "math"
+
+const noiseSquelchFilterLength = 31
+
+// NoiseSquelch is a FM squelch that measures the high frequency noise energy at the discriminator output.
+// A FM signal quiets the discriminator output above the audio band, while noise (or a too weak signal) gives a lot of
+// energy there. So it opens when the noise level goes under the threshold, and closes when it goes over the threshold
+// plus the hysteresis. Attack and hang times (in samples) can be used to avoid fluttering.
+// It should be fed with the Quadrature Demodulator output, before any audio filter.
+type NoiseSquelch struct {
+	highPass       *FloatFirFilter
+	filter         *SinglePoleIIRFilter
+	threshold      float32
+	thresholddB    float32
+	hysteresis     float32
+	closeThreshold float32
+	noiseLevel     float32
+	muted          bool
+	gate           squelchGate
+}
+
+// MakeNoiseSquelch creates a Noise Squelch with threshold in dB.
+// The noise is measured above cutFrequency, so it should be higher than the audio (or baseband) bandwidth.
+func MakeNoiseSquelch(threshold, alpha, sampleRate, cutFrequency float32) *NoiseSquelch {
+	if cutFrequency <= 0 || cutFrequency >= sampleRate/2 {
+		panic("Noise Squelch cut frequency should be between 0 and sampleRate / 2")
+	}
+
+	var s = &NoiseSquelch{
+		highPass: MakeFloatFirFilter(MakeHighPassFixed(1, float64(sampleRate), float64(cutFrequency), noiseSquelchFilterLength)),
+		filter:   MakeSinglePoleIIRFilter(alpha),
+		muted:    true,
+	}
+
+	s.SetThreshold(threshold)
+
+	return s
+}
+
+// GetAvgLevel returns the current noise level in dB
+func (f *NoiseSquelch) GetAvgLevel() float32 {
+	return float32(10 * math.Log10(float64(f.noiseLevel)))
+}
+
+func (f *NoiseSquelch) IsMuted() bool {
+	return f.muted
+}
+
+func (f *NoiseSquelch) SetAlpha(alpha float32) {
+	f.filter.SetTaps(alpha)
+}
+
+func (f *NoiseSquelch) SetThreshold(dB float32) {
+	f.thresholddB = dB
+	f.threshold = float32(math.Pow(10, float64(dB/10.0)))
+	f.closeThreshold = float32(math.Pow(10, float64((dB+f.hysteresis)/10.0)))
+}
+
+func (f *NoiseSquelch) GetThreshold() float32 {
+	return f.thresholddB
+}
+
+// SetHysteresis sets how much (in dB) the noise should go over the threshold to close the squelch
+func (f *NoiseSquelch) SetHysteresis(dB float32) {
+	f.hysteresis = dB
+	f.SetThreshold(f.thresholddB)
+}
+
+func (f *NoiseSquelch) GetHysteresis() float32 {
+	return f.hysteresis
+}
+
+// SetAttack sets for how many samples the noise should stay under the threshold to open the squelch
+func (f *NoiseSquelch) SetAttack(samples int) {
+	f.gate.attack = samples
+}
+
+func (f *NoiseSquelch) GetAttack() int {
+	return f.gate.attack
+}
+
+// SetHang sets for how many samples the squelch stays open after the noise goes over the close threshold
+func (f *NoiseSquelch) SetHang(samples int) {
+	f.gate.hang = samples
+}
+
+func (f *NoiseSquelch) GetHang() int {
+	return f.gate.hang
+}
+
+func (f *NoiseSquelch) Work(data []float32) []float32 {
+	var output = make([]float32, f.PredictOutputSize(len(data)))
+	f.WorkBuffer(data, output)
+	return output
+}
+
+func (f *NoiseSquelch) WorkBuffer(input, output []float32) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	if len(input) == 0 {
+		return 0
+	}
+
+	var noise = f.highPass.FilterOut(input)
+	var avg = float32(0)
+
+	for _, v := range noise {
+		avg += f.filter.Filter(v * v)
+	}
+
+	avg /= float32(len(noise))
+	f.noiseLevel = avg
+	f.muted = !f.gate.update(avg < f.threshold, avg >= f.closeThreshold, len(input))
+
+	if f.muted {
+		for i := range input {
+			output[i] = 0
+		}
+	} else {
+		copy(output, input)
+	}
+
+	return len(input)
+}
+
+func (f *NoiseSquelch) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
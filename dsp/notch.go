@@ -0,0 +1,183 @@
+package dsp
+
+import "math"
+
+type NotchMode int
+
+const (
+	// NotchAuto uses a NLMS adaptive line enhancer to find and remove any steady tone
+	NotchAuto NotchMode = iota
+	// NotchManual uses a fixed IIR notch at a given frequency
+	NotchManual
+)
+
+const notchEpsilon = 1e-6
+
+// NotchFilter removes steady tones (heterodynes) from audio.
+// In auto mode, a NLMS filter predicts the current sample from a delayed copy of the input. Tones are predictable
+// while speech and noise are (mostly) not decorrelated after the delay, so the prediction error is the input without the tones.
+// In manual mode, a second order IIR notch is used at the specified frequency.
+type NotchFilter struct {
+	mode          NotchMode
+	step          float32
+	delay         int
+	weights       []float32
+	sampleHistory []float32
+
+	frequency  float32
+	bandwidth  float32
+	sampleRate float32
+	iir        *IIRFilter
+}
+
+// MakeAutoNotch creates a NLMS auto-notch filter with nTaps, predicting from samples delayed by delay samples.
+// step is the normalized adaptation step size (between 0 and 2, usually around 0.01)
+func MakeAutoNotch(nTaps, delay int, step float32) *NotchFilter {
+	if nTaps < 1 {
+		panic("Notch number of taps should be at least 1")
+	}
+
+	if delay < 1 {
+		panic("Notch delay should be at least 1")
+	}
+
+	return &NotchFilter{
+		mode:          NotchAuto,
+		step:          step,
+		delay:         delay,
+		weights:       make([]float32, nTaps),
+		sampleHistory: make([]float32, nTaps+delay-1),
+	}
+}
+
+// MakeManualNotch creates an IIR notch filter at frequency with the specified bandwidth (both in Hertz)
+func MakeManualNotch(frequency, bandwidth, sampleRate float32) *NotchFilter {
+	var n = &NotchFilter{
+		mode: NotchManual,
+	}
+
+	n.SetNotchFrequency(frequency, bandwidth, sampleRate)
+
+	return n
+}
+
+// SetNotchFrequency changes to manual mode with a notch at frequency with the specified bandwidth (both in Hertz)
+func (n *NotchFilter) SetNotchFrequency(frequency, bandwidth, sampleRate float32) {
+	if frequency <= 0 || frequency >= sampleRate/2 {
+		panic("Notch frequency should be between 0 and sampleRate / 2")
+	}
+
+	if bandwidth <= 0 {
+		panic("Notch bandwidth should be higher than 0")
+	}
+
+	var w0 = 2 * math.Pi * float64(frequency) / float64(sampleRate)
+	var q = float64(frequency) / float64(bandwidth)
+	var alpha = math.Sin(w0) / (2 * q)
+	var a0 = 1 + alpha
+	var cosW0 = math.Cos(w0)
+
+	var ffTaps = []float32{
+		float32(1 / a0),
+		float32(-2 * cosW0 / a0),
+		float32(1 / a0),
+	}
+
+	var fbTaps = []float32{
+		1,
+		float32(-2 * cosW0 / a0),
+		float32((1 - alpha) / a0),
+	}
+
+	n.frequency = frequency
+	n.bandwidth = bandwidth
+	n.sampleRate = sampleRate
+	n.iir = MakeIIRFilter(ffTaps, fbTaps)
+	n.mode = NotchManual
+}
+
+// SetAuto changes to the NLMS auto-notch mode. Fails if the filter was not created with MakeAutoNotch.
+func (n *NotchFilter) SetAuto() {
+	if len(n.weights) == 0 {
+		panic("Notch was not created as an auto notch")
+	}
+	n.mode = NotchAuto
+}
+
+func (n *NotchFilter) GetMode() NotchMode {
+	return n.mode
+}
+
+// SetStep sets the normalized adaptation step size
+func (n *NotchFilter) SetStep(step float32) {
+	n.step = step
+}
+
+func (n *NotchFilter) GetStep() float32 {
+	return n.step
+}
+
+func (n *NotchFilter) GetDelay() int {
+	return n.delay
+}
+
+func (n *NotchFilter) GetNumTaps() int {
+	return len(n.weights)
+}
+
+// GetFrequency returns the manual notch frequency in Hertz
+func (n *NotchFilter) GetFrequency() float32 {
+	return n.frequency
+}
+
+// GetBandwidth returns the manual notch bandwidth in Hertz
+func (n *NotchFilter) GetBandwidth() float32 {
+	return n.bandwidth
+}
+
+// GetWeights returns the current NLMS predictor weights
+func (n *NotchFilter) GetWeights() []float32 {
+	return n.weights
+}
+
+func (n *NotchFilter) Work(input []float32) []float32 {
+	var output = make([]float32, n.PredictOutputSize(len(input)))
+	n.WorkBuffer(input, output)
+	return output
+}
+
+func (n *NotchFilter) WorkBuffer(input, output []float32) int {
+	if len(output) < len(input) {
+		panic("There is not enough space in output buffer")
+	}
+
+	if n.mode == NotchManual {
+		return n.iir.FilterArrayBuffer(input, output)
+	}
+
+	var samples = append(n.sampleHistory, input...)
+	var nTaps = len(n.weights)
+	var historyLen = len(n.sampleHistory)
+
+	for i := 0; i < len(input); i++ {
+		var reference = samples[i : i+nTaps]
+		var prediction = DotProductFloatResult(reference, n.weights)
+		var power = DotProductFloatResult(reference, reference)
+		var e = samples[historyLen+i] - prediction
+		var mu = n.step * e / (power + notchEpsilon)
+
+		for k := 0; k < nTaps; k++ {
+			n.weights[k] += mu * reference[k]
+		}
+
+		output[i] = e
+	}
+
+	n.sampleHistory = samples[len(input):]
+
+	return len(input)
+}
+
+func (n *NotchFilter) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
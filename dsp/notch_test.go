@@ -0,0 +1,103 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const notchTestSampleRate = 8000
+const notchTestToneFrequency = 1000
+
+// speechLikeNoise generates low passed noise with a syllabic (4 Hz) envelope
+func speechLikeNoise(length int, amplitude float32) []float32 {
+	var r = rand.New(rand.NewSource(1))
+	var lp = MakeSinglePoleIIRFilter(0.3)
+	var noise = make([]float32, length)
+
+	for i := range noise {
+		var envelope = float32(math.Abs(math.Sin(2 * math.Pi * 4 * float64(i) / notchTestSampleRate)))
+		noise[i] = lp.Filter(float32(r.NormFloat64())) * envelope * amplitude
+	}
+
+	return noise
+}
+
+// tonePower measures the power of a tone at frequency (Hz) using a quadrature correlation
+func tonePower(data []float32, frequency float64) float64 {
+	var i, q float64
+	for n, v := range data {
+		var s, c = math.Sincos(2 * math.Pi * frequency * float64(n) / notchTestSampleRate)
+		i += float64(v) * c
+		q += float64(v) * s
+	}
+
+	i /= float64(len(data))
+	q /= float64(len(data))
+
+	return i*i + q*q
+}
+
+func makeNotchTestSignal(length int) (mixed, noise []float32) {
+	var nco = MakeNCO()
+	nco.SetFrequency(2 * math.Pi * notchTestToneFrequency / notchTestSampleRate)
+
+	var tone = nco.Float32Sin(length, 0.5)
+	noise = speechLikeNoise(length, 0.5)
+	mixed = make([]float32, length)
+
+	for i := range mixed {
+		mixed[i] = tone[i] + noise[i]
+	}
+
+	return mixed, noise
+}
+
+func checkNotchSuppression(t *testing.T, mixed, noise, output []float32) {
+	// Skip the convergence
+	var settled = len(output) / 2
+	var toneBefore = tonePower(mixed[settled:], notchTestToneFrequency)
+	var toneAfter = tonePower(output[settled:], notchTestToneFrequency)
+	var suppression = 10 * math.Log10(toneBefore/toneAfter)
+
+	if suppression < 20 {
+		t.Fatalf("Expected at least 20 dB of tone suppression, got %f dB", suppression)
+	}
+
+	// The noise (speech) should still be there
+	var noisePower, outputPower float64
+	for i := settled; i < len(output); i++ {
+		noisePower += float64(noise[i] * noise[i])
+		outputPower += float64(output[i] * output[i])
+	}
+
+	var ratio = 10 * math.Log10(outputPower/noisePower)
+	if math.Abs(ratio) > 3 {
+		t.Fatalf("Expected the noise to pass through the notch, but output / noise power is %f dB", ratio)
+	}
+}
+
+func TestAutoNotch(t *testing.T) {
+	var mixed, noise = makeNotchTestSignal(notchTestSampleRate * 4)
+	var notch = MakeAutoNotch(32, 16, 0.005)
+	var output = make([]float32, 0, len(mixed))
+
+	// Run in blocks to check the history handling
+	for i := 0; i < len(mixed); i += 1000 {
+		var end = i + 1000
+		if end > len(mixed) {
+			end = len(mixed)
+		}
+		output = append(output, notch.Work(mixed[i:end])...)
+	}
+
+	checkNotchSuppression(t, mixed, noise, output)
+}
+
+func TestManualNotch(t *testing.T) {
+	var mixed, noise = makeNotchTestSignal(notchTestSampleRate * 4)
+	var notch = MakeManualNotch(notchTestToneFrequency, 50, notchTestSampleRate)
+	var output = notch.Work(mixed)
+
+	checkNotchSuppression(t, mixed, noise, output)
+}
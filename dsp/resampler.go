@@ -21,6 +21,9 @@ type FloatResampler struct {
 	estimatedPhaseChange float32
 	accumulator          float32
 	rate                 float32
+	tapsCenter           float32
+	lastHistory          int
+	tags                 TagPositions
 }
 
 func MakeFloatResampler(filterSize int, rate float32) *FloatResampler {
@@ -83,6 +86,7 @@ func (f *FloatResampler) setRate(rate float32) {
 
 func (f *FloatResampler) setTaps(taps []float32) {
 	var diffTaps = generateDiffTaps(taps)
+	f.tapsCenter = float32(len(taps)-1) / 2
 
 	f.taps = make([][]float32, f.filterSize)
 	f.diffTaps = make([][]float32, f.filterSize)
@@ -153,6 +157,10 @@ func (f *FloatResampler) filterBuffer(input, output []float32, length int) (read
 			output[wrote] = o0 + o1*f.accumulator
 			wrote++
 
+			// The filter j peaks at the sample (tapsCenter - j) / filterSize after read
+			var position = float64(f.tapsCenter-float32(j)) / float64(f.filterSize)
+			f.tags.Add(read - f.lastHistory + int(math.Round(position)))
+
 			f.accumulator += f.filterRate
 			j += int(f.decimationRate) + int(math.Floor(float64(f.accumulator)))
 			f.accumulator = float32(math.Mod(float64(f.accumulator), 1.0))
@@ -186,6 +194,9 @@ func (f *FloatResampler) WorkBuffer(input, output []float32) int {
 		panic("There is not enough space in output buffer")
 	}
 
+	f.lastHistory = len(f.internalBuffer)
+	f.tags.Reset()
+
 	var samples = append(f.internalBuffer, input...)
 
 	consumed, wrote := f.filterBuffer(samples, output, len(samples))
@@ -202,3 +213,7 @@ func (f *FloatResampler) WorkBuffer(input, output []float32) int {
 func (f *FloatResampler) PredictOutputSize(inputLength int) int {
 	return int(float32(inputLength) * 2 * f.rate)
 }
+
+func (f *FloatResampler) MapOffset(offset int) int {
+	return f.tags.MapOffset(offset)
+}
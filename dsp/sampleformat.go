@@ -0,0 +1,179 @@
+package dsp
+
+import "fmt"
+
+// SampleFormat is an integer IQ sample format, as delivered by files, rtl_tcp and network sources
+type SampleFormat int
+
+const (
+	// SampleFormatCU8 is interleaved unsigned 8 bit IQ (like rtl-sdr)
+	SampleFormatCU8 SampleFormat = iota
+	// SampleFormatCS8 is interleaved signed 8 bit IQ (like hackrf)
+	SampleFormatCS8
+	// SampleFormatCS16 is interleaved signed 16 bit little endian IQ
+	SampleFormatCS16
+)
+
+func (f SampleFormat) String() string {
+	switch f {
+	case SampleFormatCU8:
+		return "cu8"
+	case SampleFormatCS8:
+		return "cs8"
+	case SampleFormatCS16:
+		return "cs16"
+	}
+
+	return fmt.Sprintf("SampleFormat(%d)", int(f))
+}
+
+// BytesPerSample returns the size in bytes of an IQ sample
+func (f SampleFormat) BytesPerSample() int {
+	switch f {
+	case SampleFormatCU8, SampleFormatCS8:
+		return 2
+	case SampleFormatCS16:
+		return 4
+	}
+
+	panic(fmt.Sprintf("Unsupported sample format: %s", f))
+}
+
+// ParseSampleFormat returns the sample format named s (cu8, cs8 or cs16)
+func ParseSampleFormat(s string) (SampleFormat, error) {
+	for _, f := range []SampleFormat{SampleFormatCU8, SampleFormatCS8, SampleFormatCS16} {
+		if f.String() == s {
+			return f, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown sample format: %s", s)
+}
+
+// region Byte to Complex
+
+// ByteToComplex converts integer IQ samples to complex.
+// Bytes of a sample split across calls are kept until the rest of the sample arrives.
+type ByteToComplex struct {
+	format  SampleFormat
+	convert func(input []byte, output []complex64) int
+	pending []byte
+}
+
+// MakeByteToComplex creates a converter from format IQ samples to complex
+func MakeByteToComplex(format SampleFormat) *ByteToComplex {
+	var b = &ByteToComplex{
+		format: format,
+	}
+
+	switch format {
+	case SampleFormatCU8:
+		b.convert = ConvertCU8ToComplex
+	case SampleFormatCS8:
+		b.convert = ConvertCS8ToComplex
+	case SampleFormatCS16:
+		b.convert = ConvertCS16ToComplex
+	default:
+		panic(fmt.Sprintf("Unsupported sample format: %s", format))
+	}
+
+	b.pending = make([]byte, 0, format.BytesPerSample())
+
+	return b
+}
+
+func (b *ByteToComplex) Work(input []byte) []complex64 {
+	var output = make([]complex64, b.PredictOutputSize(len(input)))
+	var length = b.WorkBuffer(input, output)
+	return output[:length]
+}
+
+func (b *ByteToComplex) WorkBuffer(input []byte, output []complex64) int {
+	if len(output) < b.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	var bytesPerSample = b.format.BytesPerSample()
+	var length = 0
+
+	if len(b.pending) > 0 {
+		var n = Min(bytesPerSample-len(b.pending), len(input))
+		b.pending = append(b.pending, input[:n]...)
+		input = input[n:]
+
+		if len(b.pending) < bytesPerSample {
+			return 0
+		}
+
+		length += b.convert(b.pending, output)
+		b.pending = b.pending[:0]
+	}
+
+	length += b.convert(input, output[length:])
+	b.pending = append(b.pending, input[len(input)-len(input)%bytesPerSample:]...)
+
+	return length
+}
+
+func (b *ByteToComplex) PredictOutputSize(inputLength int) int {
+	return (len(b.pending) + inputLength) / b.format.BytesPerSample()
+}
+
+// GetFormat returns the input sample format
+func (b *ByteToComplex) GetFormat() SampleFormat {
+	return b.format
+}
+
+// endregion
+// region Complex to Byte
+
+// ComplexToByte converts complex samples to integer IQ, clipping them at [-1, 1]
+type ComplexToByte struct {
+	format  SampleFormat
+	convert func(input []complex64, output []byte) int
+}
+
+// MakeComplexToByte creates a converter from complex to format IQ samples
+func MakeComplexToByte(format SampleFormat) *ComplexToByte {
+	var c = &ComplexToByte{
+		format: format,
+	}
+
+	switch format {
+	case SampleFormatCU8:
+		c.convert = ConvertComplexToCU8
+	case SampleFormatCS8:
+		c.convert = ConvertComplexToCS8
+	case SampleFormatCS16:
+		c.convert = ConvertComplexToCS16
+	default:
+		panic(fmt.Sprintf("Unsupported sample format: %s", format))
+	}
+
+	return c
+}
+
+func (c *ComplexToByte) Work(input []complex64) []byte {
+	var output = make([]byte, c.PredictOutputSize(len(input)))
+	var length = c.WorkBuffer(input, output)
+	return output[:length]
+}
+
+func (c *ComplexToByte) WorkBuffer(input []complex64, output []byte) int {
+	if len(output) < c.PredictOutputSize(len(input)) {
+		panic("There is not enough space in output buffer")
+	}
+
+	return c.convert(input, output) * c.format.BytesPerSample()
+}
+
+func (c *ComplexToByte) PredictOutputSize(inputLength int) int {
+	return inputLength * c.format.BytesPerSample()
+}
+
+// GetFormat returns the output sample format
+func (c *ComplexToByte) GetFormat() SampleFormat {
+	return c.format
+}
+
+// endregion
@@ -0,0 +1,69 @@
+package dsp
+
+import (
+	"testing"
+
+	"github.com/racerxdl/segdsp/dsp/native/amd64"
+)
+
+func TestSampleFormatConversionSIMD(t *testing.T) {
+	type subarch struct {
+		name      string
+		available bool
+		toComplex []func(input []byte, output []complex64) int
+		toBytes   []func(input []complex64, output []byte) int
+	}
+
+	var subarchs = []subarch{
+		{
+			name:      "SSE2",
+			available: amd64.SSE2,
+			toComplex: []func(input []byte, output []complex64) int{amd64.ConvertCU8ToComplexSSE2, amd64.ConvertCS8ToComplexSSE2, amd64.ConvertCS16ToComplexSSE2},
+			toBytes:   []func(input []complex64, output []byte) int{amd64.ConvertComplexToCU8SSE2, amd64.ConvertComplexToCS8SSE2, amd64.ConvertComplexToCS16SSE2},
+		},
+		{
+			name:      "AVX",
+			available: amd64.AVX,
+			toComplex: []func(input []byte, output []complex64) int{amd64.ConvertCU8ToComplexAVX, amd64.ConvertCS8ToComplexAVX, amd64.ConvertCS16ToComplexAVX},
+			toBytes:   []func(input []complex64, output []byte) int{amd64.ConvertComplexToCU8AVX, amd64.ConvertComplexToCS8AVX, amd64.ConvertComplexToCS16AVX},
+		},
+	}
+
+	var genericToComplex = []func(input []byte, output []complex64) int{genericConvertCU8ToComplex, genericConvertCS8ToComplex, genericConvertCS16ToComplex}
+	var genericToBytes = []func(input []complex64, output []byte) int{genericConvertComplexToCU8, genericConvertComplexToCS8, genericConvertComplexToCS16}
+
+	for _, s := range subarchs {
+		if !s.available {
+			t.Logf("No %s to test", s.name)
+			continue
+		}
+
+		for i, format := range sampleFormats {
+			var name = s.name + " " + format.String()
+			var bytesPerSample = format.BytesPerSample()
+
+			// Different lengths for the vector loop and tail
+			for _, length := range []int{1, 7, 8, 9, 64, 69} {
+				var input = makeTestBytes(length * bytesPerSample)
+				var expected = make([]complex64, length+1)
+				var got = make([]complex64, length+1)
+
+				genericToComplex[i](input, expected)
+				if n := s.toComplex[i](input, got); n != length {
+					t.Fatalf("%s: expected %d samples got %d", name, length, n)
+				}
+				checkComplexConversion(t, name, expected, got)
+
+				var samples = makeTestFloats(length)
+				var expectedBytes = make([]byte, (length+1)*bytesPerSample)
+				var gotBytes = make([]byte, (length+1)*bytesPerSample)
+
+				genericToBytes[i](samples, expectedBytes)
+				if n := s.toBytes[i](samples, gotBytes); n != length {
+					t.Fatalf("%s: expected %d samples got %d", name, length, n)
+				}
+				checkByteConversion(t, name, expectedBytes, gotBytes)
+			}
+		}
+	}
+}
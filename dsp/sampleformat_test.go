@@ -0,0 +1,138 @@
+package dsp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var sampleFormats = []SampleFormat{SampleFormatCU8, SampleFormatCS8, SampleFormatCS16}
+
+// makeTestFloats returns values in [-1.5, 1.5] including the clipping and rounding edge cases
+func makeTestFloats(length int) []complex64 {
+	var edges = []float32{-2, -1, -1 + 1.0/256, -0.5 / 128, 0, 0.5 / 128, 1.5 / 128, 0.5 / 32768, 1 - 1.0/256, 1, 2}
+	var data = make([]complex64, length)
+
+	for i := range data {
+		data[i] = complex(rand.Float32()*3-1.5, rand.Float32()*3-1.5)
+		if i < len(edges) {
+			data[i] = complex(edges[i], -edges[i])
+		}
+	}
+
+	return data
+}
+
+func makeTestBytes(length int) []byte {
+	var data = make([]byte, length)
+	rand.Read(data)
+	return data
+}
+
+func checkComplexConversion(t *testing.T, name string, expected, got []complex64) {
+	for i := range expected {
+		if expected[i] != got[i] {
+			t.Fatalf("%s: expected %v got %v at %d", name, expected[i], got[i], i)
+		}
+	}
+}
+
+func checkByteConversion(t *testing.T, name string, expected, got []byte) {
+	for i := range expected {
+		if expected[i] != got[i] {
+			t.Fatalf("%s: expected %d got %d at %d", name, expected[i], got[i], i)
+		}
+	}
+}
+
+func TestSampleFormatConversion(t *testing.T) {
+	t.Logf("SIMD Mode: %s", GetSIMDMode())
+
+	var tests = []struct {
+		format    SampleFormat
+		toComplex func(input []byte, output []complex64) int
+		toBytes   func(input []complex64, output []byte) int
+	}{
+		{SampleFormatCU8, genericConvertCU8ToComplex, genericConvertComplexToCU8},
+		{SampleFormatCS8, genericConvertCS8ToComplex, genericConvertComplexToCS8},
+		{SampleFormatCS16, genericConvertCS16ToComplex, genericConvertComplexToCS16},
+	}
+
+	// 69 samples exercise both the vector loop and the tail
+	const length = 69
+
+	for _, test := range tests {
+		var bytesPerSample = test.format.BytesPerSample()
+		var input = makeTestBytes(length * bytesPerSample)
+		var expected = make([]complex64, length)
+		var got = make([]complex64, length)
+
+		if n := test.toComplex(input, expected); n != length {
+			t.Fatalf("%s: expected %d samples got %d", test.format, length, n)
+		}
+		MakeByteToComplex(test.format).WorkBuffer(input, got)
+		checkComplexConversion(t, test.format.String(), expected, got)
+
+		var samples = makeTestFloats(length)
+		var expectedBytes = make([]byte, length*bytesPerSample)
+		var gotBytes = make([]byte, length*bytesPerSample)
+
+		if n := test.toBytes(samples, expectedBytes); n != length {
+			t.Fatalf("%s: expected %d samples got %d", test.format, length, n)
+		}
+		MakeComplexToByte(test.format).WorkBuffer(samples, gotBytes)
+		checkByteConversion(t, test.format.String(), expectedBytes, gotBytes)
+	}
+}
+
+func TestSampleFormatRoundTrip(t *testing.T) {
+	for _, format := range sampleFormats {
+		var input = makeTestBytes(4096 * format.BytesPerSample())
+		var samples = MakeByteToComplex(format).Work(input)
+
+		for _, v := range samples {
+			if real(v) < -1 || real(v) > 1 || imag(v) < -1 || imag(v) > 1 {
+				t.Fatalf("%s: sample %v out of [-1, 1]", format, v)
+			}
+		}
+
+		var output = MakeComplexToByte(format).Work(samples)
+		checkByteConversion(t, format.String(), input, output)
+	}
+
+	var clipped = MakeComplexToByte(SampleFormatCS16).Work([]complex64{complex(1, -1), complex(3, -3)})
+	checkByteConversion(t, "cs16 clip", []byte{0xff, 0x7f, 0x00, 0x80, 0xff, 0x7f, 0x00, 0x80}, clipped)
+}
+
+func TestByteToComplexSplit(t *testing.T) {
+	for _, format := range sampleFormats {
+		var input = makeTestBytes(1000 * format.BytesPerSample())
+		var expected = MakeByteToComplex(format).Work(input)
+
+		var b = MakeByteToComplex(format)
+		var got = make([]complex64, 0, len(expected))
+
+		for len(input) > 0 {
+			var n = Min(rand.Intn(13), len(input))
+			got = append(got, b.Work(input[:n])...)
+			input = input[n:]
+		}
+
+		if len(got) != len(expected) {
+			t.Fatalf("%s: expected %d samples got %d", format, len(expected), len(got))
+		}
+		checkComplexConversion(t, format.String(), expected, got)
+	}
+}
+
+func TestParseSampleFormat(t *testing.T) {
+	for _, format := range sampleFormats {
+		var f, err = ParseSampleFormat(format.String())
+		if err != nil || f != format {
+			t.Fatalf("Expected %s got %s (%v)", format, f, err)
+		}
+	}
+
+	if _, err := ParseSampleFormat("cf32"); err == nil {
+		t.Fatal("Expected error for unknown format")
+	}
+}
@@ -0,0 +1,150 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// TrackedSignal is a detected signal followed over several spectrum frames
+type TrackedSignal struct {
+	ID              uint64
+	CenterFrequency float64
+	Bandwidth       float64
+	SNR             float32
+	Power           float32
+	FirstSeen       time.Time
+	LastSeen        time.Time
+	Active          bool
+
+	hits int
+}
+
+// SignalTracker follows the CFARDetector signals over frames, with hysteresis to avoid flickering.
+// A signal is considered active after being detected in activateFrames consecutive frames,
+// and inactive after not being detected for holdTime.
+type SignalTracker struct {
+	activateFrames int
+	holdTime       time.Duration
+	signals        []*TrackedSignal
+	nextID         uint64
+}
+
+// MakeSignalTracker creates a new Signal Tracker
+func MakeSignalTracker(activateFrames int, holdTime time.Duration) *SignalTracker {
+	if activateFrames < 1 {
+		panic("Signal Tracker activate frames should be at least 1")
+	}
+
+	return &SignalTracker{
+		activateFrames: activateFrames,
+		holdTime:       holdTime,
+		nextID:         1,
+	}
+}
+
+func (st *SignalTracker) SetActivateFrames(frames int) {
+	st.activateFrames = frames
+}
+
+func (st *SignalTracker) GetActivateFrames() int {
+	return st.activateFrames
+}
+
+func (st *SignalTracker) SetHoldTime(holdTime time.Duration) {
+	st.holdTime = holdTime
+}
+
+func (st *SignalTracker) GetHoldTime() time.Duration {
+	return st.holdTime
+}
+
+// Update matches the detected signals of a new frame against the tracked ones.
+// It returns the signals that became active and the ones that became inactive in this frame.
+func (st *SignalTracker) Update(detected []DetectedSignal, now time.Time) (activated, deactivated []TrackedSignal) {
+	var seen = make([]bool, len(st.signals))
+
+	for _, d := range detected {
+		var match = -1
+		var bestDistance = math.MaxFloat64
+
+		for i, s := range st.signals {
+			var distance = math.Abs(s.CenterFrequency - d.CenterFrequency)
+			if !seen[i] && distance <= (s.Bandwidth+d.Bandwidth)/2 && distance < bestDistance {
+				match = i
+				bestDistance = distance
+			}
+		}
+
+		if match == -1 {
+			st.signals = append(st.signals, &TrackedSignal{
+				CenterFrequency: d.CenterFrequency,
+				Bandwidth:       d.Bandwidth,
+				SNR:             d.SNR,
+				Power:           d.Power,
+				FirstSeen:       now,
+				LastSeen:        now,
+			})
+			seen = append(seen, true)
+			match = len(st.signals) - 1
+		} else {
+			var s = st.signals[match]
+			s.CenterFrequency = d.CenterFrequency
+			s.Bandwidth = d.Bandwidth
+			s.SNR = d.SNR
+			s.Power = d.Power
+			s.LastSeen = now
+			seen[match] = true
+		}
+
+		var s = st.signals[match]
+		s.hits++
+
+		if !s.Active && s.hits >= st.activateFrames {
+			s.Active = true
+			s.ID = st.nextID
+			st.nextID++
+			activated = append(activated, *s)
+		}
+	}
+
+	var remaining = st.signals[:0]
+
+	for i, s := range st.signals {
+		if !seen[i] {
+			if !s.Active {
+				// Not confirmed yet, drop it at the first miss
+				continue
+			}
+
+			if now.Sub(s.LastSeen) > st.holdTime {
+				s.Active = false
+				deactivated = append(deactivated, *s)
+				continue
+			}
+		}
+
+		remaining = append(remaining, s)
+	}
+
+	st.signals = remaining
+
+	return activated, deactivated
+}
+
+// GetActiveSignals returns a copy of the currently active signals
+func (st *SignalTracker) GetActiveSignals() []TrackedSignal {
+	var signals = make([]TrackedSignal, 0, len(st.signals))
+
+	for _, s := range st.signals {
+		if s.Active {
+			signals = append(signals, *s)
+		}
+	}
+
+	return signals
+}
+
+// Reset drops all tracked signals (without reporting them as deactivated)
+func (st *SignalTracker) Reset() {
+	st.signals = nil
+}
@@ -0,0 +1,148 @@
+package sources
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/dsp"
+)
+
+// ChirpMode selects how the chirp frequency changes over time
+type ChirpMode int
+
+const (
+	// ChirpLinear changes the frequency by the same amount of Hertz each second
+	ChirpLinear ChirpMode = iota
+	// ChirpLogarithmic multiplies the frequency by the same ratio each second
+	ChirpLogarithmic
+)
+
+// Chirp is a tone that sweeps from a start to a stop frequency over a duration, then starts again
+type Chirp struct {
+	nco            *dsp.NCO
+	mode           ChirpMode
+	sampleRate     float32
+	startFrequency float32
+	stopFrequency  float32
+	duration       float32
+	amplitude      float32
+	sweepLength    int
+	position       int
+}
+
+// MakeLinearChirp creates a chirp that sweeps linearly from startFrequency to stopFrequency Hertz in duration seconds
+func MakeLinearChirp(sampleRate, startFrequency, stopFrequency, duration, amplitude float32) *Chirp {
+	return makeChirp(ChirpLinear, sampleRate, startFrequency, stopFrequency, duration, amplitude)
+}
+
+// MakeLogChirp creates a chirp that sweeps exponentially from startFrequency to stopFrequency Hertz in duration seconds.
+// Both frequencies should have the same sign and not be zero.
+func MakeLogChirp(sampleRate, startFrequency, stopFrequency, duration, amplitude float32) *Chirp {
+	if startFrequency*stopFrequency <= 0 {
+		panic("Logarithmic chirp frequencies should have the same sign and not be zero")
+	}
+
+	return makeChirp(ChirpLogarithmic, sampleRate, startFrequency, stopFrequency, duration, amplitude)
+}
+
+func makeChirp(mode ChirpMode, sampleRate, startFrequency, stopFrequency, duration, amplitude float32) *Chirp {
+	var c = &Chirp{
+		nco:        dsp.MakeNCO(),
+		mode:       mode,
+		sampleRate: sampleRate,
+		amplitude:  amplitude,
+	}
+
+	c.SetFrequencyRange(startFrequency, stopFrequency, duration)
+
+	return c
+}
+
+// SetFrequencyRange changes the sweep and restarts it from startFrequency
+func (c *Chirp) SetFrequencyRange(startFrequency, stopFrequency, duration float32) {
+	var sweepLength = int(math.Round(float64(duration * c.sampleRate)))
+	if sweepLength < 1 {
+		panic("Chirp duration should be at least one sample")
+	}
+
+	c.startFrequency = startFrequency
+	c.stopFrequency = stopFrequency
+	c.duration = duration
+	c.sweepLength = sweepLength
+	c.position = 0
+}
+
+// GetStartFrequency returns the sweep start frequency in Hertz
+func (c *Chirp) GetStartFrequency() float32 {
+	return c.startFrequency
+}
+
+// GetStopFrequency returns the sweep stop frequency in Hertz
+func (c *Chirp) GetStopFrequency() float32 {
+	return c.stopFrequency
+}
+
+// GetDuration returns the sweep duration in seconds
+func (c *Chirp) GetDuration() float32 {
+	return c.duration
+}
+
+// GetMode returns how the frequency changes over time
+func (c *Chirp) GetMode() ChirpMode {
+	return c.mode
+}
+
+// GetFrequency returns the frequency of the next sample in Hertz
+func (c *Chirp) GetFrequency() float32 {
+	var t = float64(c.position) / float64(c.sweepLength)
+
+	if c.mode == ChirpLogarithmic {
+		return float32(float64(c.startFrequency) * math.Pow(float64(c.stopFrequency/c.startFrequency), t))
+	}
+
+	return float32(float64(c.startFrequency) + float64(c.stopFrequency-c.startFrequency)*t)
+}
+
+// SetAmplitude changes the chirp amplitude
+func (c *Chirp) SetAmplitude(amplitude float32) {
+	c.amplitude = amplitude
+}
+
+// GetAmplitude returns the chirp amplitude
+func (c *Chirp) GetAmplitude() float32 {
+	return c.amplitude
+}
+
+// SetPhase sets the phase of the next sample in radians
+func (c *Chirp) SetPhase(phase float32) {
+	c.nco.SetPhase(phase)
+}
+
+// GetPhase returns the phase of the next sample in radians
+func (c *Chirp) GetPhase() float32 {
+	return c.nco.GetPhase()
+}
+
+func (c *Chirp) step() {
+	c.nco.SetFrequency(2 * math.Pi * c.GetFrequency() / c.sampleRate)
+	c.nco.Step()
+
+	c.position++
+	if c.position >= c.sweepLength {
+		c.position = 0
+	}
+}
+
+func (c *Chirp) Complex(output []complex64) {
+	for i := range output {
+		var s, co = math.Sincos(float64(c.nco.GetPhase()))
+		output[i] = complex(float32(co)*c.amplitude, float32(s)*c.amplitude)
+		c.step()
+	}
+}
+
+func (c *Chirp) Float(output []float32) {
+	for i := range output {
+		output[i] = float32(math.Cos(float64(c.nco.GetPhase()))) * c.amplitude
+		c.step()
+	}
+}
@@ -0,0 +1,116 @@
+package sources
+
+import (
+	"math"
+	"math/rand"
+)
+
+// region White Noise
+
+// WhiteNoise is gaussian noise with a flat spectrum. The complex noise has the same power split in I and Q.
+type WhiteNoise struct {
+	rand      *rand.Rand
+	amplitude float32
+}
+
+// MakeWhiteNoise creates a white noise with amplitude RMS. The same seed gives the same noise.
+func MakeWhiteNoise(amplitude float32, seed int64) *WhiteNoise {
+	return &WhiteNoise{
+		rand:      rand.New(rand.NewSource(seed)),
+		amplitude: amplitude,
+	}
+}
+
+// SetAmplitude changes the noise RMS amplitude
+func (n *WhiteNoise) SetAmplitude(amplitude float32) {
+	n.amplitude = amplitude
+}
+
+// GetAmplitude returns the noise RMS amplitude
+func (n *WhiteNoise) GetAmplitude() float32 {
+	return n.amplitude
+}
+
+func (n *WhiteNoise) Complex(output []complex64) {
+	var scale = float64(n.amplitude) / math.Sqrt2
+	for i := range output {
+		output[i] = complex(float32(n.rand.NormFloat64()*scale), float32(n.rand.NormFloat64()*scale))
+	}
+}
+
+func (n *WhiteNoise) Float(output []float32) {
+	var scale = float64(n.amplitude)
+	for i := range output {
+		output[i] = float32(n.rand.NormFloat64() * scale)
+	}
+}
+
+// endregion
+// region Pink Noise
+
+// pinkNoiseGain normalizes the pinkFilter output to unit RMS for unit RMS white noise
+const pinkNoiseGain = 1 / 3.01
+
+// pinkFilter is the Paul Kellet -3 dB / octave filter of white noise
+type pinkFilter struct {
+	b [7]float64
+}
+
+func (f *pinkFilter) filter(white float64) float64 {
+	f.b[0] = 0.99886*f.b[0] + white*0.0555179
+	f.b[1] = 0.99332*f.b[1] + white*0.0750759
+	f.b[2] = 0.96900*f.b[2] + white*0.1538520
+	f.b[3] = 0.86650*f.b[3] + white*0.3104856
+	f.b[4] = 0.55000*f.b[4] + white*0.5329522
+	f.b[5] = -0.7616*f.b[5] - white*0.0168980
+
+	var pink = f.b[0] + f.b[1] + f.b[2] + f.b[3] + f.b[4] + f.b[5] + f.b[6] + white*0.5362
+	f.b[6] = white * 0.115926
+
+	return pink * pinkNoiseGain
+}
+
+// PinkNoise is gaussian noise with a -3 dB / octave spectrum (same power in each octave)
+type PinkNoise struct {
+	rand      *rand.Rand
+	amplitude float32
+	i         pinkFilter
+	q         pinkFilter
+}
+
+// MakePinkNoise creates a pink noise with about amplitude RMS. The same seed gives the same noise.
+func MakePinkNoise(amplitude float32, seed int64) *PinkNoise {
+	return &PinkNoise{
+		rand:      rand.New(rand.NewSource(seed)),
+		amplitude: amplitude,
+	}
+}
+
+// SetAmplitude changes the noise RMS amplitude
+func (n *PinkNoise) SetAmplitude(amplitude float32) {
+	n.amplitude = amplitude
+}
+
+// GetAmplitude returns the noise RMS amplitude
+func (n *PinkNoise) GetAmplitude() float32 {
+	return n.amplitude
+}
+
+func (n *PinkNoise) Complex(output []complex64) {
+	var scale = float64(n.amplitude) / math.Sqrt2
+	for i := range output {
+		output[i] = complex(
+			float32(n.i.filter(n.rand.NormFloat64())*scale),
+			float32(n.q.filter(n.rand.NormFloat64())*scale),
+		)
+	}
+}
+
+func (n *PinkNoise) Float(output []float32) {
+	var scale = float64(n.amplitude)
+	for i := range output {
+		output[i] = float32(n.i.filter(n.rand.NormFloat64()) * scale)
+	}
+}
+
+// endregion
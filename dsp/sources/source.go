@@ -0,0 +1,128 @@
+package sources
+
+// Generator makes the samples of a test signal. Each call continues the signal from where the last one stopped.
+type Generator interface {
+	// Complex fills output with the next complex samples
+	Complex(output []complex64)
+	// Float fills output with the next real samples
+	Float(output []float32)
+}
+
+// region Block Source
+
+// BlockSource reads a Generator in blocks of a fixed size, like the sample callbacks of a radio
+type BlockSource struct {
+	generator     Generator
+	blockSize     int
+	complexBuffer []complex64
+	floatBuffer   []float32
+}
+
+// MakeBlockSource creates a source that reads blockSize samples of generator at each call
+func MakeBlockSource(generator Generator, blockSize int) *BlockSource {
+	if blockSize < 1 {
+		panic("Block size should be at least 1")
+	}
+
+	return &BlockSource{
+		generator: generator,
+		blockSize: blockSize,
+	}
+}
+
+// GetBlockSize returns the number of samples of each block
+func (s *BlockSource) GetBlockSize() int {
+	return s.blockSize
+}
+
+// GetGenerator returns the generator of the samples
+func (s *BlockSource) GetGenerator() Generator {
+	return s.generator
+}
+
+// NextComplex returns the next block of complex samples. The block is reused by the next call.
+func (s *BlockSource) NextComplex() []complex64 {
+	if s.complexBuffer == nil {
+		s.complexBuffer = make([]complex64, s.blockSize)
+	}
+
+	s.generator.Complex(s.complexBuffer)
+
+	return s.complexBuffer
+}
+
+// NextFloat returns the next block of real samples. The block is reused by the next call.
+func (s *BlockSource) NextFloat() []float32 {
+	if s.floatBuffer == nil {
+		s.floatBuffer = make([]float32, s.blockSize)
+	}
+
+	s.generator.Float(s.floatBuffer)
+
+	return s.floatBuffer
+}
+
+// endregion
+// region Sum
+
+// Sum is the sum of the samples of other generators, like a tone over a noise floor
+type Sum struct {
+	generators    []Generator
+	complexBuffer []complex64
+	floatBuffer   []float32
+}
+
+// MakeSum creates a generator that adds the samples of generators
+func MakeSum(generators ...Generator) *Sum {
+	return &Sum{
+		generators: generators,
+	}
+}
+
+// Add adds generator to the sum
+func (s *Sum) Add(generator Generator) {
+	s.generators = append(s.generators, generator)
+}
+
+// GetGenerators returns the generators of the sum
+func (s *Sum) GetGenerators() []Generator {
+	return s.generators
+}
+
+func (s *Sum) Complex(output []complex64) {
+	if len(s.complexBuffer) < len(output) {
+		s.complexBuffer = make([]complex64, len(output))
+	}
+
+	for i := range output {
+		output[i] = 0
+	}
+
+	var buffer = s.complexBuffer[:len(output)]
+	for _, g := range s.generators {
+		g.Complex(buffer)
+		for i, v := range buffer {
+			output[i] += v
+		}
+	}
+}
+
+func (s *Sum) Float(output []float32) {
+	if len(s.floatBuffer) < len(output) {
+		s.floatBuffer = make([]float32, len(output))
+	}
+
+	for i := range output {
+		output[i] = 0
+	}
+
+	var buffer = s.floatBuffer[:len(output)]
+	for _, g := range s.generators {
+		g.Float(buffer)
+		for i, v := range buffer {
+			output[i] += v
+		}
+	}
+}
+
+// endregion
@@ -0,0 +1,256 @@
+package sources
+
+import (
+	"math"
+	"testing"
+
+	"github.com/racerxdl/segdsp/dsp/fft"
+	"github.com/racerxdl/segdsp/tools"
+)
+
+const testSampleRate = 48000
+
+func rms(data []float32) float64 {
+	var acc = 0.0
+	for _, v := range data {
+		acc += float64(v) * float64(v)
+	}
+	return math.Sqrt(acc / float64(len(data)))
+}
+
+// peakFrequency returns the frequency of the strongest FFT bin of data
+func peakFrequency(data []complex64, sampleRate float64) float64 {
+	var spectrum = fft.FFT(data)
+	var best = 0
+
+	for i, v := range spectrum {
+		if tools.ComplexAbsSquared(v) > tools.ComplexAbsSquared(spectrum[best]) {
+			best = i
+		}
+	}
+
+	if best > len(spectrum)/2 {
+		best -= len(spectrum)
+	}
+
+	return float64(best) * sampleRate / float64(len(spectrum))
+}
+
+func TestTone(t *testing.T) {
+	const length = 4800
+
+	var tone = MakeTone(testSampleRate, -1000, 0.5, math.Pi/2)
+	var samples = make([]complex64, length)
+	tone.Complex(samples)
+
+	for i, v := range samples {
+		var phase = math.Pi/2 - 2*math.Pi*1000*float64(i)/testSampleRate
+		var expected = complex(float32(0.5*math.Cos(phase)), float32(0.5*math.Sin(phase)))
+		if tools.ComplexAbs(v-expected) > 1e-3 {
+			t.Fatalf("Expected %v got %v at %d", expected, v, i)
+		}
+	}
+
+	var cosine = MakeTone(testSampleRate, 1000, 2, 0)
+	var floats = make([]float32, length)
+	cosine.Float(floats)
+
+	if floats[0] != 2 {
+		t.Errorf("Expected the real tone to start at the amplitude, got %f", floats[0])
+	}
+	if math.Abs(rms(floats)-2/math.Sqrt2) > 1e-3 {
+		t.Errorf("Expected RMS %f got %f", 2/math.Sqrt2, rms(floats))
+	}
+
+	tone.SetFrequency(3000)
+	tone.Complex(samples)
+	if peakFrequency(samples[:4096], testSampleRate) != 3000 {
+		t.Errorf("Expected the peak at 3000 Hz got %f Hz", peakFrequency(samples[:4096], testSampleRate))
+	}
+}
+
+func TestMultiTone(t *testing.T) {
+	const length = 4096
+	var frequencies = []float32{-6000, 1500, 9000}
+
+	var multiTone = MakeMultiTone(testSampleRate, frequencies, 1)
+	var samples = make([]complex64, length)
+	multiTone.Complex(samples)
+
+	var spectrum = fft.FFT(samples)
+	for _, f := range frequencies {
+		var bin = int(f) * length / testSampleRate
+		if bin < 0 {
+			bin += length
+		}
+		var magnitude = tools.ComplexAbs(spectrum[bin]) / length
+		if math.Abs(float64(magnitude)-1) > 1e-2 {
+			t.Errorf("Expected unit magnitude at %f Hz got %f", f, magnitude)
+		}
+	}
+}
+
+func TestChirp(t *testing.T) {
+	const duration = 0.5
+	const length = testSampleRate * duration
+
+	var testCases = []struct {
+		name  string
+		chirp *Chirp
+		mid   float64
+	}{
+		{"Linear", MakeLinearChirp(testSampleRate, 1000, 9000, duration, 1), 5000},
+		{"Logarithmic", MakeLogChirp(testSampleRate, 1000, 9000, duration, 1), 3000},
+	}
+
+	for _, tc := range testCases {
+		var samples = make([]complex64, length+1)
+		tc.chirp.Complex(samples)
+
+		var frequency = func(i int) float64 {
+			return float64(tools.ComplexPhase(samples[i+1]*tools.Conj(samples[i]))) * testSampleRate / (2 * math.Pi)
+		}
+
+		if math.Abs(frequency(0)-1000) > 1 {
+			t.Errorf("%s: Expected the sweep to start at 1000 Hz got %f Hz", tc.name, frequency(0))
+		}
+		if math.Abs(frequency(length/2)-tc.mid) > 1 {
+			t.Errorf("%s: Expected %f Hz in the middle of the sweep got %f Hz", tc.name, tc.mid, frequency(length/2))
+		}
+		if math.Abs(frequency(length-1)-9000) > 1 {
+			t.Errorf("%s: Expected the sweep to end at 9000 Hz got %f Hz", tc.name, frequency(length-1))
+		}
+		if math.Abs(float64(tc.chirp.GetFrequency())-1000) > 1 {
+			t.Errorf("%s: Expected the sweep to restart at 1000 Hz got %f Hz", tc.name, tc.chirp.GetFrequency())
+		}
+	}
+}
+
+func TestWhiteNoise(t *testing.T) {
+	const length = 1 << 16
+
+	var floats = make([]float32, length)
+	MakeWhiteNoise(0.5, 1).Float(floats)
+
+	if math.Abs(rms(floats)-0.5) > 0.01 {
+		t.Errorf("Expected RMS 0.5 got %f", rms(floats))
+	}
+
+	var samples = make([]complex64, length)
+	var other = make([]complex64, length)
+	MakeWhiteNoise(0.5, 1).Complex(samples)
+	MakeWhiteNoise(0.5, 1).Complex(other)
+
+	var power = 0.0
+	for i, v := range samples {
+		if v != other[i] {
+			t.Fatalf("Expected the same noise for the same seed at %d", i)
+		}
+		power += float64(tools.ComplexAbsSquared(v))
+	}
+
+	if math.Abs(math.Sqrt(power/length)-0.5) > 0.01 {
+		t.Errorf("Expected complex RMS 0.5 got %f", math.Sqrt(power/length))
+	}
+}
+
+func TestPinkNoise(t *testing.T) {
+	const fftSize = 1024
+	const frames = 256
+
+	var noise = MakePinkNoise(1, 1)
+	var floats = make([]float32, fftSize)
+	var spectrum = make([]float64, fftSize/2)
+	var total = 0.0
+
+	for f := 0; f < frames; f++ {
+		noise.Float(floats)
+		total += rms(floats) * rms(floats)
+		var bins = fft.FFTReal(floats)
+		for i := range spectrum {
+			spectrum[i] += float64(tools.ComplexAbsSquared(bins[i]))
+		}
+	}
+
+	if math.Abs(math.Sqrt(total/frames)-1) > 0.1 {
+		t.Errorf("Expected RMS 1 got %f", math.Sqrt(total/frames))
+	}
+
+	// Each octave should have the same power, so the power per bin drops 3 dB per octave
+	var bandPower = func(start, stop int) float64 {
+		var p = 0.0
+		for i := start; i < stop; i++ {
+			p += spectrum[i]
+		}
+		return p / float64(stop-start)
+	}
+
+	var low = bandPower(16, 32)
+	var high = bandPower(256, 512)
+	var slope = 10 * math.Log10(high/low) / 4
+
+	if math.Abs(slope+3) > 0.5 {
+		t.Errorf("Expected -3 dB / octave got %f dB / octave", slope)
+	}
+}
+
+func TestWaveforms(t *testing.T) {
+	// 8 samples per period
+	const frequency = testSampleRate / 8
+
+	var testCases = []struct {
+		name     string
+		wave     *Waveform
+		expected []float32
+	}{
+		{"Square", MakeSquareWave(testSampleRate, frequency, 1, 0), []float32{1, 1, 1, 1, -1, -1, -1, -1}},
+		{"Triangle", MakeTriangleWave(testSampleRate, frequency, 2, 0), []float32{2, 1, 0, -1, -2, -1, 0, 1}},
+		{"Pulse", MakePulseTrain(testSampleRate, frequency, 0.25, 1, 0), []float32{1, 1, 0, 0, 0, 0, 0, 0}},
+		{"Shifted Square", MakeSquareWave(testSampleRate, frequency, 1, math.Pi), []float32{-1, -1, -1, -1, 1, 1, 1, 1}},
+	}
+
+	for _, tc := range testCases {
+		var samples = make([]float32, 3*len(tc.expected))
+		tc.wave.Float(samples)
+
+		for i, v := range samples {
+			var expected = tc.expected[i%len(tc.expected)]
+			if math.Abs(float64(v-expected)) > 1e-4 {
+				t.Fatalf("%s: Expected %f got %f at %d", tc.name, expected, v, i)
+			}
+		}
+	}
+}
+
+func TestBlockSource(t *testing.T) {
+	const blockSize = 1000
+
+	var source = MakeBlockSource(MakeSum(
+		MakeTone(testSampleRate, 1000, 1, 0),
+		MakeWhiteNoise(0.01, 1),
+	), blockSize)
+
+	var reference = MakeSum(
+		MakeTone(testSampleRate, 1000, 1, 0),
+		MakeWhiteNoise(0.01, 1),
+	)
+	var expected = make([]complex64, blockSize)
+
+	for b := 0; b < 4; b++ {
+		var block = source.NextComplex()
+		if len(block) != blockSize {
+			t.Fatalf("Expected %d samples got %d", blockSize, len(block))
+		}
+
+		reference.Complex(expected)
+		for i, v := range block {
+			if v != expected[i] {
+				t.Fatalf("Expected %v got %v at block %d sample %d", expected[i], v, b, i)
+			}
+		}
+	}
+
+	if len(source.NextFloat()) != blockSize {
+		t.Fatalf("Expected %d samples", blockSize)
+	}
+}
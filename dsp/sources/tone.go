@@ -0,0 +1,98 @@
+package sources
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/dsp"
+)
+
+// region Tone
+
+// Tone is a single frequency signal. The complex tone is A * exp(j * phase) and the real tone is A * cos(phase).
+type Tone struct {
+	nco        *dsp.NCO
+	sampleRate float32
+	frequency  float32
+	amplitude  float32
+}
+
+// MakeTone creates a tone at frequency Hertz (negative frequencies are valid for complex tones) at sampleRate,
+// starting at phase radians
+func MakeTone(sampleRate, frequency, amplitude, phase float32) *Tone {
+	var t = &Tone{
+		nco:        dsp.MakeNCO(),
+		sampleRate: sampleRate,
+		amplitude:  amplitude,
+	}
+
+	t.SetFrequency(frequency)
+	t.SetPhase(phase)
+
+	return t
+}
+
+// SetFrequency changes the tone frequency in Hertz, keeping the phase continuous
+func (t *Tone) SetFrequency(frequency float32) {
+	t.frequency = frequency
+	t.nco.SetFrequency(2 * math.Pi * frequency / t.sampleRate)
+}
+
+// GetFrequency returns the tone frequency in Hertz
+func (t *Tone) GetFrequency() float32 {
+	return t.frequency
+}
+
+// SetAmplitude changes the tone amplitude
+func (t *Tone) SetAmplitude(amplitude float32) {
+	t.amplitude = amplitude
+}
+
+// GetAmplitude returns the tone amplitude
+func (t *Tone) GetAmplitude() float32 {
+	return t.amplitude
+}
+
+// SetPhase sets the phase of the next sample in radians
+func (t *Tone) SetPhase(phase float32) {
+	t.nco.SetPhase(phase)
+}
+
+// GetPhase returns the phase of the next sample in radians
+func (t *Tone) GetPhase() float32 {
+	return t.nco.GetPhase()
+}
+
+func (t *Tone) Complex(output []complex64) {
+	for i := range output {
+		var s, c = math.Sincos(float64(t.nco.GetPhase()))
+		output[i] = complex(float32(c)*t.amplitude, float32(s)*t.amplitude)
+		t.nco.Step()
+	}
+}
+
+func (t *Tone) Float(output []float32) {
+	for i := range output {
+		output[i] = float32(math.Cos(float64(t.nco.GetPhase()))) * t.amplitude
+		t.nco.Step()
+	}
+}
+
+// endregion
+// region Multi Tone
+
+// MakeMultiTone creates the sum of tones at frequencies, each with amplitude. The starting phases are spread
+// (Schroeder phases) to keep the peak to average ratio low.
+func MakeMultiTone(sampleRate float32, frequencies []float32, amplitude float32) *Sum {
+	var s = MakeSum()
+	var n = float64(len(frequencies))
+
+	for i, f := range frequencies {
+		var k = float64(i + 1)
+		var phase = float32(math.Remainder(-math.Pi*k*(k-1)/n, 2*math.Pi))
+		s.Add(MakeTone(sampleRate, f, amplitude, phase))
+	}
+
+	return s
+}
+
+// endregion
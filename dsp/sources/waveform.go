@@ -0,0 +1,152 @@
+package sources
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/dsp"
+)
+
+// WaveShape is the shape of each period of a Waveform
+type WaveShape int
+
+const (
+	// WaveSquare is A during the duty cycle and -A for the rest of the period
+	WaveSquare WaveShape = iota
+	// WaveTriangle goes from A to -A in the first half period and back to A in the second one
+	WaveTriangle
+	// WavePulse is A during the duty cycle and 0 for the rest of the period
+	WavePulse
+)
+
+// Waveform is a periodic square, triangle or pulse train signal. The period starts at phase 0, where a square wave
+// and a triangle wave are at their max value (like a cosine). The complex samples only have the real part.
+type Waveform struct {
+	nco        *dsp.NCO
+	shape      WaveShape
+	sampleRate float32
+	frequency  float32
+	amplitude  float32
+	dutyCycle  float32
+}
+
+// MakeSquareWave creates a square wave at frequency Hertz at sampleRate, starting at phase radians
+func MakeSquareWave(sampleRate, frequency, amplitude, phase float32) *Waveform {
+	return makeWaveform(WaveSquare, sampleRate, frequency, amplitude, phase, 0.5)
+}
+
+// MakeTriangleWave creates a triangle wave at frequency Hertz at sampleRate, starting at phase radians
+func MakeTriangleWave(sampleRate, frequency, amplitude, phase float32) *Waveform {
+	return makeWaveform(WaveTriangle, sampleRate, frequency, amplitude, phase, 0.5)
+}
+
+// MakePulseTrain creates a pulse train of frequency pulses per second at sampleRate, each one dutyCycle
+// (between 0 and 1) of the period long, starting at phase radians
+func MakePulseTrain(sampleRate, frequency, dutyCycle, amplitude, phase float32) *Waveform {
+	return makeWaveform(WavePulse, sampleRate, frequency, amplitude, phase, dutyCycle)
+}
+
+func makeWaveform(shape WaveShape, sampleRate, frequency, amplitude, phase, dutyCycle float32) *Waveform {
+	var w = &Waveform{
+		nco:        dsp.MakeNCO(),
+		shape:      shape,
+		sampleRate: sampleRate,
+		amplitude:  amplitude,
+	}
+
+	w.SetFrequency(frequency)
+	w.SetPhase(phase)
+	w.SetDutyCycle(dutyCycle)
+
+	return w
+}
+
+// GetShape returns the wave shape
+func (w *Waveform) GetShape() WaveShape {
+	return w.shape
+}
+
+// SetFrequency changes the wave frequency in Hertz
+func (w *Waveform) SetFrequency(frequency float32) {
+	w.frequency = frequency
+	w.nco.SetFrequency(2 * math.Pi * frequency / w.sampleRate)
+}
+
+// GetFrequency returns the wave frequency in Hertz
+func (w *Waveform) GetFrequency() float32 {
+	return w.frequency
+}
+
+// SetAmplitude changes the wave amplitude
+func (w *Waveform) SetAmplitude(amplitude float32) {
+	w.amplitude = amplitude
+}
+
+// GetAmplitude returns the wave amplitude
+func (w *Waveform) GetAmplitude() float32 {
+	return w.amplitude
+}
+
+// SetPhase sets the phase of the next sample in radians
+func (w *Waveform) SetPhase(phase float32) {
+	w.nco.SetPhase(phase)
+}
+
+// GetPhase returns the phase of the next sample in radians
+func (w *Waveform) GetPhase() float32 {
+	return w.nco.GetPhase()
+}
+
+// SetDutyCycle changes the part of the period (between 0 and 1) that square waves and pulses are high.
+// It does not change triangle waves.
+func (w *Waveform) SetDutyCycle(dutyCycle float32) {
+	if dutyCycle < 0 || dutyCycle > 1 {
+		panic("Duty cycle should be between 0 and 1")
+	}
+	w.dutyCycle = dutyCycle
+}
+
+// GetDutyCycle returns the part of the period that square waves and pulses are high
+func (w *Waveform) GetDutyCycle() float32 {
+	return w.dutyCycle
+}
+
+func (w *Waveform) next() float32 {
+	// Position inside the period, between 0 and 1
+	var p = w.nco.GetPhase() / (2 * math.Pi)
+	if p < 0 {
+		p++
+	}
+
+	w.nco.Step()
+
+	switch w.shape {
+	case WaveSquare:
+		if p < w.dutyCycle {
+			return w.amplitude
+		}
+		return -w.amplitude
+	case WaveTriangle:
+		if p < 0.5 {
+			return w.amplitude * (1 - 4*p)
+		}
+		return w.amplitude * (4*p - 3)
+	case WavePulse:
+		if p < w.dutyCycle {
+			return w.amplitude
+		}
+	}
+
+	return 0
+}
+
+func (w *Waveform) Complex(output []complex64) {
+	for i := range output {
+		output[i] = complex(w.next(), 0)
+	}
+}
+
+func (w *Waveform) Float(output []float32) {
+	for i := range output {
+		output[i] = w.next()
+	}
+}
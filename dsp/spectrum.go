@@ -0,0 +1,328 @@
+package dsp
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/dsp/fft"
+	"github.com/racerxdl/segdsp/tools"
+)
+
+type SpectrumAveraging int
+
+const (
+	// SpectrumAveragingExponential averages the frames with a single pole IIR filter
+	SpectrumAveragingExponential SpectrumAveraging = iota
+	// SpectrumAveragingLinear averages the last N frames
+	SpectrumAveragingLinear
+	// SpectrumAveragingPeakHold keeps the maximum value of each bin
+	SpectrumAveragingPeakHold
+	// SpectrumAveragingMinHold keeps the minimum value of each bin
+	SpectrumAveragingMinHold
+)
+
+type BinDecimation int
+
+const (
+	// BinDecimationMax uses the highest bin of each group (does not hide narrow signals)
+	BinDecimationMax BinDecimation = iota
+	// BinDecimationMean uses the mean power of each group
+	BinDecimationMean
+)
+
+const spectrumDefaultAlpha = 0.5
+const spectrumDefaultFrames = 4
+
+// SpectrumEstimator is a Welch Power Spectral Density estimator.
+// The input is split in windowed (and optionally overlapped) FFT frames, and the frames are averaged.
+// The power is scaled by N / sum(w²), so the noise floor does not depend on the window choice.
+// The output spectrum is shifted, so the first bin is -sampleRate / 2 and the center bin is DC.
+type SpectrumEstimator struct {
+	fftSize       int
+	hopSize       int
+	overlap       float32
+	windowType    WindowType
+	window        []float32
+	windowScale   float32
+	averaging     SpectrumAveraging
+	alpha         float32
+	averageFrames int
+	decimation    BinDecimation
+
+	sampleBuffer []complex64
+	fftBuffer    []complex64
+	fftPlan      *fft.Plan
+	frame        []float32
+	spectrum     []float32
+	frameHistory [][]float32
+	historyPos   int
+	historyLen   int
+	frames       uint64
+}
+
+// MakeSpectrumEstimator creates a Spectrum Estimator with fftSize bins.
+// overlap is the fraction of each frame that is shared with the next one (0 to 0.95)
+func MakeSpectrumEstimator(fftSize int, windowType WindowType, overlap float32) *SpectrumEstimator {
+	if fftSize < 2 {
+		panic("Spectrum Estimator FFT size should be at least 2")
+	}
+
+	var se = &SpectrumEstimator{
+		fftSize:       fftSize,
+		averaging:     SpectrumAveragingExponential,
+		alpha:         spectrumDefaultAlpha,
+		averageFrames: spectrumDefaultFrames,
+		decimation:    BinDecimationMax,
+		fftBuffer:     make([]complex64, fftSize),
+		fftPlan:       fft.MakePlan(fftSize, fft.Forward),
+		frame:         make([]float32, fftSize),
+		spectrum:      make([]float32, fftSize),
+	}
+
+	se.SetWindow(windowType)
+	se.SetOverlap(overlap)
+
+	return se
+}
+
+// SetWindow changes the window used in each frame
+func (se *SpectrumEstimator) SetWindow(windowType WindowType) {
+	var w = MakeWindow(windowType, se.fftSize)
+	var power = 0.0
+
+	se.window = make([]float32, se.fftSize)
+	for i, v := range w {
+		se.window[i] = float32(v)
+		power += v * v
+	}
+
+	se.windowType = windowType
+	se.windowScale = float32(float64(se.fftSize) / power)
+}
+
+func (se *SpectrumEstimator) GetWindow() WindowType {
+	return se.windowType
+}
+
+// SetOverlap sets the fraction of each frame that is shared with the next one (0 to 0.95)
+func (se *SpectrumEstimator) SetOverlap(overlap float32) {
+	if overlap < 0 || overlap > 0.95 {
+		panic("Spectrum Estimator overlap should be between 0 and 0.95")
+	}
+
+	se.overlap = overlap
+	se.hopSize = int(float32(se.fftSize) * (1 - overlap))
+	if se.hopSize < 1 {
+		se.hopSize = 1
+	}
+}
+
+func (se *SpectrumEstimator) GetOverlap() float32 {
+	return se.overlap
+}
+
+func (se *SpectrumEstimator) GetFFTSize() int {
+	return se.fftSize
+}
+
+// SetAveraging changes the averaging mode and resets the current average
+func (se *SpectrumEstimator) SetAveraging(averaging SpectrumAveraging) {
+	se.averaging = averaging
+	se.Reset()
+}
+
+func (se *SpectrumEstimator) GetAveraging() SpectrumAveraging {
+	return se.averaging
+}
+
+// SetAlpha sets the exponential averaging factor (1 means no averaging)
+func (se *SpectrumEstimator) SetAlpha(alpha float32) {
+	se.alpha = alpha
+}
+
+func (se *SpectrumEstimator) GetAlpha() float32 {
+	return se.alpha
+}
+
+// SetAverageFrames sets the number of frames averaged by the linear averaging and resets the current average
+func (se *SpectrumEstimator) SetAverageFrames(frames int) {
+	if frames < 1 {
+		panic("Spectrum Estimator average frames should be at least 1")
+	}
+	se.averageFrames = frames
+	se.Reset()
+}
+
+func (se *SpectrumEstimator) GetAverageFrames() int {
+	return se.averageFrames
+}
+
+// SetBinDecimation sets how the bins are grouped when the spectrum is decimated
+func (se *SpectrumEstimator) SetBinDecimation(decimation BinDecimation) {
+	se.decimation = decimation
+}
+
+func (se *SpectrumEstimator) GetBinDecimation() BinDecimation {
+	return se.decimation
+}
+
+// GetFrameCount returns the number of frames processed since the last reset
+func (se *SpectrumEstimator) GetFrameCount() uint64 {
+	return se.frames
+}
+
+// Reset clears the current average (useful after a frequency change or for restarting a peak / min hold)
+func (se *SpectrumEstimator) Reset() {
+	se.frames = 0
+	se.historyPos = 0
+	se.historyLen = 0
+	se.frameHistory = nil
+	for i := range se.spectrum {
+		se.spectrum[i] = 0
+	}
+}
+
+// Feed adds samples to the estimator and returns the number of new frames computed
+func (se *SpectrumEstimator) Feed(input []complex64) int {
+	se.sampleBuffer = append(se.sampleBuffer, input...)
+
+	var newFrames = 0
+	var pos = 0
+
+	for len(se.sampleBuffer)-pos >= se.fftSize {
+		se.processFrame(se.sampleBuffer[pos : pos+se.fftSize])
+		pos += se.hopSize
+		newFrames++
+	}
+
+	se.sampleBuffer = append(se.sampleBuffer[:0], se.sampleBuffer[pos:]...)
+
+	return newFrames
+}
+
+func (se *SpectrumEstimator) processFrame(samples []complex64) {
+	for i, v := range samples {
+		se.fftBuffer[i] = v * complex(se.window[i], 0)
+	}
+
+	se.fftPlan.ExecuteInPlace(se.fftBuffer)
+	var half = se.fftSize / 2
+
+	for i, v := range se.fftBuffer {
+		// Shift DC to the center
+		se.frame[(i+half)%se.fftSize] = tools.ComplexAbsSquared(v) * se.windowScale
+	}
+
+	se.average()
+	se.frames++
+}
+
+func (se *SpectrumEstimator) average() {
+	if se.frames == 0 && se.averaging != SpectrumAveragingLinear {
+		copy(se.spectrum, se.frame)
+		return
+	}
+
+	switch se.averaging {
+	case SpectrumAveragingExponential:
+		for i, v := range se.frame {
+			se.spectrum[i] = se.alpha*v + (1-se.alpha)*se.spectrum[i]
+		}
+	case SpectrumAveragingLinear:
+		se.averageLinear()
+	case SpectrumAveragingPeakHold:
+		for i, v := range se.frame {
+			if v > se.spectrum[i] {
+				se.spectrum[i] = v
+			}
+		}
+	case SpectrumAveragingMinHold:
+		for i, v := range se.frame {
+			if v < se.spectrum[i] {
+				se.spectrum[i] = v
+			}
+		}
+	}
+}
+
+func (se *SpectrumEstimator) averageLinear() {
+	if se.frameHistory == nil {
+		se.frameHistory = make([][]float32, se.averageFrames)
+		for i := range se.frameHistory {
+			se.frameHistory[i] = make([]float32, se.fftSize)
+		}
+	}
+
+	copy(se.frameHistory[se.historyPos], se.frame)
+	se.historyPos = (se.historyPos + 1) % se.averageFrames
+
+	if se.historyLen < se.averageFrames {
+		se.historyLen++
+	}
+
+	for i := range se.spectrum {
+		var sum = float32(0)
+		for j := 0; j < se.historyLen; j++ {
+			sum += se.frameHistory[j][i]
+		}
+		se.spectrum[i] = sum / float32(se.historyLen)
+	}
+}
+
+// GetSpectrum returns the current averaged power spectrum (linear scale, DC centered)
+func (se *SpectrumEstimator) GetSpectrum() []float32 {
+	return se.spectrum
+}
+
+// GetSpectrumDB returns the current averaged power spectrum in dB, optionally scaled by scale before conversion.
+// If width is higher than 0 and different from the FFT size, the bins are decimated (or repeated) to width values.
+func (se *SpectrumEstimator) GetSpectrumDB(width int, scale float32) []float32 {
+	var spectrum = se.spectrum
+
+	if width > 0 && width != se.fftSize {
+		spectrum = DecimateBins(spectrum, width, se.decimation)
+	} else {
+		spectrum = append([]float32(nil), spectrum...)
+	}
+
+	for i, v := range spectrum {
+		spectrum[i] = float32(10 * math.Log10(float64(v*scale)))
+	}
+
+	return spectrum
+}
+
+// DecimateBins groups the bins of a (linear scale) spectrum into width values.
+// If width is higher than the number of bins, the bins are repeated.
+func DecimateBins(spectrum []float32, width int, mode BinDecimation) []float32 {
+	var output = make([]float32, width)
+	var bins = len(spectrum)
+
+	for i := 0; i < width; i++ {
+		var start = i * bins / width
+		var end = (i + 1) * bins / width
+		if end <= start {
+			end = start + 1
+		}
+
+		var v = spectrum[start]
+
+		for j := start + 1; j < end; j++ {
+			switch mode {
+			case BinDecimationMax:
+				if spectrum[j] > v {
+					v = spectrum[j]
+				}
+			case BinDecimationMean:
+				v += spectrum[j]
+			}
+		}
+
+		if mode == BinDecimationMean {
+			v /= float32(end - start)
+		}
+
+		output[i] = v
+	}
+
+	return output
+}
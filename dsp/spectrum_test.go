@@ -0,0 +1,126 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func makeSpectrumTestTone(length int, frequency float32) []complex64 {
+	var nco = MakeNCO()
+	nco.SetFrequency(2 * math.Pi * frequency)
+	return nco.Complex64SinCos(length, 1)
+}
+
+func TestSpectrumEstimatorTone(t *testing.T) {
+	const fftSize = 256
+	// Tone between two bins, at bin +32.5 (after shift: 128 + 32.5)
+	var input = makeSpectrumTestTone(fftSize*8, 32.5/fftSize)
+
+	var rect = MakeSpectrumEstimator(fftSize, WindowRectangular, 0.5)
+	var bh = MakeSpectrumEstimator(fftSize, WindowBlackmanHarris, 0.5)
+
+	if n := rect.Feed(input); n != 15 {
+		t.Fatalf("Expected 15 frames, got %d", n)
+	}
+	bh.Feed(input)
+
+	var rectSpectrum = rect.GetSpectrumDB(0, 1)
+	var bhSpectrum = bh.GetSpectrumDB(0, 1)
+
+	var peak = 0
+	for i, v := range bhSpectrum {
+		if v > bhSpectrum[peak] {
+			peak = i
+		}
+	}
+
+	if peak != fftSize/2+32 && peak != fftSize/2+33 {
+		t.Fatalf("Expected peak at bin %d or %d, got %d", fftSize/2+32, fftSize/2+33, peak)
+	}
+
+	// Leakage far from the tone should be much lower with a Blackman-Harris window
+	var far = fftSize/2 - 64
+	var rectLeak = rectSpectrum[peak] - rectSpectrum[far]
+	var bhLeak = bhSpectrum[peak] - bhSpectrum[far]
+
+	if bhLeak < rectLeak+40 {
+		t.Fatalf("Expected Blackman-Harris leakage to be at least 40 dB lower than rectangular. Got %f dB vs %f dB", bhLeak, rectLeak)
+	}
+}
+
+func TestSpectrumEstimatorHold(t *testing.T) {
+	const fftSize = 64
+	var loud = makeSpectrumTestTone(fftSize, 0.25)
+	var quiet = make([]complex64, fftSize)
+	for i, v := range loud {
+		quiet[i] = v * 0.1
+	}
+
+	var peak = MakeSpectrumEstimator(fftSize, WindowHann, 0)
+	var min = MakeSpectrumEstimator(fftSize, WindowHann, 0)
+	peak.SetAveraging(SpectrumAveragingPeakHold)
+	min.SetAveraging(SpectrumAveragingMinHold)
+
+	for _, block := range [][]complex64{quiet, loud, quiet} {
+		peak.Feed(block)
+		min.Feed(block)
+	}
+
+	var bin = fftSize/2 + fftSize/4
+	var diff = 10 * math.Log10(float64(peak.GetSpectrum()[bin]/min.GetSpectrum()[bin]))
+
+	if math.Abs(diff-20) > 0.1 {
+		t.Fatalf("Expected 20 dB between peak hold and min hold, got %f", diff)
+	}
+}
+
+func TestSpectrumEstimatorLinearAverage(t *testing.T) {
+	const fftSize = 32
+	var loud = makeSpectrumTestTone(fftSize, 0.25)
+	var silence = make([]complex64, fftSize)
+
+	var se = MakeSpectrumEstimator(fftSize, WindowRectangular, 0)
+	se.SetAveraging(SpectrumAveragingLinear)
+	se.SetAverageFrames(2)
+
+	se.Feed(loud)
+	var full = se.GetSpectrum()[fftSize/2+fftSize/4]
+	se.Feed(silence)
+	var half = se.GetSpectrum()[fftSize/2+fftSize/4]
+	se.Feed(silence)
+	var none = se.GetSpectrum()[fftSize/2+fftSize/4]
+
+	if math.Abs(float64(half/full)-0.5) > 1e-3 {
+		t.Fatalf("Expected half of the power after two frames, got %f", half/full)
+	}
+
+	if none > full*1e-6 {
+		t.Fatalf("Expected no power after the loud frame left the average, got %f", none)
+	}
+}
+
+func TestDecimateBins(t *testing.T) {
+	var spectrum = []float32{1, 3, 2, 2, 0, 4, 5, 1}
+
+	var max = DecimateBins(spectrum, 4, BinDecimationMax)
+	var mean = DecimateBins(spectrum, 4, BinDecimationMean)
+	var expectedMax = []float32{3, 2, 4, 5}
+	var expectedMean = []float32{2, 2, 2, 3}
+
+	for i := range max {
+		if max[i] != expectedMax[i] {
+			t.Fatalf("Max: Expected %f got %f at %d", expectedMax[i], max[i], i)
+		}
+		if mean[i] != expectedMean[i] {
+			t.Fatalf("Mean: Expected %f got %f at %d", expectedMean[i], mean[i], i)
+		}
+	}
+
+	var repeated = DecimateBins(spectrum[:2], 4, BinDecimationMax)
+	var expectedRepeated = []float32{1, 1, 3, 3}
+	for i := range repeated {
+		if repeated[i] != expectedRepeated[i] {
+			t.Fatalf("Repeat: Expected %f got %f at %d", expectedRepeated[i], repeated[i], i)
+		}
+	}
+}
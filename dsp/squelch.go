@@ -4,17 +4,23 @@ import (
 	"math"
 )
 
+// Squelch is a RF Power squelch. It opens when the average power goes over the threshold, and closes when it goes
+// under the threshold minus the hysteresis. Attack and hang times (in samples) can be used to avoid fluttering.
 type Squelch struct {
-	threshold    float32
-	thresholddB  float32
-	muted        bool
-	filter       *SinglePoleIIRFilter
-	avgThreshold float32
+	threshold      float32
+	thresholddB    float32
+	hysteresis     float32
+	closeThreshold float32
+	muted          bool
+	filter         *SinglePoleIIRFilter
+	avgThreshold   float32
+	gate           squelchGate
 }
 
 func MakeSquelch(threshold, alpha float32) *Squelch {
 	var s = Squelch{
 		filter: MakeSinglePoleIIRFilter(alpha),
+		muted:  true,
 	}
 	s.SetThreshold(threshold)
 	return &s
@@ -35,6 +41,35 @@ func (f *Squelch) SetAlpha(alpha float32) {
 func (f *Squelch) SetThreshold(dB float32) {
 	f.thresholddB = dB
 	f.threshold = float32(math.Pow(10, float64(dB/10.0)))
+	f.closeThreshold = float32(math.Pow(10, float64((dB-f.hysteresis)/10.0)))
+}
+
+// SetHysteresis sets how much (in dB) the level should go under the threshold to close the squelch
+func (f *Squelch) SetHysteresis(dB float32) {
+	f.hysteresis = dB
+	f.SetThreshold(f.thresholddB)
+}
+
+func (f *Squelch) GetHysteresis() float32 {
+	return f.hysteresis
+}
+
+// SetAttack sets for how many samples the level should stay over the threshold to open the squelch
+func (f *Squelch) SetAttack(samples int) {
+	f.gate.attack = samples
+}
+
+func (f *Squelch) GetAttack() int {
+	return f.gate.attack
+}
+
+// SetHang sets for how many samples the squelch stays open after the level goes under the close threshold
+func (f *Squelch) SetHang(samples int) {
+	f.gate.hang = samples
+}
+
+func (f *Squelch) GetHang() int {
+	return f.gate.hang
 }
 
 func (f *Squelch) GetThreshold() float32 {
@@ -54,9 +89,9 @@ func (f *Squelch) Work(data []complex64) []complex64 {
 	}
 	avg /= float32(len(data))
 	f.avgThreshold = avg
-	f.muted = avg <= f.threshold
+	f.muted = !f.gate.update(avg > f.threshold, avg <= f.closeThreshold, len(data))
 
-	if avg >= f.threshold {
+	if !f.muted {
 		return data
 	} else {
 		return out
@@ -78,9 +113,9 @@ func (f *Squelch) WorkBuffer(input, output []complex64) int {
 	}
 	avg /= float32(len(input))
 	f.avgThreshold = avg
-	f.muted = avg <= f.threshold
+	f.muted = !f.gate.update(avg > f.threshold, avg <= f.closeThreshold, len(input))
 
-	if avg >= f.threshold {
+	if !f.muted {
 		copy(output, input)
 	}
 
@@ -0,0 +1,114 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const squelchTestSampleRate = 48000
+const squelchTestDeviation = 5000
+const squelchTestBlockSize = 1024
+
+// makeFMTestSignal generates a FM modulated 1 kHz tone with complex white noise of noiseAmplitude
+func makeFMTestSignal(length int, signalAmplitude, noiseAmplitude float32) []complex64 {
+	var r = rand.New(rand.NewSource(1))
+	var signal = make([]complex64, length)
+	var phase = 0.0
+
+	for i := range signal {
+		var tone = math.Sin(2 * math.Pi * 1000 * float64(i) / squelchTestSampleRate)
+		phase += 2 * math.Pi * squelchTestDeviation * tone / squelchTestSampleRate
+		var noise = complex(float32(r.NormFloat64()), float32(r.NormFloat64())) * complex(noiseAmplitude, 0)
+		signal[i] = complex(signalAmplitude*float32(math.Cos(phase)), signalAmplitude*float32(math.Sin(phase))) + noise
+	}
+
+	return signal
+}
+
+func runNoiseSquelch(input []complex64) *NoiseSquelch {
+	var demod = MakeQuadDemod(squelchTestSampleRate / (2 * math.Pi * squelchTestDeviation))
+	var sql = MakeNoiseSquelch(-10, 0.01, squelchTestSampleRate, squelchTestSampleRate*0.4)
+	var discriminator = demod.Work(input)
+
+	for i := 0; i+squelchTestBlockSize <= len(discriminator); i += squelchTestBlockSize {
+		sql.Work(discriminator[i : i+squelchTestBlockSize])
+	}
+
+	return sql
+}
+
+func TestNoiseSquelch(t *testing.T) {
+	var strong = runNoiseSquelch(makeFMTestSignal(squelchTestSampleRate/2, 1, 0.01))
+	var noise = runNoiseSquelch(makeFMTestSignal(squelchTestSampleRate/2, 0, 1))
+
+	if strong.IsMuted() {
+		t.Errorf("Expected squelch to be open for a strong signal. Noise level: %f dB", strong.GetAvgLevel())
+	}
+
+	if !noise.IsMuted() {
+		t.Errorf("Expected squelch to be closed for noise. Noise level: %f dB", noise.GetAvgLevel())
+	}
+
+	if noise.GetAvgLevel()-strong.GetAvgLevel() < 20 {
+		t.Errorf("Expected at least 20 dB difference between noise and signal, got %f", noise.GetAvgLevel()-strong.GetAvgLevel())
+	}
+}
+
+func TestSquelchHysteresis(t *testing.T) {
+	var block = func(amplitude float32) []complex64 {
+		var data = make([]complex64, squelchTestBlockSize)
+		for i := range data {
+			data[i] = complex(amplitude, 0)
+		}
+		return data
+	}
+
+	// alpha 1 makes the level follow the input instantly
+	var sql = MakeSquelch(-20, 1)
+	sql.SetHysteresis(6)
+	sql.SetAttack(squelchTestBlockSize)
+	sql.SetHang(2 * squelchTestBlockSize)
+
+	var over = block(0.2)     // -14 dB
+	var between = block(0.08) // -22 dB
+	var under = block(0.01)   // -40 dB
+
+	if !sql.IsMuted() {
+		t.Fatal("Expected squelch to start muted")
+	}
+
+	sql.Work(over)
+	if !sql.IsMuted() {
+		t.Fatal("Squelch opened before attack time")
+	}
+
+	sql.Work(over)
+	if sql.IsMuted() {
+		t.Fatal("Squelch did not open after attack time")
+	}
+
+	for i := 0; i < 5; i++ {
+		sql.Work(between)
+		if sql.IsMuted() {
+			t.Fatal("Squelch closed inside hysteresis")
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		sql.Work(under)
+		if sql.IsMuted() {
+			t.Fatal("Squelch closed before hang time")
+		}
+	}
+
+	sql.Work(under)
+	if !sql.IsMuted() {
+		t.Fatal("Squelch did not close after hang time")
+	}
+
+	var output = sql.Work(over)
+	if output[0] != 0 {
+		t.Fatal("Expected muted output to be zero")
+	}
+}
@@ -0,0 +1,41 @@
+package dsp
+
+// squelchGate holds the open / close state of a squelch, with attack and hang times (in samples).
+// The squelch only opens after the open condition holds for the attack time, and only closes after
+// the close condition holds for the hang time.
+type squelchGate struct {
+	attack    int
+	hang      int
+	open      bool
+	openTime  int
+	closeTime int
+}
+
+// update advances the gate by n samples and returns true if the squelch is open
+func (g *squelchGate) update(shouldOpen, shouldClose bool, n int) bool {
+	if g.open {
+		g.openTime = 0
+		if shouldClose {
+			g.closeTime += n
+			if g.closeTime > g.hang {
+				g.open = false
+				g.closeTime = 0
+			}
+		} else {
+			g.closeTime = 0
+		}
+	} else {
+		g.closeTime = 0
+		if shouldOpen {
+			g.openTime += n
+			if g.openTime > g.attack {
+				g.open = true
+				g.openTime = 0
+			}
+		} else {
+			g.openTime = 0
+		}
+	}
+
+	return g.open
+}
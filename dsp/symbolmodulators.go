@@ -0,0 +1,241 @@
+package dsp
+
+import (
+	"math"
+
+	"github.com/racerxdl/segdsp/tools"
+)
+
+// grayToIndex returns the natural index of the gray coded value g
+func grayToIndex(g int) int {
+	var n = g
+	for s := g >> 1; s != 0; s >>= 1 {
+		n ^= s
+	}
+	return n
+}
+
+// pulseShaper upsamples symbols by zero stuffing and filters them with the pulse shaping taps
+type pulseShaper struct {
+	taps             []float32
+	samplesPerSymbol int
+	sampleHistory    []complex64
+	buffer           []complex64
+}
+
+func makePulseShaper(samplesPerSymbol int, taps []float32) *pulseShaper {
+	if len(taps) == 0 {
+		taps = []float32{1}
+	}
+
+	return &pulseShaper{
+		taps:             tools.ReverseFloat32Taps(taps),
+		samplesPerSymbol: samplesPerSymbol,
+		sampleHistory:    make([]complex64, len(taps)-1),
+	}
+}
+
+func (p *pulseShaper) work(symbols, output []complex64) int {
+	var length = len(symbols) * p.samplesPerSymbol
+
+	if len(output) < length {
+		panic("There is not enough space in output buffer")
+	}
+
+	if len(p.buffer) < length {
+		p.buffer = make([]complex64, length)
+	}
+
+	for i := 0; i < length; i++ {
+		p.buffer[i] = 0
+	}
+	for i, v := range symbols {
+		p.buffer[i*p.samplesPerSymbol] = v
+	}
+
+	var samples = append(p.sampleHistory, p.buffer[:length]...)
+
+	for i := 0; i < length; i++ {
+		output[i] = DotProductResult(samples[i:], p.taps)
+	}
+
+	p.sampleHistory = samples[length:]
+
+	return length
+}
+
+// region PSK Modulator
+
+// PSKModulator maps symbols (one per input byte, using the lower bits) to a gray coded PSK constellation
+// and shapes them with a pulse shaping filter.
+type PSKModulator struct {
+	constellation    []complex64
+	samplesPerSymbol int
+	shaper           *pulseShaper
+	symbols          []complex64
+}
+
+// MakePSKModulator creates a PSK Modulator with bitsPerSymbol (1 for BPSK, 2 for QPSK, 3 for 8PSK).
+// taps is the pulse shaping filter at samplesPerSymbol, like MakeRRC(samplesPerSymbol, samplesPerSymbol, 1, 0.35, 11 * samplesPerSymbol).
+// If taps is nil, each symbol is a single impulse.
+func MakePSKModulator(bitsPerSymbol, samplesPerSymbol int, taps []float32) *PSKModulator {
+	if bitsPerSymbol < 1 || bitsPerSymbol > 8 {
+		panic("PSK Modulator bits per symbol should be between 1 and 8")
+	}
+
+	var order = 1 << uint(bitsPerSymbol)
+	var offset = 0.0
+	if order == 4 {
+		offset = math.Pi / 4
+	}
+
+	var constellation = make([]complex64, order)
+	for i := range constellation {
+		var s, c = math.Sincos(2*math.Pi*float64(grayToIndex(i))/float64(order) + offset)
+		constellation[i] = complex(float32(c), float32(s))
+	}
+
+	return &PSKModulator{
+		constellation:    constellation,
+		samplesPerSymbol: samplesPerSymbol,
+		shaper:           makePulseShaper(samplesPerSymbol, taps),
+	}
+}
+
+// GetConstellation returns the constellation point of each symbol
+func (f *PSKModulator) GetConstellation() []complex64 {
+	return f.constellation
+}
+
+func (f *PSKModulator) Work(data []byte) []complex64 {
+	var output = make([]complex64, f.PredictOutputSize(len(data)))
+	f.WorkBuffer(data, output)
+	return output
+}
+
+func (f *PSKModulator) WorkBuffer(input []byte, output []complex64) int {
+	if len(f.symbols) < len(input) {
+		f.symbols = make([]complex64, len(input))
+	}
+
+	var mask = byte(len(f.constellation) - 1)
+	for i, v := range input {
+		f.symbols[i] = f.constellation[v&mask]
+	}
+
+	return f.shaper.work(f.symbols[:len(input)], output)
+}
+
+func (f *PSKModulator) PredictOutputSize(inputLength int) int {
+	return inputLength * f.samplesPerSymbol
+}
+
+// endregion
+// region FSK Modulator
+
+// FSKModulator maps symbols (one per input byte, using the lower bits) to gray coded frequency levels.
+// With Gaussian taps (see MakeGFSKModulator) the frequency transitions are smoothed (GFSK).
+type FSKModulator struct {
+	levels           []float32
+	samplesPerSymbol int
+	sensitivity      float32
+	phase            float32
+	shaper           *pulseShaper
+	symbols          []complex64
+	frequency        []complex64
+}
+
+// MakeFSKModulator creates a FSK Modulator with levels frequencies (2 for 2FSK, 4 for 4FSK) spaced
+// between -deviation and deviation (in Hertz) at sampleRate
+func MakeFSKModulator(levels, samplesPerSymbol int, deviation, sampleRate float32) *FSKModulator {
+	var rect = make([]float32, samplesPerSymbol)
+	for i := range rect {
+		rect[i] = 1
+	}
+
+	return makeFSKModulator(levels, samplesPerSymbol, deviation, sampleRate, rect)
+}
+
+// MakeGFSKModulator creates a FSK Modulator that smooths the frequency transitions with a Gaussian filter
+// of bandwidth-time product bt (like 0.5 for GFSK and 0.3 for GMSK)
+func MakeGFSKModulator(levels, samplesPerSymbol int, deviation, sampleRate, bt float32) *FSKModulator {
+	var gaussian = MakeGaussian(1, float64(samplesPerSymbol), float64(bt), 4*samplesPerSymbol+1)
+	var taps = make([]float32, len(gaussian)+samplesPerSymbol-1)
+
+	// Gaussian filter of the rectangular (NRZ) symbol pulse
+	for i, g := range gaussian {
+		for j := 0; j < samplesPerSymbol; j++ {
+			taps[i+j] += g
+		}
+	}
+
+	return makeFSKModulator(levels, samplesPerSymbol, deviation, sampleRate, taps)
+}
+
+func makeFSKModulator(levels, samplesPerSymbol int, deviation, sampleRate float32, taps []float32) *FSKModulator {
+	if levels < 2 || levels > 256 || !tools.IsPowerOf2(levels) {
+		panic("FSK Modulator levels should be a power of 2 between 2 and 256")
+	}
+
+	var l = make([]float32, levels)
+	for i := range l {
+		var n = grayToIndex(i)
+		l[i] = float32(2*n-(levels-1)) / float32(levels-1)
+	}
+
+	return &FSKModulator{
+		levels:           l,
+		samplesPerSymbol: samplesPerSymbol,
+		sensitivity:      float32(2 * math.Pi * float64(deviation) / float64(sampleRate)),
+		shaper:           makePulseShaper(samplesPerSymbol, taps),
+	}
+}
+
+// GetLevels returns the frequency level (between -1 and 1) of each symbol
+func (f *FSKModulator) GetLevels() []float32 {
+	return f.levels
+}
+
+func (f *FSKModulator) Work(data []byte) []complex64 {
+	var output = make([]complex64, f.PredictOutputSize(len(data)))
+	f.WorkBuffer(data, output)
+	return output
+}
+
+func (f *FSKModulator) WorkBuffer(input []byte, output []complex64) int {
+	var length = f.PredictOutputSize(len(input))
+
+	if len(f.symbols) < len(input) {
+		f.symbols = make([]complex64, len(input))
+	}
+	if len(f.frequency) < length {
+		f.frequency = make([]complex64, length)
+	}
+
+	var mask = byte(len(f.levels) - 1)
+	for i, v := range input {
+		f.symbols[i] = complex(f.levels[v&mask], 0)
+	}
+
+	f.shaper.work(f.symbols[:len(input)], f.frequency)
+
+	if len(output) < length {
+		panic("There is not enough space in output buffer")
+	}
+
+	for i := 0; i < length; i++ {
+		f.phase += f.sensitivity * real(f.frequency[i])
+		if f.phase > math.Pi || f.phase < -math.Pi {
+			f.phase = float32(math.Remainder(float64(f.phase), 2*math.Pi))
+		}
+		output[i] = tools.PhaseToComplex(f.phase)
+	}
+
+	return length
+}
+
+func (f *FSKModulator) PredictOutputSize(inputLength int) int {
+	return inputLength * f.samplesPerSymbol
+}
+
+// endregion
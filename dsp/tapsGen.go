@@ -27,7 +27,7 @@ func MakeRRC(gain, sampleRate, symbolRate, alpha float64, nTaps int) []float32 {
 	var x1, x2, x3, num, den, xindx float64
 
 	for i := 0; i < nTaps; i++ {
-		xindx = float64(i) - float64(nTaps)/2.0
+		xindx = float64(i - nTaps/2)
 		x1 = math.Pi * xindx / spb
 		x2 = 4 * alpha * xindx / spb
 		x3 = x2*x2 - 1
@@ -67,10 +67,33 @@ func MakeRRC(gain, sampleRate, symbolRate, alpha float64, nTaps int) []float32 {
 	return taps
 }
 
+// MakeGaussian generates a Gaussian pulse shaping FIR with nTaps for symbols of samplesPerSymbol samples and a
+// bandwidth-time product of bt. The taps have unit DC gain scaled by gain.
+func MakeGaussian(gain, samplesPerSymbol, bt float64, nTaps int) []float32 {
+	var taps = make([]float32, nTaps)
+	var dt = 1 / samplesPerSymbol
+	var s = 1 / (math.Sqrt(math.Log(2)) / (2 * math.Pi * bt))
+	var center = float64(nTaps-1) / 2
+	var scale = 0.0
+
+	for i := 0; i < nTaps; i++ {
+		var ts = s * dt * (float64(i) - center)
+		var v = math.Exp(-0.5 * ts * ts)
+		taps[i] = float32(v)
+		scale += v
+	}
+
+	for i := 0; i < nTaps; i++ {
+		taps[i] = float32(float64(taps[i]) * gain / scale)
+	}
+
+	return taps
+}
+
 func MakeLowPass(gain, sampleRate, cutFrequency, transitionWidth float64) []float32 {
 	var nTaps = computeNTaps(sampleRate, transitionWidth)
 	var taps = make([]float32, nTaps)
-	var w = HammingWindow(nTaps)
+	var w = HannWindow(nTaps)
 
 	var M = (nTaps - 1) / 2
 	var fwT0 = 2 * math.Pi * cutFrequency / sampleRate
@@ -100,7 +123,7 @@ func MakeLowPass(gain, sampleRate, cutFrequency, transitionWidth float64) []floa
 func MakeLowPass2(gain, sampleRate, cutFrequency, transitionWidth, attenuation float64) []float32 {
 	var nTaps = computeNTapsAtt(sampleRate, transitionWidth, attenuation)
 	var taps = make([]float32, nTaps)
-	var w = HammingWindow(nTaps)
+	var w = HannWindow(nTaps)
 
 	var M = (nTaps - 1) / 2
 	var fwT0 = 2 * math.Pi * cutFrequency / sampleRate
@@ -170,3 +193,37 @@ func MakeLowPassFixed(gain, sampleRate, cutFrequency float64, length int) []floa
 
 	return taps
 }
+
+// MakeHighPassFixed generates a High Pass FIR with length taps (forced to be odd) by spectral inversion of MakeLowPassFixed
+func MakeHighPassFixed(gain, sampleRate, cutFrequency float64, length int) []float32 {
+	var taps = MakeLowPassFixed(1, sampleRate, cutFrequency, length)
+	var center = len(taps) / 2
+
+	for i := range taps {
+		taps[i] = -taps[i] * float32(gain)
+	}
+
+	taps[center] += float32(gain)
+
+	return taps
+}
+
+// MakeHilbert generates a Hilbert Transformer FIR with nTaps (forced to be odd).
+// The taps are ordered as a convolution kernel, so they need to be reversed before being used by the Fir Filters.
+func MakeHilbert(nTaps int) []float32 {
+	nTaps |= 1
+
+	var taps = make([]float32, nTaps)
+	var w = HannWindow(nTaps)
+	var M = (nTaps - 1) / 2
+
+	for i := -M; i <= M; i++ {
+		if i&1 == 0 {
+			taps[i+M] = 0
+		} else {
+			taps[i+M] = float32(2 / (math.Pi * float64(i)) * w[i+M])
+		}
+	}
+
+	return taps
+}
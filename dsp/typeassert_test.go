@@ -16,6 +16,10 @@ func TestComplexWorkers(t *testing.T) {
 		&RationalResampler{},
 		&Squelch{},
 		&Rotator{},
+		&FLLBandEdge{},
+		&AFC{},
+		&NoiseBlanker{},
+		&IQCorrector{},
 	}
 
 	for _, v := range complexWorkersType {
@@ -33,6 +37,13 @@ func TestFloat32Workers(t *testing.T) {
 		&FloatInterpolator{},
 		&FloatRationalResampler{},
 		&FloatResampler{},
+		&SpectralNoiseReduction{},
+		&NotchFilter{},
+		&NoiseSquelch{},
+		&FMPreemph{},
+		&AudioAGC{},
+		&AudioGain{},
+		&SoftLimiter{},
 	}
 
 	for _, v := range floatWorkersType {
@@ -47,6 +58,7 @@ func TestComplex2Float32Workers(t *testing.T) {
 	var cfWorkersType = []interface{}{
 		&QuadDemod{},
 		&Complex2Magnitude{},
+		&Complex2FloatHilbert{},
 	}
 
 	for _, v := range cfWorkersType {
@@ -56,3 +68,70 @@ func TestComplex2Float32Workers(t *testing.T) {
 		}
 	}
 }
+
+func TestFloat322ComplexWorkers(t *testing.T) {
+	var fcWorkersType = []interface{}{
+		&Float2ComplexHilbert{},
+		&FMModulator{},
+		&AMModulator{},
+		&SSBModulator{},
+	}
+
+	for _, v := range fcWorkersType {
+		_, ok := v.(Float322ComplexWorker)
+		if !ok {
+			t.Fatalf("Type %T does not implement Float322ComplexWorker type!\n", v)
+		}
+	}
+}
+
+func TestByte2ComplexWorkers(t *testing.T) {
+	var bcWorkersType = []interface{}{
+		&ByteToComplex{},
+		&PSKModulator{},
+		&FSKModulator{},
+	}
+
+	for _, v := range bcWorkersType {
+		_, ok := v.(Byte2ComplexWorker)
+		if !ok {
+			t.Fatalf("Type %T does not implement Byte2ComplexWorker type!\n", v)
+		}
+	}
+}
+
+func TestComplex2ByteWorkers(t *testing.T) {
+	var cbWorkersType = []interface{}{
+		&ComplexToByte{},
+	}
+
+	for _, v := range cbWorkersType {
+		_, ok := v.(Complex2ByteWorker)
+		if !ok {
+			t.Fatalf("Type %T does not implement Complex2ByteWorker type!\n", v)
+		}
+	}
+}
+
+func TestTagOffsetMappers(t *testing.T) {
+	var mappersType = []interface{}{
+		&CTFirFilter{},
+		&Decimator{},
+		&FirFilter{},
+		&FrequencyTranslator{},
+		&Interpolator{},
+		&RationalResampler{},
+		&FloatFirFilter{},
+		&FloatDecimator{},
+		&FloatInterpolator{},
+		&FloatRationalResampler{},
+		&FloatResampler{},
+	}
+
+	for _, v := range mappersType {
+		_, ok := v.(TagOffsetMapper)
+		if !ok {
+			t.Fatalf("Type %T does not implement TagOffsetMapper type!\n", v)
+		}
+	}
+}
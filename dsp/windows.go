@@ -2,7 +2,8 @@ package dsp
 
 import "math"
 
-func HammingWindow(nTaps int) []float64 {
+// HannWindow generates a Hann (raised cosine) window
+func HannWindow(nTaps int) []float64 {
 	var taps = make([]float64, nTaps)
 
 	var M = float64(nTaps - 1)
@@ -14,6 +15,30 @@ func HammingWindow(nTaps int) []float64 {
 	return taps
 }
 
+// HammingWindow generates a Hamming window
+func HammingWindow(nTaps int) []float64 {
+	var taps = make([]float64, nTaps)
+
+	var M = float64(nTaps - 1)
+
+	for i := 0; i < nTaps; i++ {
+		taps[i] = 0.54 - 0.46*math.Cos((2*math.Pi*float64(i))/M)
+	}
+
+	return taps
+}
+
+// RectangularWindow generates a window with all values set to 1
+func RectangularWindow(nTaps int) []float64 {
+	var taps = make([]float64, nTaps)
+
+	for i := 0; i < nTaps; i++ {
+		taps[i] = 1
+	}
+
+	return taps
+}
+
 func cosWindow3(nTaps int, c0, c1, c2 float64) []float64 {
 	taps := make([]float64, nTaps)
 	M := float64(nTaps - 1)
@@ -40,21 +65,21 @@ func cosWindow4(nTaps int, c0, c1, c2, c3 float64) []float64 {
 	return taps
 }
 
-//func cosWindow5(nTaps int, c0, c1, c2, c3, c4 float64) []float64 {
-//	taps := make([]float64, nTaps)
-//	M := float64(nTaps - 1)
-//
-//	for i := 0; i < nTaps; i++ {
-//		var a = c0
-//		var b = c1 * math.Cos((2 * math.Pi * float64(i)) / M)
-//		var c = c2 * math.Cos((4 * math.Pi * float64(i)) / M)
-//		var d = c3 * math.Cos((6 * math.Pi * float64(i)) / M)
-//		var e = c3 * math.Cos((8 * math.Pi * float64(i)) / M)
-//		taps[i] = a - b + c - d + e
-//	}
-//
-//	return taps
-//}
+func cosWindow5(nTaps int, c0, c1, c2, c3, c4 float64) []float64 {
+	taps := make([]float64, nTaps)
+	M := float64(nTaps - 1)
+
+	for i := 0; i < nTaps; i++ {
+		var a = c0
+		var b = c1 * math.Cos((2*math.Pi*float64(i))/M)
+		var c = c2 * math.Cos((4*math.Pi*float64(i))/M)
+		var d = c3 * math.Cos((6*math.Pi*float64(i))/M)
+		var e = c4 * math.Cos((8*math.Pi*float64(i))/M)
+		taps[i] = a - b + c - d + e
+	}
+
+	return taps
+}
 
 func BlackmanHarris(nTaps, atten int) []float64 {
 	switch atten {
@@ -70,3 +95,36 @@ func BlackmanHarris(nTaps, atten int) []float64 {
 		panic("BlackmanHarris attenuation must be one of the following values: 61, 67, 74, or 92")
 	}
 }
+
+// FlatTopWindow generates a Flat Top window (good amplitude accuracy for spectrum measurements)
+func FlatTopWindow(nTaps int) []float64 {
+	return cosWindow5(nTaps, 0.21557895, 0.41663158, 0.277263158, 0.083578947, 0.006947368)
+}
+
+type WindowType int
+
+const (
+	WindowRectangular WindowType = iota
+	WindowHamming
+	WindowHann
+	WindowBlackmanHarris
+	WindowFlatTop
+)
+
+// MakeWindow generates a window of the specified type. Blackman-Harris uses the 92 dB variant.
+func MakeWindow(windowType WindowType, nTaps int) []float64 {
+	switch windowType {
+	case WindowRectangular:
+		return RectangularWindow(nTaps)
+	case WindowHamming:
+		return HammingWindow(nTaps)
+	case WindowHann:
+		return HannWindow(nTaps)
+	case WindowBlackmanHarris:
+		return BlackmanHarris(nTaps, 92)
+	case WindowFlatTop:
+		return FlatTopWindow(nTaps)
+	default:
+		panic("Unknown window type")
+	}
+}
@@ -3,22 +3,182 @@ package main
 import (
 	"fmt"
 	"github.com/racerxdl/segdsp/demodcore"
+	"github.com/racerxdl/segdsp/dsp"
+	"time"
 )
 
+const noiseReductionFrameSize = 512
+
+const cfarGuardCells = 2
+const cfarReferenceCells = 16
+const signalActivateFrames = 3
+const signalHoldTime = 2 * time.Second
+
+const notchTaps = 32
+const notchDelay = 16
+const notchStep = 0.005
+const notchBandwidth = 100
+
 func buildFM(sampleRate uint32) *demodcore.FMDemod {
-	return demodcore.MakeCustomFMDemodulator(sampleRate, float64(filterBandwidth), uint32(outputRate), fmTau, squelch, squelchAlpha, float32(fmDeviation))
+	var fm = demodcore.MakeCustomFMDemodulator(sampleRate, float64(filterBandwidth), uint32(outputRate), fmTau, squelch, squelchAlpha, float32(fmDeviation))
+	if afc {
+		fm.EnableAFC(afcPullIn)
+	}
+	switch squelchMode {
+	case squelchModePower:
+	case squelchModeNoise:
+		fm.EnableNoiseSquelch(noiseSquelch)
+	default:
+		panic(fmt.Sprintf("Unsupported Squelch Mode: %s", squelchMode))
+	}
+	return fm
 }
 func buildAM(sampleRate uint32) *demodcore.AMDemod {
-	return demodcore.MakeCustomAMDemodulator(sampleRate, float64(filterBandwidth), uint32(outputRate), amAudioCut, squelch, squelchAlpha)
+	var am = demodcore.MakeCustomAMDemodulator(sampleRate, float64(filterBandwidth), uint32(outputRate), amAudioCut, squelch, squelchAlpha)
+	am.SetNotch(buildNotch(amNotch, amNotchFrequency))
+	return am
+}
+
+func buildPSK(sampleRate uint32) *demodcore.PSKDemod {
+	var psk = demodcore.MakePSKDemodulator(sampleRate, float64(filterBandwidth), pskSymbolRate, int(pskOrder), squelch, squelchAlpha)
+	if afc {
+		psk.EnableAFC(afcPullIn)
+	}
+	return psk
+}
+
+func buildNotch(mode string, frequency float32) *dsp.NotchFilter {
+	switch mode {
+	case notchModeOff:
+		return nil
+	case notchModeAuto:
+		return dsp.MakeAutoNotch(notchTaps, notchDelay, notchStep)
+	case notchModeManual:
+		return dsp.MakeManualNotch(frequency, notchBandwidth, float32(outputRate))
+	}
+
+	panic(fmt.Sprintf("Unsupported Notch Mode: %s", mode))
+}
+
+func buildNoiseBlanker() *dsp.NoiseBlanker {
+	switch nbMode {
+	case nbModeBlank:
+		return dsp.MakeNoiseBlanker(nbThreshold, int(nbWidth), dsp.NoiseBlankerBlank)
+	case nbModeInterpolate:
+		return dsp.MakeNoiseBlanker(nbThreshold, int(nbWidth), dsp.NoiseBlankerInterpolate)
+	}
+
+	panic(fmt.Sprintf("Unsupported Noise Blanker Mode: %s", nbMode))
 }
 
 func buildDSP(sampleRate uint32) demodcore.DemodCore {
+	var demod demodcore.DemodCore
+
 	switch demodulatorMode {
 	case modeFM:
-		return buildFM(sampleRate)
+		demod = buildFM(sampleRate)
 	case modeAM:
-		return buildAM(sampleRate)
+		demod = buildAM(sampleRate)
+	case modePSK:
+		demod = buildPSK(sampleRate)
+	default:
+		panic(fmt.Sprintf("Unsupported Mode: %s", demodulatorMode))
+	}
+
+	demod.SetSquelchTiming(squelchHysteresis, squelchAttack, squelchHang)
+
+	if noiseBlanker {
+		demod.SetNoiseBlanker(buildNoiseBlanker())
+	}
+
+	if noiseReduction > 0 {
+		demod.SetNoiseReduction(dsp.MakeSpectralNoiseReduction(noiseReductionFrameSize, noiseReduction))
+	}
+
+	if audioAGC {
+		demod.SetAudioAGC(dsp.MakeAudioAGC(float32(outputRate), agcAttack, agcDecay, agcHang, agcTarget, agcMaxGain))
 	}
 
-	panic(fmt.Sprintf("Unsupported Mode: %s", demodulatorMode))
+	if volume != 0 {
+		demod.SetVolume(dsp.MakeAudioGain(volume))
+	}
+
+	if limiter {
+		demod.SetLimiter(dsp.MakeSoftLimiter(limiterThreshold))
+	}
+
+	return demod
+}
+
+func buildSpectrumEstimator() *dsp.SpectrumEstimator {
+	var windowType dsp.WindowType
+
+	switch fftWindow {
+	case fftWindowRectangular:
+		windowType = dsp.WindowRectangular
+	case fftWindowHamming:
+		windowType = dsp.WindowHamming
+	case fftWindowHann:
+		windowType = dsp.WindowHann
+	case fftWindowBlackmanHarris:
+		windowType = dsp.WindowBlackmanHarris
+	case fftWindowFlatTop:
+		windowType = dsp.WindowFlatTop
+	default:
+		panic(fmt.Sprintf("Unsupported FFT Window: %s", fftWindow))
+	}
+
+	var se = dsp.MakeSpectrumEstimator(int(fftSize), windowType, fftOverlap)
+
+	switch fftAveraging {
+	case fftAveragingExponential:
+		se.SetAveraging(dsp.SpectrumAveragingExponential)
+		se.SetAlpha(1 / float32(fftAverageFrames))
+	case fftAveragingLinear:
+		se.SetAveraging(dsp.SpectrumAveragingLinear)
+		se.SetAverageFrames(int(fftAverageFrames))
+	case fftAveragingPeak:
+		se.SetAveraging(dsp.SpectrumAveragingPeakHold)
+	case fftAveragingMin:
+		se.SetAveraging(dsp.SpectrumAveragingMinHold)
+	default:
+		panic(fmt.Sprintf("Unsupported FFT Averaging Mode: %s", fftAveraging))
+	}
+
+	switch fftBinMode {
+	case fftBinModeMax:
+		se.SetBinDecimation(dsp.BinDecimationMax)
+	case fftBinModeMean:
+		se.SetBinDecimation(dsp.BinDecimationMean)
+	default:
+		panic(fmt.Sprintf("Unsupported FFT Bin Mode: %s", fftBinMode))
+	}
+
+	return se
+}
+
+func buildCFARDetector() *dsp.CFARDetector {
+	var mode dsp.CFARMode
+
+	switch cfarMode {
+	case cfarModeCA:
+		mode = dsp.CFARCellAveraging
+	case cfarModeOS:
+		mode = dsp.CFAROrderedStatistic
+	default:
+		panic(fmt.Sprintf("Unsupported CFAR Mode: %s", cfarMode))
+	}
+
+	return dsp.MakeCFARDetector(mode, cfarGuardCells, cfarReferenceCells, cfarThreshold)
+}
+
+func buildSignalDetector() (*dsp.CFARDetector, *dsp.SignalTracker) {
+	return buildCFARDetector(), dsp.MakeSignalTracker(signalActivateFrames, signalHoldTime)
+}
+
+func buildBurstDetector(sampleRate uint32) *dsp.BurstDetector {
+	var minDuration = time.Duration(float64(burstMinDuration) * float64(time.Second))
+	var maxDuration = time.Duration(float64(burstMaxDuration) * float64(time.Second))
+
+	return dsp.MakeBurstDetector(float64(sampleRate), int(burstFFTSize), buildCFARDetector(), minDuration, maxDuration)
 }
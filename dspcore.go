@@ -1,74 +1,153 @@
 package main
 
 import (
-	"github.com/racerxdl/go.fifo"
+	"github.com/racerxdl/radioserver/client"
+	"github.com/racerxdl/radioserver/protocol"
 	"github.com/racerxdl/segdsp/demodcore"
-	"runtime"
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/flowgraph"
+	"log"
 	"time"
 )
 
-var samplesFifo *fifo.Queue
+const dspBufferSize = 1 << 20
+const dspMonitorInterval = 10 * time.Second
+
+// dspTimeTagInterval is how often the IQ samples are tagged with their reception time
+const dspTimeTagInterval = time.Second
+
+var dspGraph *flowgraph.Flowgraph
+var dspSource *flowgraph.ComplexSource
 var demodulator demodcore.DemodCore
-var running = false
-var buffer []complex64
-var delta = 0.0
-var count = 0
+var iqCorrector *dsp.IQCorrector
+
+// burstSource feeds the burst detector, that runs over the raw channel IQ in parallel to the demodulator
+var burstSource *flowgraph.ComplexSource
+var burstDetector *dsp.BurstDetector
 
 var dspCb func(interface{})
+var lastTimeTag time.Time
 
 func addComplex(data []complex64) {
-	samplesFifo.Add(data)
+	if dspSource != nil {
+		var now = time.Now()
+		if now.Sub(lastTimeTag) >= dspTimeTagInterval {
+			addSourceTag(flowgraph.TagTime, now)
+			lastTimeTag = now
+		}
+		dspSource.Write(data)
+		if burstSource != nil {
+			burstSource.Write(data)
+		}
+	}
 }
 
-func initDSP() {
-	samplesFifo = fifo.NewQueue()
+func addSourceTag(key string, value interface{}) {
+	dspSource.AddTag(key, value)
+	if burstSource != nil {
+		burstSource.AddTag(key, value)
+	}
 }
 
-func startDSP() {
-	if !running {
-		running = true
-		go dspLoop()
+// tuneChannel tunes the IQ channel to frequency. While the flowgraph runs the IQ samples from the next one on are
+// tagged with the new center frequency, so the blocks see the retune with the samples.
+func tuneChannel(rs *client.RadioClient, frequency uint32) bool {
+	if rs.SetCenterFrequency(frequency) == protocol.Invalid {
+		return false
 	}
-}
 
-func stopDSP() {
-	if running {
-		running = false
+	if dspGraph != nil && dspGraph.IsRunning() {
+		addSourceTag(flowgraph.TagFrequency, float64(rs.GetCenterFrequency()))
 	}
+
+	return true
 }
 
-func dspRun() {
+// initDSP builds the flowgraph: IQ source -> IQ correction (optional) -> demodulator -> dspCb,
+// and IQ source -> burst detector (optional)
+func initDSP() {
+	dspGraph = flowgraph.MakeFlowgraph(dspBufferSize)
+	dspSource = dspGraph.AddComplexSource("iq")
+	// Never block the radioserver client, if the DSP is not keeping up the samples are dropped
+	dspSource.SetOverflowMode(flowgraph.OverflowDrop)
 
-	length := samplesFifo.Len()
+	var input = dspSource.Port()
 
-	if length == 0 {
-		return
+	if iqCorrector != nil {
+		input = dspGraph.AddBlock("iq-correction", input, iqCorrector)
 	}
 
-	if demodulator == nil || dspCb == nil {
-		return
+	demodulator.Connect(dspGraph, input, func(data interface{}) {
+		if dspCb != nil {
+			dspCb(data)
+		}
+	})
+
+	if burstDetector != nil {
+		initBurstDetector()
 	}
+}
+
+func initBurstDetector() {
+	burstSource = dspGraph.AddComplexSource("burst-iq")
+	burstSource.SetOverflowMode(flowgraph.OverflowDrop)
+
+	var timeTracker = flowgraph.MakeTimeTracker(burstDetector.GetSampleRate())
 
-	buffer = samplesFifo.Next().([]complex64)
+	dspGraph.AddComplexTaggedSink("burst-detector", burstSource.Port(), func(data []complex64, offset uint64, tags []flowgraph.Tag) {
+		timeTracker.Update(tags)
 
-	var t0 = time.Now()
-	var out = demodulator.Work(buffer)
-	var d = time.Since(t0)
-	delta += d.Seconds()
-	count++
+		if tag := flowgraph.FindTag(tags, flowgraph.TagFrequency); tag != nil {
+			burstDetector.SetCenterFrequency(tag.Value.(float64))
+		}
 
-	if out != nil {
-		delta /= float64(count)
-		//log.Println("Delta: ", delta, "seconds")
-		delta = 0
-		count = 0
-		dspCb(out)
+		if t, ok := timeTracker.TimeAt(offset); ok {
+			burstDetector.SetTime(t)
+		}
+
+		burstDetector.Feed(data)
+	})
+}
+
+// startDSP starts the flowgraph, tagging the IQ samples with their center frequency
+func startDSP(centerFrequency uint32) {
+	if !dspGraph.IsRunning() {
+		dspGraph.Start()
+		lastTimeTag = time.Time{}
+		addSourceTag(flowgraph.TagFrequency, float64(centerFrequency))
+		go monitorDSP()
 	}
 }
 
-func dspLoop() {
-	for running {
-		dspRun()
-		runtime.Gosched()
+func stopDSP() {
+	dspGraph.Stop()
+
+	if burstDetector != nil {
+		burstDetector.Flush()
+	}
+
+	for _, s := range dspGraph.GetStats() {
+		if s.Input.Dropped > 0 || s.Input.Stalls > 0 {
+			log.Printf("DSP Block %s: %d samples dropped, %d stalls\n", s.Name, s.Input.Dropped, s.Input.Stalls)
+		}
+	}
+}
+
+func monitorDSP() {
+	var ticker = time.NewTicker(dspMonitorInterval)
+	defer ticker.Stop()
+
+	var lastDropped = uint64(0)
+
+	for range ticker.C {
+		if !dspGraph.IsRunning() {
+			return
+		}
+
+		var dropped = dspGraph.GetDropped()
+		if dropped > lastDropped {
+			log.Printf("DSP is not keeping up: %d samples dropped\n", dropped-lastDropped)
+			lastDropped = dropped
+		}
 	}
 }
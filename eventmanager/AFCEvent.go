@@ -0,0 +1,8 @@
+package eventmanager
+
+const EvAFCOffset = "afcOffsetEvent"
+
+type AFCEventData struct {
+	Offset      float32
+	PullInRange float32
+}
@@ -0,0 +1,15 @@
+package eventmanager
+
+import "time"
+
+const EvSignalOn = "signalOnEvent"
+const EvSignalOff = "signalOffEvent"
+
+type SignalEventData struct {
+	ID              uint64
+	CenterFrequency float64
+	Bandwidth       float64
+	SNR             float32
+	FirstSeen       time.Time
+	LastSeen        time.Time
+}
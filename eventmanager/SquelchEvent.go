@@ -3,7 +3,14 @@ package eventmanager
 const EvSquelchOn = "squelchOnEvent"
 const EvSquelchOff = "squelchOffEvent"
 
+// Squelch Criteria
+const (
+	SquelchCriterionPower = "power"
+	SquelchCriterionNoise = "noise"
+)
+
 type SquelchEventData struct {
 	Threshold float32
 	AvgValue  float32
+	Criterion string
 }
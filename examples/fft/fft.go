@@ -7,6 +7,7 @@ import (
 	"github.com/llgcode/draw2d/draw2dimg"
 	"github.com/racerxdl/segdsp/dsp"
 	"github.com/racerxdl/segdsp/dsp/fft"
+	"github.com/racerxdl/segdsp/dsp/sources"
 	"github.com/racerxdl/segdsp/tools"
 	"image"
 	"image/color"
@@ -14,7 +15,6 @@ import (
 	"io/ioutil"
 	"log"
 	"math"
-	"math/rand"
 	"os"
 )
 
@@ -70,20 +70,19 @@ func main() {
 	}, loadedFont)
 	// endregion
 	// region Generate IQ Sample
-	var samples = make([]complex64, 1024)
 	var interp = dsp.MakeInterpolator(20)
 	var lowPass = dsp.MakeLowPassFixed(1, sampleRate, sampleRate/2-5e3, 63)
 	var frequencyShift = dsp.MakeFrequencyTranslator(1, -100e3, sampleRate, lowPass)
 
-	for i := 0; i < len(samples); i++ {
-		samples[i] = complex((rand.Float32()-1)*0.5, 0)
-	}
+	var samples = sources.MakeBlockSource(sources.MakeWhiteNoise(0.25, 1), 1024).NextComplex()
 
 	samples = interp.Work(samples)
 
 	// Generate some background noise
+	var background = make([]complex64, len(samples))
+	sources.MakeWhiteNoise(1e-4, 2).Complex(background)
 	for i := 0; i < len(samples); i++ {
-		samples[i] += complex((rand.Float32()-1)*1e-4, 0)
+		samples[i] += background[i]
 	}
 
 	samples = frequencyShift.Work(samples)
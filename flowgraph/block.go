@@ -0,0 +1,259 @@
+package flowgraph
+
+import (
+	"fmt"
+
+	"github.com/racerxdl/segdsp/dsp"
+)
+
+// segmentData holds the samples flowing between the workers of a block
+type segmentData struct {
+	complexInput []complex64
+	floatInput   []float32
+	byteInput    []byte
+
+	complexData []complex64
+	floatData   []float32
+	byteData    []byte
+
+	// tags of the current samples, with offsets relative to the first sample
+	tags []Tag
+}
+
+// stage is a worker inside a block, with its own output buffer
+type stage interface {
+	// process runs the worker, returning the number of input and output samples
+	process(d *segmentData) (n, m int)
+}
+
+type complexStage struct {
+	worker dsp.ComplexWorker
+	output []complex64
+}
+
+func (s *complexStage) process(d *segmentData) (n, m int) {
+	n = len(d.complexData)
+	var size = s.worker.PredictOutputSize(n)
+	if len(s.output) < size {
+		s.output = make([]complex64, size)
+	}
+	m = s.worker.WorkBuffer(d.complexData, s.output[:size])
+	d.complexData = s.output[:m]
+	return n, m
+}
+
+type floatStage struct {
+	worker dsp.Float32Worker
+	output []float32
+}
+
+func (s *floatStage) process(d *segmentData) (n, m int) {
+	n = len(d.floatData)
+	var size = s.worker.PredictOutputSize(n)
+	if len(s.output) < size {
+		s.output = make([]float32, size)
+	}
+	m = s.worker.WorkBuffer(d.floatData, s.output[:size])
+	d.floatData = s.output[:m]
+	return n, m
+}
+
+type complex2FloatStage struct {
+	worker dsp.Complex2Float32Worker
+	output []float32
+}
+
+func (s *complex2FloatStage) process(d *segmentData) (n, m int) {
+	n = len(d.complexData)
+	var size = s.worker.PredictOutputSize(n)
+	if len(s.output) < size {
+		s.output = make([]float32, size)
+	}
+	m = s.worker.WorkBuffer(d.complexData, s.output[:size])
+	d.floatData = s.output[:m]
+	return n, m
+}
+
+type float2ComplexStage struct {
+	worker dsp.Float322ComplexWorker
+	output []complex64
+}
+
+func (s *float2ComplexStage) process(d *segmentData) (n, m int) {
+	n = len(d.floatData)
+	var size = s.worker.PredictOutputSize(n)
+	if len(s.output) < size {
+		s.output = make([]complex64, size)
+	}
+	m = s.worker.WorkBuffer(d.floatData, s.output[:size])
+	d.complexData = s.output[:m]
+	return n, m
+}
+
+type float2ByteStage struct {
+	worker dsp.Float322ByteWorker
+	output []byte
+}
+
+func (s *float2ByteStage) process(d *segmentData) (n, m int) {
+	n = len(d.floatData)
+	var size = s.worker.PredictOutputSize(n)
+	if len(s.output) < size {
+		s.output = make([]byte, size)
+	}
+	m = s.worker.WorkBuffer(d.floatData, s.output[:size])
+	d.byteData = s.output[:m]
+	return n, m
+}
+
+type byteStage struct {
+	worker dsp.ByteWorker
+	output []byte
+}
+
+func (s *byteStage) process(d *segmentData) (n, m int) {
+	n = len(d.byteData)
+	var size = s.worker.PredictOutputSize(n)
+	if len(s.output) < size {
+		s.output = make([]byte, size)
+	}
+	m = s.worker.WorkBuffer(d.byteData, s.output[:size])
+	d.byteData = s.output[:m]
+	return n, m
+}
+
+type byte2ComplexStage struct {
+	worker dsp.Byte2ComplexWorker
+	output []complex64
+}
+
+func (s *byte2ComplexStage) process(d *segmentData) (n, m int) {
+	n = len(d.byteData)
+	var size = s.worker.PredictOutputSize(n)
+	if len(s.output) < size {
+		s.output = make([]complex64, size)
+	}
+	m = s.worker.WorkBuffer(d.byteData, s.output[:size])
+	d.complexData = s.output[:m]
+	return n, m
+}
+
+type complex2ByteStage struct {
+	worker dsp.Complex2ByteWorker
+	output []byte
+}
+
+func (s *complex2ByteStage) process(d *segmentData) (n, m int) {
+	n = len(d.complexData)
+	var size = s.worker.PredictOutputSize(n)
+	if len(s.output) < size {
+		s.output = make([]byte, size)
+	}
+	m = s.worker.WorkBuffer(d.complexData, s.output[:size])
+	d.byteData = s.output[:m]
+	return n, m
+}
+
+// makeStage wraps the worker in a stage, returning the stage output type.
+// It panics if the worker does not take inputType samples.
+func makeStage(worker interface{}, inputType DataType) (stage, DataType) {
+	switch inputType {
+	case ComplexData:
+		switch w := worker.(type) {
+		case dsp.ComplexWorker:
+			return &complexStage{worker: w}, ComplexData
+		case dsp.Complex2Float32Worker:
+			return &complex2FloatStage{worker: w}, FloatData
+		case dsp.Complex2ByteWorker:
+			return &complex2ByteStage{worker: w}, ByteData
+		}
+	case FloatData:
+		switch w := worker.(type) {
+		case dsp.Float32Worker:
+			return &floatStage{worker: w}, FloatData
+		case dsp.Float322ComplexWorker:
+			return &float2ComplexStage{worker: w}, ComplexData
+		case dsp.Float322ByteWorker:
+			return &float2ByteStage{worker: w}, ByteData
+		}
+	case ByteData:
+		switch w := worker.(type) {
+		case dsp.ByteWorker:
+			return &byteStage{worker: w}, ByteData
+		case dsp.Byte2ComplexWorker:
+			return &byte2ComplexStage{worker: w}, ComplexData
+		}
+	}
+
+	panic(fmt.Sprintf("Worker %T does not take %s input", worker, inputType))
+}
+
+// block runs a segment of workers in a single goroutine
+type block struct {
+	name      string
+	chunkSize int
+	input     *Port
+	output    *Port
+	stages    []stage
+	tags      []stageTags
+	data      segmentData
+}
+
+// AddBlock adds a block that runs the workers (in order) over the input samples and returns its output port.
+// Workers can be dsp.ComplexWorker, dsp.Float32Worker, dsp.Complex2Float32Worker, dsp.Float322ComplexWorker,
+// dsp.Float322ByteWorker, dsp.ByteWorker, dsp.Byte2ComplexWorker or dsp.Complex2ByteWorker, as long as each one takes the output
+// type of the previous one.
+// All workers of a block run in the same goroutine, so a block can also be used to group cheap workers in a segment.
+func (g *Flowgraph) AddBlock(name string, input *Port, workers ...interface{}) *Port {
+	if len(workers) == 0 {
+		panic("A block needs at least one worker")
+	}
+
+	var b = &block{
+		name:      name,
+		chunkSize: g.chunkSize,
+		input:     input,
+		stages:    make([]stage, len(workers)),
+		tags:      make([]stageTags, len(workers)),
+	}
+
+	var dataType = input.GetType()
+	for i, w := range workers {
+		b.stages[i], dataType = makeStage(w, dataType)
+		b.tags[i].mapper, _ = w.(dsp.TagOffsetMapper)
+	}
+
+	g.checkPort(input)
+	b.output = g.makePort(name, dataType)
+	g.addNode(b)
+
+	return b.output
+}
+
+func (b *block) getName() string {
+	return b.name
+}
+
+func (b *block) inputStats() *BufferStats {
+	var stats = b.input.GetStats()
+	return &stats
+}
+
+func (b *block) reset() {
+	b.output.reset()
+	for i := range b.tags {
+		b.tags[i].reset()
+	}
+}
+
+func (b *block) run() {
+	defer b.output.close()
+
+	for b.input.read(&b.data, b.chunkSize) > 0 {
+		for i, s := range b.stages {
+			var n, m = s.process(&b.data)
+			b.data.tags = b.tags[i].move(b.data.tags, n, m)
+		}
+		b.output.write(&b.data)
+	}
+}
@@ -0,0 +1,189 @@
+package flowgraph
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultBufferSize is the default capacity (in samples) of the buffers between blocks
+const DefaultBufferSize = 1 << 16
+
+// DefaultChunkSize is the default maximum number of samples a block processes per call
+const DefaultChunkSize = 8192
+
+// Flowgraph connects dsp workers into a graph. Each block (a segment of one or more workers) runs in its own goroutine,
+// and the blocks are connected by bounded ring buffers. A full buffer blocks its writer (backpressure), so the graph
+// runs at the speed of its slowest block. Sources can be set to drop samples instead, accounting them as dropped.
+type Flowgraph struct {
+	bufferSize int
+	chunkSize  int
+	nodes      []node
+	ports      []*Port
+	wg         sync.WaitGroup
+	lock       sync.Mutex
+	running    bool
+}
+
+// node is a flowgraph element that runs in its own goroutine
+type node interface {
+	getName() string
+	// run processes until the input is closed and drained, then closes the output
+	run()
+	// inputStats returns the stats of the input buffer (if any)
+	inputStats() *BufferStats
+	// reset reopens the node output buffer to start again
+	reset()
+}
+
+// BlockStats are the input buffer counters of a block
+type BlockStats struct {
+	Name  string
+	Input BufferStats
+}
+
+// MakeFlowgraph creates a flowgraph with buffers of bufferSize samples
+func MakeFlowgraph(bufferSize int) *Flowgraph {
+	if bufferSize < 1 {
+		panic("Flowgraph buffer size should be at least 1")
+	}
+
+	return &Flowgraph{
+		bufferSize: bufferSize,
+		chunkSize:  DefaultChunkSize,
+	}
+}
+
+// SetChunkSize sets the maximum number of samples read by a block on each call
+func (g *Flowgraph) SetChunkSize(chunkSize int) {
+	if chunkSize < 1 {
+		panic("Flowgraph chunk size should be at least 1")
+	}
+	g.chunkSize = chunkSize
+}
+
+func (g *Flowgraph) GetChunkSize() int {
+	return g.chunkSize
+}
+
+func (g *Flowgraph) GetBufferSize() int {
+	return g.bufferSize
+}
+
+func (g *Flowgraph) addNode(n node) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.running {
+		panic("Cannot add blocks to a running flowgraph")
+	}
+
+	g.nodes = append(g.nodes, n)
+}
+
+// IsRunning returns if the flowgraph was started and not stopped yet
+func (g *Flowgraph) IsRunning() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.running
+}
+
+// Start starts all blocks. A stopped flowgraph can be started again.
+func (g *Flowgraph) Start() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.running {
+		return
+	}
+
+	for _, p := range g.ports {
+		if !p.connected {
+			// Nobody would read it, so the block writing to it would stall when it gets full
+			panic(fmt.Sprintf("Port %s is not connected", p.name))
+		}
+	}
+
+	for _, n := range g.nodes {
+		n.reset()
+	}
+
+	g.running = true
+
+	for _, n := range g.nodes {
+		g.wg.Add(1)
+		go func(n node) {
+			defer g.wg.Done()
+			n.run()
+		}(n)
+	}
+}
+
+// Stop closes the sources and waits for every block to process the samples already in the graph and exit
+func (g *Flowgraph) Stop() {
+	g.lock.Lock()
+
+	if !g.running {
+		g.lock.Unlock()
+		return
+	}
+
+	for _, n := range g.nodes {
+		if s, ok := n.(source); ok {
+			s.close()
+		}
+	}
+
+	g.lock.Unlock()
+	g.wg.Wait()
+
+	g.lock.Lock()
+	g.running = false
+	g.lock.Unlock()
+}
+
+// GetStats returns the input buffer counters of every block
+func (g *Flowgraph) GetStats() []BlockStats {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	var stats = make([]BlockStats, 0, len(g.nodes))
+
+	for _, n := range g.nodes {
+		var s = n.inputStats()
+		if s != nil {
+			stats = append(stats, BlockStats{
+				Name:  n.getName(),
+				Input: *s,
+			})
+		}
+	}
+
+	return stats
+}
+
+// GetDropped returns the total number of samples dropped by all buffers of the graph
+func (g *Flowgraph) GetDropped() uint64 {
+	var dropped = uint64(0)
+
+	for _, s := range g.GetStats() {
+		dropped += s.Input.Dropped
+	}
+
+	return dropped
+}
+
+func (g *Flowgraph) checkPort(p *Port) {
+	if p == nil {
+		panic("Flowgraph input port is nil")
+	}
+
+	if p.graph != g {
+		panic("Port belongs to another flowgraph")
+	}
+
+	if p.connected {
+		panic(fmt.Sprintf("Port %s is already connected", p.name))
+	}
+
+	p.connected = true
+}
@@ -0,0 +1,470 @@
+package flowgraph
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/dsp/digital/framing"
+)
+
+func TestRingBufferWrap(t *testing.T) {
+	var b = MakeFloatRingBuffer(8)
+	var output = make([]float32, 8)
+	var next = float32(0)
+	var expected = float32(0)
+
+	for i := 0; i < 10; i++ {
+		var input = make([]float32, 5)
+		for j := range input {
+			input[j] = next
+			next++
+		}
+
+		if n := b.Write(input); n != 5 {
+			t.Fatalf("Expected 5 samples written got %d", n)
+		}
+
+		var n = b.Read(output)
+		for _, v := range output[:n] {
+			if v != expected {
+				t.Fatalf("Expected %f got %f", expected, v)
+			}
+			expected++
+		}
+	}
+
+	if b.Len() != 0 {
+		t.Fatalf("Expected empty buffer, got %d samples", b.Len())
+	}
+}
+
+func TestRingBufferOverflow(t *testing.T) {
+	var b = MakeComplexRingBuffer(16)
+	b.SetOverflowMode(OverflowDrop)
+
+	if n := b.Write(make([]complex64, 10)); n != 10 {
+		t.Fatalf("Expected 10 samples written got %d", n)
+	}
+
+	if n := b.Write(make([]complex64, 10)); n != 6 {
+		t.Fatalf("Expected 6 samples written got %d", n)
+	}
+
+	var stats = b.GetStats()
+	if stats.Dropped != 4 || stats.Used != 16 || stats.Written != 16 {
+		t.Fatalf("Unexpected stats %+v", stats)
+	}
+
+	// Blocking writer should wait for the reader
+	b.SetOverflowMode(OverflowBlock)
+	var done = make(chan int)
+	go func() {
+		done <- b.Write(make([]complex64, 4))
+	}()
+
+	for b.GetStats().Stalls == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	b.Read(make([]complex64, 8))
+
+	if n := <-done; n != 4 {
+		t.Fatalf("Expected 4 samples written got %d", n)
+	}
+
+	b.Close()
+	if n := b.Read(make([]complex64, 32)); n != 12 {
+		t.Fatalf("Expected the 12 pending samples after close, got %d", n)
+	}
+	if n := b.Read(make([]complex64, 32)); n != 0 {
+		t.Fatalf("Expected 0 samples from closed buffer, got %d", n)
+	}
+}
+
+func makeTestChain() []interface{} {
+	var gain = ComplexFunc(func(input []complex64) []complex64 {
+		var output = make([]complex64, len(input))
+		for i, v := range input {
+			output[i] = v * 2
+		}
+		return output
+	})
+
+	return []interface{}{
+		gain,
+		dsp.MakeDecimationFirFilter(2, dsp.MakeLowPassFixed(1, 1, 0.2, 31)),
+		dsp.MakeComplex2Magnitude(),
+		dsp.MakeFloatFirFilter(dsp.MakeLowPassFixed(1, 1, 0.1, 15)),
+	}
+}
+
+func makeTestSignal(length int) []complex64 {
+	var signal = make([]complex64, length)
+	for i := range signal {
+		var phase = 0.01 * float64(i*i%10007)
+		signal[i] = complex(float32(math.Cos(phase)), float32(math.Sin(phase)))
+	}
+	return signal
+}
+
+func TestFlowgraph(t *testing.T) {
+	const length = 100000
+	var signal = makeTestSignal(length)
+
+	// Reference, running the chain by hand
+	var reference = signal
+	var chain = makeTestChain()
+	reference = chain[0].(dsp.ComplexWorker).Work(reference)
+	reference = chain[1].(dsp.ComplexWorker).Work(reference)
+	var referenceFloat = chain[2].(dsp.Complex2Float32Worker).Work(reference)
+	referenceFloat = chain[3].(dsp.Float32Worker).Work(referenceFloat)
+
+	chain = makeTestChain()
+	var g = MakeFlowgraph(1024)
+	g.SetChunkSize(333)
+	var src = g.AddComplexSource("source")
+	var channel = g.AddBlock("channel", src.Port(), chain[0], chain[1])
+	var audio = g.AddBlock("audio", channel, chain[2], chain[3])
+
+	var output []float32
+	g.AddFloatSink("sink", audio, func(data []float32) {
+		output = append(output, data...)
+	})
+
+	g.Start()
+	for i := 0; i < length; i += 1000 {
+		src.Write(signal[i : i+1000])
+	}
+	g.Stop()
+
+	if g.IsRunning() {
+		t.Fatal("Flowgraph should be stopped")
+	}
+
+	if math.Abs(float64(len(output)-length/2)) > 16 {
+		t.Fatalf("Expected about %d samples got %d", length/2, len(output))
+	}
+
+	for i := 0; i < dsp.Min(len(output), len(referenceFloat)); i++ {
+		if math.Abs(float64(output[i]-referenceFloat[i])) > 1e-4 {
+			t.Fatalf("Sample %d: Expected %f got %f", i, referenceFloat[i], output[i])
+		}
+	}
+
+	for _, s := range g.GetStats() {
+		if s.Input.Dropped != 0 {
+			t.Errorf("Block %s dropped %d samples", s.Name, s.Input.Dropped)
+		}
+		if s.Input.Used != 0 {
+			t.Errorf("Block %s has %d samples left after stop", s.Name, s.Input.Used)
+		}
+	}
+
+	// Should be able to run again
+	var count = len(output)
+	g.Start()
+	src.Write(signal[:10000])
+	g.Stop()
+
+	if len(output) <= count {
+		t.Fatal("Flowgraph did not process samples after restart")
+	}
+}
+
+func TestFlowgraphChecks(t *testing.T) {
+	var expectPanic = func(name string, f func()) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected panic", name)
+			}
+		}()
+		f()
+	}
+
+	expectPanic("type mismatch", func() {
+		var g = MakeFlowgraph(1024)
+		var src = g.AddComplexSource("source")
+		g.AddBlock("block", src.Port(), dsp.MakeFloatFirFilter([]float32{1}))
+	})
+
+	expectPanic("double connection", func() {
+		var g = MakeFlowgraph(1024)
+		var src = g.AddComplexSource("source")
+		g.AddComplexSink("a", src.Port(), func([]complex64) {})
+		g.AddComplexSink("b", src.Port(), func([]complex64) {})
+	})
+
+	expectPanic("unconnected port", func() {
+		var g = MakeFlowgraph(1024)
+		var src = g.AddComplexSource("source")
+		g.AddBlock("block", src.Port(), dsp.MakeComplex2Magnitude())
+		g.Start()
+	})
+}
+
+func TestFlowgraphTags(t *testing.T) {
+	const length = 4096
+	const decimation = 4
+	const tagOffset = 2048
+
+	var start = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	var input = makeTestSignal(length)
+	var taps = dsp.MakeLowPass(1, 1, 0.1, 0.05)
+
+	var g = MakeFlowgraph(1 << 12)
+	g.SetChunkSize(1000)
+
+	var source = g.AddComplexSource("source")
+	var output = g.AddBlock("decimator", source.Port(), dsp.MakeDecimationFirFilter(decimation, taps))
+
+	var tags []Tag
+	var samples = 0
+	g.AddComplexTaggedSink("sink", output, func(data []complex64, offset uint64, chunkTags []Tag) {
+		if offset != uint64(samples) {
+			t.Errorf("Expected chunk offset %d got %d", samples, offset)
+		}
+		samples += len(data)
+		tags = append(tags, chunkTags...)
+	})
+
+	g.Start()
+	source.AddTag(TagTime, start)
+	source.Write(input[:tagOffset])
+	if source.GetOffset() != tagOffset {
+		t.Fatalf("Expected source offset %d got %d", tagOffset, source.GetOffset())
+	}
+	source.AddTag(TagFrequency, 106.3e6)
+	source.Write(input[tagOffset:])
+	g.Stop()
+
+	if len(tags) != 2 {
+		t.Fatalf("Expected 2 tags got %d", len(tags))
+	}
+
+	// The tags come out with their samples, after the filter delay
+	var delay = len(taps) - (len(taps)-1)/2
+	var timeTag = FindTag(tags, TagTime)
+	if timeTag == nil || timeTag.Offset != uint64((delay+decimation-1)/decimation) || timeTag.Value.(time.Time) != start {
+		t.Fatalf("Bad time tag: %+v", timeTag)
+	}
+
+	var frequencyTag = FindTag(tags, TagFrequency)
+	if frequencyTag == nil || frequencyTag.Value.(float64) != 106.3e6 {
+		t.Fatalf("Bad frequency tag: %+v", frequencyTag)
+	}
+
+	var expected = uint64((tagOffset + delay + decimation - 1) / decimation)
+	if frequencyTag.Offset != expected {
+		t.Fatalf("Expected frequency tag at offset %d got %d", expected, frequencyTag.Offset)
+	}
+
+	var tracker = MakeTimeTracker(1000)
+	if _, ok := tracker.TimeAt(0); ok {
+		t.Fatal("Expected no time before the first time tag")
+	}
+
+	tracker.Update(tags)
+	var timestamp, ok = tracker.TimeAt(timeTag.Offset + 1500)
+	if !ok || !timestamp.Equal(start.Add(1500*time.Millisecond)) {
+		t.Fatalf("Expected %s got %s", start.Add(1500*time.Millisecond), timestamp)
+	}
+}
+
+// writeImpulses writes length zero samples with an impulse tagged at each offset, calling write with the number of
+// samples of each segment and if it starts with an impulse
+func writeImpulses(length int, offsets []int, write func(samples int, impulse bool), tag func(offset int)) {
+	var from = 0
+	for _, offset := range offsets {
+		write(offset-from, from > 0)
+		tag(offset)
+		from = offset
+	}
+	write(length-from, true)
+}
+
+// checkImpulseTags checks that each tag is at the peak of its impulse in the output magnitudes
+func checkImpulseTags(t *testing.T, name string, magnitudes []float32, tags []Tag, count int) {
+	if len(tags) != count {
+		t.Fatalf("%s: expected %d tags got %d", name, count, len(tags))
+	}
+
+	for _, tag := range tags {
+		var offset = int(tag.Offset)
+		var peak = offset
+		for i := offset - 8; i <= offset+8; i++ {
+			if i >= 0 && i < len(magnitudes) && magnitudes[i] > magnitudes[peak] {
+				peak = i
+			}
+		}
+
+		// The tag goes to the first sample after the impulse, so it can be one sample after the peak
+		if peak != offset && peak != offset-1 {
+			t.Errorf("%s: tag %v at offset %d but its impulse peaks at %d", name, tag.Value, offset, peak)
+		}
+	}
+}
+
+func TestFlowgraphTagsFollowSamples(t *testing.T) {
+	const length = 8192
+	// Impulses at the start, middle and end of the 1000 samples chunks
+	var offsets = []int{1000, 2500, 2995, 6001}
+
+	var g = MakeFlowgraph(1 << 14)
+	g.SetChunkSize(1000)
+
+	var complexSource = g.AddComplexSource("complex")
+	var channel = g.AddBlock("channel", complexSource.Port(),
+		dsp.MakeFrequencyTranslator(4, 0, 1, dsp.MakeLowPassFixed(1, 1, 0.1, 127)),
+		dsp.MakeDecimationFirFilter(2, dsp.MakeLowPassFixed(1, 1, 0.2, 63)),
+	)
+
+	var complexOutput []float32
+	var complexTags []Tag
+	g.AddComplexTaggedSink("complex-sink", channel, func(data []complex64, offset uint64, tags []Tag) {
+		for _, v := range data {
+			complexOutput = append(complexOutput, float32(math.Hypot(float64(real(v)), float64(imag(v)))))
+		}
+		complexTags = append(complexTags, tags...)
+	})
+
+	var floatSource = g.AddFloatSource("float")
+	var audio = g.AddBlock("audio", floatSource.Port(), dsp.MakeFloatResampler(32, 0.75))
+
+	var floatOutput []float32
+	var floatTags []Tag
+	g.AddFloatTaggedSink("float-sink", audio, func(data []float32, offset uint64, tags []Tag) {
+		floatOutput = append(floatOutput, data...)
+		floatTags = append(floatTags, tags...)
+	})
+
+	g.Start()
+	writeImpulses(length, offsets, func(samples int, impulse bool) {
+		var data = make([]complex64, samples)
+		if impulse {
+			data[0] = 1
+		}
+		complexSource.Write(data)
+	}, func(offset int) {
+		complexSource.AddTag("impulse", offset)
+	})
+	writeImpulses(length, offsets, func(samples int, impulse bool) {
+		var data = make([]float32, samples)
+		if impulse {
+			data[0] = 1
+		}
+		floatSource.Write(data)
+	}, func(offset int) {
+		floatSource.AddTag("impulse", offset)
+	})
+	g.Stop()
+
+	checkImpulseTags(t, "channel", complexOutput, complexTags, len(offsets))
+
+	for i := range floatOutput {
+		floatOutput[i] = float32(math.Abs(float64(floatOutput[i])))
+	}
+	checkImpulseTags(t, "audio", floatOutput, floatTags, len(offsets))
+}
+
+func TestRescaleTags(t *testing.T) {
+	var tags = []Tag{{Offset: 0}, {Offset: 5}, {Offset: 9}}
+
+	rescaleTags(tags, 10, 5)
+	var expected = []uint64{0, 2, 4}
+	for i, tag := range tags {
+		if tag.Offset != expected[i] {
+			t.Fatalf("Tag %d: expected offset %d got %d", i, expected[i], tag.Offset)
+		}
+	}
+
+	rescaleTags(tags, 5, 20)
+	expected = []uint64{0, 8, 16}
+	for i, tag := range tags {
+		if tag.Offset != expected[i] {
+			t.Fatalf("Tag %d: expected offset %d got %d", i, expected[i], tag.Offset)
+		}
+	}
+
+	rescaleTags(tags, 20, 0)
+	for i, tag := range tags {
+		if tag.Offset != 0 {
+			t.Fatalf("Tag %d: expected offset 0 got %d", i, tag.Offset)
+		}
+	}
+}
+
+func TestFlowgraphByteSamples(t *testing.T) {
+	var input = make([]byte, 10000)
+	for i := range input {
+		input[i] = byte(i * 7)
+	}
+
+	var g = MakeFlowgraph(1 << 12)
+	g.SetChunkSize(999)
+
+	var source = g.AddByteSource("source")
+	var output = g.AddBlock("convert", source.Port(),
+		dsp.MakeByteToComplex(dsp.SampleFormatCS16),
+		dsp.MakeComplexToByte(dsp.SampleFormatCS16),
+	)
+
+	var got []byte
+	g.AddByteSink("sink", output, func(data []byte) {
+		got = append(got, data...)
+	})
+
+	g.Start()
+	for i := 0; i < len(input); i += 333 {
+		source.Write(input[i:dsp.Min(i+333, len(input))])
+	}
+	g.Stop()
+
+	if len(got) != len(input) {
+		t.Fatalf("Expected %d bytes got %d", len(input), len(got))
+	}
+
+	for i := range input {
+		if input[i] != got[i] {
+			t.Fatalf("Expected %d got %d at %d", input[i], got[i], i)
+		}
+	}
+}
+
+func TestFlowgraphByteWorkers(t *testing.T) {
+	var input = make([]byte, 10000)
+	for i := range input {
+		input[i] = byte((i * 7 / 3) & 1)
+	}
+
+	var g = MakeFlowgraph(1 << 12)
+	g.SetChunkSize(999)
+
+	var source = g.AddByteSource("source")
+	var output = g.AddBlock("nrzi", source.Port(),
+		framing.MakeNRZIEncoder(framing.NRZISpace),
+		framing.MakeNRZIDecoder(framing.NRZISpace),
+	)
+
+	var got []byte
+	g.AddByteSink("sink", output, func(data []byte) {
+		got = append(got, data...)
+	})
+
+	g.Start()
+	for i := 0; i < len(input); i += 333 {
+		source.Write(input[i:dsp.Min(i+333, len(input))])
+	}
+	g.Stop()
+
+	if len(got) != len(input) {
+		t.Fatalf("Expected %d bits got %d", len(input), len(got))
+	}
+
+	for i := range input {
+		if input[i] != got[i] {
+			t.Fatalf("Expected %d got %d at %d", input[i], got[i], i)
+		}
+	}
+}
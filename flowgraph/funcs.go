@@ -0,0 +1,41 @@
+package flowgraph
+
+// ComplexFunc adapts a function to a dsp.ComplexWorker, so any processing (like measurements or taps) can be
+// part of a block. The function should not return more samples than its input.
+type ComplexFunc func(input []complex64) []complex64
+
+func (f ComplexFunc) Work(input []complex64) []complex64 {
+	return f(input)
+}
+
+func (f ComplexFunc) WorkBuffer(input, output []complex64) int {
+	var result = f(input)
+	if len(output) < len(result) {
+		panic("There is not enough space in output buffer")
+	}
+	return copy(output, result)
+}
+
+func (f ComplexFunc) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
+
+// FloatFunc adapts a function to a dsp.Float32Worker, so any processing (like measurements or taps) can be
+// part of a block. The function should not return more samples than its input.
+type FloatFunc func(input []float32) []float32
+
+func (f FloatFunc) Work(input []float32) []float32 {
+	return f(input)
+}
+
+func (f FloatFunc) WorkBuffer(input, output []float32) int {
+	var result = f(input)
+	if len(output) < len(result) {
+		panic("There is not enough space in output buffer")
+	}
+	return copy(output, result)
+}
+
+func (f FloatFunc) PredictOutputSize(inputLength int) int {
+	return inputLength
+}
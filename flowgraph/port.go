@@ -0,0 +1,162 @@
+package flowgraph
+
+import "fmt"
+
+// DataType is the sample type carried by a Port
+type DataType int
+
+const (
+	ComplexData DataType = iota
+	FloatData
+	ByteData
+)
+
+func (t DataType) String() string {
+	switch t {
+	case ComplexData:
+		return "complex64"
+	case FloatData:
+		return "float32"
+	case ByteData:
+		return "byte"
+	}
+
+	return fmt.Sprintf("DataType(%d)", int(t))
+}
+
+// Port is the output of a flowgraph block. It holds the ring buffer to the (single) block connected to it.
+type Port struct {
+	graph     *Flowgraph
+	name      string
+	dataType  DataType
+	connected bool
+
+	complexBuffer *ComplexRingBuffer
+	floatBuffer   *FloatRingBuffer
+	byteBuffer    *ByteRingBuffer
+}
+
+func (g *Flowgraph) makePort(name string, dataType DataType) *Port {
+	var p = &Port{
+		graph:    g,
+		name:     name,
+		dataType: dataType,
+	}
+
+	switch dataType {
+	case ComplexData:
+		p.complexBuffer = MakeComplexRingBuffer(g.bufferSize)
+	case FloatData:
+		p.floatBuffer = MakeFloatRingBuffer(g.bufferSize)
+	case ByteData:
+		p.byteBuffer = MakeByteRingBuffer(g.bufferSize)
+	default:
+		panic(fmt.Sprintf("Unsupported data type: %s", dataType))
+	}
+
+	g.ports = append(g.ports, p)
+
+	return p
+}
+
+// GetName returns the name of the block that owns the port
+func (p *Port) GetName() string {
+	return p.name
+}
+
+// GetType returns the sample type of the port
+func (p *Port) GetType() DataType {
+	return p.dataType
+}
+
+// IsConnected returns if a block is connected to the port
+func (p *Port) IsConnected() bool {
+	return p.connected
+}
+
+func (p *Port) buffer() *ringState {
+	switch p.dataType {
+	case ComplexData:
+		return &p.complexBuffer.ringState
+	case FloatData:
+		return &p.floatBuffer.ringState
+	}
+
+	return &p.byteBuffer.ringState
+}
+
+// GetStats returns the port buffer counters
+func (p *Port) GetStats() BufferStats {
+	return p.buffer().GetStats()
+}
+
+func (p *Port) close() {
+	p.buffer().Close()
+}
+
+func (p *Port) reset() {
+	p.buffer().Reset()
+}
+
+// read reads up to chunkSize samples into the data input, returning 0 when the port is closed and drained
+func (p *Port) read(d *segmentData, chunkSize int) int {
+	var n int
+
+	switch p.dataType {
+	case ComplexData:
+		if len(d.complexInput) < chunkSize {
+			d.complexInput = make([]complex64, chunkSize)
+		}
+		n = p.complexBuffer.Read(d.complexInput[:chunkSize])
+		d.complexData = d.complexInput[:n]
+	case FloatData:
+		if len(d.floatInput) < chunkSize {
+			d.floatInput = make([]float32, chunkSize)
+		}
+		n = p.floatBuffer.Read(d.floatInput[:chunkSize])
+		d.floatData = d.floatInput[:n]
+	case ByteData:
+		if len(d.byteInput) < chunkSize {
+			d.byteInput = make([]byte, chunkSize)
+		}
+		n = p.byteBuffer.Read(d.byteInput[:chunkSize])
+		d.byteData = d.byteInput[:n]
+	}
+
+	var buffer = p.buffer()
+	var start = buffer.ReadOffset() - uint64(n)
+
+	d.tags = buffer.TakeTags(d.tags[:0])
+	for i := range d.tags {
+		if d.tags[i].Offset < start {
+			// Tag of a sample dropped before the buffer was read
+			d.tags[i].Offset = start
+		}
+		d.tags[i].Offset -= start
+	}
+
+	return n
+}
+
+// write writes the data of the port type and its tags
+func (p *Port) write(d *segmentData) {
+	if len(d.tags) > 0 {
+		var buffer = p.buffer()
+		var start = buffer.WriteOffset()
+
+		for i := range d.tags {
+			d.tags[i].Offset += start
+		}
+
+		buffer.AddTags(d.tags...)
+	}
+
+	switch p.dataType {
+	case ComplexData:
+		p.complexBuffer.Write(d.complexData)
+	case FloatData:
+		p.floatBuffer.Write(d.floatData)
+	case ByteData:
+		p.byteBuffer.Write(d.byteData)
+	}
+}
@@ -0,0 +1,351 @@
+package flowgraph
+
+import (
+	"sync"
+
+	"github.com/racerxdl/segdsp/dsp"
+)
+
+// OverflowMode is what a Ring Buffer does when it is written while full
+type OverflowMode int
+
+const (
+	// OverflowBlock blocks the writer until there is space (backpressure)
+	OverflowBlock OverflowMode = iota
+	// OverflowDrop drops the samples that does not fit, and accounts them as dropped
+	OverflowDrop
+)
+
+// BufferStats are the counters of a Ring Buffer
+type BufferStats struct {
+	Capacity int
+	Used     int
+	Written  uint64
+	Read     uint64
+	// Dropped is the number of samples dropped by a full buffer in OverflowDrop mode
+	Dropped uint64
+	// Stalls is the number of times a writer had to wait for space in OverflowBlock mode
+	Stalls uint64
+}
+
+// ringState holds the positions and synchronization of a bounded single producer / single consumer ring buffer.
+// The typed ring buffers only hold the storage and do the copies, through write and read.
+type ringState struct {
+	lock     sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	capacity int
+	start    int
+	length   int
+	closed   bool
+	mode     OverflowMode
+	stats    BufferStats
+	tags     []Tag
+}
+
+func (r *ringState) init(capacity int) {
+	if capacity < 1 {
+		panic("Ring Buffer capacity should be at least 1")
+	}
+
+	r.capacity = capacity
+	r.notEmpty = sync.NewCond(&r.lock)
+	r.notFull = sync.NewCond(&r.lock)
+}
+
+// waitWrite waits for space to write up to n samples and returns the position and contiguous count to write.
+// count is 0 when the buffer is closed. Should be called with the lock held.
+func (r *ringState) waitWrite(n int) (pos, count int) {
+	if r.length == r.capacity && !r.closed {
+		if r.mode == OverflowDrop {
+			return 0, 0
+		}
+
+		r.stats.Stalls++
+		for r.length == r.capacity && !r.closed {
+			r.notFull.Wait()
+		}
+	}
+
+	if r.closed {
+		return 0, 0
+	}
+
+	pos = (r.start + r.length) % r.capacity
+	count = dsp.Min(n, r.capacity-r.length)
+	count = dsp.Min(count, r.capacity-pos)
+
+	return pos, count
+}
+
+func (r *ringState) commitWrite(count int) {
+	r.length += count
+	r.stats.Written += uint64(count)
+	r.notEmpty.Signal()
+}
+
+// waitRead waits for samples to read and returns the position and contiguous count to read (up to n).
+// count is 0 when the buffer is closed and empty. Should be called with the lock held.
+func (r *ringState) waitRead(n int) (pos, count int) {
+	for r.length == 0 && !r.closed {
+		r.notEmpty.Wait()
+	}
+
+	return r.start, dsp.Min(dsp.Min(n, r.length), r.capacity-r.start)
+}
+
+func (r *ringState) commitRead(count int) {
+	r.start = (r.start + count) % r.capacity
+	r.length -= count
+	r.stats.Read += uint64(count)
+	r.notFull.Signal()
+}
+
+// write writes n samples, calling copyIn to copy count samples starting at the sample from to the storage at pos.
+// It returns how many were written, accounting the ones that does not fit as dropped.
+func (r *ringState) write(n int, copyIn func(pos, from, count int)) int {
+	var written = 0
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for written < n {
+		var pos, count = r.waitWrite(n - written)
+		if count == 0 {
+			break
+		}
+		copyIn(pos, written, count)
+		r.commitWrite(count)
+		written += count
+	}
+
+	if written < n && !r.closed {
+		r.stats.Dropped += uint64(n - written)
+	}
+
+	return written
+}
+
+// read reads up to n samples, waiting for at least one, calling copyOut to copy count samples from the storage at
+// pos to the sample to. It returns 0 when the buffer is closed and empty.
+func (r *ringState) read(n int, copyOut func(pos, to, count int)) int {
+	var read = 0
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for read < n {
+		var pos, count = r.waitRead(n - read)
+		if count == 0 {
+			break
+		}
+		copyOut(pos, read, count)
+		r.commitRead(count)
+		read += count
+
+		if r.length == 0 {
+			break
+		}
+	}
+
+	return read
+}
+
+// Close closes the buffer. Pending samples can still be read, new writes are discarded.
+func (r *ringState) Close() {
+	r.lock.Lock()
+	r.closed = true
+	r.notEmpty.Broadcast()
+	r.notFull.Broadcast()
+	r.lock.Unlock()
+}
+
+// IsClosed returns if the buffer was closed
+func (r *ringState) IsClosed() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.closed
+}
+
+// Reset drops all samples, reopens the buffer and clears the counters
+func (r *ringState) Reset() {
+	r.lock.Lock()
+	r.start = 0
+	r.length = 0
+	r.closed = false
+	r.stats = BufferStats{}
+	r.tags = r.tags[:0]
+	r.notFull.Broadcast()
+	r.lock.Unlock()
+}
+
+// AddTags adds tags to the buffer. The offsets are absolute, relative to the first sample written to the buffer.
+func (r *ringState) AddTags(tags ...Tag) {
+	r.lock.Lock()
+	r.tags = append(r.tags, tags...)
+	r.lock.Unlock()
+}
+
+// WriteOffset returns the absolute offset of the next sample to be written
+func (r *ringState) WriteOffset() uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.stats.Written
+}
+
+// ReadOffset returns the absolute offset of the next sample to be read
+func (r *ringState) ReadOffset() uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.stats.Read
+}
+
+// TakeTags appends to dst and removes from the buffer the tags of the samples already read
+func (r *ringState) TakeTags(dst []Tag) []Tag {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var remaining = r.tags[:0]
+
+	for _, t := range r.tags {
+		if t.Offset < r.stats.Read {
+			dst = append(dst, t)
+		} else {
+			remaining = append(remaining, t)
+		}
+	}
+
+	r.tags = remaining
+
+	return dst
+}
+
+// SetOverflowMode sets what happens when the buffer is written while full
+func (r *ringState) SetOverflowMode(mode OverflowMode) {
+	r.lock.Lock()
+	r.mode = mode
+	r.notFull.Broadcast()
+	r.lock.Unlock()
+}
+
+func (r *ringState) GetOverflowMode() OverflowMode {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.mode
+}
+
+// GetStats returns a copy of the buffer counters
+func (r *ringState) GetStats() BufferStats {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var stats = r.stats
+	stats.Capacity = r.capacity
+	stats.Used = r.length
+
+	return stats
+}
+
+// Len returns the number of samples waiting to be read
+func (r *ringState) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.length
+}
+
+// region Complex Ring Buffer
+
+// ComplexRingBuffer is a bounded complex64 ring buffer for one writer and one reader
+type ComplexRingBuffer struct {
+	ringState
+	data []complex64
+}
+
+func MakeComplexRingBuffer(capacity int) *ComplexRingBuffer {
+	var b = &ComplexRingBuffer{
+		data: make([]complex64, capacity),
+	}
+	b.init(capacity)
+	return b
+}
+
+// Write writes the samples to the buffer and returns how many were written.
+// In OverflowBlock mode it only returns early if the buffer is closed.
+func (b *ComplexRingBuffer) Write(samples []complex64) int {
+	return b.write(len(samples), func(pos, from, count int) {
+		copy(b.data[pos:pos+count], samples[from:from+count])
+	})
+}
+
+// Read reads up to len(output) samples, waiting for at least one. It returns 0 when the buffer is closed and empty.
+func (b *ComplexRingBuffer) Read(output []complex64) int {
+	return b.read(len(output), func(pos, to, count int) {
+		copy(output[to:to+count], b.data[pos:pos+count])
+	})
+}
+
+// endregion
+// region Float Ring Buffer
+
+// FloatRingBuffer is a bounded float32 ring buffer for one writer and one reader
+type FloatRingBuffer struct {
+	ringState
+	data []float32
+}
+
+func MakeFloatRingBuffer(capacity int) *FloatRingBuffer {
+	var b = &FloatRingBuffer{
+		data: make([]float32, capacity),
+	}
+	b.init(capacity)
+	return b
+}
+
+// Write writes the samples to the buffer and returns how many were written.
+// In OverflowBlock mode it only returns early if the buffer is closed.
+func (b *FloatRingBuffer) Write(samples []float32) int {
+	return b.write(len(samples), func(pos, from, count int) {
+		copy(b.data[pos:pos+count], samples[from:from+count])
+	})
+}
+
+// Read reads up to len(output) samples, waiting for at least one. It returns 0 when the buffer is closed and empty.
+func (b *FloatRingBuffer) Read(output []float32) int {
+	return b.read(len(output), func(pos, to, count int) {
+		copy(output[to:to+count], b.data[pos:pos+count])
+	})
+}
+
+// endregion
+// region Byte Ring Buffer
+
+// ByteRingBuffer is a bounded byte ring buffer for one writer and one reader
+type ByteRingBuffer struct {
+	ringState
+	data []byte
+}
+
+func MakeByteRingBuffer(capacity int) *ByteRingBuffer {
+	var b = &ByteRingBuffer{
+		data: make([]byte, capacity),
+	}
+	b.init(capacity)
+	return b
+}
+
+// Write writes the samples to the buffer and returns how many were written.
+// In OverflowBlock mode it only returns early if the buffer is closed.
+func (b *ByteRingBuffer) Write(samples []byte) int {
+	return b.write(len(samples), func(pos, from, count int) {
+		copy(b.data[pos:pos+count], samples[from:from+count])
+	})
+}
+
+// Read reads up to len(output) samples, waiting for at least one. It returns 0 when the buffer is closed and empty.
+func (b *ByteRingBuffer) Read(output []byte) int {
+	return b.read(len(output), func(pos, to, count int) {
+		copy(output[to:to+count], b.data[pos:pos+count])
+	})
+}
+
+// endregion
@@ -0,0 +1,99 @@
+package flowgraph
+
+import "fmt"
+
+// sink calls a callback with the samples of its input port. The slices passed to the callback are reused, so the
+// callback should copy the samples it wants to keep.
+type sink struct {
+	name      string
+	chunkSize int
+	input     *Port
+	data      segmentData
+	callback  func(d *segmentData, offset uint64)
+}
+
+func (g *Flowgraph) addSink(name string, input *Port, dataType DataType, callback func(d *segmentData, offset uint64)) {
+	if input.GetType() != dataType {
+		panic(fmt.Sprintf("Sink %s takes %s input, got %s", name, dataType, input.GetType()))
+	}
+
+	g.checkPort(input)
+	g.addNode(&sink{
+		name:      name,
+		chunkSize: g.chunkSize,
+		input:     input,
+		callback:  callback,
+	})
+}
+
+// AddComplexTaggedSink adds a sink that calls cb with the complex samples of the input port, the absolute offset
+// of the first sample and the tags of the samples (with absolute offsets)
+func (g *Flowgraph) AddComplexTaggedSink(name string, input *Port, cb func(data []complex64, offset uint64, tags []Tag)) {
+	g.addSink(name, input, ComplexData, func(d *segmentData, offset uint64) {
+		cb(d.complexData, offset, d.tags)
+	})
+}
+
+// AddFloatTaggedSink adds a sink that calls cb with the float samples of the input port, the absolute offset
+// of the first sample and the tags of the samples (with absolute offsets)
+func (g *Flowgraph) AddFloatTaggedSink(name string, input *Port, cb func(data []float32, offset uint64, tags []Tag)) {
+	g.addSink(name, input, FloatData, func(d *segmentData, offset uint64) {
+		cb(d.floatData, offset, d.tags)
+	})
+}
+
+// AddByteTaggedSink adds a sink that calls cb with the bytes of the input port, the absolute offset
+// of the first byte and the tags of the bytes (with absolute offsets)
+func (g *Flowgraph) AddByteTaggedSink(name string, input *Port, cb func(data []byte, offset uint64, tags []Tag)) {
+	g.addSink(name, input, ByteData, func(d *segmentData, offset uint64) {
+		cb(d.byteData, offset, d.tags)
+	})
+}
+
+// AddComplexSink adds a sink that calls cb with the complex samples of the input port
+func (g *Flowgraph) AddComplexSink(name string, input *Port, cb func(data []complex64)) {
+	g.addSink(name, input, ComplexData, func(d *segmentData, offset uint64) {
+		cb(d.complexData)
+	})
+}
+
+// AddFloatSink adds a sink that calls cb with the float samples of the input port
+func (g *Flowgraph) AddFloatSink(name string, input *Port, cb func(data []float32)) {
+	g.addSink(name, input, FloatData, func(d *segmentData, offset uint64) {
+		cb(d.floatData)
+	})
+}
+
+// AddByteSink adds a sink that calls cb with the bytes of the input port
+func (g *Flowgraph) AddByteSink(name string, input *Port, cb func(data []byte)) {
+	g.addSink(name, input, ByteData, func(d *segmentData, offset uint64) {
+		cb(d.byteData)
+	})
+}
+
+func (s *sink) getName() string {
+	return s.name
+}
+
+func (s *sink) inputStats() *BufferStats {
+	var stats = s.input.GetStats()
+	return &stats
+}
+
+func (s *sink) reset() {}
+
+func (s *sink) run() {
+	for {
+		var n = s.input.read(&s.data, s.chunkSize)
+		if n == 0 {
+			return
+		}
+
+		var offset = s.input.buffer().ReadOffset() - uint64(n)
+		for i := range s.data.tags {
+			s.data.tags[i].Offset += offset
+		}
+
+		s.callback(&s.data, offset)
+	}
+}
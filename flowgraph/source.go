@@ -0,0 +1,116 @@
+package flowgraph
+
+// source is a node fed by the user, that is closed to stop the flowgraph
+type source interface {
+	close()
+}
+
+// sourceNode is the part of the sources shared by all sample types. The typed sources only add Write.
+type sourceNode struct {
+	name   string
+	output *Port
+}
+
+func (g *Flowgraph) makeSourceNode(name string, dataType DataType) sourceNode {
+	return sourceNode{
+		name:   name,
+		output: g.makePort(name, dataType),
+	}
+}
+
+// Port returns the source output port
+func (s *sourceNode) Port() *Port {
+	return s.output
+}
+
+// SetOverflowMode sets what happens when the source is written while the first block is not keeping up
+func (s *sourceNode) SetOverflowMode(mode OverflowMode) {
+	s.output.buffer().SetOverflowMode(mode)
+}
+
+// AddTag tags the next sample written to the source
+func (s *sourceNode) AddTag(key string, value interface{}) {
+	var buffer = s.output.buffer()
+	buffer.AddTags(Tag{
+		Offset: buffer.WriteOffset(),
+		Key:    key,
+		Value:  value,
+	})
+}
+
+// GetOffset returns the absolute offset of the next sample written to the source
+func (s *sourceNode) GetOffset() uint64 {
+	return s.output.buffer().WriteOffset()
+}
+
+func (s *sourceNode) getName() string {
+	return s.name
+}
+
+func (s *sourceNode) run() {}
+
+func (s *sourceNode) inputStats() *BufferStats {
+	return nil
+}
+
+func (s *sourceNode) reset() {
+	s.output.reset()
+}
+
+func (s *sourceNode) close() {
+	s.output.close()
+}
+
+// ComplexSource feeds complex samples into a flowgraph
+type ComplexSource struct {
+	sourceNode
+}
+
+// AddComplexSource adds a complex sample source to the flowgraph
+func (g *Flowgraph) AddComplexSource(name string) *ComplexSource {
+	var s = &ComplexSource{g.makeSourceNode(name, ComplexData)}
+	g.addNode(s)
+	return s
+}
+
+// Write feeds samples into the flowgraph, returning how many were accepted.
+// Samples written after the flowgraph is stopped are discarded.
+func (s *ComplexSource) Write(data []complex64) int {
+	return s.output.complexBuffer.Write(data)
+}
+
+// FloatSource feeds float samples into a flowgraph
+type FloatSource struct {
+	sourceNode
+}
+
+// AddFloatSource adds a float sample source to the flowgraph
+func (g *Flowgraph) AddFloatSource(name string) *FloatSource {
+	var s = &FloatSource{g.makeSourceNode(name, FloatData)}
+	g.addNode(s)
+	return s
+}
+
+// Write feeds samples into the flowgraph, returning how many were accepted.
+// Samples written after the flowgraph is stopped are discarded.
+func (s *FloatSource) Write(data []float32) int {
+	return s.output.floatBuffer.Write(data)
+}
+
+// ByteSource feeds bytes (like integer IQ samples, see dsp.ByteToComplex) into a flowgraph
+type ByteSource struct {
+	sourceNode
+}
+
+// AddByteSource adds a byte source to the flowgraph
+func (g *Flowgraph) AddByteSource(name string) *ByteSource {
+	var s = &ByteSource{g.makeSourceNode(name, ByteData)}
+	g.addNode(s)
+	return s
+}
+
+// Write feeds bytes into the flowgraph, returning how many were accepted.
+// Bytes written after the flowgraph is stopped are discarded.
+func (s *ByteSource) Write(data []byte) int {
+	return s.output.byteBuffer.Write(data)
+}
@@ -0,0 +1,161 @@
+package flowgraph
+
+import (
+	"time"
+
+	"github.com/racerxdl/segdsp/dsp"
+)
+
+// Well known tag keys
+const (
+	// TagTime is the reception time (time.Time) of the tagged sample
+	TagTime = "rx_time"
+	// TagFrequency is the center frequency (float64, in Hertz) from the tagged sample on
+	TagFrequency = "rx_freq"
+)
+
+// Tag is a key / value metadata attached to a sample of a stream.
+// Offset is the absolute sample number since the flowgraph started.
+// Tags are propagated by the blocks. The workers that implement dsp.TagOffsetMapper move the tags with the samples,
+// through their delay and rate change. The offsets are rescaled by the rate change of the other workers.
+type Tag struct {
+	Offset uint64
+	Key    string
+	Value  interface{}
+}
+
+// rescaleTags maps the tags relative offsets of an input chunk of n samples to an output chunk of m samples.
+// Tags of samples that did not produce an output yet (like inside a decimation) go to the next output sample.
+func rescaleTags(tags []Tag, n, m int) {
+	if n == m {
+		return
+	}
+
+	for i := range tags {
+		if n == 0 {
+			tags[i].Offset = 0
+			continue
+		}
+
+		var offset = tags[i].Offset * uint64(m) / uint64(n)
+		if offset >= uint64(m) && m > 0 {
+			offset = uint64(m) - 1
+		}
+		tags[i].Offset = offset
+	}
+}
+
+// pendingTag is a tag of a sample that did not come out of a worker yet
+type pendingTag struct {
+	Tag
+	// offset is the input offset, relative to the first sample of the next chunk
+	offset int
+}
+
+// stageTags moves the tags through a worker of a block
+type stageTags struct {
+	mapper  dsp.TagOffsetMapper
+	pending []pendingTag
+}
+
+// move maps the tags of an input chunk of n samples to the output chunk of m samples of the worker.
+// Tags of samples still inside the worker are kept for the next chunks.
+func (s *stageTags) move(tags []Tag, n, m int) []Tag {
+	if s.mapper == nil {
+		rescaleTags(tags, n, m)
+		return tags
+	}
+
+	var output = tags[:0]
+	if len(s.pending) > 0 {
+		// The pending tags come first, so the tags can not be mapped in place
+		output = make([]Tag, 0, len(s.pending)+len(tags))
+	}
+
+	var pending = s.pending[:0]
+	for _, p := range s.pending {
+		output, pending = s.place(p.Tag, p.offset, n, m, output, pending)
+	}
+	for _, t := range tags {
+		output, pending = s.place(t, int(t.Offset), n, m, output, pending)
+	}
+	s.pending = pending
+
+	return output
+}
+
+func (s *stageTags) place(tag Tag, offset, n, m int, output []Tag, pending []pendingTag) ([]Tag, []pendingTag) {
+	var outputOffset = s.mapper.MapOffset(offset)
+	if outputOffset >= m {
+		return output, append(pending, pendingTag{Tag: tag, offset: offset - n})
+	}
+
+	if outputOffset < 0 {
+		outputOffset = 0
+	}
+	tag.Offset = uint64(outputOffset)
+
+	return append(output, tag), pending
+}
+
+func (s *stageTags) reset() {
+	s.pending = s.pending[:0]
+}
+
+// TimeTracker computes the time of any sample of a stream from its time tags.
+// The time is extrapolated from the last time tag with the sample rate of the stream where the tracker is,
+// so it works after any rate change, as the blocks move the tag offsets.
+type TimeTracker struct {
+	sampleRate float64
+	offset     uint64
+	time       time.Time
+	valid      bool
+}
+
+// MakeTimeTracker creates a Time Tracker for a stream of sampleRate samples per second
+func MakeTimeTracker(sampleRate float64) *TimeTracker {
+	return &TimeTracker{
+		sampleRate: sampleRate,
+	}
+}
+
+// Update updates the tracker with the tags of a stream
+func (t *TimeTracker) Update(tags []Tag) {
+	for _, tag := range tags {
+		if tag.Key != TagTime {
+			continue
+		}
+
+		if v, ok := tag.Value.(time.Time); ok {
+			t.offset = tag.Offset
+			t.time = v
+			t.valid = true
+		}
+	}
+}
+
+// TimeAt returns the time of the sample at offset. ok is false if no time tag was seen yet.
+func (t *TimeTracker) TimeAt(offset uint64) (timestamp time.Time, ok bool) {
+	if !t.valid {
+		return time.Time{}, false
+	}
+
+	var delta = (float64(offset) - float64(t.offset)) / t.sampleRate
+
+	return t.time.Add(time.Duration(delta * float64(time.Second))), true
+}
+
+func (t *TimeTracker) GetSampleRate() float64 {
+	return t.sampleRate
+}
+
+// FindTag returns the last tag with key, or nil if there is none
+func FindTag(tags []Tag, key string) *Tag {
+	for i := len(tags) - 1; i >= 0; i-- {
+		if tags[i].Key == key {
+			return &tags[i]
+		}
+	}
+
+	return nil
+}
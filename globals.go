@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/gorilla/websocket"
 	"github.com/racerxdl/radioserver/protocol"
+	"github.com/racerxdl/segdsp/eventmanager"
 	"net/http"
 	"sync"
 )
@@ -14,6 +15,10 @@ var upgrader = websocket.Upgrader{
 } // use default options
 
 var wsMutex = sync.Mutex{}
+var ev = eventmanager.EventManager{}
 var currDevice = deviceMessage{
 	Gain: protocol.Invalid,
 }
+
+// deviceLock protects currDevice, that is updated from the radio, DSP, websocket and refresh goroutines
+var deviceLock = sync.Mutex{}
@@ -6,6 +6,5 @@ require (
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
 	github.com/gorilla/websocket v1.4.0
 	github.com/llgcode/draw2d v0.0.0-20180825133448-f52c8a71aff0
-	github.com/racerxdl/go.fifo v0.0.0-20180604061744-c6aa83afe374
 	github.com/racerxdl/radioserver v0.0.0-20190324175745-bd3bd7179419
 )
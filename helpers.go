@@ -4,6 +4,10 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"github.com/racerxdl/segdsp/demodcore"
+	"github.com/racerxdl/segdsp/dsp"
+	"github.com/racerxdl/segdsp/dsp/digital"
+	"github.com/racerxdl/segdsp/tools"
 	"math"
 )
 
@@ -36,6 +40,22 @@ type fftMessage struct {
 	MessageType      string
 	DemodOutputLevel float32
 	FFTData          jsonUint8s
+	Measurements     demodcore.Measurements
+}
+
+type signalsMessage struct {
+	MessageType string
+	Signals     []dsp.TrackedSignal
+}
+
+// diagnosticsMessage has the symbol diagnostics of the digital modes. The points are int8 I / Q pairs scaled to
+// their peak, and the eye traces are EyeTracePoints points each, over EyeTraceSymbols symbols.
+type diagnosticsMessage struct {
+	MessageType     string
+	Constellation   jsonUint8s
+	Eye             jsonUint8s
+	EyeTracePoints  int
+	EyeTraceSymbols int
 }
 
 type dataMessage struct {
@@ -66,9 +86,20 @@ type deviceMessage struct {
 	WebCanControl bool
 	TCPCanControl bool
 	IsMuted       bool
+
+	Measurements demodcore.Measurements
+	IQCorrection *iqCorrectionInfo
+}
+
+type iqCorrectionInfo struct {
+	DCOffsetI      float32
+	DCOffsetQ      float32
+	GainImbalance  float32
+	PhaseImbalance float32
+	ImageRejection float32
 }
 
-func makeFFTMessage(data []uint8, level float32) fftMessage {
+func makeFFTMessage(data []uint8, level float32, measurements demodcore.Measurements) fftMessage {
 	if math.IsInf(float64(level), 0) {
 		level = 0
 	}
@@ -76,6 +107,74 @@ func makeFFTMessage(data []uint8, level float32) fftMessage {
 		MessageType:      "fft",
 		DemodOutputLevel: level,
 		FFTData:          data,
+		Measurements:     measurements,
+	}
+}
+
+func makeIQCorrectionInfo(c *dsp.IQCorrector) *iqCorrectionInfo {
+	if c == nil {
+		return nil
+	}
+
+	var imageRejection = c.GetImageRejection()
+	if math.IsInf(float64(imageRejection), 0) {
+		imageRejection = 0
+	}
+
+	return &iqCorrectionInfo{
+		DCOffsetI:      real(c.GetDCOffset()),
+		DCOffsetQ:      imag(c.GetDCOffset()),
+		GainImbalance:  c.GetGainImbalance(),
+		PhaseImbalance: c.GetPhaseImbalance(),
+		ImageRejection: imageRejection,
+	}
+}
+
+func makeSignalsMessage(signals []dsp.TrackedSignal) signalsMessage {
+	return signalsMessage{
+		MessageType: "signals",
+		Signals:     signals,
+	}
+}
+
+// packIQ8 packs the points as int8 I / Q pairs, with the highest component at 127
+func packIQ8(points []complex64) jsonUint8s {
+	var peak = float32(0)
+	for _, v := range points {
+		if tools.Abs(real(v)) > peak {
+			peak = tools.Abs(real(v))
+		}
+		if tools.Abs(imag(v)) > peak {
+			peak = tools.Abs(imag(v))
+		}
+	}
+
+	var scale = float32(0)
+	if peak > 0 {
+		scale = 127 / peak
+	}
+
+	var data = make(jsonUint8s, len(points)*2)
+	for i, v := range points {
+		data[i*2] = uint8(int8(math.Round(float64(real(v) * scale))))
+		data[i*2+1] = uint8(int8(math.Round(float64(imag(v) * scale))))
+	}
+
+	return data
+}
+
+func makeDiagnosticsMessage(points []complex64, traces [][]complex64) diagnosticsMessage {
+	var eye = make([]complex64, 0, len(traces)*digital.EyeTracePoints)
+	for _, trace := range traces {
+		eye = append(eye, trace...)
+	}
+
+	return diagnosticsMessage{
+		MessageType:     "diagnostics",
+		Constellation:   packIQ8(points),
+		Eye:             packIQ8(eye),
+		EyeTracePoints:  digital.EyeTracePoints,
+		EyeTraceSymbols: digital.EyeTraceSymbols,
 	}
 }
 
@@ -6,6 +6,7 @@ type presetStruct struct {
 	outputRate      uint
 	filterBandwidth float64
 	demodOptions    map[string]interface{}
+	audioOptions    map[string]interface{}
 }
 
 var presets = map[string]presetStruct{
@@ -17,6 +18,15 @@ var presets = map[string]presetStruct{
 		demodOptions: map[string]interface{}{
 			"audioCut": 5e3,
 		},
+		audioOptions: map[string]interface{}{
+			"agc":        true,
+			"agcAttack":  0.01,
+			"agcDecay":   1.0,
+			"agcHang":    0.5,
+			"agcTarget":  -6.0,
+			"agcMaxGain": 40.0,
+			"limiter":    true,
+		},
 	},
 	"nbfm": {
 		name:            "Narrow Band FM",
@@ -27,6 +37,15 @@ var presets = map[string]presetStruct{
 			"deviation": 5e3,
 			"tau":       75e-6,
 		},
+		audioOptions: map[string]interface{}{
+			"agc":        true,
+			"agcAttack":  0.005,
+			"agcDecay":   0.5,
+			"agcHang":    0.3,
+			"agcTarget":  -6.0,
+			"agcMaxGain": 30.0,
+			"limiter":    true,
+		},
 	},
 	"wbfm": {
 		name:            "Wide Band FM",
@@ -37,5 +56,8 @@ var presets = map[string]presetStruct{
 			"deviation": 75e3,
 			"tau":       75e-6,
 		},
+		audioOptions: map[string]interface{}{
+			"limiter": true,
+		},
 	},
 }
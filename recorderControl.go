@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"github.com/racerxdl/segdsp/demodcore"
+	"github.com/racerxdl/segdsp/dsp"
 	"github.com/racerxdl/segdsp/recorders"
+	"log"
 	"sync"
 	"time"
 )
@@ -24,6 +27,7 @@ var recordingParams = struct {
 
 type recordingMetadata struct {
 	DemodParams  interface{}
+	Measurements demodcore.Measurements
 	BaseFilename string
 	Timestamp    time.Time
 }
@@ -39,6 +43,7 @@ func startRecording() {
 			filename,
 			recordingMetadata{
 				DemodParams:  demodulator.GetDemodParams(),
+				Measurements: demodulator.GetMeasurements(),
 				BaseFilename: filename,
 				Timestamp:    time.Now().Local(),
 			},
@@ -74,6 +79,51 @@ func recordAudio(data []float32) {
 //	recordMutex.Unlock()
 //}
 
+type burstMetadata struct {
+	ID              uint64
+	Start           time.Time
+	Duration        float64
+	CenterFrequency float64
+	Bandwidth       float64
+	SNR             float32
+	SampleRate      float64
+	BaseFilename    string
+}
+
+// burstRecorder is a burst decoder that logs the bursts and, when recording, writes each one to its own recording
+type burstRecorder struct{}
+
+func (burstRecorder) DecodeBurst(burst dsp.Burst) {
+	log.Printf("Burst %d: %.0f Hz (%.0f Hz wide, SNR %.1f dB, %s)\n", burst.ID, burst.CenterFrequency, burst.Bandwidth, burst.SNR, burst.Duration)
+
+	if !recordingParams.recorderEnable {
+		return
+	}
+
+	var filename = fmt.Sprintf(recordingParams.baseFilename, stationName, fmt.Sprintf("burst-%s-%d", burst.Start.Local().Format("20060102_150405"), burst.ID))
+	var params = []interface{}{
+		filename,
+		burstMetadata{
+			ID:              burst.ID,
+			Start:           burst.Start,
+			Duration:        burst.Duration.Seconds(),
+			CenterFrequency: burst.CenterFrequency,
+			Bandwidth:       burst.Bandwidth,
+			SNR:             burst.SNR,
+			SampleRate:      burst.SampleRate,
+			BaseFilename:    filename,
+		},
+	}
+
+	// Do not hold the DSP while writing
+	go func() {
+		var r recorders.BaseRecorder = &recorders.FileRecorder{}
+		r.Open(params)
+		r.WriteIQ(burst.Samples)
+		r.Close()
+	}()
+}
+
 func stopRecording() {
 	recordMutex.Lock()
 	recorder.Close()
@@ -7,26 +7,34 @@ import (
 	"github.com/racerxdl/radioserver/client"
 	"github.com/racerxdl/radioserver/protocol"
 	"github.com/racerxdl/segdsp/demodcore"
-	"github.com/racerxdl/segdsp/dsp/fft"
+	"github.com/racerxdl/segdsp/dsp"
 	"github.com/racerxdl/segdsp/eventmanager"
 	"github.com/racerxdl/segdsp/recorders"
-	"github.com/racerxdl/segdsp/tools"
 	"log"
-	"math"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime/pprof"
+	"sync"
 	"syscall"
 	"time"
 )
 
 var displayRange = 90
 var displayOffset = -60
-var lastFFT []float32
 var fftSamples []uint8
-var fftSamplesF []float32
-var smartIqSamples []complex64
+var spectrumEstimator *dsp.SpectrumEstimator
+var spectrumIQCorrector *dsp.IQCorrector
+var spectrumLock = sync.Mutex{}
+var signalDetector *dsp.CFARDetector
+var signalTracker *dsp.SignalTracker
+var lastSignalsMessage time.Time
+
+const signalsRefreshInterval = time.Second
+const iqCorrectionRefreshInterval = 5 * time.Second
+
+// diagnosticsInterval limits the rate of the symbol diagnostics messages
+const diagnosticsInterval = 200 * time.Millisecond
 
 type segdspCallback struct {
 	rs *client.RadioClient
@@ -66,15 +74,28 @@ func onDeviceSync(rs *client.RadioClient) {
 	if demodulator != nil {
 		d.DemodulatorParams = demodulator.GetDemodParams()
 		d.IsMuted = demodulator.IsMuted()
+		d.Measurements = demodulator.GetMeasurements()
 	}
 
+	d.IQCorrection = makeIQCorrectionInfo(iqCorrector)
+
+	deviceLock.Lock()
 	currDevice = makeDeviceMessage(d)
+	deviceLock.Unlock()
+
 	refreshDevice()
 }
 
 func refreshDevice() {
+	deviceLock.Lock()
+	defer deviceLock.Unlock()
+
 	sendPacket := currDevice.Gain != protocol.Invalid
 	if sendPacket {
+		if demodulator != nil {
+			currDevice.Measurements = demodulator.GetMeasurements()
+		}
+		currDevice.IQCorrection = makeIQCorrectionInfo(iqCorrector)
 		m, err := json.Marshal(currDevice)
 		if err != nil {
 			log.Println("Error serializing JSON: ", err)
@@ -85,56 +106,89 @@ func refreshDevice() {
 
 func onSmartIQ(rs *client.RadioClient, data []complex64) {
 	var scale = 256 / float32(displayRange)
-	data = data[:displayPixels]
-	if smartIqSamples == nil || len(smartIqSamples) != len(data) {
-		smartIqSamples = make([]complex64, len(data))
-	}
-
-	copy(smartIqSamples, data)
 
-	fftCData := fft.FFT(smartIqSamples)
+	spectrumLock.Lock()
+	defer spectrumLock.Unlock()
 
-	var l = len(fftCData)
-	var scaledV float32
-
-	if fftSamples == nil || len(fftSamples) != len(fftCData) {
-		fftSamples = make([]uint8, len(fftCData))
-		fftSamplesF = make([]float32, len(fftCData))
+	if spectrumEstimator == nil {
+		spectrumEstimator = buildSpectrumEstimator()
+		if iqCorrection {
+			spectrumIQCorrector = dsp.MakeIQCorrector()
+		}
 	}
 
-	if lastFFT == nil || len(lastFFT) != len(fftSamples) {
-		lastFFT = make([]float32, len(fftCData))
+	if spectrumIQCorrector != nil {
+		data = spectrumIQCorrector.Work(data)
 	}
 
-	for i, v := range fftCData {
-		var m = float64(tools.ComplexAbsSquared(v) * (1.0 / float32(rs.GetSmartSampleRate())))
-		var o = float32(10 * math.Log10(m))
-		fftSamplesF[i] = o
+	if spectrumEstimator.Feed(data) == 0 {
+		return
 	}
 
-	for i := range fftSamplesF {
-		fftSamplesF[i] = (lastFFT[i] + fftSamplesF[i]) / 2
+	if signalDetection {
+		detectSignals(rs)
 	}
 
-	copy(lastFFT, fftSamplesF)
+	// Normalize to the sample rate, so the level does not depend on the decimation
+	var spectrum = spectrumEstimator.GetSpectrumDB(int(displayPixels), 1.0/float32(rs.GetSmartSampleRate()))
+
+	if fftSamples == nil || len(fftSamples) != len(spectrum) {
+		fftSamples = make([]uint8, len(spectrum))
+	}
 
-	for i, v := range fftSamplesF {
-		// FFT is symmetric
-		var oI = (i + l/2) % l
-		scaledV = 255 + ((v - float32(displayOffset)) * scale)
+	for i, v := range spectrum {
+		var scaledV = 255 + ((v - float32(displayOffset)) * scale)
 		if scaledV < 0 {
 			scaledV = 0
 		} else if scaledV > 255 {
 			scaledV = 255
 		}
-		fftSamples[oI] = uint8(scaledV)
+		fftSamples[i] = uint8(scaledV)
 	}
 	onFFT(fftSamples)
 }
 
+func makeSignalEventData(s dsp.TrackedSignal) eventmanager.SignalEventData {
+	return eventmanager.SignalEventData{
+		ID:              s.ID,
+		CenterFrequency: s.CenterFrequency,
+		Bandwidth:       s.Bandwidth,
+		SNR:             s.SNR,
+		FirstSeen:       s.FirstSeen,
+		LastSeen:        s.LastSeen,
+	}
+}
+
+func detectSignals(rs *client.RadioClient) {
+	if signalDetector == nil {
+		signalDetector, signalTracker = buildSignalDetector()
+	}
+
+	var now = time.Now()
+	var detected = signalDetector.FindSignals(spectrumEstimator.GetSpectrum(), float64(rs.GetSmartCenterFrequency()), float64(rs.GetSmartSampleRate()))
+	var activated, deactivated = signalTracker.Update(detected, now)
+
+	for _, s := range activated {
+		ev.Emit(eventmanager.EvSignalOn, makeSignalEventData(s))
+	}
+
+	for _, s := range deactivated {
+		ev.Emit(eventmanager.EvSignalOff, makeSignalEventData(s))
+	}
+
+	if len(activated) > 0 || len(deactivated) > 0 || now.Sub(lastSignalsMessage) > signalsRefreshInterval {
+		lastSignalsMessage = now
+		m, err := json.Marshal(makeSignalsMessage(signalTracker.GetActiveSignals()))
+		if err != nil {
+			log.Println("Error serializing JSON: ", err)
+		}
+		go broadcastMessage(string(m))
+	}
+}
+
 func onFFT(data []uint8) {
 	//log.Println("Received FFT! ", len(data))
-	var j = makeFFTMessage(data, demodulator.GetLevel())
+	var j = makeFFTMessage(data, demodulator.GetLevel(), demodulator.GetMeasurements())
 	m, err := json.Marshal(j)
 	if err != nil {
 		log.Println("Error serializing JSON: ", err)
@@ -158,6 +212,45 @@ func sendData(data interface{}) {
 	}
 }
 
+// sendDiagnostics streams the symbol diagnostics of the digital demodulators to the clients that asked for them.
+// The taps only run while there is any of these clients.
+func sendDiagnostics() {
+	var provider, ok = demodulator.(demodcore.DiagnosticsProvider)
+	if !ok {
+		return
+	}
+
+	var constellation = provider.GetConstellationTap()
+	var eye = provider.GetEyeTap()
+
+	for range time.Tick(diagnosticsInterval) {
+		var enabled = hasDiagnosticsClients()
+		var points []complex64
+		var traces [][]complex64
+
+		if constellation != nil {
+			constellation.SetEnabled(enabled)
+			points = constellation.GetPoints()
+		}
+
+		if eye != nil {
+			eye.SetEnabled(enabled)
+			traces = eye.GetTraces()
+		}
+
+		if !enabled || (len(points) == 0 && len(traces) == 0) {
+			continue
+		}
+
+		m, err := json.Marshal(makeDiagnosticsMessage(points, traces))
+		if err != nil {
+			log.Println("Error serializing JSON: ", err)
+			continue
+		}
+		go broadcastDiagnosticsMessage(string(m))
+	}
+}
+
 func createServer() *http.Server {
 	srv := &http.Server{Addr: httpAddr}
 
@@ -176,21 +269,44 @@ func createServer() *http.Server {
 
 var squelchOn chan interface{}
 var squelchOff chan interface{}
+var afcOffset chan interface{}
+var signalOn chan interface{}
+var signalOff chan interface{}
 
 func onSquelchOn(data eventmanager.SquelchEventData) {
-	log.Println("Squelch ON", data.AvgValue, data.Threshold)
+	log.Println("Squelch ON", data.Criterion, data.AvgValue, data.Threshold)
+	deviceLock.Lock()
 	currDevice.IsMuted = demodulator.IsMuted()
+	deviceLock.Unlock()
 	stopRecording()
 	refreshDevice()
 }
 
 func onSquelchOff(data eventmanager.SquelchEventData) {
-	log.Println("Squelch OFF", data.AvgValue, data.Threshold)
+	log.Println("Squelch OFF", data.Criterion, data.AvgValue, data.Threshold)
+	deviceLock.Lock()
 	currDevice.IsMuted = demodulator.IsMuted()
+	deviceLock.Unlock()
 	startRecording()
 	refreshDevice()
 }
 
+func onAFCOffset(data eventmanager.AFCEventData) {
+	log.Printf("AFC Offset: %.1f Hz\n", data.Offset)
+	deviceLock.Lock()
+	currDevice.DemodulatorParams = demodulator.GetDemodParams()
+	deviceLock.Unlock()
+	refreshDevice()
+}
+
+func onSignalOn(data eventmanager.SignalEventData) {
+	log.Printf("Signal %d ON: %.0f Hz (%.0f Hz wide, SNR %.1f dB)\n", data.ID, data.CenterFrequency, data.Bandwidth, data.SNR)
+}
+
+func onSignalOff(data eventmanager.SignalEventData) {
+	log.Printf("Signal %d OFF: %.0f Hz (seen for %s)\n", data.ID, data.CenterFrequency, data.LastSeen.Sub(data.FirstSeen))
+}
+
 func main() {
 	var err error
 	setEnv()
@@ -212,11 +328,15 @@ func main() {
 
 	squelchOn = make(chan interface{})
 	squelchOff = make(chan interface{})
-
-	var ev = eventmanager.EventManager{}
+	afcOffset = make(chan interface{})
+	signalOn = make(chan interface{})
+	signalOff = make(chan interface{})
 
 	ev.AddHandler(eventmanager.EvSquelchOn, squelchOn)
 	ev.AddHandler(eventmanager.EvSquelchOff, squelchOff)
+	ev.AddHandler(eventmanager.EvAFCOffset, afcOffset)
+	ev.AddHandler(eventmanager.EvSignalOn, signalOn)
+	ev.AddHandler(eventmanager.EvSignalOff, signalOff)
 
 	go func() {
 		log.Println("Starting Handler loop")
@@ -226,6 +346,12 @@ func main() {
 				onSquelchOn(msg.(eventmanager.SquelchEventData))
 			case msg := <-squelchOff:
 				onSquelchOff(msg.(eventmanager.SquelchEventData))
+			case msg := <-afcOffset:
+				onAFCOffset(msg.(eventmanager.AFCEventData))
+			case msg := <-signalOn:
+				onSignalOn(msg.(eventmanager.SignalEventData))
+			case msg := <-signalOff:
+				onSignalOff(msg.(eventmanager.SignalEventData))
 			}
 		}
 		//log.Println("Ending Handler loop")
@@ -238,7 +364,6 @@ func main() {
 		panic("Only\"file\" method is supported for recording.")
 	}
 
-	initDSP()
 	var rs = client.MakeRadioClient(radioserverhost, "User", "SegDSP")
 	var cb = segdspCallback{
 		rs: rs,
@@ -265,7 +390,7 @@ func main() {
 	if rs.SetDecimationStage(uint32(channelDecimationStage)) == protocol.Invalid {
 		log.Println("Error setting sample rate.")
 	}
-	if rs.SetCenterFrequency(uint32(channelFrequency)) == protocol.Invalid {
+	if !tuneChannel(rs, uint32(channelFrequency)) {
 		log.Println("Error setting center frequency.")
 	}
 
@@ -276,10 +401,27 @@ func main() {
 	log.Println("SmartIQ Center Frequency ", rs.GetSmartCenterFrequency())
 	log.Println("SmartIQ Sample Rate: ", rs.GetSmartSampleRate())
 
+	if iqCorrection {
+		iqCorrector = dsp.MakeIQCorrector()
+		go func() {
+			// Keep the clients updated with the latest estimation
+			for range time.Tick(iqCorrectionRefreshInterval) {
+				refreshDevice()
+			}
+		}()
+	}
+
 	demodulator = buildDSP(rs.GetSampleRate())
 	demodulator.SetEventManager(&ev)
 
+	if burstDetection {
+		burstDetector = buildBurstDetector(rs.GetSampleRate())
+		burstDetector.AddDecoder(burstRecorder{})
+	}
+
 	dspCb = sendData
+	initDSP()
+	go sendDiagnostics()
 
 	sigs := make(chan os.Signal, 1)
 	done := make(chan bool, 1)
@@ -294,7 +436,7 @@ func main() {
 	var srv = createServer()
 
 	onDeviceSync(rs)
-	startDSP()
+	startDSP(rs.GetCenterFrequency())
 
 	log.Println("Starting")
 	rs.Start()
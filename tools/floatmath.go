@@ -8,9 +8,9 @@ import (
 // All Functions here are from Standard Go Library but ported to float32
 
 const (
-	mask  = 0x7FF
+	mask  = 0xFF
 	shift = 32 - 8 - 1
-	bias  = 255
+	bias  = 127
 	//signMask = 1 << 31
 	//fracMask = 1<<shift - 1
 
@@ -276,9 +276,9 @@ func Modf(f float32) (int float32, frac float32) {
 	x := math.Float32bits(f)
 	e := uint(x>>shift)&mask - bias
 
-	// Keep the top 8+e bits, the integer part; clear the rest.
-	if e < 32-8 {
-		x &^= 1<<(32-8-e) - 1
+	// Keep the top 9+e bits, the integer part; clear the rest.
+	if e < shift {
+		x &^= 1<<(shift-e) - 1
 	}
 	int = math.Float32frombits(x)
 	frac = f - int
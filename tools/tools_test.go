@@ -26,6 +26,16 @@ func TestAbs(t *testing.T) {
 
 func TestFloor(t *testing.T) {
 	DoTestAgainstStdGo("Floor", Floor, math.Floor, t)
+
+	for i := 0; i < testRuns; i++ {
+		var v = (rand.Float32()*2 - 1) * 100000
+		got := Floor(v)
+		expected := math.Floor(float64(v))
+
+		if got != float32(expected) {
+			t.Errorf("Float32 Floor wrong. Expected (%f) got (%f)", expected, got)
+		}
+	}
 }
 
 func TestAtan(t *testing.T) {
@@ -68,6 +78,16 @@ func TestModf(t *testing.T) {
 			t.Errorf("Float32 Modf wrong. Expected (%f, %f) got (%f, %f)", gint, gfrac, eint, efrac)
 		}
 	}
+
+	for i := 0; i < testRuns; i++ {
+		var v = (rand.Float32()*2 - 1) * 100000
+		gint, gfrac := Modf(v)
+		eint, efrac := math.Modf(float64(v))
+
+		if gint != float32(eint) || gint+gfrac != v || Abs(gfrac-float32(efrac)) > 1e-2 {
+			t.Errorf("Float32 Modf wrong. Expected (%f, %f) got (%f, %f)", eint, efrac, gint, gfrac)
+		}
+	}
 }
 
 func TestAlmostFloatEqual(t *testing.T) {
@@ -14,6 +14,14 @@ var chanList = list.New()
 type conn struct {
 	stringc chan string
 	bytec   chan []byte
+	// diagnostics is set when the client asked for the symbol diagnostics, protected by wsMutex
+	diagnostics bool
+}
+
+// clientMessage is a request from a websocket client
+type clientMessage struct {
+	MessageType string
+	Enabled     bool
 }
 
 func closeN(c *list.Element) {
@@ -25,7 +33,7 @@ func closeN(c *list.Element) {
 func broadcastMessage(data string) {
 	wsMutex.Lock()
 	for e := chanList.Front(); e != nil; {
-		var c = e.Value.(conn)
+		var c = e.Value.(*conn)
 		go func() {
 			c.stringc <- data
 		}()
@@ -37,7 +45,7 @@ func broadcastMessage(data string) {
 func broadcastBMessage(data []byte) {
 	wsMutex.Lock()
 	for e := chanList.Front(); e != nil; {
-		var c = e.Value.(conn)
+		var c = e.Value.(*conn)
 		go func() {
 			c.bytec <- data
 		}()
@@ -47,21 +55,79 @@ func broadcastBMessage(data []byte) {
 	wsMutex.Unlock()
 }
 
+// broadcastDiagnosticsMessage sends data only to the clients that asked for the diagnostics
+func broadcastDiagnosticsMessage(data string) {
+	wsMutex.Lock()
+	for e := chanList.Front(); e != nil; e = e.Next() {
+		var c = e.Value.(*conn)
+		if c.diagnostics {
+			go func() {
+				c.stringc <- data
+			}()
+		}
+	}
+	wsMutex.Unlock()
+}
+
+// hasDiagnosticsClients returns if any client asked for the diagnostics
+func hasDiagnosticsClients() bool {
+	wsMutex.Lock()
+	defer wsMutex.Unlock()
+
+	for e := chanList.Front(); e != nil; e = e.Next() {
+		if e.Value.(*conn).diagnostics {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readMessages handles the client requests until the connection is closed
+func readMessages(c *websocket.Conn, cl *conn, closed chan struct{}) {
+	defer close(closed)
+
+	for {
+		_, data, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg clientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Println("Invalid message from", c.RemoteAddr(), ":", err)
+			continue
+		}
+
+		switch msg.MessageType {
+		case "diagnostics":
+			wsMutex.Lock()
+			cl.diagnostics = msg.Enabled
+			wsMutex.Unlock()
+		default:
+			log.Println("Unknown message type", msg.MessageType, "from", c.RemoteAddr())
+		}
+	}
+}
+
 func handleMessages(c *websocket.Conn) {
 
 	var cChannel = make(chan string)
 	var bChannel = make(chan []byte)
-	wsMutex.Lock()
-	var li = chanList.PushBack(conn{
+	var cl = &conn{
 		stringc: cChannel,
 		bytec:   bChannel,
-	})
+	}
+	wsMutex.Lock()
+	var li = chanList.PushBack(cl)
 	wsMutex.Unlock()
 	defer closeN(li)
 
 	// region Send DeviceInfo
 	log.Println("New connection from", c.RemoteAddr())
+	deviceLock.Lock()
 	m, err := json.Marshal(currDevice)
+	deviceLock.Unlock()
 	if err != nil {
 		log.Println("Error serializing JSON: ", err)
 	}
@@ -73,13 +139,14 @@ func handleMessages(c *websocket.Conn) {
 	}
 	// endregion
 	// region Client Loop
+	var closed = make(chan struct{})
+	go readMessages(c, cl, closed)
+
 	running := true
 	for running {
-		//_, _, err := c.ReadMessage()
-		//if err != nil {
-		//	break
-		//}
 		select {
+		case <-closed:
+			running = false
 		case msg := <-cChannel:
 			err = c.WriteMessage(websocket.TextMessage, []byte(msg))
 			if err != nil {
@@ -107,5 +174,6 @@ func ws(w http.ResponseWriter, r *http.Request) {
 		log.Print("upgrade:", err)
 		return
 	}
+	defer c.Close()
 	handleMessages(c)
 }